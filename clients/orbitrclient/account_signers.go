@@ -0,0 +1,17 @@
+package orbitrclient
+
+import (
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/txnbuild"
+)
+
+// AccountSigners returns the signers and thresholds of account, as used to
+// check the signatures of a transaction with SignatureStatus of txnbuild.
+func AccountSigners(account hProtocol.Account) txnbuild.AccountSigners {
+	return txnbuild.AccountSigners{
+		Signers:         account.SignerSummary(),
+		LowThreshold:    txnbuild.Threshold(account.Thresholds.LowThreshold),
+		MediumThreshold: txnbuild.Threshold(account.Thresholds.MedThreshold),
+		HighThreshold:   txnbuild.Threshold(account.Thresholds.HighThreshold),
+	}
+}
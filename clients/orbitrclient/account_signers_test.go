@@ -0,0 +1,49 @@
+package orbitrclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lantah/go/network"
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/txnbuild"
+)
+
+func TestAccountSigners(t *testing.T) {
+	account := hProtocol.Account{
+		AccountID: managerKP.Address(),
+		Sequence:  1,
+		Signers: []hProtocol.Signer{
+			{Key: managerKP.Address(), Weight: 1},
+			{Key: feeBumpKP.Address(), Weight: 1},
+		},
+		Thresholds: hProtocol.AccountThresholds{LowThreshold: 1, MedThreshold: 2, HighThreshold: 2},
+	}
+	signers := AccountSigners(account)
+	assert.Equal(t, txnbuild.AccountSigners{
+		Signers:         txnbuild.SignerSummary{managerKP.Address(): 1, feeBumpKP.Address(): 1},
+		LowThreshold:    1,
+		MediumThreshold: 2,
+		HighThreshold:   2,
+	}, signers)
+
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &account,
+		IncrementSequenceNum: true,
+		Operations:           managerPayment(),
+		BaseFee:              txnbuild.MinBaseFee,
+		Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()},
+	})
+	require.NoError(t, err)
+	tx, err = tx.Sign(network.TestNetworkPassphrase, managerKP)
+	require.NoError(t, err)
+
+	status, err := tx.SignatureStatus(network.TestNetworkPassphrase, map[string]txnbuild.AccountSigners{
+		account.AccountID: signers,
+	})
+	require.NoError(t, err)
+	require.Len(t, status.Unmet(), 1)
+	assert.Equal(t, []string{feeBumpKP.Address()}, status.Unmet()[0].Missing)
+}
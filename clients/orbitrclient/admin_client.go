@@ -112,5 +112,116 @@ func (c *AdminClient) SetIngestionAccountFilter(filter hProtocol.AccountFilterCo
 	return c.sendHTTPRequest(req, nil)
 }
 
+func (c *AdminClient) GetIngestionOperationFilter() (hProtocol.OperationFilterConfig, error) {
+	var filter hProtocol.OperationFilterConfig
+	err := c.sendGetRequest(c.getIngestionFiltersURL("operation"), &filter)
+	return filter, err
+}
+
+func (c *AdminClient) GetIngestionContractFilter() (hProtocol.ContractFilterConfig, error) {
+	var filter hProtocol.ContractFilterConfig
+	err := c.sendGetRequest(c.getIngestionFiltersURL("contract"), &filter)
+	return filter, err
+}
+
+func (c *AdminClient) SetIngestionOperationFilter(filter hProtocol.OperationFilterConfig) error {
+	buf := bytes.NewBuffer(nil)
+	err := json.NewEncoder(buf).Encode(filter)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, c.getIngestionFiltersURL("operation"), buf)
+	if err != nil {
+		return errors.Wrap(err, "error creating HTTP request")
+	}
+	req.Header.Add("Content-Type", "application/json")
+	return c.sendHTTPRequest(req, nil)
+}
+
+func (c *AdminClient) SetIngestionContractFilter(filter hProtocol.ContractFilterConfig) error {
+	buf := bytes.NewBuffer(nil)
+	err := json.NewEncoder(buf).Encode(filter)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, c.getIngestionFiltersURL("contract"), buf)
+	if err != nil {
+		return errors.Wrap(err, "error creating HTTP request")
+	}
+	req.Header.Add("Content-Type", "application/json")
+	return c.sendHTTPRequest(req, nil)
+}
+
+// GetLedgerGaps returns the ranges of ledgers missing from orbitr's history
+// database. If start and end are not zero only the gaps within [start, end]
+// are returned.
+func (c *AdminClient) GetLedgerGaps(start, end uint32) (hProtocol.LedgerGaps, error) {
+	var gaps hProtocol.LedgerGaps
+	requestURL := fmt.Sprintf("%s/ingestion/gaps", c.baseURL)
+	if start > 0 || end > 0 {
+		requestURL += fmt.Sprintf("?start=%d&end=%d", start, end)
+	}
+	err := c.sendGetRequest(requestURL, &gaps)
+	return gaps, err
+}
+
+func (c *AdminClient) getReingestJobsURL() string {
+	return fmt.Sprintf("%s/ingestion/reingest/jobs", c.baseURL)
+}
+
+func (c *AdminClient) getReingestJobURL(id string) string {
+	return fmt.Sprintf("%s/%s", c.getReingestJobsURL(), url.PathEscape(id))
+}
+
+// Reingest enqueues a job reingesting the requested ledger ranges, or the
+// detected gaps if request.FillGaps is set. The job is run in the background,
+// use GetReingestJob to follow its progress.
+func (c *AdminClient) Reingest(request hProtocol.ReingestRequest) (hProtocol.ReingestJob, error) {
+	var job hProtocol.ReingestJob
+	buf := bytes.NewBuffer(nil)
+	err := json.NewEncoder(buf).Encode(request)
+	if err != nil {
+		return job, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.getReingestJobsURL(), buf)
+	if err != nil {
+		return job, errors.Wrap(err, "error creating HTTP request")
+	}
+	req.Header.Add("Content-Type", "application/json")
+	err = c.sendHTTPRequest(req, &job)
+	return job, err
+}
+
+// FillLedgerGaps enqueues a job reingesting all the ledgers missing from
+// orbitr's history database.
+func (c *AdminClient) FillLedgerGaps() (hProtocol.ReingestJob, error) {
+	return c.Reingest(hProtocol.ReingestRequest{FillGaps: true})
+}
+
+// GetReingestJobs returns all the reingestion jobs.
+func (c *AdminClient) GetReingestJobs() (hProtocol.ReingestJobs, error) {
+	var jobs hProtocol.ReingestJobs
+	err := c.sendGetRequest(c.getReingestJobsURL(), &jobs)
+	return jobs, err
+}
+
+// GetReingestJob returns the status and progress of a reingestion job.
+func (c *AdminClient) GetReingestJob(id string) (hProtocol.ReingestJob, error) {
+	var job hProtocol.ReingestJob
+	err := c.sendGetRequest(c.getReingestJobURL(id), &job)
+	return job, err
+}
+
+// CancelReingestJob cancels a queued reingestion job or stops a running one.
+func (c *AdminClient) CancelReingestJob(id string) (hProtocol.ReingestJob, error) {
+	var job hProtocol.ReingestJob
+	req, err := http.NewRequest(http.MethodDelete, c.getReingestJobURL(id), nil)
+	if err != nil {
+		return job, errors.Wrap(err, "error creating HTTP request")
+	}
+	err = c.sendHTTPRequest(req, &job)
+	return job, err
+}
+
 // ensure that the orbitr admin client implements AdminClientInterface
 var _ AdminClientInterface = &AdminClient{}
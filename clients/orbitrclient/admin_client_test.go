@@ -5,6 +5,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/support/http/httptest"
 )
 
 func TestDefaultAdminHostPort(t *testing.T) {
@@ -22,3 +25,94 @@ func TestOverrideAdminHostPort(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "http://127.0.0.1:1234/ingestion/filters/test", fullAdminURL)
 }
+
+func TestReingestJobs(t *testing.T) {
+	hmock := httptest.NewClient()
+	orbitrAdminClient, err := NewAdminClient(0, "", 0)
+	require.NoError(t, err)
+	orbitrAdminClient.http = hmock
+
+	jobResponse := `{
+  "id": "1",
+  "status": "running",
+  "ranges": [{"start": 10, "end": 20}],
+  "force": false,
+  "total_ledgers": 11,
+  "ingested_ledgers": 3,
+  "current_ledger": 12,
+  "created_at": "2022-01-01T00:00:00Z",
+  "started_at": "2022-01-01T00:00:01Z"
+}`
+
+	hmock.On("GET", "http://localhost:4200/ingestion/gaps?start=1&end=100").
+		ReturnString(200, `{"gaps": [{"start": 10, "end": 20}]}`)
+	gaps, err := orbitrAdminClient.GetLedgerGaps(1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []hProtocol.LedgerRange{{Start: 10, End: 20}}, gaps.Gaps)
+
+	hmock.On("POST", "http://localhost:4200/ingestion/reingest/jobs").
+		ReturnString(202, jobResponse)
+	job, err := orbitrAdminClient.FillLedgerGaps()
+	require.NoError(t, err)
+	assert.Equal(t, "1", job.ID)
+	assert.Equal(t, "running", job.Status)
+	assert.Equal(t, uint32(3), job.IngestedLedgers)
+	assert.Nil(t, job.FinishedAt)
+
+	hmock.On("GET", "http://localhost:4200/ingestion/reingest/jobs").
+		ReturnString(200, `{"jobs": [`+jobResponse+`]}`)
+	jobs, err := orbitrAdminClient.GetReingestJobs()
+	require.NoError(t, err)
+	assert.Equal(t, []hProtocol.ReingestJob{job}, jobs.Jobs)
+
+	hmock.On("GET", "http://localhost:4200/ingestion/reingest/jobs/1").
+		ReturnString(200, jobResponse)
+	job, err = orbitrAdminClient.GetReingestJob("1")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(12), job.CurrentLedger)
+
+	hmock.On("DELETE", "http://localhost:4200/ingestion/reingest/jobs/2").
+		ReturnString(404, `{"type": "https://lantah.network/orbitr-errors/not_found", "title": "Resource Missing", "status": 404}`)
+	_, err = orbitrAdminClient.CancelReingestJob("2")
+	require.Error(t, err)
+	orbitrError, ok := err.(*Error)
+	require.True(t, ok)
+	assert.Equal(t, 404, orbitrError.Problem.Status)
+}
+
+func TestIngestionOperationAndContractFilters(t *testing.T) {
+	hmock := httptest.NewClient()
+	orbitrAdminClient, err := NewAdminClient(0, "", 0)
+	require.NoError(t, err)
+	orbitrAdminClient.http = hmock
+
+	hmock.On("GET", "http://localhost:4200/ingestion/filters/operation").
+		ReturnString(200, `{"whitelist": ["payment"], "blacklist": ["bump_sequence"], "enabled": true, "last_modified": 1647121423}`)
+	operationFilter, err := orbitrAdminClient.GetIngestionOperationFilter()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"payment"}, operationFilter.Whitelist)
+	assert.Equal(t, []string{"bump_sequence"}, operationFilter.Blacklist)
+	assert.True(t, *operationFilter.Enabled)
+	assert.Equal(t, int64(1647121423), operationFilter.LastModified)
+
+	hmock.On("GET", "http://localhost:4200/ingestion/filters/contract").
+		ReturnString(200, `{"whitelist": ["CDLZFC3SYJYDZT7K67VZ75HPJVIEUVNIXF47ZG2FB2RMQQVU2HHGCYSC"], "enabled": false}`)
+	contractFilter, err := orbitrAdminClient.GetIngestionContractFilter()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CDLZFC3SYJYDZT7K67VZ75HPJVIEUVNIXF47ZG2FB2RMQQVU2HHGCYSC"}, contractFilter.Whitelist)
+	assert.False(t, *contractFilter.Enabled)
+
+	enabled := true
+	hmock.On("PUT", "http://localhost:4200/ingestion/filters/operation").ReturnString(200, `{}`)
+	assert.NoError(t, orbitrAdminClient.SetIngestionOperationFilter(hProtocol.OperationFilterConfig{
+		Whitelist: []string{"payment"},
+		Blacklist: []string{},
+		Enabled:   &enabled,
+	}))
+
+	hmock.On("PUT", "http://localhost:4200/ingestion/filters/contract").ReturnString(200, `{}`)
+	assert.NoError(t, orbitrAdminClient.SetIngestionContractFilter(hProtocol.ContractFilterConfig{
+		Whitelist: []string{"CDLZFC3SYJYDZT7K67VZ75HPJVIEUVNIXF47ZG2FB2RMQQVU2HHGCYSC"},
+		Enabled:   &enabled,
+	}))
+}
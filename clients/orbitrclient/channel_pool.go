@@ -0,0 +1,365 @@
+package orbitrclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lantah/go/keypair"
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/txnbuild"
+)
+
+// channelsPerTransaction is the number of channel accounts created by a
+// single transaction, which is the maximum number of operations in a
+// transaction.
+const channelsPerTransaction = 100
+
+// ErrChannelPoolClosed is returned by the submissions to a closed
+// ChannelPool.
+var ErrChannelPoolClosed = errors.New("channel pool closed")
+
+// ChannelPoolConfig configures a ChannelPool.
+type ChannelPoolConfig struct {
+	// Client submits the transactions.
+	Client ClientInterface
+	// NetworkPassphrase is the passphrase of the network the transactions
+	// are signed for.
+	NetworkPassphrase string
+	// MainAccount is the source account of the operations submitted through
+	// the pool. It funds the channel accounts created by the pool and
+	// receives the balance of the channel accounts merged when the pool is
+	// closed.
+	MainAccount *keypair.Full
+	// Channels are existing channel accounts, which are loaded rather than
+	// created.
+	Channels []*keypair.Full
+	// NumChannels is the number of channel accounts of the pool. The missing
+	// channel accounts, Channels included, are created by the pool.
+	NumChannels int
+	// ChannelBalance is the starting balance of the channel accounts created
+	// by the pool.
+	ChannelBalance string
+	// BaseFee is the base fee of the transactions, txnbuild.MinBaseFee if
+	// zero.
+	BaseFee int64
+	// TransactionTimeout is how long transactions are valid after they are
+	// built, 5 minutes if zero.
+	TransactionTimeout time.Duration
+	// SubmitTxOpts are the options of the submissions.
+	SubmitTxOpts SubmitTxOpts
+}
+
+// ChannelPool submits transactions on behalf of a main account using a pool
+// of channel accounts, so that many transactions of the main account can be
+// included in the same ledger. Each transaction is submitted with an idle
+// channel account as its source account and the main account as the source
+// account of its operations, and is signed by both. The sequence numbers of
+// the channel accounts are tracked by the pool, they must not be used as
+// source accounts elsewhere while the pool is open.
+type ChannelPool struct {
+	config   ChannelPoolConfig
+	channels []*poolChannel
+	idle     chan *poolChannel
+
+	lock   sync.Mutex
+	closed bool
+}
+
+type poolChannel struct {
+	keypair  *keypair.Full
+	sequence int64
+	// stale is set when the outcome of the last transaction of the channel is
+	// unknown, in which case its sequence number is reloaded before it is
+	// used again.
+	stale bool
+}
+
+// NewChannelPool returns a ChannelPool, loading the channel accounts in
+// config.Channels and creating the missing ones.
+func NewChannelPool(ctx context.Context, config ChannelPoolConfig) (*ChannelPool, error) {
+	if config.MainAccount == nil {
+		return nil, errors.New("main account is required")
+	}
+	if config.BaseFee == 0 {
+		config.BaseFee = txnbuild.MinBaseFee
+	}
+	if config.TransactionTimeout == 0 {
+		config.TransactionTimeout = defaultTransactionTimeout
+	}
+	if config.NumChannels < len(config.Channels) {
+		config.NumChannels = len(config.Channels)
+	}
+	if config.NumChannels == 0 {
+		return nil, errors.New("channel pool has no channel accounts")
+	}
+
+	p := &ChannelPool{
+		config: config,
+		idle:   make(chan *poolChannel, config.NumChannels),
+	}
+
+	var missing []*keypair.Full
+	for _, kp := range config.Channels {
+		channel := &poolChannel{keypair: kp}
+		err := p.loadSequence(ctx, channel)
+		if IsNotFoundError(err) {
+			missing = append(missing, kp)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.channels = append(p.channels, channel)
+	}
+	for i := len(config.Channels); i < config.NumChannels; i++ {
+		kp, err := keypair.Random()
+		if err != nil {
+			return nil, errors.Wrap(err, "error generating channel account")
+		}
+		missing = append(missing, kp)
+	}
+
+	for len(missing) > 0 {
+		batch := missing
+		if len(batch) > channelsPerTransaction {
+			batch = batch[:channelsPerTransaction]
+		}
+		if err := p.createChannels(ctx, batch); err != nil {
+			return nil, err
+		}
+		missing = missing[len(batch):]
+	}
+
+	for _, channel := range p.channels {
+		p.idle <- channel
+	}
+	return p, nil
+}
+
+// Channels returns the keypairs of the channel accounts of the pool, which
+// can be saved to load the channel accounts created by the pool next time.
+func (p *ChannelPool) Channels() []*keypair.Full {
+	keypairs := make([]*keypair.Full, len(p.channels))
+	for i, channel := range p.channels {
+		keypairs[i] = channel.keypair
+	}
+	return keypairs
+}
+
+// createChannels creates the channel accounts of keypairs with a single
+// transaction of the main account.
+func (p *ChannelPool) createChannels(ctx context.Context, keypairs []*keypair.Full) error {
+	if p.config.ChannelBalance == "" {
+		return errors.New("channel balance is required to create channel accounts")
+	}
+
+	mainAccount, err := p.config.Client.AccountDetailWithContext(ctx, AccountRequest{AccountID: p.config.MainAccount.Address()})
+	if err != nil {
+		return errors.Wrap(err, "error loading main account")
+	}
+
+	ops := make([]txnbuild.Operation, len(keypairs))
+	for i, kp := range keypairs {
+		ops[i] = &txnbuild.CreateAccount{
+			Destination: kp.Address(),
+			Amount:      p.config.ChannelBalance,
+		}
+	}
+	tx, err := p.buildTransaction(&mainAccount, ops, nil, p.config.MainAccount)
+	if err != nil {
+		return err
+	}
+	if _, err = p.config.Client.SubmitTransactionWithOptionsWithContext(ctx, tx, p.config.SubmitTxOpts); err != nil {
+		return errors.Wrap(err, "error creating channel accounts")
+	}
+
+	for _, kp := range keypairs {
+		channel := &poolChannel{keypair: kp}
+		if err := p.loadSequence(ctx, channel); err != nil {
+			return err
+		}
+		p.channels = append(p.channels, channel)
+	}
+	return nil
+}
+
+func (p *ChannelPool) loadSequence(ctx context.Context, channel *poolChannel) error {
+	account, err := p.config.Client.AccountDetailWithContext(ctx, AccountRequest{AccountID: channel.keypair.Address()})
+	if err != nil {
+		return errors.Wrapf(err, "error loading channel account %s", channel.keypair.Address())
+	}
+	channel.sequence = account.Sequence
+	channel.stale = false
+	return nil
+}
+
+func (p *ChannelPool) buildTransaction(
+	source txnbuild.Account,
+	ops []txnbuild.Operation,
+	memo txnbuild.Memo,
+	signers ...*keypair.Full,
+) (*txnbuild.Transaction, error) {
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        source,
+		IncrementSequenceNum: true,
+		Operations:           ops,
+		BaseFee:              p.config.BaseFee,
+		Memo:                 memo,
+		Preconditions: txnbuild.Preconditions{
+			TimeBounds: txnbuild.NewTimeout(int64(p.config.TransactionTimeout / time.Second)),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error building transaction")
+	}
+	tx, err = tx.Sign(p.config.NetworkPassphrase, signers...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error signing transaction")
+	}
+	return tx, nil
+}
+
+// acquire returns an idle channel account, waiting for one if they are all
+// in use.
+func (p *ChannelPool) acquire(ctx context.Context) (*poolChannel, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case channel, ok := <-p.idle:
+		if !ok {
+			return nil, ErrChannelPoolClosed
+		}
+		return channel, nil
+	}
+}
+
+// Submit submits ops with an idle channel account as the source account of
+// the transaction. The operations without a source account are submitted
+// with the main account as their source account. Submit waits for a channel
+// account to be idle if they are all in use, and is safe for concurrent use.
+func (p *ChannelPool) Submit(ctx context.Context, ops []txnbuild.Operation, memo txnbuild.Memo) (hProtocol.Transaction, error) {
+	var resp hProtocol.Transaction
+	p.lock.Lock()
+	closed := p.closed
+	p.lock.Unlock()
+	if closed {
+		return resp, ErrChannelPoolClosed
+	}
+
+	channelOps := make([]txnbuild.Operation, len(ops))
+	for i, op := range ops {
+		var err error
+		channelOps[i], err = txnbuild.OperationWithSourceAccount(op, p.config.MainAccount.Address())
+		if err != nil {
+			return resp, errors.Wrapf(err, "error setting source account of operation %d", i)
+		}
+	}
+
+	channel, err := p.acquire(ctx)
+	if err != nil {
+		return resp, err
+	}
+	defer func() { p.idle <- channel }()
+
+	if channel.stale {
+		if err = p.loadSequence(ctx, channel); err != nil {
+			return resp, err
+		}
+	}
+
+	source := &txnbuild.SimpleAccount{AccountID: channel.keypair.Address(), Sequence: channel.sequence}
+	tx, err := p.buildTransaction(source, channelOps, memo, channel.keypair, p.config.MainAccount)
+	if err != nil {
+		return resp, err
+	}
+
+	resp, err = p.config.Client.SubmitTransactionWithOptionsWithContext(ctx, tx, p.config.SubmitTxOpts)
+	if err == nil || isConsumedSequenceError(err) {
+		channel.sequence = tx.SequenceNumber()
+	} else {
+		channel.stale = true
+	}
+	return resp, err
+}
+
+// Close waits for the submissions in progress and merges the channel
+// accounts into the main account. The pool cannot be used afterwards, unless
+// ctx is done before the submissions in progress complete. The channel
+// accounts which could not be merged are returned by Channels.
+func (p *ChannelPool) Close(ctx context.Context) error {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.lock.Unlock()
+
+	channels := make([]*poolChannel, 0, len(p.channels))
+	for len(channels) < len(p.channels) {
+		channel, err := p.acquire(ctx)
+		if err != nil {
+			// the pool is left open so that Close can be called again
+			for _, channel := range channels {
+				p.idle <- channel
+			}
+			p.lock.Lock()
+			p.closed = false
+			p.lock.Unlock()
+			return err
+		}
+		channels = append(channels, channel)
+	}
+	close(p.idle)
+
+	var remaining []*poolChannel
+	var mergeErr error
+	for _, channel := range channels {
+		if err := p.merge(ctx, channel); err != nil {
+			remaining = append(remaining, channel)
+			if mergeErr == nil {
+				mergeErr = err
+			}
+		}
+	}
+	p.channels = remaining
+	return mergeErr
+}
+
+// merge merges channel into the main account.
+func (p *ChannelPool) merge(ctx context.Context, channel *poolChannel) error {
+	if channel.stale {
+		if err := p.loadSequence(ctx, channel); err != nil {
+			return err
+		}
+	}
+
+	source := &txnbuild.SimpleAccount{AccountID: channel.keypair.Address(), Sequence: channel.sequence}
+	ops := []txnbuild.Operation{&txnbuild.AccountMerge{Destination: p.config.MainAccount.Address()}}
+	tx, err := p.buildTransaction(source, ops, nil, channel.keypair)
+	if err != nil {
+		return err
+	}
+	if _, err = p.config.Client.SubmitTransactionWithOptionsWithContext(ctx, tx, p.config.SubmitTxOpts); err != nil {
+		channel.stale = true
+		return errors.Wrapf(err, "error merging channel account %s", channel.keypair.Address())
+	}
+	return nil
+}
+
+// isConsumedSequenceError returns true if err is a submission error for a
+// transaction included in a ledger despite failing, which consumes its
+// sequence number.
+func isConsumedSequenceError(err error) bool {
+	hErr := GetError(err)
+	if hErr == nil {
+		return false
+	}
+	codes, err := hErr.ResultCodes()
+	if err != nil {
+		return false
+	}
+	return codes.TransactionCode == "tx_failed"
+}
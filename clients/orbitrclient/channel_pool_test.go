@@ -0,0 +1,83 @@
+package orbitrclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lantah/go/keypair"
+	"github.com/lantah/go/network"
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/txnbuild"
+)
+
+func TestChannelPool(t *testing.T) {
+	ctx := context.Background()
+	mainKP := managerKP
+	existingKP := keypair.MustParseFull("SA5ZEFDVFZ52GRU7YUGR6EDPBNRU2WLA6IQFQ7S2IH2DG3VFV3DOMV2Q")
+
+	client := &MockClient{}
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: existingKP.Address()}).
+		Return(hProtocol.Account{Sequence: 500}, nil)
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: mainKP.Address()}).
+		Return(hProtocol.Account{AccountID: mainKP.Address(), Sequence: 100}, nil).Once()
+	client.On("AccountDetailWithContext", mock.Anything, mock.Anything).
+		Return(hProtocol.Account{Sequence: 7000}, nil).Once()
+	var submitted []*txnbuild.Transaction
+	client.On("SubmitTransactionWithOptionsWithContext", mock.Anything, mock.Anything, SubmitTxOpts{}).
+		Run(func(args mock.Arguments) { submitted = append(submitted, args.Get(1).(*txnbuild.Transaction)) }).
+		Return(hProtocol.Transaction{Successful: true}, nil)
+
+	pool, err := NewChannelPool(ctx, ChannelPoolConfig{
+		Client:            client,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		MainAccount:       mainKP,
+		Channels:          []*keypair.Full{existingKP},
+		NumChannels:       2,
+		ChannelBalance:    "10",
+	})
+	require.NoError(t, err)
+	channels := pool.Channels()
+	require.Len(t, channels, 2)
+	assert.Equal(t, existingKP, channels[0])
+
+	// the missing channel account is created by the main account
+	require.Len(t, submitted, 1)
+	assert.Equal(t, mainKP.Address(), submitted[0].SourceAccount().AccountID)
+	assert.Equal(t, int64(101), submitted[0].SequenceNumber())
+	require.Len(t, submitted[0].Operations(), 1)
+	assert.Equal(t, channels[1].Address(), submitted[0].Operations()[0].(*txnbuild.CreateAccount).Destination)
+
+	// the operations are submitted by a channel account on behalf of the main
+	// account
+	_, err = pool.Submit(ctx, managerPayment(), nil)
+	require.NoError(t, err)
+	require.Len(t, submitted, 2)
+	tx := submitted[1]
+	assert.Equal(t, existingKP.Address(), tx.SourceAccount().AccountID)
+	assert.Equal(t, int64(501), tx.SequenceNumber())
+	assert.Equal(t, mainKP.Address(), tx.Operations()[0].GetSourceAccount())
+	assert.Len(t, tx.Signatures(), 2)
+
+	// the channel accounts are merged into the main account
+	require.NoError(t, pool.Close(ctx))
+	require.Len(t, submitted, 4)
+	merged := map[string]int64{}
+	for _, tx := range submitted[2:] {
+		merge := tx.Operations()[0].(*txnbuild.AccountMerge)
+		assert.Equal(t, mainKP.Address(), merge.Destination)
+		merged[tx.SourceAccount().AccountID] = tx.SequenceNumber()
+	}
+	assert.Equal(t, map[string]int64{
+		existingKP.Address():  502,
+		channels[1].Address(): 7001,
+	}, merged)
+	assert.Empty(t, pool.Channels())
+
+	_, err = pool.Submit(ctx, managerPayment(), nil)
+	assert.Equal(t, ErrChannelPoolClosed, err)
+	client.AssertExpectations(t)
+}
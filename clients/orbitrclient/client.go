@@ -25,18 +25,18 @@ import (
 )
 
 // sendRequest builds the URL for the given orbitr request and sends the url to a orbitr server
-func (c *Client) sendRequest(hr OrbitRRequest, resp interface{}) (err error) {
+func (c *Client) sendRequest(ctx context.Context, hr OrbitRRequest, resp interface{}) (err error) {
 	req, err := hr.HTTPRequest(c.fixOrbitRURL())
 	if err != nil {
 		return err
 	}
 
-	return c.sendHTTPRequest(req, resp)
+	return c.sendHTTPRequest(ctx, req, resp)
 }
 
 // checkMemoRequired implements a memo required check as defined in
 // https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0029.md
-func (c *Client) checkMemoRequired(transaction *txnbuild.Transaction) error {
+func (c *Client) checkMemoRequired(ctx context.Context, transaction *txnbuild.Transaction) error {
 	destinations := map[string]bool{}
 
 	for i, op := range transaction.Operations() {
@@ -77,7 +77,7 @@ func (c *Client) checkMemoRequired(transaction *txnbuild.Transaction) error {
 			DataKey:   "config.memo_required",
 		}
 
-		data, err := c.AccountData(request)
+		data, err := c.AccountDataWithContext(ctx, request)
 		if err != nil {
 			orbitrError := GetError(err)
 
@@ -101,35 +101,23 @@ func (c *Client) checkMemoRequired(transaction *txnbuild.Transaction) error {
 
 // sendGetRequest sends a HTTP GET request to a orbitr server.
 // It can be used for requests that do not implement the OrbitRRequest interface.
-func (c *Client) sendGetRequest(requestURL string, a interface{}) error {
+func (c *Client) sendGetRequest(ctx context.Context, requestURL string, a interface{}) error {
 	req, err := http.NewRequest("GET", requestURL, nil)
 	if err != nil {
 		return errors.Wrap(err, "error creating HTTP request")
 	}
-	return c.sendHTTPRequest(req, a)
+	return c.sendHTTPRequest(ctx, req, a)
 }
 
-func (c *Client) sendHTTPRequest(req *http.Request, a interface{}) error {
-	c.setClientAppHeaders(req)
-	c.setDefaultClient()
-
-	if c.orbitrTimeout == 0 {
-		c.orbitrTimeout = OrbitRTimeout
-	}
-	ctx, cancel := context.WithTimeout(context.Background(), c.orbitrTimeout)
-	defer cancel()
-
-	if resp, err := c.HTTP.Do(req.WithContext(ctx)); err != nil {
-		return err
-	} else {
-		return decodeResponse(resp, a, c.OrbitRURL, c.clock)
-	}
-}
-
-// stream handles connections to endpoints that support streaming on a orbitr server
+// stream handles connections to endpoints that support streaming on a orbitr server.
+// When the client has a CursorStore, the stream resumes from the saved cursor, if any,
+// and saves the cursor of every event once handler returns, so that events are delivered
+// at least once. ledgerCursors must be true when the paging tokens of the events are made
+// of toids, in which case the stream is checked for gaps every time it resumes.
 func (c *Client) stream(
 	ctx context.Context,
 	streamURL string,
+	ledgerCursors bool,
 	handler func(data []byte) error,
 ) error {
 	su, err := url.Parse(streamURL)
@@ -138,11 +126,28 @@ func (c *Client) stream(
 	}
 
 	query := su.Query()
+	var cursorKey string
+	if c.CursorStore != nil {
+		cursorKey = streamCursorKey(su.Path, query)
+		cursor, err := c.CursorStore.LoadCursor(ctx, cursorKey)
+		if err != nil {
+			return errors.Wrap(err, "error loading stream cursor")
+		}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+	}
 	if query.Get("cursor") == "" {
 		query.Set("cursor", "now")
 	}
 
 	for {
+		if cursor := query.Get("cursor"); c.CursorStore != nil && ledgerCursors && cursor != "now" {
+			if err := c.checkStreamGap(ctx, cursor); err != nil {
+				return err
+			}
+		}
+
 		// updates the url with new cursor
 		su.RawQuery = query.Encode()
 		req, err := http.NewRequest("GET", su.String(), nil)
@@ -248,11 +253,33 @@ func (c *Client) stream(
 				if err != nil {
 					return err
 				}
+
+				if c.CursorStore != nil && event.Id != "" {
+					if err = c.CursorStore.SaveCursor(ctx, cursorKey, event.Id); err != nil {
+						return errors.Wrap(err, "error saving stream cursor")
+					}
+				}
 			}
 		}
 	}
 }
 
+// streamCursorKey returns the key identifying the stream with the given path
+// and query in a CursorStore.
+func streamCursorKey(path string, query url.Values) string {
+	params := url.Values{}
+	for k, v := range query {
+		if k != "cursor" {
+			params[k] = v
+		}
+	}
+	key := strings.TrimPrefix(path, "/")
+	if len(params) > 0 {
+		key += "?" + params.Encode()
+	}
+	return key
+}
+
 func (c *Client) setClientAppHeaders(req *http.Request) {
 	req.Header.Set("X-Client-Name", "go-stellar-sdk")
 	req.Header.Set("X-Client-Version", c.Version())
@@ -279,7 +306,8 @@ func (c *Client) fixOrbitRURL() string {
 }
 
 // SetOrbitRTimeout allows users to set the timeout before a orbitr request is canceled.
-// The timeout is specified as a time.Duration which is in nanoseconds.
+// The timeout is specified as a time.Duration which is in nanoseconds. It does not
+// apply to requests made with a context that has a deadline.
 func (c *Client) SetOrbitRTimeout(t time.Duration) *Client {
 	c.orbitrTimeout = t
 	return c
@@ -294,13 +322,23 @@ func (c *Client) OrbitRTimeout() time.Duration {
 // have a trustline to an asset.
 // See https://developers.stellar.org/api/resources/accounts/
 func (c *Client) Accounts(request AccountsRequest) (accounts hProtocol.AccountsPage, err error) {
-	err = c.sendRequest(request, &accounts)
+	return c.AccountsWithContext(context.Background(), request)
+}
+
+// AccountsWithContext is like Accounts but the request is bound to ctx.
+func (c *Client) AccountsWithContext(ctx context.Context, request AccountsRequest) (accounts hProtocol.AccountsPage, err error) {
+	err = c.sendRequest(ctx, request, &accounts)
 	return
 }
 
 // AccountDetail returns information for a single account.
 // See https://developers.stellar.org/api/resources/accounts/single/
 func (c *Client) AccountDetail(request AccountRequest) (account hProtocol.Account, err error) {
+	return c.AccountDetailWithContext(context.Background(), request)
+}
+
+// AccountDetailWithContext is like AccountDetail but the request is bound to ctx.
+func (c *Client) AccountDetailWithContext(ctx context.Context, request AccountRequest) (account hProtocol.Account, err error) {
 	if request.AccountID == "" {
 		err = errors.New("no account ID provided")
 	}
@@ -309,13 +347,18 @@ func (c *Client) AccountDetail(request AccountRequest) (account hProtocol.Accoun
 		return
 	}
 
-	err = c.sendRequest(request, &account)
+	err = c.sendRequest(ctx, request, &account)
 	return
 }
 
 // AccountData returns a single data associated with a given account
 // See https://developers.stellar.org/api/resources/accounts/data/
 func (c *Client) AccountData(request AccountRequest) (accountData hProtocol.AccountData, err error) {
+	return c.AccountDataWithContext(context.Background(), request)
+}
+
+// AccountDataWithContext is like AccountData but the request is bound to ctx.
+func (c *Client) AccountDataWithContext(ctx context.Context, request AccountRequest) (accountData hProtocol.AccountData, err error) {
 	if request.AccountID == "" || request.DataKey == "" {
 		err = errors.New("too few parameters")
 	}
@@ -324,34 +367,54 @@ func (c *Client) AccountData(request AccountRequest) (accountData hProtocol.Acco
 		return
 	}
 
-	err = c.sendRequest(request, &accountData)
+	err = c.sendRequest(ctx, request, &accountData)
 	return
 }
 
 // Effects returns effects (https://developers.stellar.org/api/resources/effects/)
 // It can be used to return effects for an account, a ledger, an operation, a transaction and all effects on the network.
 func (c *Client) Effects(request EffectRequest) (effects effects.EffectsPage, err error) {
-	err = c.sendRequest(request, &effects)
+	return c.EffectsWithContext(context.Background(), request)
+}
+
+// EffectsWithContext is like Effects but the request is bound to ctx.
+func (c *Client) EffectsWithContext(ctx context.Context, request EffectRequest) (effects effects.EffectsPage, err error) {
+	err = c.sendRequest(ctx, request, &effects)
 	return
 }
 
 // Assets returns asset information.
 // See https://developers.stellar.org/api/resources/assets/list/
 func (c *Client) Assets(request AssetRequest) (assets hProtocol.AssetsPage, err error) {
-	err = c.sendRequest(request, &assets)
+	return c.AssetsWithContext(context.Background(), request)
+}
+
+// AssetsWithContext is like Assets but the request is bound to ctx.
+func (c *Client) AssetsWithContext(ctx context.Context, request AssetRequest) (assets hProtocol.AssetsPage, err error) {
+	err = c.sendRequest(ctx, request, &assets)
 	return
 }
 
 // Ledgers returns information about all ledgers.
 // See https://developers.stellar.org/api/resources/ledgers/list/
 func (c *Client) Ledgers(request LedgerRequest) (ledgers hProtocol.LedgersPage, err error) {
-	err = c.sendRequest(request, &ledgers)
+	return c.LedgersWithContext(context.Background(), request)
+}
+
+// LedgersWithContext is like Ledgers but the request is bound to ctx.
+func (c *Client) LedgersWithContext(ctx context.Context, request LedgerRequest) (ledgers hProtocol.LedgersPage, err error) {
+	err = c.sendRequest(ctx, request, &ledgers)
 	return
 }
 
 // LedgerDetail returns information about a particular ledger for a given sequence number
 // See https://developers.stellar.org/api/resources/ledgers/single/
 func (c *Client) LedgerDetail(sequence uint32) (ledger hProtocol.Ledger, err error) {
+	return c.LedgerDetailWithContext(context.Background(), sequence)
+}
+
+// LedgerDetailWithContext is like LedgerDetail but the request is bound to ctx.
+func (c *Client) LedgerDetailWithContext(ctx context.Context, sequence uint32) (ledger hProtocol.Ledger, err error) {
 	if sequence == 0 {
 		err = errors.New("invalid sequence number provided")
 	}
@@ -361,28 +424,43 @@ func (c *Client) LedgerDetail(sequence uint32) (ledger hProtocol.Ledger, err err
 	}
 
 	request := LedgerRequest{forSequence: sequence}
-	err = c.sendRequest(request, &ledger)
+	err = c.sendRequest(ctx, request, &ledger)
 	return
 }
 
 // FeeStats returns information about fees in the last 5 ledgers.
 // See https://developers.stellar.org/api/aggregations/fee-stats/
 func (c *Client) FeeStats() (feestats hProtocol.FeeStats, err error) {
+	return c.FeeStatsWithContext(context.Background())
+}
+
+// FeeStatsWithContext is like FeeStats but the request is bound to ctx.
+func (c *Client) FeeStatsWithContext(ctx context.Context) (feestats hProtocol.FeeStats, err error) {
 	request := feeStatsRequest{endpoint: "fee_stats"}
-	err = c.sendRequest(request, &feestats)
+	err = c.sendRequest(ctx, request, &feestats)
 	return
 }
 
 // Offers returns information about offers made on the SDEX.
 // See https://developers.stellar.org/api/resources/offers/list/
 func (c *Client) Offers(request OfferRequest) (offers hProtocol.OffersPage, err error) {
-	err = c.sendRequest(request, &offers)
+	return c.OffersWithContext(context.Background(), request)
+}
+
+// OffersWithContext is like Offers but the request is bound to ctx.
+func (c *Client) OffersWithContext(ctx context.Context, request OfferRequest) (offers hProtocol.OffersPage, err error) {
+	err = c.sendRequest(ctx, request, &offers)
 	return
 }
 
 // OfferDetails returns information for a single offer.
 // See https://developers.stellar.org/api/resources/offers/single/
 func (c *Client) OfferDetails(offerID string) (offer hProtocol.Offer, err error) {
+	return c.OfferDetailsWithContext(context.Background(), offerID)
+}
+
+// OfferDetailsWithContext is like OfferDetails but the request is bound to ctx.
+func (c *Client) OfferDetailsWithContext(ctx context.Context, offerID string) (offer hProtocol.Offer, err error) {
 	if len(offerID) == 0 {
 		err = errors.New("no offer ID provided")
 		return
@@ -393,20 +471,30 @@ func (c *Client) OfferDetails(offerID string) (offer hProtocol.Offer, err error)
 		return
 	}
 
-	err = c.sendRequest(OfferRequest{OfferID: offerID}, &offer)
+	err = c.sendRequest(ctx, OfferRequest{OfferID: offerID}, &offer)
 	return
 }
 
 // Operations returns stellar operations (https://developers.stellar.org/api/resources/operations/list/)
 // It can be used to return operations for an account, a ledger, a transaction and all operations on the network.
 func (c *Client) Operations(request OperationRequest) (ops operations.OperationsPage, err error) {
-	err = c.sendRequest(request.SetOperationsEndpoint(), &ops)
+	return c.OperationsWithContext(context.Background(), request)
+}
+
+// OperationsWithContext is like Operations but the request is bound to ctx.
+func (c *Client) OperationsWithContext(ctx context.Context, request OperationRequest) (ops operations.OperationsPage, err error) {
+	err = c.sendRequest(ctx, request.SetOperationsEndpoint(), &ops)
 	return
 }
 
 // OperationDetail returns a single stellar operation for a given operation id
 // See https://developers.stellar.org/api/resources/operations/single/
 func (c *Client) OperationDetail(id string) (ops operations.Operation, err error) {
+	return c.OperationDetailWithContext(context.Background(), id)
+}
+
+// OperationDetailWithContext is like OperationDetail but the request is bound to ctx.
+func (c *Client) OperationDetailWithContext(ctx context.Context, id string) (ops operations.Operation, err error) {
 	if id == "" {
 		return ops, errors.New("invalid operation id provided")
 	}
@@ -415,7 +503,7 @@ func (c *Client) OperationDetail(id string) (ops operations.Operation, err error
 
 	var record interface{}
 
-	err = c.sendRequest(request, &record)
+	err = c.sendRequest(ctx, request, &record)
 	if err != nil {
 		return ops, errors.Wrap(err, "sending request to orbitr")
 	}
@@ -439,9 +527,15 @@ func (c *Client) OperationDetail(id string) (ops operations.Operation, err error
 // SubmitTransactionXDR submits a transaction represented as a base64 XDR string to the network. err can be either error object or orbitr.Error object.
 // See https://developers.stellar.org/api/resources/transactions/post/
 func (c *Client) SubmitTransactionXDR(transactionXdr string) (tx hProtocol.Transaction,
+	err error) {
+	return c.SubmitTransactionXDRWithContext(context.Background(), transactionXdr)
+}
+
+// SubmitTransactionXDRWithContext is like SubmitTransactionXDR but the request is bound to ctx.
+func (c *Client) SubmitTransactionXDRWithContext(ctx context.Context, transactionXdr string) (tx hProtocol.Transaction,
 	err error) {
 	request := submitRequest{endpoint: "transactions", transactionXdr: transactionXdr}
-	err = c.sendRequest(request, &tx)
+	err = c.sendRequest(ctx, request, &tx)
 	return
 }
 
@@ -455,7 +549,12 @@ func (c *Client) SubmitTransactionXDR(transactionXdr string) (tx hProtocol.Trans
 //
 // See https://developers.stellar.org/api/resources/transactions/post/
 func (c *Client) SubmitFeeBumpTransaction(transaction *txnbuild.FeeBumpTransaction) (tx hProtocol.Transaction, err error) {
-	return c.SubmitFeeBumpTransactionWithOptions(transaction, SubmitTxOpts{})
+	return c.SubmitFeeBumpTransactionWithContext(context.Background(), transaction)
+}
+
+// SubmitFeeBumpTransactionWithContext is like SubmitFeeBumpTransaction but the request is bound to ctx.
+func (c *Client) SubmitFeeBumpTransactionWithContext(ctx context.Context, transaction *txnbuild.FeeBumpTransaction) (tx hProtocol.Transaction, err error) {
+	return c.SubmitFeeBumpTransactionWithOptionsWithContext(ctx, transaction, SubmitTxOpts{})
 }
 
 // SubmitFeeBumpTransactionWithOptions submits a fee bump transaction to the network, allowing
@@ -463,10 +562,15 @@ func (c *Client) SubmitFeeBumpTransaction(transaction *txnbuild.FeeBumpTransacti
 //
 // See https://developers.stellar.org/api/resources/transactions/post/
 func (c *Client) SubmitFeeBumpTransactionWithOptions(transaction *txnbuild.FeeBumpTransaction, opts SubmitTxOpts) (tx hProtocol.Transaction, err error) {
+	return c.SubmitFeeBumpTransactionWithOptionsWithContext(context.Background(), transaction, opts)
+}
+
+// SubmitFeeBumpTransactionWithOptionsWithContext is like SubmitFeeBumpTransactionWithOptions but the request is bound to ctx.
+func (c *Client) SubmitFeeBumpTransactionWithOptionsWithContext(ctx context.Context, transaction *txnbuild.FeeBumpTransaction, opts SubmitTxOpts) (tx hProtocol.Transaction, err error) {
 	// only check if memo is required if skip is false and the inner transaction
 	// doesn't have a memo.
 	if inner := transaction.InnerTransaction(); !opts.SkipMemoRequiredCheck && inner.Memo() == nil {
-		err = c.checkMemoRequired(inner)
+		err = c.checkMemoRequired(ctx, inner)
 		if err != nil {
 			return
 		}
@@ -478,7 +582,7 @@ func (c *Client) SubmitFeeBumpTransactionWithOptions(transaction *txnbuild.FeeBu
 		return
 	}
 
-	return c.SubmitTransactionXDR(txeBase64)
+	return c.SubmitTransactionXDRWithContext(ctx, txeBase64)
 }
 
 // SubmitTransaction submits a transaction to the network. err can be either an
@@ -491,7 +595,12 @@ func (c *Client) SubmitFeeBumpTransactionWithOptions(transaction *txnbuild.FeeBu
 //
 // See https://developers.stellar.org/api/resources/transactions/post/
 func (c *Client) SubmitTransaction(transaction *txnbuild.Transaction) (tx hProtocol.Transaction, err error) {
-	return c.SubmitTransactionWithOptions(transaction, SubmitTxOpts{})
+	return c.SubmitTransactionWithContext(context.Background(), transaction)
+}
+
+// SubmitTransactionWithContext is like SubmitTransaction but the request is bound to ctx.
+func (c *Client) SubmitTransactionWithContext(ctx context.Context, transaction *txnbuild.Transaction) (tx hProtocol.Transaction, err error) {
+	return c.SubmitTransactionWithOptionsWithContext(ctx, transaction, SubmitTxOpts{})
 }
 
 // SubmitTransactionWithOptions submits a transaction to the network, allowing
@@ -499,10 +608,15 @@ func (c *Client) SubmitTransaction(transaction *txnbuild.Transaction) (tx hProto
 //
 // See https://developers.stellar.org/api/resources/transactions/post/
 func (c *Client) SubmitTransactionWithOptions(transaction *txnbuild.Transaction, opts SubmitTxOpts) (tx hProtocol.Transaction, err error) {
+	return c.SubmitTransactionWithOptionsWithContext(context.Background(), transaction, opts)
+}
+
+// SubmitTransactionWithOptionsWithContext is like SubmitTransactionWithOptions but the request is bound to ctx.
+func (c *Client) SubmitTransactionWithOptionsWithContext(ctx context.Context, transaction *txnbuild.Transaction, opts SubmitTxOpts) (tx hProtocol.Transaction, err error) {
 	// only check if memo is required if skip is false and the transaction
 	// doesn't have a memo.
 	if !opts.SkipMemoRequiredCheck && transaction.Memo() == nil {
-		err = c.checkMemoRequired(transaction)
+		err = c.checkMemoRequired(ctx, transaction)
 		if err != nil {
 			return
 		}
@@ -514,72 +628,117 @@ func (c *Client) SubmitTransactionWithOptions(transaction *txnbuild.Transaction,
 		return
 	}
 
-	return c.SubmitTransactionXDR(txeBase64)
+	return c.SubmitTransactionXDRWithContext(ctx, txeBase64)
 }
 
 // Transactions returns stellar transactions (https://developers.stellar.org/api/resources/transactions/list/)
 // It can be used to return transactions for an account, a ledger,and all transactions on the network.
 func (c *Client) Transactions(request TransactionRequest) (txs hProtocol.TransactionsPage, err error) {
-	err = c.sendRequest(request, &txs)
+	return c.TransactionsWithContext(context.Background(), request)
+}
+
+// TransactionsWithContext is like Transactions but the request is bound to ctx.
+func (c *Client) TransactionsWithContext(ctx context.Context, request TransactionRequest) (txs hProtocol.TransactionsPage, err error) {
+	err = c.sendRequest(ctx, request, &txs)
 	return
 }
 
 // TransactionDetail returns information about a particular transaction for a given transaction hash
 // See https://developers.stellar.org/api/resources/transactions/single/
 func (c *Client) TransactionDetail(txHash string) (tx hProtocol.Transaction, err error) {
+	return c.TransactionDetailWithContext(context.Background(), txHash)
+}
+
+// TransactionDetailWithContext is like TransactionDetail but the request is bound to ctx.
+func (c *Client) TransactionDetailWithContext(ctx context.Context, txHash string) (tx hProtocol.Transaction, err error) {
 	if txHash == "" {
 		return tx, errors.New("no transaction hash provided")
 	}
 
 	request := TransactionRequest{forTransactionHash: txHash}
-	err = c.sendRequest(request, &tx)
+	err = c.sendRequest(ctx, request, &tx)
 	return
 }
 
 // OrderBook returns the orderbook for an asset pair (https://developers.stellar.org/api/aggregations/order-books/single/)
 func (c *Client) OrderBook(request OrderBookRequest) (obs hProtocol.OrderBookSummary, err error) {
-	err = c.sendRequest(request, &obs)
+	return c.OrderBookWithContext(context.Background(), request)
+}
+
+// OrderBookWithContext is like OrderBook but the request is bound to ctx.
+func (c *Client) OrderBookWithContext(ctx context.Context, request OrderBookRequest) (obs hProtocol.OrderBookSummary, err error) {
+	err = c.sendRequest(ctx, request, &obs)
 	return
 }
 
 // Paths returns the available paths to make a strict receive path payment. See https://developers.stellar.org/api/aggregations/paths/strict-receive/
 // This function is an alias for `client.StrictReceivePaths` and will be deprecated, use `client.StrictReceivePaths` instead.
 func (c *Client) Paths(request PathsRequest) (paths hProtocol.PathsPage, err error) {
-	paths, err = c.StrictReceivePaths(request)
+	return c.PathsWithContext(context.Background(), request)
+}
+
+// PathsWithContext is like Paths but the request is bound to ctx.
+func (c *Client) PathsWithContext(ctx context.Context, request PathsRequest) (paths hProtocol.PathsPage, err error) {
+	paths, err = c.StrictReceivePathsWithContext(ctx, request)
 	return
 }
 
 // StrictReceivePaths returns the available paths to make a strict receive path payment. See https://developers.stellar.org/api/aggregations/paths/strict-receive/
 func (c *Client) StrictReceivePaths(request PathsRequest) (paths hProtocol.PathsPage, err error) {
-	err = c.sendRequest(request, &paths)
+	return c.StrictReceivePathsWithContext(context.Background(), request)
+}
+
+// StrictReceivePathsWithContext is like StrictReceivePaths but the request is bound to ctx.
+func (c *Client) StrictReceivePathsWithContext(ctx context.Context, request PathsRequest) (paths hProtocol.PathsPage, err error) {
+	err = c.sendRequest(ctx, request, &paths)
 	return
 }
 
 // StrictSendPaths returns the available paths to make a strict send path payment. See https://developers.stellar.org/api/aggregations/paths/strict-send/
 func (c *Client) StrictSendPaths(request StrictSendPathsRequest) (paths hProtocol.PathsPage, err error) {
-	err = c.sendRequest(request, &paths)
+	return c.StrictSendPathsWithContext(context.Background(), request)
+}
+
+// StrictSendPathsWithContext is like StrictSendPaths but the request is bound to ctx.
+func (c *Client) StrictSendPathsWithContext(ctx context.Context, request StrictSendPathsRequest) (paths hProtocol.PathsPage, err error) {
+	err = c.sendRequest(ctx, request, &paths)
 	return
 }
 
 // Payments returns stellar account_merge, create_account, path payment and payment operations.
 // It can be used to return payments for an account, a ledger, a transaction and all payments on the network.
 func (c *Client) Payments(request OperationRequest) (ops operations.OperationsPage, err error) {
-	err = c.sendRequest(request.SetPaymentsEndpoint(), &ops)
+	return c.PaymentsWithContext(context.Background(), request)
+}
+
+// PaymentsWithContext is like Payments but the request is bound to ctx.
+func (c *Client) PaymentsWithContext(ctx context.Context, request OperationRequest) (ops operations.OperationsPage, err error) {
+	err = c.sendRequest(ctx, request.SetPaymentsEndpoint(), &ops)
 	return
 }
 
 // Trades returns stellar trades (https://developers.stellar.org/api/resources/trades/list/)
 // It can be used to return trades for an account, an offer and all trades on the network.
 func (c *Client) Trades(request TradeRequest) (tds hProtocol.TradesPage, err error) {
-	err = c.sendRequest(request, &tds)
+	return c.TradesWithContext(context.Background(), request)
+}
+
+// TradesWithContext is like Trades but the request is bound to ctx.
+func (c *Client) TradesWithContext(ctx context.Context, request TradeRequest) (tds hProtocol.TradesPage, err error) {
+	err = c.sendRequest(ctx, request, &tds)
 	return
 }
 
 // Fund creates a new account funded from friendbot. It only works on test networks. See
 // https://developers.stellar.org/docs/tutorials/create-account/ for more information.
 func (c *Client) Fund(addr string) (tx hProtocol.Transaction, err error) {
+	return c.FundWithContext(context.Background(), addr)
+}
+
+// FundWithContext is like Fund but the request is bound to ctx.
+func (c *Client) FundWithContext(ctx context.Context, addr string) (tx hProtocol.Transaction, err error) {
 	friendbotURL := fmt.Sprintf("%sfriendbot?addr=%s", c.fixOrbitRURL(), addr)
-	err = c.sendGetRequest(friendbotURL, &tx)
+	err = c.sendGetRequest(ctx, friendbotURL, &tx)
 	if IsNotFoundError(err) {
 		return tx, errors.Wrap(err, "funding is only available on test networks and may not be supported by "+c.fixOrbitRURL())
 	}
@@ -596,7 +755,12 @@ func (c *Client) StreamTrades(ctx context.Context, request TradeRequest, handler
 
 // TradeAggregations returns stellar trade aggregations (https://developers.stellar.org/api/aggregations/trade-aggregations/list/)
 func (c *Client) TradeAggregations(request TradeAggregationRequest) (tds hProtocol.TradeAggregationsPage, err error) {
-	err = c.sendRequest(request, &tds)
+	return c.TradeAggregationsWithContext(context.Background(), request)
+}
+
+// TradeAggregationsWithContext is like TradeAggregations but the request is bound to ctx.
+func (c *Client) TradeAggregationsWithContext(ctx context.Context, request TradeAggregationRequest) (tds hProtocol.TradeAggregationsPage, err error) {
+	err = c.sendRequest(ctx, request, &tds)
 	return
 }
 
@@ -673,7 +837,12 @@ func (c *Client) FetchTimebounds(seconds int64) (txnbuild.TimeBounds, error) {
 
 // Root loads the root endpoint of orbitr
 func (c *Client) Root() (root hProtocol.Root, err error) {
-	err = c.sendGetRequest(c.fixOrbitRURL(), &root)
+	return c.RootWithContext(context.Background())
+}
+
+// RootWithContext is like Root but the request is bound to ctx.
+func (c *Client) RootWithContext(ctx context.Context) (root hProtocol.Root, err error) {
+	err = c.sendGetRequest(ctx, c.fixOrbitRURL(), &root)
 	return
 }
 
@@ -684,111 +853,201 @@ func (c *Client) Version() string {
 
 // NextAccountsPage returns the next page of accounts.
 func (c *Client) NextAccountsPage(page hProtocol.AccountsPage) (accounts hProtocol.AccountsPage, err error) {
-	err = c.sendGetRequest(page.Links.Next.Href, &accounts)
+	return c.NextAccountsPageWithContext(context.Background(), page)
+}
+
+// NextAccountsPageWithContext is like NextAccountsPage but the request is bound to ctx.
+func (c *Client) NextAccountsPageWithContext(ctx context.Context, page hProtocol.AccountsPage) (accounts hProtocol.AccountsPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Next.Href, &accounts)
 	return
 }
 
 // NextAssetsPage returns the next page of assets.
 func (c *Client) NextAssetsPage(page hProtocol.AssetsPage) (assets hProtocol.AssetsPage, err error) {
-	err = c.sendGetRequest(page.Links.Next.Href, &assets)
+	return c.NextAssetsPageWithContext(context.Background(), page)
+}
+
+// NextAssetsPageWithContext is like NextAssetsPage but the request is bound to ctx.
+func (c *Client) NextAssetsPageWithContext(ctx context.Context, page hProtocol.AssetsPage) (assets hProtocol.AssetsPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Next.Href, &assets)
 	return
 }
 
 // PrevAssetsPage returns the previous page of assets.
 func (c *Client) PrevAssetsPage(page hProtocol.AssetsPage) (assets hProtocol.AssetsPage, err error) {
-	err = c.sendGetRequest(page.Links.Prev.Href, &assets)
+	return c.PrevAssetsPageWithContext(context.Background(), page)
+}
+
+// PrevAssetsPageWithContext is like PrevAssetsPage but the request is bound to ctx.
+func (c *Client) PrevAssetsPageWithContext(ctx context.Context, page hProtocol.AssetsPage) (assets hProtocol.AssetsPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Prev.Href, &assets)
 	return
 }
 
 // NextLedgersPage returns the next page of ledgers.
 func (c *Client) NextLedgersPage(page hProtocol.LedgersPage) (ledgers hProtocol.LedgersPage, err error) {
-	err = c.sendGetRequest(page.Links.Next.Href, &ledgers)
+	return c.NextLedgersPageWithContext(context.Background(), page)
+}
+
+// NextLedgersPageWithContext is like NextLedgersPage but the request is bound to ctx.
+func (c *Client) NextLedgersPageWithContext(ctx context.Context, page hProtocol.LedgersPage) (ledgers hProtocol.LedgersPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Next.Href, &ledgers)
 	return
 }
 
 // PrevLedgersPage returns the previous page of ledgers.
 func (c *Client) PrevLedgersPage(page hProtocol.LedgersPage) (ledgers hProtocol.LedgersPage, err error) {
-	err = c.sendGetRequest(page.Links.Prev.Href, &ledgers)
+	return c.PrevLedgersPageWithContext(context.Background(), page)
+}
+
+// PrevLedgersPageWithContext is like PrevLedgersPage but the request is bound to ctx.
+func (c *Client) PrevLedgersPageWithContext(ctx context.Context, page hProtocol.LedgersPage) (ledgers hProtocol.LedgersPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Prev.Href, &ledgers)
 	return
 }
 
 // NextEffectsPage returns the next page of effects.
 func (c *Client) NextEffectsPage(page effects.EffectsPage) (efp effects.EffectsPage, err error) {
-	err = c.sendGetRequest(page.Links.Next.Href, &efp)
+	return c.NextEffectsPageWithContext(context.Background(), page)
+}
+
+// NextEffectsPageWithContext is like NextEffectsPage but the request is bound to ctx.
+func (c *Client) NextEffectsPageWithContext(ctx context.Context, page effects.EffectsPage) (efp effects.EffectsPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Next.Href, &efp)
 	return
 }
 
 // PrevEffectsPage returns the previous page of effects.
 func (c *Client) PrevEffectsPage(page effects.EffectsPage) (efp effects.EffectsPage, err error) {
-	err = c.sendGetRequest(page.Links.Prev.Href, &efp)
+	return c.PrevEffectsPageWithContext(context.Background(), page)
+}
+
+// PrevEffectsPageWithContext is like PrevEffectsPage but the request is bound to ctx.
+func (c *Client) PrevEffectsPageWithContext(ctx context.Context, page effects.EffectsPage) (efp effects.EffectsPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Prev.Href, &efp)
 	return
 }
 
 // NextTransactionsPage returns the next page of transactions.
 func (c *Client) NextTransactionsPage(page hProtocol.TransactionsPage) (transactions hProtocol.TransactionsPage, err error) {
-	err = c.sendGetRequest(page.Links.Next.Href, &transactions)
+	return c.NextTransactionsPageWithContext(context.Background(), page)
+}
+
+// NextTransactionsPageWithContext is like NextTransactionsPage but the request is bound to ctx.
+func (c *Client) NextTransactionsPageWithContext(ctx context.Context, page hProtocol.TransactionsPage) (transactions hProtocol.TransactionsPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Next.Href, &transactions)
 	return
 }
 
 // PrevTransactionsPage returns the previous page of transactions.
 func (c *Client) PrevTransactionsPage(page hProtocol.TransactionsPage) (transactions hProtocol.TransactionsPage, err error) {
-	err = c.sendGetRequest(page.Links.Prev.Href, &transactions)
+	return c.PrevTransactionsPageWithContext(context.Background(), page)
+}
+
+// PrevTransactionsPageWithContext is like PrevTransactionsPage but the request is bound to ctx.
+func (c *Client) PrevTransactionsPageWithContext(ctx context.Context, page hProtocol.TransactionsPage) (transactions hProtocol.TransactionsPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Prev.Href, &transactions)
 	return
 }
 
 // NextOperationsPage returns the next page of operations.
 func (c *Client) NextOperationsPage(page operations.OperationsPage) (operations operations.OperationsPage, err error) {
-	err = c.sendGetRequest(page.Links.Next.Href, &operations)
+	return c.NextOperationsPageWithContext(context.Background(), page)
+}
+
+// NextOperationsPageWithContext is like NextOperationsPage but the request is bound to ctx.
+func (c *Client) NextOperationsPageWithContext(ctx context.Context, page operations.OperationsPage) (operations operations.OperationsPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Next.Href, &operations)
 	return
 }
 
 // PrevOperationsPage returns the previous page of operations.
 func (c *Client) PrevOperationsPage(page operations.OperationsPage) (operations operations.OperationsPage, err error) {
-	err = c.sendGetRequest(page.Links.Prev.Href, &operations)
+	return c.PrevOperationsPageWithContext(context.Background(), page)
+}
+
+// PrevOperationsPageWithContext is like PrevOperationsPage but the request is bound to ctx.
+func (c *Client) PrevOperationsPageWithContext(ctx context.Context, page operations.OperationsPage) (operations operations.OperationsPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Prev.Href, &operations)
 	return
 }
 
 // NextPaymentsPage returns the next page of payments.
 func (c *Client) NextPaymentsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
-	return c.NextOperationsPage(page)
+	return c.NextPaymentsPageWithContext(context.Background(), page)
+}
+
+// NextPaymentsPageWithContext is like NextPaymentsPage but the request is bound to ctx.
+func (c *Client) NextPaymentsPageWithContext(ctx context.Context, page operations.OperationsPage) (operations.OperationsPage, error) {
+	return c.NextOperationsPageWithContext(ctx, page)
 }
 
 // PrevPaymentsPage returns the previous page of payments.
 func (c *Client) PrevPaymentsPage(page operations.OperationsPage) (operations.OperationsPage, error) {
-	return c.PrevOperationsPage(page)
+	return c.PrevPaymentsPageWithContext(context.Background(), page)
+}
+
+// PrevPaymentsPageWithContext is like PrevPaymentsPage but the request is bound to ctx.
+func (c *Client) PrevPaymentsPageWithContext(ctx context.Context, page operations.OperationsPage) (operations.OperationsPage, error) {
+	return c.PrevOperationsPageWithContext(ctx, page)
 }
 
 // NextOffersPage returns the next page of offers.
 func (c *Client) NextOffersPage(page hProtocol.OffersPage) (offers hProtocol.OffersPage, err error) {
-	err = c.sendGetRequest(page.Links.Next.Href, &offers)
+	return c.NextOffersPageWithContext(context.Background(), page)
+}
+
+// NextOffersPageWithContext is like NextOffersPage but the request is bound to ctx.
+func (c *Client) NextOffersPageWithContext(ctx context.Context, page hProtocol.OffersPage) (offers hProtocol.OffersPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Next.Href, &offers)
 	return
 }
 
 // PrevOffersPage returns the previous page of offers.
 func (c *Client) PrevOffersPage(page hProtocol.OffersPage) (offers hProtocol.OffersPage, err error) {
-	err = c.sendGetRequest(page.Links.Prev.Href, &offers)
+	return c.PrevOffersPageWithContext(context.Background(), page)
+}
+
+// PrevOffersPageWithContext is like PrevOffersPage but the request is bound to ctx.
+func (c *Client) PrevOffersPageWithContext(ctx context.Context, page hProtocol.OffersPage) (offers hProtocol.OffersPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Prev.Href, &offers)
 	return
 }
 
 // NextTradesPage returns the next page of trades.
 func (c *Client) NextTradesPage(page hProtocol.TradesPage) (trades hProtocol.TradesPage, err error) {
-	err = c.sendGetRequest(page.Links.Next.Href, &trades)
+	return c.NextTradesPageWithContext(context.Background(), page)
+}
+
+// NextTradesPageWithContext is like NextTradesPage but the request is bound to ctx.
+func (c *Client) NextTradesPageWithContext(ctx context.Context, page hProtocol.TradesPage) (trades hProtocol.TradesPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Next.Href, &trades)
 	return
 }
 
 // PrevTradesPage returns the previous page of trades.
 func (c *Client) PrevTradesPage(page hProtocol.TradesPage) (trades hProtocol.TradesPage, err error) {
-	err = c.sendGetRequest(page.Links.Prev.Href, &trades)
+	return c.PrevTradesPageWithContext(context.Background(), page)
+}
+
+// PrevTradesPageWithContext is like PrevTradesPage but the request is bound to ctx.
+func (c *Client) PrevTradesPageWithContext(ctx context.Context, page hProtocol.TradesPage) (trades hProtocol.TradesPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Prev.Href, &trades)
 	return
 }
 
 // HomeDomainForAccount returns the home domain for a single account.
 func (c *Client) HomeDomainForAccount(aid string) (string, error) {
+	return c.HomeDomainForAccountWithContext(context.Background(), aid)
+}
+
+// HomeDomainForAccountWithContext is like HomeDomainForAccount but the request is bound to ctx.
+func (c *Client) HomeDomainForAccountWithContext(ctx context.Context, aid string) (string, error) {
 	if aid == "" {
 		return "", errors.New("no account ID provided")
 	}
 
-	accountDetail, err := c.AccountDetail(AccountRequest{AccountID: aid})
+	accountDetail, err := c.AccountDetailWithContext(ctx, AccountRequest{AccountID: aid})
 	if err != nil {
 		return "", errors.Wrap(err, "get account detail failed")
 	}
@@ -799,48 +1058,88 @@ func (c *Client) HomeDomainForAccount(aid string) (string, error) {
 // NextTradeAggregationsPage returns the next page of trade aggregations from the current
 // trade aggregations response.
 func (c *Client) NextTradeAggregationsPage(page hProtocol.TradeAggregationsPage) (ta hProtocol.TradeAggregationsPage, err error) {
-	err = c.sendGetRequest(page.Links.Next.Href, &ta)
+	return c.NextTradeAggregationsPageWithContext(context.Background(), page)
+}
+
+// NextTradeAggregationsPageWithContext is like NextTradeAggregationsPage but the request is bound to ctx.
+func (c *Client) NextTradeAggregationsPageWithContext(ctx context.Context, page hProtocol.TradeAggregationsPage) (ta hProtocol.TradeAggregationsPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Next.Href, &ta)
 	return
 }
 
 // PrevTradeAggregationsPage returns the previous page of trade aggregations from the current
 // trade aggregations response.
 func (c *Client) PrevTradeAggregationsPage(page hProtocol.TradeAggregationsPage) (ta hProtocol.TradeAggregationsPage, err error) {
-	err = c.sendGetRequest(page.Links.Prev.Href, &ta)
+	return c.PrevTradeAggregationsPageWithContext(context.Background(), page)
+}
+
+// PrevTradeAggregationsPageWithContext is like PrevTradeAggregationsPage but the request is bound to ctx.
+func (c *Client) PrevTradeAggregationsPageWithContext(ctx context.Context, page hProtocol.TradeAggregationsPage) (ta hProtocol.TradeAggregationsPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Prev.Href, &ta)
 	return
 }
 
 // ClaimableBalances returns details about available claimable balances,
 // possibly filtered to a specific sponsor or other parameters.
 func (c *Client) ClaimableBalances(cbr ClaimableBalanceRequest) (cb hProtocol.ClaimableBalances, err error) {
-	err = c.sendRequest(cbr, &cb)
+	return c.ClaimableBalancesWithContext(context.Background(), cbr)
+}
+
+// ClaimableBalancesWithContext is like ClaimableBalances but the request is bound to ctx.
+func (c *Client) ClaimableBalancesWithContext(ctx context.Context, cbr ClaimableBalanceRequest) (cb hProtocol.ClaimableBalances, err error) {
+	err = c.sendRequest(ctx, cbr, &cb)
 	return
 }
 
 // ClaimableBalance returns details about a *specific*, unique claimable balance.
 func (c *Client) ClaimableBalance(id string) (cb hProtocol.ClaimableBalance, err error) {
+	return c.ClaimableBalanceWithContext(context.Background(), id)
+}
+
+// ClaimableBalanceWithContext is like ClaimableBalance but the request is bound to ctx.
+func (c *Client) ClaimableBalanceWithContext(ctx context.Context, id string) (cb hProtocol.ClaimableBalance, err error) {
 	cbr := ClaimableBalanceRequest{ID: id}
-	err = c.sendRequest(cbr, &cb)
+	err = c.sendRequest(ctx, cbr, &cb)
 	return
 }
 
 func (c *Client) LiquidityPoolDetail(request LiquidityPoolRequest) (lp hProtocol.LiquidityPool, err error) {
-	err = c.sendRequest(request, &lp)
+	return c.LiquidityPoolDetailWithContext(context.Background(), request)
+}
+
+// LiquidityPoolDetailWithContext is like LiquidityPoolDetail but the request is bound to ctx.
+func (c *Client) LiquidityPoolDetailWithContext(ctx context.Context, request LiquidityPoolRequest) (lp hProtocol.LiquidityPool, err error) {
+	err = c.sendRequest(ctx, request, &lp)
 	return
 }
 
 func (c *Client) LiquidityPools(request LiquidityPoolsRequest) (lp hProtocol.LiquidityPoolsPage, err error) {
-	err = c.sendRequest(request, &lp)
+	return c.LiquidityPoolsWithContext(context.Background(), request)
+}
+
+// LiquidityPoolsWithContext is like LiquidityPools but the request is bound to ctx.
+func (c *Client) LiquidityPoolsWithContext(ctx context.Context, request LiquidityPoolsRequest) (lp hProtocol.LiquidityPoolsPage, err error) {
+	err = c.sendRequest(ctx, request, &lp)
 	return
 }
 
 func (c *Client) NextLiquidityPoolsPage(page hProtocol.LiquidityPoolsPage) (lp hProtocol.LiquidityPoolsPage, err error) {
-	err = c.sendGetRequest(page.Links.Next.Href, &lp)
+	return c.NextLiquidityPoolsPageWithContext(context.Background(), page)
+}
+
+// NextLiquidityPoolsPageWithContext is like NextLiquidityPoolsPage but the request is bound to ctx.
+func (c *Client) NextLiquidityPoolsPageWithContext(ctx context.Context, page hProtocol.LiquidityPoolsPage) (lp hProtocol.LiquidityPoolsPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Next.Href, &lp)
 	return
 }
 
 func (c *Client) PrevLiquidityPoolsPage(page hProtocol.LiquidityPoolsPage) (lp hProtocol.LiquidityPoolsPage, err error) {
-	err = c.sendGetRequest(page.Links.Prev.Href, &lp)
+	return c.PrevLiquidityPoolsPageWithContext(context.Background(), page)
+}
+
+// PrevLiquidityPoolsPageWithContext is like PrevLiquidityPoolsPage but the request is bound to ctx.
+func (c *Client) PrevLiquidityPoolsPageWithContext(ctx context.Context, page hProtocol.LiquidityPoolsPage) (lp hProtocol.LiquidityPoolsPage, err error) {
+	err = c.sendGetRequest(ctx, page.Links.Prev.Href, &lp)
 	return
 }
 
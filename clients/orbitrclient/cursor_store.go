@@ -0,0 +1,231 @@
+package orbitrclient
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/toid"
+)
+
+// ErrStreamGap is returned by streams resuming from a cursor older than the
+// oldest ledger in the history of OrbitR, in which case some of the events
+// following the cursor cannot be streamed anymore.
+var ErrStreamGap = errors.New("stream cursor is before the history of orbitr")
+
+// CursorStore persists the cursors of streams. Streams are identified by
+// their endpoint and query parameters, the cursor excepted.
+type CursorStore interface {
+	// LoadCursor returns the cursor saved for the stream identified by key,
+	// or an empty string if there is none.
+	LoadCursor(ctx context.Context, key string) (string, error)
+	// SaveCursor saves the cursor of the stream identified by key.
+	SaveCursor(ctx context.Context, key, cursor string) error
+}
+
+// MemoryCursorStore is a CursorStore keeping cursors in memory, which
+// resumes streams after a reconnection but not after a restart.
+type MemoryCursorStore struct {
+	lock    sync.Mutex
+	cursors map[string]string
+}
+
+// NewMemoryCursorStore returns a new MemoryCursorStore.
+func NewMemoryCursorStore() *MemoryCursorStore {
+	return &MemoryCursorStore{cursors: map[string]string{}}
+}
+
+// LoadCursor implements CursorStore.
+func (s *MemoryCursorStore) LoadCursor(ctx context.Context, key string) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.cursors[key], nil
+}
+
+// SaveCursor implements CursorStore.
+func (s *MemoryCursorStore) SaveCursor(ctx context.Context, key, cursor string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.cursors[key] = cursor
+	return nil
+}
+
+// FileCursorStoreOptions configures how often a FileCursorStore writes its
+// file.
+type FileCursorStoreOptions struct {
+	// SaveEvery is the number of saved cursors after which the file is
+	// written.
+	SaveEvery int
+	// SaveInterval is the time after which a saved cursor is written to the
+	// file by the next save.
+	SaveInterval time.Duration
+}
+
+// FileCursorStore is a CursorStore keeping the cursors of all the streams in
+// a JSON file. Saved cursors are written to the file every SaveEvery saves or
+// SaveInterval, whichever comes first, and on Flush and Close. Every save is
+// written when both are zero. The file is replaced atomically when it is
+// written, so it is never left partially written. A file must not be shared
+// by several processes.
+//
+// The cursors saved since the file was last written are lost if the process
+// exits without closing the store, in which case the streams resume from an
+// older cursor and deliver the events following it again.
+type FileCursorStore struct {
+	path    string
+	options FileCursorStoreOptions
+
+	lock      sync.Mutex
+	cursors   map[string]string
+	unwritten int
+	written   time.Time
+}
+
+// NewFileCursorStore returns a new FileCursorStore keeping cursors in the
+// file at path, which is created on the first write.
+func NewFileCursorStore(path string, options FileCursorStoreOptions) *FileCursorStore {
+	return &FileCursorStore{path: path, options: options, written: time.Now()}
+}
+
+// LoadCursor implements CursorStore.
+func (s *FileCursorStore) LoadCursor(ctx context.Context, key string) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return "", err
+	}
+	return s.cursors[key], nil
+}
+
+// SaveCursor implements CursorStore.
+func (s *FileCursorStore) SaveCursor(ctx context.Context, key, cursor string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	s.cursors[key] = cursor
+	s.unwritten++
+	if !s.writeDue() {
+		return nil
+	}
+	return s.write()
+}
+
+// writeDue returns true if the saved cursors must be written to the file, it
+// must be called with s.lock held.
+func (s *FileCursorStore) writeDue() bool {
+	every, interval := s.options.SaveEvery, s.options.SaveInterval
+	if every == 0 && interval == 0 {
+		return true
+	}
+	if every > 0 && s.unwritten >= every {
+		return true
+	}
+	return interval > 0 && time.Since(s.written) >= interval
+}
+
+// Flush writes the cursors saved since the file was last written.
+func (s *FileCursorStore) Flush() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.unwritten == 0 {
+		return nil
+	}
+	return s.write()
+}
+
+// Close writes the cursors saved since the file was last written. The store
+// must not be used afterwards.
+func (s *FileCursorStore) Close() error {
+	return s.Flush()
+}
+
+// write replaces the file with the saved cursors, it must be called with
+// s.lock held.
+func (s *FileCursorStore) write() error {
+	data, err := json.Marshal(s.cursors)
+	if err != nil {
+		return errors.Wrap(err, "error encoding cursors")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return errors.Wrap(err, "error creating cursor file")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "error writing cursor file")
+	}
+	if err = os.Rename(tmp.Name(), s.path); err != nil {
+		return errors.Wrap(err, "error replacing cursor file")
+	}
+	s.unwritten = 0
+	s.written = time.Now()
+	return nil
+}
+
+// load reads the cursors from the file the first time it is called, it must
+// be called with s.lock held.
+func (s *FileCursorStore) load() error {
+	if s.cursors != nil {
+		return nil
+	}
+
+	cursors := map[string]string{}
+	data, err := os.ReadFile(s.path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "error reading cursor file")
+	}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, &cursors); err != nil {
+			return errors.Wrap(err, "error decoding cursor file")
+		}
+	}
+	s.cursors = cursors
+	return nil
+}
+
+// cursorLedger returns the ledger of a cursor made of a toid, optionally
+// followed by a dash and an index like the paging tokens of effects and
+// trades.
+func cursorLedger(cursor string) (int32, bool) {
+	id, err := strconv.ParseInt(strings.SplitN(cursor, "-", 2)[0], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return toid.Parse(id).LedgerSequence, true
+}
+
+// checkStreamGap returns ErrStreamGap if the stream cannot resume from cursor
+// without missing events. OrbitR has the same rule for cursors of requests in
+// descending order.
+func (c *Client) checkStreamGap(ctx context.Context, cursor string) error {
+	ledger, ok := cursorLedger(cursor)
+	if !ok {
+		return nil
+	}
+
+	root, err := c.RootWithContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error checking stream cursor")
+	}
+	if ledger < root.HistoryElderSequence {
+		return errors.Wrapf(ErrStreamGap, "cursor %s is in ledger %d but history starts at ledger %d",
+			cursor, ledger, root.HistoryElderSequence)
+	}
+	return nil
+}
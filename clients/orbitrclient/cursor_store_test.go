@@ -0,0 +1,178 @@
+package orbitrclient
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/support/http/httptest"
+)
+
+func TestMemoryCursorStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCursorStore()
+
+	cursor, err := store.LoadCursor(ctx, "payments")
+	require.NoError(t, err)
+	assert.Equal(t, "", cursor)
+
+	require.NoError(t, store.SaveCursor(ctx, "payments", "2608707301036033"))
+	cursor, err = store.LoadCursor(ctx, "payments")
+	require.NoError(t, err)
+	assert.Equal(t, "2608707301036033", cursor)
+}
+
+func TestFileCursorStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cursors.json")
+
+	store := NewFileCursorStore(path, FileCursorStoreOptions{})
+	cursor, err := store.LoadCursor(ctx, "payments")
+	require.NoError(t, err)
+	assert.Equal(t, "", cursor)
+
+	require.NoError(t, store.SaveCursor(ctx, "payments", "2608707301036033"))
+	require.NoError(t, store.SaveCursor(ctx, "effects", "2608707301036033-1"))
+	require.NoError(t, store.SaveCursor(ctx, "payments", "2608707301036034"))
+
+	// cursors survive a restart
+	store = NewFileCursorStore(path, FileCursorStoreOptions{})
+	cursor, err = store.LoadCursor(ctx, "payments")
+	require.NoError(t, err)
+	assert.Equal(t, "2608707301036034", cursor)
+	cursor, err = store.LoadCursor(ctx, "effects")
+	require.NoError(t, err)
+	assert.Equal(t, "2608707301036033-1", cursor)
+}
+
+func TestFileCursorStoreSaveEvery(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cursors.json")
+	load := func() string {
+		cursor, err := NewFileCursorStore(path, FileCursorStoreOptions{}).LoadCursor(ctx, "payments")
+		require.NoError(t, err)
+		return cursor
+	}
+
+	store := NewFileCursorStore(path, FileCursorStoreOptions{SaveEvery: 3})
+	require.NoError(t, store.SaveCursor(ctx, "payments", "2608707301036033"))
+	require.NoError(t, store.SaveCursor(ctx, "payments", "2608707301036034"))
+	assert.Equal(t, "", load())
+	require.NoError(t, store.SaveCursor(ctx, "payments", "2608707301036035"))
+	assert.Equal(t, "2608707301036035", load())
+
+	// the saved cursor is loaded before it is written
+	require.NoError(t, store.SaveCursor(ctx, "payments", "2608707301036036"))
+	cursor, err := store.LoadCursor(ctx, "payments")
+	require.NoError(t, err)
+	assert.Equal(t, "2608707301036036", cursor)
+	assert.Equal(t, "2608707301036035", load())
+
+	require.NoError(t, store.Close())
+	assert.Equal(t, "2608707301036036", load())
+}
+
+func TestFileCursorStoreSaveInterval(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cursors.json")
+	load := func() string {
+		cursor, err := NewFileCursorStore(path, FileCursorStoreOptions{}).LoadCursor(ctx, "payments")
+		require.NoError(t, err)
+		return cursor
+	}
+
+	store := NewFileCursorStore(path, FileCursorStoreOptions{SaveInterval: time.Hour})
+	require.NoError(t, store.SaveCursor(ctx, "payments", "2608707301036033"))
+	assert.Equal(t, "", load())
+
+	store.written = time.Now().Add(-time.Hour)
+	require.NoError(t, store.SaveCursor(ctx, "payments", "2608707301036034"))
+	assert.Equal(t, "2608707301036034", load())
+
+	require.NoError(t, store.SaveCursor(ctx, "payments", "2608707301036035"))
+	require.NoError(t, store.Flush())
+	assert.Equal(t, "2608707301036035", load())
+}
+
+func TestStreamCursorKey(t *testing.T) {
+	trRequest := TransactionRequest{ForAccount: "GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR", Cursor: "now", IncludeFailed: true}
+	req, err := trRequest.HTTPRequest("https://localhost/")
+	require.NoError(t, err)
+	assert.Equal(t,
+		"accounts/GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR/transactions?include_failed=true",
+		streamCursorKey(req.URL.Path, req.URL.Query()),
+	)
+}
+
+func TestStreamWithCursorStore(t *testing.T) {
+	hmock := httptest.NewClient()
+	store := NewMemoryCursorStore()
+	client := &Client{
+		OrbitRURL:   "https://localhost/",
+		HTTP:        hmock,
+		CursorStore: store,
+	}
+	ctx := context.Background()
+
+	// the stream starts from the cursor of the request and saves the cursor
+	// of the handled event
+	hmock.On(
+		"GET",
+		"https://localhost/",
+	).ReturnString(200, `{"history_elder_ledger": 607000}`)
+	hmock.On(
+		"GET",
+		"https://localhost/transactions?cursor=2608707301036032",
+	).ReturnString(200, "id: 2608707301036033\n"+txStreamResponse+"\n")
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	var transactions []hProtocol.Transaction
+	err := client.StreamTransactions(streamCtx, TransactionRequest{Cursor: "2608707301036032"}, func(tr hProtocol.Transaction) {
+		transactions = append(transactions, tr)
+		cancel()
+	})
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	cursor, err := store.LoadCursor(ctx, "transactions")
+	require.NoError(t, err)
+	assert.Equal(t, "2608707301036033", cursor)
+
+	// after a restart, the stream resumes from the saved cursor rather than
+	// from the cursor of the request
+	hmock.On(
+		"GET",
+		"https://localhost/",
+	).ReturnString(200, `{"history_elder_ledger": 607000}`)
+	hmock.On(
+		"GET",
+		"https://localhost/transactions?cursor=2608707301036033",
+	).ReturnString(200, "id: 2608707301036034\n"+txStreamResponse+"\n")
+
+	streamCtx, cancel = context.WithCancel(ctx)
+	err = client.StreamTransactions(streamCtx, TransactionRequest{}, func(tr hProtocol.Transaction) {
+		transactions = append(transactions, tr)
+		cancel()
+	})
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	cursor, err = store.LoadCursor(ctx, "transactions")
+	require.NoError(t, err)
+	assert.Equal(t, "2608707301036034", cursor)
+
+	// the stream fails when its cursor is not in the history of orbitr
+	hmock.On(
+		"GET",
+		"https://localhost/",
+	).ReturnString(200, `{"history_elder_ledger": 700000}`)
+
+	err = client.StreamTransactions(ctx, TransactionRequest{}, func(tr hProtocol.Transaction) {
+		t.Fatal("unexpected transaction")
+	})
+	assert.Equal(t, ErrStreamGap, errors.Cause(err))
+}
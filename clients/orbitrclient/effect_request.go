@@ -45,7 +45,8 @@ func (er EffectRequest) BuildURL() (endpoint string, err error) {
 		endpoint = fmt.Sprintf("transactions/%s/effects", er.ForTransaction)
 	}
 
-	queryParams := addQueryParams(cursor(er.Cursor), limit(er.Limit), er.Order)
+	queryParams := addQueryParams(cursor(er.Cursor), limit(er.Limit), er.Order,
+		historyFilterParams(er.Types, er.Asset, er.CreatedAfter, er.CreatedBefore))
 	if queryParams != "" {
 		endpoint = fmt.Sprintf("%s?%s", endpoint, queryParams)
 	}
@@ -68,7 +69,7 @@ func (er EffectRequest) StreamEffects(ctx context.Context, client *Client, handl
 	}
 
 	url := fmt.Sprintf("%s%s", client.fixOrbitRURL(), endpoint)
-	return client.stream(ctx, url, func(data []byte) error {
+	return client.stream(ctx, url, true, func(data []byte) error {
 		var baseEffect effects.Base
 		// unmarshal into the base effect type
 		if err = json.Unmarshal(data, &baseEffect); err != nil {
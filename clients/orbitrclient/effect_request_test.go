@@ -3,6 +3,7 @@ package orbitrclient
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/lantah/go/protocols/orbitr/effects"
 	"github.com/lantah/go/support/http/httptest"
@@ -67,6 +68,15 @@ func TestEffectRequestBuildUrl(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "effects?cursor=123456&limit=30&order=asc", endpoint)
 
+	er = EffectRequest{
+		Types:        []string{"account_credited", "account_debited"},
+		Asset:        "native",
+		CreatedAfter: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	endpoint, err = er.BuildURL()
+	// It should return valid all effects endpoint with the history filters and no errors
+	require.NoError(t, err)
+	assert.Equal(t, "effects?asset=native&created_after=2023-01-02T03%3A04%3A05Z&type=account_credited%2Caccount_debited", endpoint)
 }
 
 func TestEffectRequestStreamEffects(t *testing.T) {
@@ -0,0 +1,155 @@
+package orbitrclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/support/clock"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/txnbuild"
+)
+
+const (
+	// defaultFeeStatsMaxAge is how long fee stats are reused by a
+	// FeeEstimator when FeeStatsMaxAge is not set, which is about the time
+	// between two ledgers.
+	defaultFeeStatsMaxAge = 5 * time.Second
+	// surgePricingCapacityUsage is the ledger capacity usage from which
+	// transactions compete for inclusion. Below it, all the transactions
+	// paying the base fee of the last ledger are included in the next one.
+	surgePricingCapacityUsage = 0.8
+)
+
+// InclusionTarget is the number of ledgers within which a transaction should
+// be included.
+type InclusionTarget uint32
+
+const (
+	// InclusionNextLedger targets the inclusion in the next ledger.
+	InclusionNextLedger InclusionTarget = 1
+	// InclusionWithin5Ledgers targets the inclusion within the next 5 ledgers,
+	// which is the window of the fee stats of OrbitR.
+	InclusionWithin5Ledgers InclusionTarget = 5
+)
+
+// FeeEstimator recommends base fees from the fee stats of OrbitR.
+type FeeEstimator struct {
+	// Client fetches the fee stats.
+	Client ClientInterface
+	// MaxBaseFee is the maximum base fee recommended, there is no maximum if
+	// it is zero.
+	MaxBaseFee int64
+	// FeeStatsMaxAge is how long fee stats are reused before they are
+	// fetched again, 5 seconds if zero.
+	FeeStatsMaxAge time.Duration
+
+	clock     *clock.Clock
+	lock      sync.Mutex
+	stats     hProtocol.FeeStats
+	fetchedAt time.Time
+}
+
+// EstimateBaseFee returns the base fee recommended for a transaction to be
+// included within target ledgers given the fee stats of the last ledgers.
+// When the last ledgers were not congested, it is the base fee of the last
+// ledger. Otherwise, it is the percentile of the fees charged in the last
+// ledgers which is higher the closer the target is.
+func EstimateBaseFee(stats hProtocol.FeeStats, target InclusionTarget) int64 {
+	fee := stats.LastLedgerBaseFee
+	if stats.LedgerCapacityUsage >= surgePricingCapacityUsage {
+		var percentile int64
+		switch {
+		case target <= InclusionNextLedger && stats.LedgerCapacityUsage >= 1:
+			percentile = stats.FeeCharged.P99
+		case target <= InclusionNextLedger:
+			percentile = stats.FeeCharged.P90
+		case target <= 2:
+			percentile = stats.FeeCharged.P70
+		case target < InclusionWithin5Ledgers:
+			percentile = stats.FeeCharged.P50
+		default:
+			percentile = stats.FeeCharged.P30
+		}
+		if percentile > fee {
+			fee = percentile
+		}
+	}
+	if fee < txnbuild.MinBaseFee {
+		fee = txnbuild.MinBaseFee
+	}
+	return fee
+}
+
+// BaseFee returns the base fee recommended for a transaction to be included
+// within target ledgers, capped at MaxBaseFee.
+func (e *FeeEstimator) BaseFee(ctx context.Context, target InclusionTarget) (int64, error) {
+	stats, err := e.feeStats(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	fee := EstimateBaseFee(stats, target)
+	if e.MaxBaseFee > 0 && fee > e.MaxBaseFee {
+		fee = e.MaxBaseFee
+	}
+	return fee, nil
+}
+
+// SetBaseFee sets the BaseFee of params to the base fee recommended for the
+// transaction to be included within target ledgers.
+func (e *FeeEstimator) SetBaseFee(ctx context.Context, params *txnbuild.TransactionParams, target InclusionTarget) error {
+	fee, err := e.BaseFee(ctx, target)
+	if err != nil {
+		return err
+	}
+	params.BaseFee = fee
+	return nil
+}
+
+// FeeBumpParams returns the parameters of a fee bump of inner paid by
+// feeAccount with the base fee recommended for the transaction to be
+// included within target ledgers. The base fee is never lower than the base
+// fee of inner, which is the minimum of a fee bump.
+func (e *FeeEstimator) FeeBumpParams(
+	ctx context.Context,
+	inner *txnbuild.Transaction,
+	feeAccount string,
+	target InclusionTarget,
+) (txnbuild.FeeBumpTransactionParams, error) {
+	fee, err := e.BaseFee(ctx, target)
+	if err != nil {
+		return txnbuild.FeeBumpTransactionParams{}, err
+	}
+	if fee < inner.BaseFee() {
+		fee = inner.BaseFee()
+	}
+	return txnbuild.FeeBumpTransactionParams{
+		Inner:      inner,
+		FeeAccount: feeAccount,
+		BaseFee:    fee,
+	}, nil
+}
+
+func (e *FeeEstimator) feeStats(ctx context.Context) (hProtocol.FeeStats, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	maxAge := e.FeeStatsMaxAge
+	if maxAge == 0 {
+		maxAge = defaultFeeStatsMaxAge
+	}
+	now := e.clock.Now()
+	if !e.fetchedAt.IsZero() && now.Sub(e.fetchedAt) < maxAge {
+		return e.stats, nil
+	}
+
+	stats, err := e.Client.FeeStatsWithContext(ctx)
+	if err != nil {
+		return hProtocol.FeeStats{}, errors.Wrap(err, "error fetching fee stats")
+	}
+	e.stats = stats
+	e.fetchedAt = now
+	return stats, nil
+}
@@ -0,0 +1,130 @@
+package orbitrclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/support/clock"
+	"github.com/lantah/go/support/clock/clocktest"
+	"github.com/lantah/go/txnbuild"
+)
+
+func testFeeStats(capacityUsage float64) hProtocol.FeeStats {
+	return hProtocol.FeeStats{
+		LastLedger:          100,
+		LastLedgerBaseFee:   100,
+		LedgerCapacityUsage: capacityUsage,
+		FeeCharged: hProtocol.FeeDistribution{
+			Min: 100,
+			P10: 100,
+			P20: 100,
+			P30: 150,
+			P40: 200,
+			P50: 300,
+			P60: 400,
+			P70: 500,
+			P80: 700,
+			P90: 1000,
+			P95: 2000,
+			P99: 5000,
+			Max: 10000,
+		},
+	}
+}
+
+func TestEstimateBaseFee(t *testing.T) {
+	for _, testCase := range []struct {
+		name          string
+		capacityUsage float64
+		target        InclusionTarget
+		expected      int64
+	}{
+		{"uncongested next ledger", 0.5, InclusionNextLedger, 100},
+		{"uncongested within 5 ledgers", 0.5, InclusionWithin5Ledgers, 100},
+		{"congested next ledger", 0.9, InclusionNextLedger, 1000},
+		{"congested within 2 ledgers", 0.9, 2, 500},
+		{"congested within 3 ledgers", 0.9, 3, 300},
+		{"congested within 5 ledgers", 0.9, InclusionWithin5Ledgers, 150},
+		{"congested within 10 ledgers", 0.9, 10, 150},
+		{"full next ledger", 1, InclusionNextLedger, 5000},
+		{"full within 5 ledgers", 1, InclusionWithin5Ledgers, 150},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			stats := testFeeStats(testCase.capacityUsage)
+			assert.Equal(t, testCase.expected, EstimateBaseFee(stats, testCase.target))
+		})
+	}
+
+	// the base fee of the last ledger is a lower bound
+	stats := testFeeStats(0.9)
+	stats.LastLedgerBaseFee = 2500
+	assert.Equal(t, int64(2500), EstimateBaseFee(stats, InclusionWithin5Ledgers))
+
+	// the minimum base fee is a lower bound
+	assert.Equal(t, int64(txnbuild.MinBaseFee), EstimateBaseFee(hProtocol.FeeStats{}, InclusionNextLedger))
+}
+
+func TestFeeEstimator(t *testing.T) {
+	ctx := context.Background()
+	client := &MockClient{}
+	client.On("FeeStatsWithContext", mock.Anything).Return(testFeeStats(0.9), nil).Once()
+
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	estimator := &FeeEstimator{
+		Client:     client,
+		MaxBaseFee: 800,
+		clock:      &clock.Clock{Source: clocktest.FixedSource(now)},
+	}
+
+	fee, err := estimator.BaseFee(ctx, InclusionNextLedger)
+	require.NoError(t, err)
+	assert.Equal(t, int64(800), fee)
+
+	// the fee stats are reused
+	params := txnbuild.TransactionParams{}
+	require.NoError(t, estimator.SetBaseFee(ctx, &params, InclusionWithin5Ledgers))
+	assert.Equal(t, int64(150), params.BaseFee)
+	client.AssertExpectations(t)
+
+	// the fee stats are fetched again once they are too old
+	client.On("FeeStatsWithContext", mock.Anything).Return(testFeeStats(0.5), nil).Once()
+	estimator.clock = &clock.Clock{Source: clocktest.FixedSource(now.Add(defaultFeeStatsMaxAge))}
+	fee, err = estimator.BaseFee(ctx, InclusionNextLedger)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), fee)
+	client.AssertExpectations(t)
+}
+
+func TestFeeEstimatorFeeBumpParams(t *testing.T) {
+	client := &MockClient{}
+	client.On("FeeStatsWithContext", mock.Anything).Return(testFeeStats(0.9), nil)
+	estimator := &FeeEstimator{Client: client}
+
+	inner, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: managerKP.Address(), Sequence: 1},
+		IncrementSequenceNum: true,
+		Operations:           managerPayment(),
+		BaseFee:              200,
+		Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()},
+	})
+	require.NoError(t, err)
+
+	params, err := estimator.FeeBumpParams(context.Background(), inner, feeBumpKP.Address(), InclusionNextLedger)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), params.BaseFee)
+	assert.Equal(t, feeBumpKP.Address(), params.FeeAccount)
+	assert.Equal(t, inner, params.Inner)
+
+	// the fee bump pays at least the base fee of the inner transaction
+	params, err = estimator.FeeBumpParams(context.Background(), inner, feeBumpKP.Address(), InclusionWithin5Ledgers)
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), params.BaseFee)
+	_, err = txnbuild.NewFeeBumpTransaction(params)
+	assert.NoError(t, err)
+}
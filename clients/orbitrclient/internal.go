@@ -123,6 +123,23 @@ func addQueryParams(params ...interface{}) string {
 	return query.Encode()
 }
 
+// historyFilterParams returns the query parameters for the type, asset and
+// ledger close time filters supported by the operations, payments and effects
+// endpoints.
+func historyFilterParams(types []string, asset string, createdAfter, createdBefore time.Time) map[string]string {
+	params := map[string]string{
+		"type":  strings.Join(types, ","),
+		"asset": asset,
+	}
+	if !createdAfter.IsZero() {
+		params["created_after"] = createdAfter.UTC().Format(time.RFC3339)
+	}
+	if !createdBefore.IsZero() {
+		params["created_before"] = createdBefore.UTC().Format(time.RFC3339)
+	}
+	return params
+}
+
 // setCurrentServerTime saves the current time returned by a orbitr server
 func setCurrentServerTime(host string, serverDate []string, clock *clock.Clock) {
 	if len(serverDate) == 0 {
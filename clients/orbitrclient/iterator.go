@@ -0,0 +1,248 @@
+package orbitrclient
+
+import (
+	"context"
+	"io"
+
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/protocols/orbitr/effects"
+	"github.com/lantah/go/protocols/orbitr/operations"
+	"github.com/lantah/go/support/render/hal"
+)
+
+// IteratorOptions configures an Iterator.
+type IteratorOptions struct {
+	// Limit is the maximum number of records returned by the iterator, all
+	// the records of the collection are returned when it is zero. The number
+	// of records fetched per page is set by the Limit of the request.
+	Limit uint
+	// Prefetch fetches the next page in the background while the records of
+	// the current page are being returned.
+	Prefetch bool
+}
+
+// Iterator returns the records of a paged collection one by one, starting at
+// the Cursor of the request it was created with and following the next links
+// returned by OrbitR. An Iterator is not safe for concurrent use, and should
+// be closed once it is no longer used when Prefetch is set.
+type Iterator[T hal.Pageable] struct {
+	fetch    func(ctx context.Context, href string) ([]T, string, error)
+	limit    uint
+	prefetch bool
+	// ctx is the context of the pages being prefetched, which outlive the
+	// Next call starting them. It is cancelled by Close.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	records  []T
+	next     string
+	done     bool
+	returned uint
+	cursor   string
+	pending  chan iteratorPage[T]
+}
+
+type iteratorPage[T hal.Pageable] struct {
+	records []T
+	next    string
+	err     error
+}
+
+// newIterator returns an Iterator over the pages of type P, the first of
+// which is returned by request. page returns the records of a page and the
+// link to the next one.
+func newIterator[T hal.Pageable, P any](
+	c *Client,
+	request OrbitRRequest,
+	options IteratorOptions,
+	page func(P) ([]T, hal.Link),
+) *Iterator[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Iterator[T]{
+		fetch: func(ctx context.Context, href string) ([]T, string, error) {
+			var p P
+			var err error
+			if href == "" {
+				err = c.sendRequest(ctx, request, &p)
+			} else {
+				err = c.sendGetRequest(ctx, href, &p)
+			}
+			if err != nil {
+				return nil, "", err
+			}
+			records, next := page(p)
+			return records, next.Href, nil
+		},
+		limit:    options.Limit,
+		prefetch: options.Prefetch,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Next returns the next record of the collection. It returns io.EOF when all
+// the records, or Limit records, have been returned. Next can be called again
+// after an error, in which case the page which could not be fetched is
+// fetched again.
+func (it *Iterator[T]) Next(ctx context.Context) (T, error) {
+	var record T
+	if it.limit > 0 && it.returned >= it.limit {
+		return record, io.EOF
+	}
+
+	for len(it.records) == 0 {
+		if it.done {
+			return record, io.EOF
+		}
+		if err := it.fetchPage(ctx); err != nil {
+			return record, err
+		}
+	}
+
+	record = it.records[0]
+	it.records = it.records[1:]
+	it.returned++
+	it.cursor = record.PagingToken()
+	return record, nil
+}
+
+// Cursor returns the paging token of the last record returned by Next, which
+// can be used as the Cursor of a request to resume iterating later.
+func (it *Iterator[T]) Cursor() string {
+	return it.cursor
+}
+
+// Close cancels the page being prefetched, if any, and stops prefetching. Next
+// can still be called afterwards, in which case the pages are fetched when
+// they are needed.
+func (it *Iterator[T]) Close() {
+	it.cancel()
+	it.prefetch = false
+	it.pending = nil
+}
+
+func (it *Iterator[T]) fetchPage(ctx context.Context) error {
+	var page iteratorPage[T]
+	if it.pending != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case page = <-it.pending:
+			it.pending = nil
+		}
+	} else {
+		page.records, page.next, page.err = it.fetch(ctx, it.next)
+	}
+	if page.err != nil {
+		return page.err
+	}
+
+	it.records = page.records
+	it.next = page.next
+	// OrbitR returns a next link on the last page too, the collection ends
+	// with the first empty page
+	it.done = len(page.records) == 0 || page.next == ""
+
+	remaining := it.limit == 0 || it.returned+uint(len(page.records)) < it.limit
+	if it.prefetch && !it.done && remaining {
+		// the channel is buffered so that the goroutine exits even if the
+		// page is never read. The page is fetched with the context of the
+		// iterator as ctx may be done before the page is needed, e.g. when
+		// each call to Next has its own timeout.
+		it.pending = make(chan iteratorPage[T], 1)
+		go func(pending chan<- iteratorPage[T], href string) {
+			var p iteratorPage[T]
+			p.records, p.next, p.err = it.fetch(it.ctx, href)
+			pending <- p
+		}(it.pending, it.next)
+	}
+	return nil
+}
+
+// AccountsIterator returns an iterator over the accounts matching request.
+func (c *Client) AccountsIterator(request AccountsRequest, options IteratorOptions) *Iterator[hProtocol.Account] {
+	return newIterator(c, request, options, func(p hProtocol.AccountsPage) ([]hProtocol.Account, hal.Link) {
+		return p.Embedded.Records, p.Links.Next
+	})
+}
+
+// AssetsIterator returns an iterator over the assets matching request.
+func (c *Client) AssetsIterator(request AssetRequest, options IteratorOptions) *Iterator[hProtocol.AssetStat] {
+	return newIterator(c, request, options, func(p hProtocol.AssetsPage) ([]hProtocol.AssetStat, hal.Link) {
+		return p.Embedded.Records, p.Links.Next
+	})
+}
+
+// LedgersIterator returns an iterator over the ledgers matching request.
+func (c *Client) LedgersIterator(request LedgerRequest, options IteratorOptions) *Iterator[hProtocol.Ledger] {
+	return newIterator(c, request, options, func(p hProtocol.LedgersPage) ([]hProtocol.Ledger, hal.Link) {
+		return p.Embedded.Records, p.Links.Next
+	})
+}
+
+// TransactionsIterator returns an iterator over the transactions matching request.
+func (c *Client) TransactionsIterator(request TransactionRequest, options IteratorOptions) *Iterator[hProtocol.Transaction] {
+	return newIterator(c, request, options, func(p hProtocol.TransactionsPage) ([]hProtocol.Transaction, hal.Link) {
+		return p.Embedded.Records, p.Links.Next
+	})
+}
+
+// OperationsIterator returns an iterator over the operations matching request.
+func (c *Client) OperationsIterator(request OperationRequest, options IteratorOptions) *Iterator[operations.Operation] {
+	return newIterator(c, request.SetOperationsEndpoint(), options, func(p operations.OperationsPage) ([]operations.Operation, hal.Link) {
+		return p.Embedded.Records, p.Links.Next
+	})
+}
+
+// PaymentsIterator returns an iterator over the payments matching request.
+func (c *Client) PaymentsIterator(request OperationRequest, options IteratorOptions) *Iterator[operations.Operation] {
+	return newIterator(c, request.SetPaymentsEndpoint(), options, func(p operations.OperationsPage) ([]operations.Operation, hal.Link) {
+		return p.Embedded.Records, p.Links.Next
+	})
+}
+
+// EffectsIterator returns an iterator over the effects matching request.
+func (c *Client) EffectsIterator(request EffectRequest, options IteratorOptions) *Iterator[effects.Effect] {
+	return newIterator(c, request, options, func(p effects.EffectsPage) ([]effects.Effect, hal.Link) {
+		return p.Embedded.Records, p.Links.Next
+	})
+}
+
+// TradesIterator returns an iterator over the trades matching request.
+func (c *Client) TradesIterator(request TradeRequest, options IteratorOptions) *Iterator[hProtocol.Trade] {
+	return newIterator(c, request, options, func(p hProtocol.TradesPage) ([]hProtocol.Trade, hal.Link) {
+		return p.Embedded.Records, p.Links.Next
+	})
+}
+
+// OffersIterator returns an iterator over the offers matching request.
+func (c *Client) OffersIterator(request OfferRequest, options IteratorOptions) *Iterator[hProtocol.Offer] {
+	return newIterator(c, request, options, func(p hProtocol.OffersPage) ([]hProtocol.Offer, hal.Link) {
+		return p.Embedded.Records, p.Links.Next
+	})
+}
+
+// claimableBalancesPage is a page of claimable balances. Unlike
+// hProtocol.ClaimableBalances, it decodes the next link.
+type claimableBalancesPage struct {
+	Links    hal.Links `json:"_links"`
+	Embedded struct {
+		Records []hProtocol.ClaimableBalance `json:"records"`
+	} `json:"_embedded"`
+}
+
+// ClaimableBalancesIterator returns an iterator over the claimable balances
+// matching request.
+func (c *Client) ClaimableBalancesIterator(request ClaimableBalanceRequest, options IteratorOptions) *Iterator[hProtocol.ClaimableBalance] {
+	return newIterator(c, request, options, func(p claimableBalancesPage) ([]hProtocol.ClaimableBalance, hal.Link) {
+		return p.Embedded.Records, p.Links.Next
+	})
+}
+
+// LiquidityPoolsIterator returns an iterator over the liquidity pools matching
+// request.
+func (c *Client) LiquidityPoolsIterator(request LiquidityPoolsRequest, options IteratorOptions) *Iterator[hProtocol.LiquidityPool] {
+	return newIterator(c, request, options, func(p hProtocol.LiquidityPoolsPage) ([]hProtocol.LiquidityPool, hal.Link) {
+		return p.Embedded.Records, p.Links.Next
+	})
+}
@@ -0,0 +1,214 @@
+package orbitrclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	stdtest "net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAccountsServer returns a server paging through records accounts whose
+// paging tokens are 1 to records. The server fails the requests for which
+// fail returns true.
+func newAccountsServer(t *testing.T, records int, fail func(request int32) bool) (*stdtest.Server, *int32) {
+	var requests int32
+	var server *stdtest.Server
+	server = stdtest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := atomic.AddInt32(&requests, 1)
+		if fail != nil && fail(request) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		cursor, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		require.NoError(t, err)
+
+		type record struct {
+			ID string `json:"id"`
+			PT string `json:"paging_token"`
+		}
+		var page struct {
+			Links struct {
+				Next struct {
+					Href string `json:"href"`
+				} `json:"next"`
+			} `json:"_links"`
+			Embedded struct {
+				Records []record `json:"records"`
+			} `json:"_embedded"`
+		}
+		page.Embedded.Records = []record{}
+		last := cursor + limit
+		for i := cursor + 1; i <= records && i <= last; i++ {
+			page.Embedded.Records = append(page.Embedded.Records, record{ID: strconv.Itoa(i), PT: strconv.Itoa(i)})
+			cursor = i
+		}
+		page.Links.Next.Href = server.URL + "/accounts?cursor=" + strconv.Itoa(cursor) + "&limit=" + strconv.Itoa(limit)
+		require.NoError(t, json.NewEncoder(w).Encode(page))
+	}))
+	return server, &requests
+}
+
+func iterateAccounts(t *testing.T, it *Iterator[hProtocol.Account]) ([]string, error) {
+	var ids []string
+	for {
+		account, err := it.Next(context.Background())
+		if err == io.EOF {
+			return ids, nil
+		}
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, account.ID)
+	}
+}
+
+func TestIteratorFollowsNextLinks(t *testing.T) {
+	server, requests := newAccountsServer(t, 5, nil)
+	defer server.Close()
+
+	client := &Client{OrbitRURL: server.URL}
+	it := client.AccountsIterator(AccountsRequest{Signer: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU", Limit: 2}, IteratorOptions{})
+	ids, err := iterateAccounts(t, it)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, ids)
+	assert.Equal(t, "5", it.Cursor())
+	// the last page is empty
+	assert.Equal(t, int32(4), atomic.LoadInt32(requests))
+
+	_, err = it.Next(context.Background())
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(requests))
+}
+
+func TestIteratorStartCursorAndLimit(t *testing.T) {
+	server, requests := newAccountsServer(t, 10, nil)
+	defer server.Close()
+
+	client := &Client{OrbitRURL: server.URL}
+	request := AccountsRequest{Signer: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU", Cursor: "2", Limit: 2}
+	it := client.AccountsIterator(request, IteratorOptions{Limit: 4})
+	ids, err := iterateAccounts(t, it)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "4", "5", "6"}, ids)
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+}
+
+func TestIteratorPrefetch(t *testing.T) {
+	server, requests := newAccountsServer(t, 5, nil)
+	defer server.Close()
+
+	client := &Client{OrbitRURL: server.URL}
+	request := AccountsRequest{Signer: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU", Limit: 2}
+	it := client.AccountsIterator(request, IteratorOptions{Prefetch: true})
+	ids, err := iterateAccounts(t, it)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, ids)
+	assert.Equal(t, int32(4), atomic.LoadInt32(requests))
+}
+
+func TestIteratorResumesAfterError(t *testing.T) {
+	for _, prefetch := range []bool{false, true} {
+		server, _ := newAccountsServer(t, 5, func(request int32) bool { return request == 2 })
+
+		client := &Client{OrbitRURL: server.URL}
+		request := AccountsRequest{Signer: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU", Limit: 2}
+		it := client.AccountsIterator(request, IteratorOptions{Prefetch: prefetch})
+		ids, err := iterateAccounts(t, it)
+		assert.Error(t, err)
+		assert.Equal(t, []string{"1", "2"}, ids)
+
+		ids, err = iterateAccounts(t, it)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"3", "4", "5"}, ids)
+		server.Close()
+	}
+}
+
+func TestIteratorPrefetchOutlivesNextContext(t *testing.T) {
+	release := make(chan struct{})
+	server, requests := newAccountsServer(t, 5, func(request int32) bool {
+		if request == 2 {
+			<-release
+		}
+		return false
+	})
+	defer server.Close()
+
+	client := &Client{OrbitRURL: server.URL}
+	request := AccountsRequest{Signer: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU", Limit: 2}
+	it := client.AccountsIterator(request, IteratorOptions{Prefetch: true})
+	defer it.Close()
+
+	// the context of the call starting the prefetch is done before the
+	// prefetched page is returned
+	ctx, cancel := context.WithCancel(context.Background())
+	account, err := it.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1", account.ID)
+	cancel()
+	close(release)
+
+	ids, err := iterateAccounts(t, it)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "3", "4", "5"}, ids)
+	assert.Equal(t, int32(4), atomic.LoadInt32(requests))
+}
+
+func TestIteratorClose(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	server, requests := newAccountsServer(t, 5, func(request int32) bool {
+		if request == 2 {
+			close(started)
+			<-release
+		}
+		return false
+	})
+	defer server.Close()
+	defer close(release)
+
+	client := &Client{OrbitRURL: server.URL}
+	request := AccountsRequest{Signer: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU", Limit: 2}
+	it := client.AccountsIterator(request, IteratorOptions{Prefetch: true})
+	account, err := it.Next(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "1", account.ID)
+
+	// the prefetched page is fetched again when it is needed
+	<-started
+	it.Close()
+	ids, err := iterateAccounts(t, it)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "3", "4", "5"}, ids)
+	assert.Equal(t, int32(5), atomic.LoadInt32(requests))
+}
+
+func TestClaimableBalancesIterator(t *testing.T) {
+	var server *stdtest.Server
+	server = stdtest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		records := `[{"id":"00000000da0d57da7d4850e7fc10d2a9d0ebc731f7afb40574c03395b17d49149b91f5be","paging_token":"1-00000000da0d57da7d4850e7fc10d2a9d0ebc731f7afb40574c03395b17d49149b91f5be"}]`
+		if r.URL.Query().Get("cursor") != "" {
+			records = `[]`
+		}
+		_, err := io.WriteString(w, `{"_links":{"next":{"href":"`+server.URL+`/claimable_balances?cursor=1"}},"_embedded":{"records":`+records+`}}`)
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	client := &Client{OrbitRURL: server.URL}
+	it := client.ClaimableBalancesIterator(ClaimableBalanceRequest{}, IteratorOptions{})
+	balance, err := it.Next(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "00000000da0d57da7d4850e7fc10d2a9d0ebc731f7afb40574c03395b17d49149b91f5be", balance.BalanceID)
+	_, err = it.Next(context.Background())
+	assert.Equal(t, io.EOF, err)
+}
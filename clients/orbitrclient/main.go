@@ -145,6 +145,30 @@ type Client struct {
 	AppVersion     string
 	orbitrTimeout time.Duration
 
+	// RetryPolicy is the policy used to retry GET requests, they are not
+	// retried when it is nil.
+	RetryPolicy *RetryPolicy
+
+	// SubmitRetryPolicy is the policy used to resubmit transactions, they
+	// are not resubmitted when it is nil. Resubmitting is safe since the
+	// same transaction envelope is sent again and OrbitR looks transactions
+	// up by hash before submitting them, so a transaction applied during a
+	// failed attempt is returned instead of being submitted twice. OrbitR
+	// reports Gravity being unavailable or asking to try again later
+	// (TRY_AGAIN_LATER) with a 503 or 504 status.
+	SubmitRetryPolicy *RetryPolicy
+
+	// FailoverURLs are the URLs of OrbitR servers on the same network as
+	// OrbitRURL. Retries are sent to OrbitRURL and FailoverURLs in turn.
+	FailoverURLs []string
+
+	// CursorStore persists the cursors of streams, so that they resume where
+	// they stopped after a restart instead of from the cursor of the request.
+	// Streams of ledgers, transactions, operations, payments, effects and
+	// trades fail with ErrStreamGap when they resume from a cursor which is
+	// not in the history of OrbitR anymore.
+	CursorStore CursorStore
+
 	// clock is a Clock returning the current time.
 	clock *clock.Clock
 }
@@ -170,6 +194,16 @@ type AdminClientInterface interface {
 	GetIngestionAssetFilter() (hProtocol.AssetFilterConfig, error)
 	SetIngestionAccountFilter(hProtocol.AccountFilterConfig) error
 	SetIngestionAssetFilter(hProtocol.AssetFilterConfig) error
+	GetIngestionOperationFilter() (hProtocol.OperationFilterConfig, error)
+	GetIngestionContractFilter() (hProtocol.ContractFilterConfig, error)
+	SetIngestionOperationFilter(hProtocol.OperationFilterConfig) error
+	SetIngestionContractFilter(hProtocol.ContractFilterConfig) error
+	GetLedgerGaps(start, end uint32) (hProtocol.LedgerGaps, error)
+	Reingest(request hProtocol.ReingestRequest) (hProtocol.ReingestJob, error)
+	FillLedgerGaps() (hProtocol.ReingestJob, error)
+	GetReingestJobs() (hProtocol.ReingestJobs, error)
+	GetReingestJob(id string) (hProtocol.ReingestJob, error)
+	CancelReingestJob(id string) (hProtocol.ReingestJob, error)
 }
 
 // ClientInterface contains methods implemented by the orbitr client
@@ -232,6 +266,56 @@ type ClientInterface interface {
 	LiquidityPools(request LiquidityPoolsRequest) (hProtocol.LiquidityPoolsPage, error)
 	NextLiquidityPoolsPage(hProtocol.LiquidityPoolsPage) (hProtocol.LiquidityPoolsPage, error)
 	PrevLiquidityPoolsPage(hProtocol.LiquidityPoolsPage) (hProtocol.LiquidityPoolsPage, error)
+	AccountsWithContext(ctx context.Context, request AccountsRequest) (hProtocol.AccountsPage, error)
+	AccountDetailWithContext(ctx context.Context, request AccountRequest) (hProtocol.Account, error)
+	AccountDataWithContext(ctx context.Context, request AccountRequest) (hProtocol.AccountData, error)
+	EffectsWithContext(ctx context.Context, request EffectRequest) (effects.EffectsPage, error)
+	AssetsWithContext(ctx context.Context, request AssetRequest) (hProtocol.AssetsPage, error)
+	LedgersWithContext(ctx context.Context, request LedgerRequest) (hProtocol.LedgersPage, error)
+	LedgerDetailWithContext(ctx context.Context, sequence uint32) (hProtocol.Ledger, error)
+	FeeStatsWithContext(ctx context.Context) (hProtocol.FeeStats, error)
+	OffersWithContext(ctx context.Context, request OfferRequest) (hProtocol.OffersPage, error)
+	OfferDetailsWithContext(ctx context.Context, offerID string) (offer hProtocol.Offer, err error)
+	OperationsWithContext(ctx context.Context, request OperationRequest) (operations.OperationsPage, error)
+	OperationDetailWithContext(ctx context.Context, id string) (operations.Operation, error)
+	SubmitTransactionXDRWithContext(ctx context.Context, transactionXdr string) (hProtocol.Transaction, error)
+	SubmitFeeBumpTransactionWithOptionsWithContext(ctx context.Context, transaction *txnbuild.FeeBumpTransaction, opts SubmitTxOpts) (hProtocol.Transaction, error)
+	SubmitTransactionWithOptionsWithContext(ctx context.Context, transaction *txnbuild.Transaction, opts SubmitTxOpts) (hProtocol.Transaction, error)
+	SubmitFeeBumpTransactionWithContext(ctx context.Context, transaction *txnbuild.FeeBumpTransaction) (hProtocol.Transaction, error)
+	SubmitTransactionWithContext(ctx context.Context, transaction *txnbuild.Transaction) (hProtocol.Transaction, error)
+	TransactionsWithContext(ctx context.Context, request TransactionRequest) (hProtocol.TransactionsPage, error)
+	TransactionDetailWithContext(ctx context.Context, txHash string) (hProtocol.Transaction, error)
+	OrderBookWithContext(ctx context.Context, request OrderBookRequest) (hProtocol.OrderBookSummary, error)
+	PathsWithContext(ctx context.Context, request PathsRequest) (hProtocol.PathsPage, error)
+	PaymentsWithContext(ctx context.Context, request OperationRequest) (operations.OperationsPage, error)
+	TradeAggregationsWithContext(ctx context.Context, request TradeAggregationRequest) (hProtocol.TradeAggregationsPage, error)
+	TradesWithContext(ctx context.Context, request TradeRequest) (hProtocol.TradesPage, error)
+	FundWithContext(ctx context.Context, addr string) (hProtocol.Transaction, error)
+	RootWithContext(ctx context.Context) (hProtocol.Root, error)
+	NextAccountsPageWithContext(context.Context, hProtocol.AccountsPage) (hProtocol.AccountsPage, error)
+	NextAssetsPageWithContext(context.Context, hProtocol.AssetsPage) (hProtocol.AssetsPage, error)
+	PrevAssetsPageWithContext(context.Context, hProtocol.AssetsPage) (hProtocol.AssetsPage, error)
+	NextLedgersPageWithContext(context.Context, hProtocol.LedgersPage) (hProtocol.LedgersPage, error)
+	PrevLedgersPageWithContext(context.Context, hProtocol.LedgersPage) (hProtocol.LedgersPage, error)
+	NextEffectsPageWithContext(context.Context, effects.EffectsPage) (effects.EffectsPage, error)
+	PrevEffectsPageWithContext(context.Context, effects.EffectsPage) (effects.EffectsPage, error)
+	NextTransactionsPageWithContext(context.Context, hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error)
+	PrevTransactionsPageWithContext(context.Context, hProtocol.TransactionsPage) (hProtocol.TransactionsPage, error)
+	NextOperationsPageWithContext(context.Context, operations.OperationsPage) (operations.OperationsPage, error)
+	PrevOperationsPageWithContext(context.Context, operations.OperationsPage) (operations.OperationsPage, error)
+	NextPaymentsPageWithContext(context.Context, operations.OperationsPage) (operations.OperationsPage, error)
+	PrevPaymentsPageWithContext(context.Context, operations.OperationsPage) (operations.OperationsPage, error)
+	NextOffersPageWithContext(context.Context, hProtocol.OffersPage) (hProtocol.OffersPage, error)
+	PrevOffersPageWithContext(context.Context, hProtocol.OffersPage) (hProtocol.OffersPage, error)
+	NextTradesPageWithContext(context.Context, hProtocol.TradesPage) (hProtocol.TradesPage, error)
+	PrevTradesPageWithContext(context.Context, hProtocol.TradesPage) (hProtocol.TradesPage, error)
+	HomeDomainForAccountWithContext(ctx context.Context, aid string) (string, error)
+	NextTradeAggregationsPageWithContext(context.Context, hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error)
+	PrevTradeAggregationsPageWithContext(context.Context, hProtocol.TradeAggregationsPage) (hProtocol.TradeAggregationsPage, error)
+	LiquidityPoolDetailWithContext(ctx context.Context, request LiquidityPoolRequest) (hProtocol.LiquidityPool, error)
+	LiquidityPoolsWithContext(ctx context.Context, request LiquidityPoolsRequest) (hProtocol.LiquidityPoolsPage, error)
+	NextLiquidityPoolsPageWithContext(context.Context, hProtocol.LiquidityPoolsPage) (hProtocol.LiquidityPoolsPage, error)
+	PrevLiquidityPoolsPageWithContext(context.Context, hProtocol.LiquidityPoolsPage) (hProtocol.LiquidityPoolsPage, error)
 }
 
 // DefaultTestNetClient is a default client to connect to test network.
@@ -280,12 +364,18 @@ type AccountRequest struct {
 // "ForAccount", "ForLedger", "ForOperation" and "ForTransaction": Not more than one of these
 // can be set at a time. If none are set, the default is to return all effects.
 // The query parameters (Order, Cursor and Limit) are optional. All or none can be set.
+// The filters (Types, Asset, CreatedAfter and CreatedBefore) are optional and can be
+// combined with any of the above. Asset is given in canonical form ("native" or "CODE:ISSUER").
 type EffectRequest struct {
 	ForAccount       string
 	ForLedger        string
 	ForLiquidityPool string
 	ForOperation     string
 	ForTransaction   string
+	Types            []string
+	Asset            string
+	CreatedAfter     time.Time
+	CreatedBefore    time.Time
 	Order            Order
 	Cursor           string
 	Limit            uint
@@ -329,16 +419,24 @@ type OfferRequest struct {
 }
 
 // OperationRequest struct contains data for getting operation details from a orbitr server.
-// "ForAccount", "ForLedger", "ForTransaction": Only one of these can be set at a time. If none
-// are provided, the default is to return all operations.
+// "ForAccount", "ForMuxedAccount", "ForLedger", "ForTransaction": Only one of these can be set
+// at a time. If none are provided, the default is to return all operations.
+// ForMuxedAccount takes a muxed (M...) address and only returns the operations it took part in.
 // The query parameters (Order, Cursor, Limit and IncludeFailed) are optional. All or none can be set.
+// The filters (Types, Asset, CreatedAfter and CreatedBefore) are optional and can be
+// combined with any of the above. Asset is given in canonical form ("native" or "CODE:ISSUER").
 type OperationRequest struct {
 	ForAccount          string
 	ForClaimableBalance string
 	ForLedger           uint
 	ForLiquidityPool    string
+	ForMuxedAccount     string
 	ForTransaction      string
 	forOperationID      string
+	Types               []string
+	Asset               string
+	CreatedAfter        time.Time
+	CreatedBefore       time.Time
 	Order               Order
 	Cursor              string
 	Limit               uint
@@ -353,14 +451,16 @@ type submitRequest struct {
 }
 
 // TransactionRequest struct contains data for getting transaction details from a orbitr server.
-// "ForAccount", "ForClaimableBalance", "ForLedger": Only one of these can be set at a time.
+// "ForAccount", "ForClaimableBalance", "ForLedger", "ForMuxedAccount": Only one of these can be set at a time.
 // If none are provided, the default is to return all transactions.
+// ForMuxedAccount takes a muxed (M...) address and only returns the transactions it took part in.
 // The query parameters (Order, Cursor, Limit and IncludeFailed) are optional. All or none can be set.
 type TransactionRequest struct {
 	ForAccount          string
 	ForClaimableBalance string
 	ForLedger           uint
 	ForLiquidityPool    string
+	ForMuxedAccount     string
 	forTransactionHash  string
 	Order               Order
 	Cursor              string
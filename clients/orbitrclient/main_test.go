@@ -1,8 +1,10 @@
 package orbitrclient
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	stdtest "net/http/httptest"
 	"testing"
 	"time"
 
@@ -165,7 +167,7 @@ func TestCheckMemoRequired(t *testing.T) {
 				).ReturnString(404, notFoundResponse)
 			}
 
-			err = client.checkMemoRequired(tx)
+			err = client.checkMemoRequired(context.Background(), tx)
 
 			if len(tc.expected) > 0 {
 				tt.Error(err)
@@ -1495,6 +1497,39 @@ func TestFetchTimebounds(t *testing.T) {
 	assert.Equal(t, st.MaxTime, int64(200))
 }
 
+func TestRequestWithContext(t *testing.T) {
+	server := stdtest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			fmt.Fprint(w, accountResponse)
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{OrbitRURL: server.URL}
+	client.SetOrbitRTimeout(10 * time.Millisecond)
+	request := AccountRequest{AccountID: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU"}
+
+	// the client timeout applies when ctx has no deadline
+	_, err := client.AccountDetailWithContext(context.Background(), request)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// a deadline set on ctx takes precedence over the client timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	account, err := client.AccountDetailWithContext(ctx, request)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU", account.AccountID)
+	}
+
+	// canceling ctx cancels the request
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = client.AccountDetailWithContext(ctx, request)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
 func TestVersion(t *testing.T) {
 	hmock := httptest.NewClient()
 	client := &Client{
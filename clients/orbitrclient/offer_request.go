@@ -75,7 +75,7 @@ func (or OfferRequest) StreamOffers(ctx context.Context, client *Client, handler
 
 	url := fmt.Sprintf("%s%s", client.fixOrbitRURL(), endpoint)
 
-	return client.stream(ctx, url, func(data []byte) error {
+	return client.stream(ctx, url, false, func(data []byte) error {
 		var offer hProtocol.Offer
 		err = json.Unmarshal(data, &offer)
 		if err != nil {
@@ -14,7 +14,7 @@ import (
 // BuildURL creates the endpoint to be queried based on the data in the OperationRequest struct.
 // If no data is set, it defaults to the build the URL for all operations or all payments; depending on thevalue of `op.endpoint`
 func (op OperationRequest) BuildURL() (endpoint string, err error) {
-	nParams := countParams(op.ForAccount, op.ForLedger, op.ForLiquidityPool, op.forOperationID, op.ForTransaction)
+	nParams := countParams(op.ForAccount, op.ForLedger, op.ForLiquidityPool, op.ForMuxedAccount, op.forOperationID, op.ForTransaction)
 
 	if nParams > 1 {
 		return endpoint, errors.New("invalid request: too many parameters")
@@ -37,6 +37,9 @@ func (op OperationRequest) BuildURL() (endpoint string, err error) {
 	if op.ForLiquidityPool != "" {
 		endpoint = fmt.Sprintf("liquidity_pools/%s/%s", op.ForLiquidityPool, op.endpoint)
 	}
+	if op.ForMuxedAccount != "" {
+		endpoint = fmt.Sprintf("muxed_accounts/%s/%s", op.ForMuxedAccount, op.endpoint)
+	}
 	if op.forOperationID != "" {
 		endpoint = fmt.Sprintf("operations/%s", op.forOperationID)
 	}
@@ -45,7 +48,8 @@ func (op OperationRequest) BuildURL() (endpoint string, err error) {
 	}
 
 	queryParams := addQueryParams(cursor(op.Cursor), limit(op.Limit), op.Order,
-		includeFailed(op.IncludeFailed), join(op.Join))
+		includeFailed(op.IncludeFailed), join(op.Join),
+		historyFilterParams(op.Types, op.Asset, op.CreatedAfter, op.CreatedBefore))
 	if queryParams != "" {
 		endpoint = fmt.Sprintf("%s?%s", endpoint, queryParams)
 	}
@@ -103,7 +107,7 @@ func (op OperationRequest) StreamOperations(ctx context.Context, client *Client,
 	}
 
 	url := fmt.Sprintf("%s%s", client.fixOrbitRURL(), endpoint)
-	return client.stream(ctx, url, func(data []byte) error {
+	return client.stream(ctx, url, true, func(data []byte) error {
 		var baseRecord operations.Base
 
 		if err = json.Unmarshal(data, &baseRecord); err != nil {
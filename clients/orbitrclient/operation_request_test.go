@@ -3,6 +3,7 @@ package orbitrclient
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/lantah/go/protocols/orbitr/operations"
 	"github.com/lantah/go/support/http/httptest"
@@ -46,6 +47,13 @@ func TestOperationRequestBuildUrl(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "liquidity_pools/123/operations", endpoint)
 
+	op = OperationRequest{ForMuxedAccount: "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK", endpoint: "payments"}
+	endpoint, err = op.BuildURL()
+
+	// It should return valid muxed account payments endpoint and no errors
+	require.NoError(t, err)
+	assert.Equal(t, "muxed_accounts/MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK/payments", endpoint)
+
 	op = OperationRequest{forOperationID: "123", endpoint: "operations"}
 	endpoint, err = op.BuildURL()
 
@@ -91,6 +99,22 @@ func TestOperationRequestBuildUrl(t *testing.T) {
 	// It should return valid all operations endpoint with query params and no errors
 	require.NoError(t, err)
 	assert.Equal(t, "operations/1234?join=transactions", endpoint)
+
+	op = OperationRequest{
+		ForAccount:    "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU",
+		Types:         []string{"path_payment_strict_send", "manage_sell_offer"},
+		Asset:         "USD:GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU",
+		CreatedAfter:  time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		CreatedBefore: time.Date(2023, 2, 2, 3, 4, 5, 0, time.UTC),
+		endpoint:      "operations",
+	}
+	endpoint, err = op.BuildURL()
+	// It should return valid account operations endpoint with the history filters and no errors
+	require.NoError(t, err)
+	assert.Equal(t, "accounts/GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU/operations?"+
+		"asset=USD%3AGCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU&"+
+		"created_after=2023-01-02T03%3A04%3A05Z&created_before=2023-02-02T03%3A04%3A05Z&"+
+		"type=path_payment_strict_send%2Cmanage_sell_offer", endpoint)
 }
 
 func TestNextOperationsPage(t *testing.T) {
@@ -61,7 +61,7 @@ func (obr OrderBookRequest) StreamOrderBooks(ctx context.Context, client *Client
 	}
 
 	url := fmt.Sprintf("%s%s", client.fixOrbitRURL(), endpoint)
-	return client.stream(ctx, url, func(data []byte) error {
+	return client.stream(ctx, url, false, func(data []byte) error {
 		var orderbook hProtocol.OrderBookSummary
 		err = json.Unmarshal(data, &orderbook)
 		if err != nil {
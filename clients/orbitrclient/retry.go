@@ -0,0 +1,194 @@
+package orbitrclient
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lantah/go/support/errors"
+)
+
+// RetryPolicy configures how a Client retries requests which failed with a
+// network error or with a 429, 502, 503 or 504 status. The delay before a
+// retry doubles after every attempt, starting at InitialBackoff and up to
+// MaxBackoff, and is randomized by up to half of its value. A longer delay
+// requested by OrbitR with the Retry-After or X-RateLimit-Reset headers takes
+// precedence. Requests are not retried when the deadline of their context
+// would pass before the delay.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a request, including
+	// the first one.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum delay between two attempts.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is a RetryPolicy suitable for most applications.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+}
+
+// maxAttempts returns the maximum number of attempts allowed by p, a nil
+// policy allows a single attempt.
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// backoff returns the randomized delay before the attempt following the
+// given one.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// retryPolicy returns the policy used to retry req. Transaction submissions
+// are the only POST requests sent by the client.
+func (c *Client) retryPolicy(req *http.Request) *RetryPolicy {
+	if req.Method == http.MethodGet {
+		return c.RetryPolicy
+	}
+	return c.SubmitRetryPolicy
+}
+
+// sendHTTPRequest sends req bound to ctx, retrying it according to the retry
+// policy of the client. The orbitr timeout of the client applies to every
+// attempt when ctx has no deadline, so that callers can set a longer or
+// shorter deadline per request.
+func (c *Client) sendHTTPRequest(ctx context.Context, req *http.Request, a interface{}) error {
+	c.setClientAppHeaders(req)
+	c.setDefaultClient()
+
+	policy := c.retryPolicy(req)
+	for attempt := 1; ; attempt++ {
+		attemptReq, err := c.requestForAttempt(req, attempt)
+		if err != nil {
+			return err
+		}
+
+		retryAfter, retryable, err := c.doHTTPRequest(ctx, attemptReq, a)
+		if !retryable || attempt >= policy.maxAttempts() || ctx.Err() != nil {
+			return err
+		}
+
+		delay := policy.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// doHTTPRequest sends req once. It returns true if the request failed in a
+// way which may not happen again when retrying it, along with the delay
+// requested by OrbitR before retrying, if any.
+func (c *Client) doHTTPRequest(ctx context.Context, req *http.Request, a interface{}) (time.Duration, bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		if c.orbitrTimeout == 0 {
+			c.orbitrTimeout = OrbitRTimeout
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.orbitrTimeout)
+		defer cancel()
+	}
+
+	resp, err := c.HTTP.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, true, err
+	}
+
+	// the status is checked before decoding the response since proxies in
+	// front of OrbitR may not respond with a problem
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		delay := retryAfter(resp.Header, c.clock.Now())
+		return delay, true, decodeResponse(resp, a, c.OrbitRURL, c.clock)
+	}
+	return 0, false, decodeResponse(resp, a, c.OrbitRURL, c.clock)
+}
+
+// requestForAttempt returns the request sent at the given attempt. Retries
+// are sent to the failover URLs of the client in turn, if any. Requests for
+// URLs which do not belong to OrbitRURL, such as links returned by OrbitR
+// behind a proxy, are always sent to the same URL.
+func (c *Client) requestForAttempt(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 {
+		return req, nil
+	}
+
+	attemptReq := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, errors.Wrap(err, "error copying HTTP request body")
+		}
+		attemptReq.Body = body
+	}
+
+	orbitrURL := c.fixOrbitRURL()
+	requestURL := req.URL.String()
+	if len(c.FailoverURLs) == 0 || !strings.HasPrefix(requestURL, orbitrURL) {
+		return attemptReq, nil
+	}
+
+	// the first attempt is sent to OrbitRURL
+	i := (attempt - 1) % (len(c.FailoverURLs) + 1)
+	if i == 0 {
+		return attemptReq, nil
+	}
+	failoverURL := strings.TrimRight(c.FailoverURLs[i-1], "/") + "/"
+	u, err := url.Parse(failoverURL + strings.TrimPrefix(requestURL, orbitrURL))
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing failover url")
+	}
+	attemptReq.URL = u
+	attemptReq.Host = u.Host
+	return attemptReq, nil
+}
+
+// retryAfter returns the delay requested by the Retry-After header, either
+// in seconds or as a date, or by the X-RateLimit-Reset header set by OrbitR
+// on rate limited requests.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if date, err := http.ParseTime(value); err == nil && date.After(now) {
+			return date.Sub(now)
+		}
+	}
+	if seconds, err := strconv.Atoi(header.Get("X-RateLimit-Reset")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 0
+}
@@ -0,0 +1,153 @@
+package orbitrclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	stdtest "net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = &RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     2 * time.Millisecond,
+}
+
+// newFlakyServer returns a server which responds with status to the first
+// failures requests and with body afterwards.
+func newFlakyServer(failures int32, status int, body string) (*stdtest.Server, *int32) {
+	var requests int32
+	server := stdtest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	return server, &requests
+}
+
+func TestRetryPolicyRetriesGetRequests(t *testing.T) {
+	server, requests := newFlakyServer(2, http.StatusServiceUnavailable, metricsResponse)
+	defer server.Close()
+
+	client := &Client{OrbitRURL: server.URL}
+	_, err := client.Root()
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+
+	atomic.StoreInt32(requests, 0)
+	client = &Client{OrbitRURL: server.URL, RetryPolicy: testRetryPolicy}
+	_, err = client.Root()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+}
+
+func TestRetryPolicyGivesUpAfterMaxAttempts(t *testing.T) {
+	server, requests := newFlakyServer(5, http.StatusBadGateway, metricsResponse)
+	defer server.Close()
+
+	client := &Client{OrbitRURL: server.URL, RetryPolicy: testRetryPolicy}
+	_, err := client.Root()
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+}
+
+func TestRetryPolicyDoesNotRetryClientErrors(t *testing.T) {
+	server, requests := newFlakyServer(1, http.StatusBadRequest, metricsResponse)
+	defer server.Close()
+
+	client := &Client{OrbitRURL: server.URL, RetryPolicy: testRetryPolicy}
+	_, err := client.Root()
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+}
+
+func TestRetryPolicyStopsAtContextDeadline(t *testing.T) {
+	server := stdtest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := &Client{OrbitRURL: server.URL, RetryPolicy: testRetryPolicy}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.RootWithContext(ctx)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestSubmitRetryPolicy(t *testing.T) {
+	var requests int32
+	var bodies []string
+	server := stdtest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		fmt.Fprint(w, txSuccess)
+	}))
+	defer server.Close()
+
+	// the retry policy of GET requests does not apply to submissions
+	client := &Client{OrbitRURL: server.URL, RetryPolicy: testRetryPolicy}
+	txXdr := `AAAAABB90WssODNIgi6BHveqzxTRmIpvAFRyVNM+Hm2GVuCcAAAAZAAABD0AAuV/AAAAAAAAAAAAAAABAAAAAAAAAAAAAAAAyTBGxOgfSApppsTnb/YRr6gOR8WT0LZNrhLh4y3FCgoAAAAXSHboAAAAAAAAAAABhlbgnAAAAEAivKe977CQCxMOKTuj+cWTFqc2OOJU8qGr9afrgu2zDmQaX5Q0cNshc3PiBwe0qw/+D/qJk5QqM5dYeSUGeDQP`
+	_, err := client.SubmitTransactionXDR(txXdr)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	atomic.StoreInt32(&requests, 0)
+	bodies = nil
+	client = &Client{OrbitRURL: server.URL, SubmitRetryPolicy: testRetryPolicy}
+	tx, err := client.SubmitTransactionXDR(txXdr)
+	require.NoError(t, err)
+	assert.Equal(t, "bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca", tx.Hash)
+	require.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0], bodies[1])
+}
+
+func TestFailoverURLs(t *testing.T) {
+	primary, primaryRequests := newFlakyServer(100, http.StatusServiceUnavailable, "")
+	defer primary.Close()
+	failover, failoverRequests := newFlakyServer(0, http.StatusOK, accountResponse)
+	defer failover.Close()
+
+	client := &Client{
+		OrbitRURL:    primary.URL,
+		FailoverURLs: []string{failover.URL + "/"},
+		RetryPolicy:  testRetryPolicy,
+	}
+	account, err := client.AccountDetail(AccountRequest{AccountID: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU"})
+	require.NoError(t, err)
+	assert.Equal(t, "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU", account.AccountID)
+	assert.Equal(t, int32(1), atomic.LoadInt32(primaryRequests))
+	assert.Equal(t, int32(1), atomic.LoadInt32(failoverRequests))
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2019, 6, 19, 12, 24, 56, 0, time.UTC)
+
+	header := http.Header{}
+	assert.Equal(t, time.Duration(0), retryAfter(header, now))
+
+	header.Set("X-RateLimit-Reset", "30")
+	assert.Equal(t, 30*time.Second, retryAfter(header, now))
+
+	header.Set("Retry-After", "5")
+	assert.Equal(t, 5*time.Second, retryAfter(header, now))
+
+	header.Set("Retry-After", "Wed, 19 Jun 2019 12:25:56 GMT")
+	assert.Equal(t, time.Minute, retryAfter(header, now))
+}
@@ -93,7 +93,7 @@ func (tr TradeRequest) StreamTrades(ctx context.Context, client *Client,
 
 	url := fmt.Sprintf("%s%s", client.fixOrbitRURL(), endpoint)
 
-	return client.stream(ctx, url, func(data []byte) error {
+	return client.stream(ctx, url, true, func(data []byte) error {
 		var trade hProtocol.Trade
 		err = json.Unmarshal(data, &trade)
 		if err != nil {
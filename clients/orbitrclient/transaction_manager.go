@@ -0,0 +1,391 @@
+package orbitrclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/lantah/go/keypair"
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/txnbuild"
+)
+
+const (
+	// defaultTransactionTimeout is the validity of the transactions built by
+	// a TransactionManager when TransactionTimeout is not set.
+	defaultTransactionTimeout = 5 * time.Minute
+	// defaultMaxRebuilds is the number of times a transaction is rebuilt
+	// after failing with tx_bad_seq when MaxRebuilds is not set.
+	defaultMaxRebuilds = 3
+	// feeBumpMultiplier is the factor by which the fee of a stuck transaction
+	// is increased. Gravity only replaces a transaction waiting in its queue
+	// with a fee bump paying at least ten times its fee.
+	feeBumpMultiplier = 10
+)
+
+// ErrTransactionManagerStopped is the error reported for the transactions
+// still queued when the TransactionManager stops.
+var ErrTransactionManagerStopped = errors.New("transaction manager stopped")
+
+// TransactionManagerConfig configures a TransactionManager.
+type TransactionManagerConfig struct {
+	// Client submits the transactions.
+	Client ClientInterface
+	// NetworkPassphrase is the passphrase of the network the transactions
+	// are signed for.
+	NetworkPassphrase string
+	// SourceAccount is the source account of the transactions. Its sequence
+	// number is owned by the manager, no other transaction must use it as
+	// source account while the manager is running.
+	SourceAccount string
+	// Signers sign the transactions.
+	Signers []*keypair.Full
+	// BaseFee is the base fee of the transactions, txnbuild.MinBaseFee if
+	// zero.
+	BaseFee int64
+	// FeeEstimator, if set, recommends the base fee of the transactions and
+	// of their fee bumps, BaseFee being used when the fee stats of OrbitR
+	// cannot be fetched.
+	FeeEstimator *FeeEstimator
+	// InclusionTarget is the number of ledgers within which the transactions
+	// should be included according to FeeEstimator, InclusionNextLedger if
+	// zero.
+	InclusionTarget InclusionTarget
+	// FeeBumpAccount pays the fee bumps of stuck transactions. Stuck
+	// transactions are resubmitted as they are when it is nil.
+	FeeBumpAccount *keypair.Full
+	// MaxFeeBumpBaseFee is the maximum base fee of the fee bumps, which must
+	// be higher than BaseFee for stuck transactions to be fee bumped.
+	MaxFeeBumpBaseFee int64
+	// TransactionTimeout is how long transactions are valid after they are
+	// built, 5 minutes if zero. A transaction which is still stuck when it
+	// expires fails.
+	TransactionTimeout time.Duration
+	// MaxConcurrency is the maximum number of transactions submitted at the
+	// same time, 1 if zero. OrbitR holds transactions until their sequence
+	// number is next, so consecutive transactions can be in flight together.
+	MaxConcurrency int
+	// QueueSize is the number of transactions which can be queued before
+	// Submit blocks.
+	QueueSize int
+	// MaxRebuilds is the maximum number of times a transaction is rebuilt
+	// with a new sequence number after failing with tx_bad_seq, 3 if zero.
+	MaxRebuilds int
+	// SubmitTxOpts are the options of the submissions.
+	SubmitTxOpts SubmitTxOpts
+}
+
+// QueuedTransaction is a transaction to be built and submitted by a
+// TransactionManager.
+type QueuedTransaction struct {
+	Operations []txnbuild.Operation
+	Memo       txnbuild.Memo
+	// Callback, if set, is called with the final result of the transaction.
+	Callback func(TransactionResult)
+}
+
+// TransactionResult is the final result of a QueuedTransaction.
+type TransactionResult struct {
+	// Hash is the hash of the last transaction submitted, which is a fee bump
+	// transaction if the transaction was fee bumped. It is empty if the
+	// transaction was never submitted.
+	Hash string
+	// Transaction is the transaction included in the ledger, when Err is nil.
+	Transaction hProtocol.Transaction
+	Err         error
+}
+
+// TransactionManager builds, signs and submits the transactions of a single
+// source account. It tracks the sequence number of the account locally so
+// that several transactions can be submitted concurrently, and resyncs it
+// from OrbitR when a transaction fails with tx_bad_seq. Transactions whose
+// submission times out are resubmitted, fee bumped if FeeBumpAccount is set,
+// until they are included in a ledger or expire.
+type TransactionManager struct {
+	config        TransactionManagerConfig
+	queue         chan QueuedTransaction
+	resubmitDelay time.Duration
+
+	lock       sync.Mutex
+	sequence   int64
+	generation int
+}
+
+// NewTransactionManager returns a TransactionManager, which does not submit
+// any transaction before Run is called.
+func NewTransactionManager(config TransactionManagerConfig) *TransactionManager {
+	if config.BaseFee == 0 {
+		config.BaseFee = txnbuild.MinBaseFee
+	}
+	if config.TransactionTimeout == 0 {
+		config.TransactionTimeout = defaultTransactionTimeout
+	}
+	if config.InclusionTarget == 0 {
+		config.InclusionTarget = InclusionNextLedger
+	}
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = 1
+	}
+	if config.MaxRebuilds == 0 {
+		config.MaxRebuilds = defaultMaxRebuilds
+	}
+	return &TransactionManager{
+		config:        config,
+		queue:         make(chan QueuedTransaction, config.QueueSize),
+		resubmitDelay: time.Second,
+	}
+}
+
+// Submit queues tx, blocking while the queue is full. Its result is reported
+// to tx.Callback once it is final.
+func (m *TransactionManager) Submit(ctx context.Context, tx QueuedTransaction) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case m.queue <- tx:
+		return nil
+	}
+}
+
+// Run loads the sequence number of the source account and submits the queued
+// transactions until ctx is done. The transactions still queued when Run
+// returns are reported with ErrTransactionManagerStopped.
+func (m *TransactionManager) Run(ctx context.Context) error {
+	if err := m.resync(ctx, m.generation); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < m.config.MaxConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case tx := <-m.queue:
+					m.report(tx, m.process(ctx, tx))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for {
+		select {
+		case tx := <-m.queue:
+			m.report(tx, TransactionResult{Err: ErrTransactionManagerStopped})
+		default:
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *TransactionManager) report(tx QueuedTransaction, result TransactionResult) {
+	if tx.Callback != nil {
+		tx.Callback(result)
+	}
+}
+
+// process builds and submits tx until its result is final, rebuilding it
+// with a new sequence number when it fails with tx_bad_seq.
+func (m *TransactionManager) process(ctx context.Context, queued QueuedTransaction) TransactionResult {
+	var result TransactionResult
+	for rebuilds := 0; ; rebuilds++ {
+		tx, generation, err := m.build(queued, m.baseFee(ctx))
+		if err != nil {
+			result.Err = err
+			return result
+		}
+
+		result = m.submit(ctx, tx)
+		if !isBadSequenceError(result.Err) || rebuilds >= m.config.MaxRebuilds {
+			return result
+		}
+		if err := m.resync(ctx, generation); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+}
+
+// build returns tx signed with the next sequence number, and the generation
+// of the sequence number it was built with.
+func (m *TransactionManager) build(queued QueuedTransaction, baseFee int64) (*txnbuild.Transaction, int, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount: &txnbuild.SimpleAccount{
+			AccountID: m.config.SourceAccount,
+			Sequence:  m.sequence,
+		},
+		IncrementSequenceNum: true,
+		Operations:           queued.Operations,
+		BaseFee:              baseFee,
+		Memo:                 queued.Memo,
+		Preconditions: txnbuild.Preconditions{
+			TimeBounds: txnbuild.NewTimeout(int64(m.config.TransactionTimeout / time.Second)),
+		},
+	})
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "error building transaction")
+	}
+	tx, err = tx.Sign(m.config.NetworkPassphrase, m.config.Signers...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "error signing transaction")
+	}
+
+	// the sequence number is only used once the transaction is built, so
+	// that a transaction which cannot be built does not leave a gap
+	m.sequence = tx.SequenceNumber()
+	return tx, m.generation, nil
+}
+
+// baseFee returns the base fee recommended by FeeEstimator, or BaseFee.
+func (m *TransactionManager) baseFee(ctx context.Context) int64 {
+	if m.config.FeeEstimator == nil {
+		return m.config.BaseFee
+	}
+	fee, err := m.config.FeeEstimator.BaseFee(ctx, m.config.InclusionTarget)
+	if err != nil {
+		return m.config.BaseFee
+	}
+	return fee
+}
+
+// resync reloads the sequence number of the source account, unless it was
+// already reloaded since generation.
+func (m *TransactionManager) resync(ctx context.Context, generation int) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if generation != m.generation {
+		return nil
+	}
+
+	account, err := m.config.Client.AccountDetailWithContext(ctx, AccountRequest{AccountID: m.config.SourceAccount})
+	if err != nil {
+		return errors.Wrap(err, "error loading sequence number")
+	}
+	m.sequence = account.Sequence
+	m.generation++
+	return nil
+}
+
+// submit submits tx until its result is final. When the submission times
+// out, tx is resubmitted, fee bumped if possible. Resubmitting is safe as
+// OrbitR returns the result of a transaction already included in a ledger.
+func (m *TransactionManager) submit(ctx context.Context, tx *txnbuild.Transaction) TransactionResult {
+	var result TransactionResult
+	var feeBump *txnbuild.FeeBumpTransaction
+	baseFee := tx.BaseFee()
+	maxTime := time.Unix(tx.Timebounds().MaxTime, 0)
+
+	for {
+		var err error
+		if feeBump == nil {
+			result.Hash, err = tx.HashHex(m.config.NetworkPassphrase)
+			if err == nil {
+				result.Transaction, err = m.config.Client.SubmitTransactionWithOptionsWithContext(ctx, tx, m.config.SubmitTxOpts)
+			}
+		} else {
+			result.Hash, err = feeBump.HashHex(m.config.NetworkPassphrase)
+			if err == nil {
+				result.Transaction, err = m.config.Client.SubmitFeeBumpTransactionWithOptionsWithContext(ctx, feeBump, m.config.SubmitTxOpts)
+			}
+		}
+		result.Err = err
+		if err == nil || !isStuckError(err) || ctx.Err() != nil || time.Now().After(maxTime) {
+			return result
+		}
+
+		if m.config.FeeBumpAccount != nil {
+			bumped, err := m.feeBump(ctx, tx, baseFee)
+			if err != nil {
+				result.Err = err
+				return result
+			}
+			if bumped != nil {
+				feeBump = bumped
+				baseFee = bumped.BaseFee()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return result
+		case <-time.After(m.resubmitDelay):
+		}
+	}
+}
+
+// feeBump returns tx fee bumped with a base fee higher than baseFee, or nil
+// if the base fee cannot be raised anymore.
+func (m *TransactionManager) feeBump(ctx context.Context, tx *txnbuild.Transaction, baseFee int64) (*txnbuild.FeeBumpTransaction, error) {
+	next := baseFee * feeBumpMultiplier
+	if estimate := m.baseFee(ctx); estimate > next {
+		next = estimate
+	}
+	if next > m.config.MaxFeeBumpBaseFee {
+		next = m.config.MaxFeeBumpBaseFee
+	}
+	if next <= baseFee {
+		return nil, nil
+	}
+
+	feeBump, err := txnbuild.NewFeeBumpTransaction(txnbuild.FeeBumpTransactionParams{
+		Inner:      tx,
+		FeeAccount: m.config.FeeBumpAccount.Address(),
+		BaseFee:    next,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error building fee bump transaction")
+	}
+	feeBump, err = feeBump.Sign(m.config.NetworkPassphrase, m.config.FeeBumpAccount)
+	if err != nil {
+		return nil, errors.Wrap(err, "error signing fee bump transaction")
+	}
+	return feeBump, nil
+}
+
+// isBadSequenceError returns true if err is a submission error for a
+// transaction, or the inner transaction of a fee bump, failing with
+// tx_bad_seq.
+func isBadSequenceError(err error) bool {
+	hErr := GetError(err)
+	if hErr == nil {
+		return false
+	}
+	codes, err := hErr.ResultCodes()
+	if err != nil {
+		return false
+	}
+	return codes.TransactionCode == "tx_bad_seq" ||
+		(codes.TransactionCode == "tx_fee_bump_inner_failed" && codes.InnerTransactionCode == "tx_bad_seq")
+}
+
+// isStuckError returns true if err leaves the outcome of a submission
+// unknown, which is the case when OrbitR times out waiting for the
+// transaction to be included in a ledger or cannot be reached.
+func isStuckError(err error) bool {
+	if _, ok := errors.Cause(err).(*url.Error); ok {
+		return true
+	}
+	hErr := GetError(err)
+	if hErr == nil {
+		return false
+	}
+	status := hErr.Problem.Status
+	if hErr.Response != nil {
+		status = hErr.Response.StatusCode
+	}
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package orbitrclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lantah/go/keypair"
+	"github.com/lantah/go/network"
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/support/render/problem"
+	"github.com/lantah/go/txnbuild"
+)
+
+var (
+	managerKP = keypair.MustParseFull("SDQQUZMIPUP5TSDWH3UJYAKUOP55IJ4KTBXTY7RCOMEFRQGYA6GIR3OD")
+	feeBumpKP = keypair.MustParseFull("SA5ZEFDVFZ52GRU7YUGR6EDPBNRU2WLA6IQFQ7S2IH2DG3VFV3DOMV2Q")
+)
+
+func managerPayment() []txnbuild.Operation {
+	return []txnbuild.Operation{&txnbuild.Payment{
+		Destination: "GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR",
+		Amount:      "10",
+		Asset:       txnbuild.NativeAsset{},
+	}}
+}
+
+func withSequence(sequence int64) interface{} {
+	return mock.MatchedBy(func(tx *txnbuild.Transaction) bool {
+		return tx.SequenceNumber() == sequence
+	})
+}
+
+// runTransactionManager submits count payments with manager and returns their
+// results.
+func runTransactionManager(t *testing.T, manager *TransactionManager, count int) []TransactionResult {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- manager.Run(ctx) }()
+
+	results := make(chan TransactionResult, count)
+	for i := 0; i < count; i++ {
+		err := manager.Submit(ctx, QueuedTransaction{
+			Operations: managerPayment(),
+			Callback:   func(result TransactionResult) { results <- result },
+		})
+		require.NoError(t, err)
+	}
+
+	var collected []TransactionResult
+	for i := 0; i < count; i++ {
+		select {
+		case result := <-results:
+			collected = append(collected, result)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for transaction results")
+		}
+	}
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+	return collected
+}
+
+func TestTransactionManagerTracksSequence(t *testing.T) {
+	client := &MockClient{}
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: managerKP.Address()}).
+		Return(hProtocol.Account{Sequence: 100}, nil).Once()
+	client.On("SubmitTransactionWithOptionsWithContext", mock.Anything, withSequence(101), SubmitTxOpts{}).
+		Return(hProtocol.Transaction{Successful: true, Ledger: 1}, nil).Once()
+	client.On("SubmitTransactionWithOptionsWithContext", mock.Anything, withSequence(102), SubmitTxOpts{}).
+		Return(hProtocol.Transaction{Successful: true, Ledger: 2}, nil).Once()
+
+	manager := NewTransactionManager(TransactionManagerConfig{
+		Client:            client,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		SourceAccount:     managerKP.Address(),
+		Signers:           []*keypair.Full{managerKP},
+	})
+	results := runTransactionManager(t, manager, 2)
+	for i, result := range results {
+		require.NoError(t, result.Err)
+		assert.Equal(t, int32(i+1), result.Transaction.Ledger)
+		assert.Len(t, result.Hash, 64)
+	}
+	client.AssertExpectations(t)
+}
+
+func TestTransactionManagerResyncsOnBadSequence(t *testing.T) {
+	badSeq := &Error{
+		Response: &http.Response{StatusCode: http.StatusBadRequest},
+		Problem: problem.P{
+			Status: http.StatusBadRequest,
+			Extras: map[string]interface{}{
+				"result_codes": map[string]interface{}{"transaction": "tx_bad_seq"},
+			},
+		},
+	}
+
+	client := &MockClient{}
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: managerKP.Address()}).
+		Return(hProtocol.Account{Sequence: 100}, nil).Once()
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: managerKP.Address()}).
+		Return(hProtocol.Account{Sequence: 200}, nil).Once()
+	client.On("SubmitTransactionWithOptionsWithContext", mock.Anything, withSequence(101), SubmitTxOpts{}).
+		Return(hProtocol.Transaction{}, badSeq).Once()
+	client.On("SubmitTransactionWithOptionsWithContext", mock.Anything, withSequence(201), SubmitTxOpts{}).
+		Return(hProtocol.Transaction{Successful: true}, nil).Once()
+
+	manager := NewTransactionManager(TransactionManagerConfig{
+		Client:            client,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		SourceAccount:     managerKP.Address(),
+		Signers:           []*keypair.Full{managerKP},
+	})
+	results := runTransactionManager(t, manager, 1)
+	require.NoError(t, results[0].Err)
+	assert.True(t, results[0].Transaction.Successful)
+	client.AssertExpectations(t)
+}
+
+func TestTransactionManagerFeeBumpsStuckTransactions(t *testing.T) {
+	timeout := &Error{
+		Response: &http.Response{StatusCode: http.StatusGatewayTimeout},
+		Problem:  problem.P{Status: http.StatusGatewayTimeout},
+	}
+
+	client := &MockClient{}
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: managerKP.Address()}).
+		Return(hProtocol.Account{Sequence: 100}, nil).Once()
+	client.On("SubmitTransactionWithOptionsWithContext", mock.Anything, withSequence(101), SubmitTxOpts{}).
+		Return(hProtocol.Transaction{}, timeout).Once()
+	var feeBump *txnbuild.FeeBumpTransaction
+	client.On("SubmitFeeBumpTransactionWithOptionsWithContext", mock.Anything, mock.Anything, SubmitTxOpts{}).
+		Run(func(args mock.Arguments) { feeBump = args.Get(1).(*txnbuild.FeeBumpTransaction) }).
+		Return(hProtocol.Transaction{Successful: true}, nil).Once()
+
+	manager := NewTransactionManager(TransactionManagerConfig{
+		Client:            client,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		SourceAccount:     managerKP.Address(),
+		Signers:           []*keypair.Full{managerKP},
+		FeeBumpAccount:    feeBumpKP,
+		MaxFeeBumpBaseFee: 5000,
+	})
+	manager.resubmitDelay = time.Millisecond
+	results := runTransactionManager(t, manager, 1)
+	require.NoError(t, results[0].Err)
+	client.AssertExpectations(t)
+
+	require.NotNil(t, feeBump)
+	assert.Equal(t, int64(1000), feeBump.BaseFee())
+	assert.Equal(t, feeBumpKP.Address(), feeBump.FeeAccount())
+	assert.Equal(t, int64(101), feeBump.InnerTransaction().SequenceNumber())
+	hash, err := feeBump.HashHex(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, hash, results[0].Hash)
+}
+
+func TestTransactionManagerReportsFailures(t *testing.T) {
+	failed := &Error{
+		Response: &http.Response{StatusCode: http.StatusBadRequest},
+		Problem: problem.P{
+			Status: http.StatusBadRequest,
+			Extras: map[string]interface{}{
+				"result_codes": map[string]interface{}{
+					"transaction": "tx_failed",
+					"operations":  []string{"op_underfunded"},
+				},
+			},
+		},
+	}
+
+	client := &MockClient{}
+	client.On("AccountDetailWithContext", mock.Anything, AccountRequest{AccountID: managerKP.Address()}).
+		Return(hProtocol.Account{Sequence: 100}, nil).Once()
+	client.On("SubmitTransactionWithOptionsWithContext", mock.Anything, withSequence(101), SubmitTxOpts{}).
+		Return(hProtocol.Transaction{}, failed).Once()
+
+	manager := NewTransactionManager(TransactionManagerConfig{
+		Client:            client,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		SourceAccount:     managerKP.Address(),
+		Signers:           []*keypair.Full{managerKP},
+	})
+	results := runTransactionManager(t, manager, 1)
+	assert.Equal(t, failed, results[0].Err)
+	client.AssertExpectations(t)
+}
@@ -14,7 +14,7 @@ import (
 // BuildURL creates the endpoint to be queried based on the data in the TransactionRequest struct.
 // If no data is set, it defaults to the build the URL for all transactions
 func (tr TransactionRequest) BuildURL() (endpoint string, err error) {
-	nParams := countParams(tr.ForAccount, tr.ForLedger, tr.ForLiquidityPool, tr.forTransactionHash)
+	nParams := countParams(tr.ForAccount, tr.ForLedger, tr.ForLiquidityPool, tr.ForMuxedAccount, tr.forTransactionHash)
 
 	if nParams > 1 {
 		return endpoint, errors.New("invalid request: too many parameters")
@@ -33,6 +33,9 @@ func (tr TransactionRequest) BuildURL() (endpoint string, err error) {
 	if tr.ForLiquidityPool != "" {
 		endpoint = fmt.Sprintf("liquidity_pools/%s/transactions", tr.ForLiquidityPool)
 	}
+	if tr.ForMuxedAccount != "" {
+		endpoint = fmt.Sprintf("muxed_accounts/%s/transactions", tr.ForMuxedAccount)
+	}
 	if tr.forTransactionHash != "" {
 		endpoint = fmt.Sprintf("transactions/%s", tr.forTransactionHash)
 	}
@@ -75,7 +78,7 @@ func (tr TransactionRequest) StreamTransactions(ctx context.Context, client *Cli
 
 	url := fmt.Sprintf("%s%s", client.fixOrbitRURL(), endpoint)
 
-	return client.stream(ctx, url, func(data []byte) error {
+	return client.stream(ctx, url, true, func(data []byte) error {
 		var transaction hProtocol.Transaction
 		err = json.Unmarshal(data, &transaction)
 		if err != nil {
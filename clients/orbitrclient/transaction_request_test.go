@@ -46,6 +46,13 @@ func TestTransactionRequestBuildUrl(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "liquidity_pools/123/transactions", endpoint)
 
+	tr = TransactionRequest{ForMuxedAccount: "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK"}
+	endpoint, err = tr.BuildURL()
+
+	// It should return valid muxed account transactions endpoint and no errors
+	require.NoError(t, err)
+	assert.Equal(t, "muxed_accounts/MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK/transactions", endpoint)
+
 	tr = TransactionRequest{forTransactionHash: "123"}
 	endpoint, err = tr.BuildURL()
 
@@ -25,6 +25,7 @@ type S3ArchiveBackend struct {
 	bucket           string
 	prefix           string
 	unsignedRequests bool
+	objectACL        string
 }
 
 func (b *S3ArchiveBackend) GetFile(pth string) (io.ReadCloser, error) {
@@ -121,7 +122,7 @@ func (b *S3ArchiveBackend) PutFile(pth string, in io.ReadCloser) error {
 	params := &s3.PutObjectInput{
 		Bucket: aws.String(b.bucket),
 		Key:    aws.String(key),
-		ACL:    aws.String(s3.ObjectCannedACLPublicRead),
+		ACL:    aws.String(b.objectACL),
 		Body:   bytes.NewReader(buf.Bytes()),
 	}
 	req, _ := b.svc.PutObjectRequest(params)
@@ -210,12 +211,18 @@ func makeS3Backend(bucket string, prefix string, opts ConnectOptions) (ArchiveBa
 		return nil, err
 	}
 
+	objectACL := opts.S3ObjectACL
+	if objectACL == "" {
+		objectACL = s3.ObjectCannedACLPublicRead
+	}
+
 	backend := S3ArchiveBackend{
 		ctx:              opts.Context,
 		svc:              s3.New(sess),
 		bucket:           bucket,
 		prefix:           prefix,
 		unsignedRequests: opts.UnsignedRequests,
+		objectACL:        objectACL,
 	}
 	return &backend, nil
 }
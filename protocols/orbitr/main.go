@@ -840,6 +840,44 @@ type LiquidityPoolReserve struct {
 	Amount string `json:"amount"`
 }
 
+// LedgerEntryChange represents a change of a ledger entry applied by a
+// transaction. PreEntry and PostEntry are the base64 encoded XDR LedgerEntry
+// before and after the change; PreEntry is empty for created entries and
+// PostEntry for removed entries. OperationID is empty for the changes made by
+// the transaction itself (fees, sequence numbers, ...) rather than one of its
+// operations.
+type LedgerEntryChange struct {
+	Links struct {
+		Transaction hal.Link  `json:"transaction"`
+		Operation   *hal.Link `json:"operation,omitempty"`
+	} `json:"_links"`
+
+	ID              string    `json:"id"`
+	PT              string    `json:"paging_token"`
+	Ledger          int32     `json:"ledger"`
+	LedgerCloseTime time.Time `json:"closed_at"`
+	TransactionHash string    `json:"transaction_hash"`
+	OperationID     string    `json:"operation_id,omitempty"`
+	LedgerKey       string    `json:"ledger_key"`
+	EntryType       string    `json:"entry_type"`
+	ChangeType      string    `json:"change_type"`
+	PreEntry        string    `json:"pre_entry,omitempty"`
+	PostEntry       string    `json:"post_entry,omitempty"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res LedgerEntryChange) PagingToken() string {
+	return res.PT
+}
+
+// LedgerEntryChangesPage returns a list of ledger entry change records
+type LedgerEntryChangesPage struct {
+	Links    hal.Links `json:"_links"`
+	Embedded struct {
+		Records []LedgerEntryChange `json:"records"`
+	} `json:"_embedded"`
+}
+
 type AssetFilterConfig struct {
 	Whitelist    []string `json:"whitelist"`
 	Enabled      *bool    `json:"enabled"`
@@ -891,3 +929,136 @@ func (f *AssetFilterConfig) UnmarshalJSON(data []byte) error {
 	*f = AssetFilterConfig(config)
 	return nil
 }
+
+// OperationFilterConfig lists operation types by the names used in the
+// operation resources, e.g. "payment" or "invoke_host_function".
+type OperationFilterConfig struct {
+	Whitelist    []string `json:"whitelist"`
+	Blacklist    []string `json:"blacklist"`
+	Enabled      *bool    `json:"enabled"`
+	LastModified int64    `json:"last_modified,omitempty"`
+}
+
+// ContractFilterConfig lists contract ids as strkeys (C...).
+type ContractFilterConfig struct {
+	Whitelist    []string `json:"whitelist"`
+	Enabled      *bool    `json:"enabled"`
+	LastModified int64    `json:"last_modified,omitempty"`
+}
+
+func (f *OperationFilterConfig) UnmarshalJSON(data []byte) error {
+	type operationFilterConfig OperationFilterConfig
+	var config = operationFilterConfig{}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return err
+	}
+
+	if config.Whitelist == nil {
+		return errors.New("missing required whitelist")
+	}
+
+	if config.Blacklist == nil {
+		return errors.New("missing required blacklist")
+	}
+
+	if config.Enabled == nil {
+		return errors.New("missing required enabled")
+	}
+
+	*f = OperationFilterConfig(config)
+	return nil
+}
+
+func (f *ContractFilterConfig) UnmarshalJSON(data []byte) error {
+	type contractFilterConfig ContractFilterConfig
+	var config = contractFilterConfig{}
+
+	if err := json.Unmarshal(data, &config); err != nil {
+		return err
+	}
+
+	if config.Whitelist == nil {
+		return errors.New("missing required whitelist")
+	}
+
+	if config.Enabled == nil {
+		return errors.New("missing required enabled")
+	}
+
+	*f = ContractFilterConfig(config)
+	return nil
+}
+
+// LedgerRange is an inclusive range of ledgers, used by the admin
+// reingestion endpoints.
+type LedgerRange struct {
+	Start uint32 `json:"start"`
+	End   uint32 `json:"end"`
+}
+
+// LedgerGaps is the response of the admin endpoint listing the ranges of
+// ledgers missing from history.
+type LedgerGaps struct {
+	Gaps []LedgerRange `json:"gaps"`
+}
+
+// ReingestRequest is the request body of the admin endpoint enqueuing
+// reingestion jobs. Either Ranges is set or FillGaps, in which case the
+// detected gaps are reingested.
+type ReingestRequest struct {
+	Ranges   []LedgerRange `json:"ranges,omitempty"`
+	FillGaps bool          `json:"fill_gaps,omitempty"`
+	Force    bool          `json:"force,omitempty"`
+}
+
+// ReingestJob is the status of a reingestion job enqueued with the admin API.
+type ReingestJob struct {
+	ID              string        `json:"id"`
+	Status          string        `json:"status"`
+	Ranges          []LedgerRange `json:"ranges"`
+	Force           bool          `json:"force"`
+	TotalLedgers    uint32        `json:"total_ledgers"`
+	IngestedLedgers uint32        `json:"ingested_ledgers"`
+	CurrentLedger   uint32        `json:"current_ledger,omitempty"`
+	Error           string        `json:"error,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	StartedAt       *time.Time    `json:"started_at,omitempty"`
+	FinishedAt      *time.Time    `json:"finished_at,omitempty"`
+}
+
+// ReingestJobs is the response of the admin endpoint listing reingestion jobs.
+type ReingestJobs struct {
+	Jobs []ReingestJob `json:"jobs"`
+}
+
+// ArchivedLedgerEntry is the response of the admin endpoint looking up a
+// ledger entry at a checkpoint in the history archives. Key and Entry are
+// base64 encoded XDR.
+type ArchivedLedgerEntry struct {
+	Checkpoint         uint32 `json:"checkpoint"`
+	Key                string `json:"key"`
+	Entry              string `json:"entry"`
+	LastModifiedLedger uint32 `json:"last_modified_ledger"`
+}
+
+// IngestionLeader is the response of the admin endpoints reporting the lease
+// held by the leader of the ingesting instances. Identity and IsLeader
+// describe the instance serving the request, the other fields the lease.
+type IngestionLeader struct {
+	Identity     string    `json:"identity"`
+	IsLeader     bool      `json:"is_leader"`
+	LeaderID     string    `json:"leader_id"`
+	FencingToken int64     `json:"fencing_token"`
+	AcquiredAt   time.Time `json:"acquired_at"`
+	HeartbeatAt  time.Time `json:"heartbeat_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	HandoffTo    *string   `json:"handoff_to,omitempty"`
+}
+
+// IngestionLeaderHandoffRequest is the request body of the admin endpoint
+// requesting the ingestion leader to step down. The lease is handed off to
+// the instance identified by To or, when empty, to any other instance.
+type IngestionLeaderHandoffRequest struct {
+	To string `json:"to,omitempty"`
+}
@@ -17,6 +17,8 @@ import (
 	orbitr "github.com/lantah/go/services/orbitr/internal"
 	"github.com/lantah/go/services/orbitr/internal/db2/schema"
 	"github.com/lantah/go/services/orbitr/internal/ingest"
+	"github.com/lantah/go/services/orbitr/internal/ingest/sink"
+	"github.com/lantah/go/services/orbitr/internal/reap"
 	support "github.com/lantah/go/support/config"
 	"github.com/lantah/go/support/db"
 	"github.com/lantah/go/support/errors"
@@ -230,6 +232,69 @@ var dbReapCmd = &cobra.Command{
 	},
 }
 
+var dbRestoreRangeCmd = &cobra.Command{
+	Use:   "restore-range [Start sequence number] [End sequence number]",
+	Short: "restores reaped history within a range",
+	Long: "restores the history of the ledgers between X and Y sequence number (closed intervals) " +
+		"from the archive written by the reaper (see --" + orbitr.ReapedHistoryArchiveURLFlagName + "). " +
+		"Restored ledgers older than the retention window will be reaped again unless " +
+		"--history-retention-count is increased.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := requireAndSetFlags(
+			orbitr.DatabaseURLFlagName,
+			orbitr.ReapedHistoryArchiveURLFlagName,
+			orbitr.ReapedHistoryArchiveS3RegionFlagName,
+			orbitr.ReapedHistoryArchiveS3EndpointFlagName,
+			orbitr.RoundingSlippageFilterFlagName,
+		)
+		if err != nil {
+			return err
+		}
+		if config.ReapedHistoryArchiveURL == "" {
+			return fmt.Errorf("flag --%s cannot be empty", orbitr.ReapedHistoryArchiveURLFlagName)
+		}
+
+		if len(args) != 2 {
+			return ErrUsage{cmd}
+		}
+
+		argsUInt32 := make([]uint32, 2)
+		for i, arg := range args {
+			if seq, err := strconv.ParseUint(arg, 10, 32); err != nil {
+				cmd.Usage()
+				return fmt.Errorf(`invalid sequence number "%s"`, arg)
+			} else {
+				argsUInt32[i] = uint32(seq)
+			}
+		}
+
+		archive, err := orbitr.ConnectReapedHistoryArchive(*config)
+		if err != nil {
+			return fmt.Errorf("cannot connect to reaped history archive: %v", err)
+		}
+
+		orbitrSession, err := db.Open("postgres", config.DatabaseURL)
+		if err != nil {
+			return fmt.Errorf("cannot open OrbitR DB: %v", err)
+		}
+		defer orbitrSession.Close()
+
+		err = reap.RestoreRange(
+			context.Background(),
+			&history.Q{orbitrSession},
+			archive,
+			argsUInt32[0],
+			argsUInt32[1],
+			config.RoundingSlippageFilter,
+		)
+		if err != nil {
+			return err
+		}
+		hlog.Info("Range restored successfully!")
+		return nil
+	},
+}
+
 var dbReingestCmd = &cobra.Command{
 	Use:   "reingest",
 	Short: "reingest commands",
@@ -416,6 +481,13 @@ func runDBReingestRange(ledgerRanges []history.LedgerRange, reingestForce bool,
 		GravityURL:                    config.GravityURL,
 		RoundingSlippageFilter:      config.RoundingSlippageFilter,
 		EnableIngestionFiltering:    config.EnableIngestionFiltering,
+		LedgerEntryChangeTypes:      config.IngestLedgerEntryChanges,
+	}
+
+	if config.IngestHistorySinkURL != "" {
+		if ingestConfig.HistorySink, err = sink.Connect(config.IngestHistorySinkURL); err != nil {
+			return fmt.Errorf("cannot connect to history sink: %v", err)
+		}
 	}
 
 	if ingestConfig.HistorySession, err = db.Open("postgres", config.DatabaseURL); err != nil {
@@ -533,6 +605,7 @@ func init() {
 		dbMigrateCmd,
 		dbReapCmd,
 		dbReingestCmd,
+		dbRestoreRangeCmd,
 		dbDetectGapsCmd,
 		dbFillGapsCmd,
 	)
@@ -6,6 +6,7 @@ import (
 	"go/types"
 	"net/http"
 	_ "net/http/pprof"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -286,6 +287,81 @@ var ingestTriggerStateRebuildCmd = &cobra.Command{
 	},
 }
 
+var ingestHandoffTo string
+var ingestHandoffTimeout uint
+
+var ingestHandoffCmdOpts = []*support.ConfigOption{
+	{
+		Name:        "handoff-to",
+		ConfigKey:   &ingestHandoffTo,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: "",
+		Usage:       "[optional] identity of the instance to hand off ingestion leadership to, any other ingesting instance can take over when not set",
+	},
+	{
+		Name:        "handoff-timeout",
+		ConfigKey:   &ingestHandoffTimeout,
+		OptType:     types.Uint,
+		Required:    false,
+		FlagDefault: uint(60),
+		Usage:       "[optional] how long (in seconds) to wait for another instance to take over, 0 to return right after requesting the handoff",
+	},
+}
+
+var ingestHandoffCmd = &cobra.Command{
+	Use:   "handoff",
+	Short: "requests the ingestion leader to hand off the ingestion lease, requires --ingest-leader-election on the ingesting instances",
+	Long: "requests the ingestion leader to hand off the ingestion lease once it has finished ingesting the current ledger " +
+		"and waits for another instance to take over. Use it to rotate ingesting instances without downtime.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, co := range ingestHandoffCmdOpts {
+			if err := co.RequireE(); err != nil {
+				return err
+			}
+			co.SetValue()
+		}
+
+		ctx := context.Background()
+		if err := orbitr.ApplyFlags(config, flags, orbitr.ApplyOptions{RequireCaptiveCoreConfig: false, AlwaysIngest: false}); err != nil {
+			return err
+		}
+
+		orbitrSession, err := db.Open("postgres", config.DatabaseURL)
+		if err != nil {
+			return fmt.Errorf("cannot open OrbitR DB: %v", err)
+		}
+		historyQ := &history.Q{SessionInterface: orbitrSession}
+
+		lease, err := ingest.RequestIngestionLeaseHandoff(ctx, historyQ, ingestHandoffTo)
+		if historyQ.NoRows(err) {
+			return fmt.Errorf("no instance has ever held the ingestion lease")
+		} else if err != nil {
+			return err
+		}
+		log.WithField("leader", lease.LeaderID).WithField("fencing_token", lease.FencingToken).
+			Info("Requested ingestion leadership handoff")
+
+		deadline := time.Now().Add(time.Duration(ingestHandoffTimeout) * time.Second)
+		for time.Now().Before(deadline) {
+			time.Sleep(time.Second)
+			current, err := historyQ.GetIngestionLease(ctx, ingest.IngestionLeaseName)
+			if err != nil {
+				return err
+			}
+			if current.FencingToken != lease.FencingToken {
+				log.WithField("leader", current.LeaderID).WithField("fencing_token", current.FencingToken).
+					Info("Ingestion leadership handed off")
+				return nil
+			}
+		}
+		if ingestHandoffTimeout > 0 {
+			return fmt.Errorf("no instance took over ingestion leadership within %d seconds", ingestHandoffTimeout)
+		}
+		return nil
+	},
+}
+
 var ingestInitGenesisStateCmd = &cobra.Command{
 	Use:   "init-genesis-state",
 	Short: "ingests genesis state (ledger 1)",
@@ -457,6 +533,13 @@ func init() {
 		}
 	}
 
+	for _, co := range ingestHandoffCmdOpts {
+		err := co.Init(ingestHandoffCmd)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
 	viper.BindPFlags(ingestVerifyRangeCmd.PersistentFlags())
 
 	RootCmd.AddCommand(ingestCmd)
@@ -466,5 +549,6 @@ func init() {
 		ingestTriggerStateRebuildCmd,
 		ingestInitGenesisStateCmd,
 		ingestBuildStateCmd,
+		ingestHandoffCmd,
 	)
 }
@@ -0,0 +1,91 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/services/orbitr/internal/archivestate"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/support/render/problem"
+	"github.com/lantah/go/xdr"
+)
+
+// ArchiveStateReader looks up ledger entries at past checkpoints, it is
+// implemented by archivestate.Reader.
+type ArchiveStateReader interface {
+	LedgerEntry(ctx context.Context, checkpoint uint32, key xdr.LedgerKey) (xdr.LedgerEntry, error)
+}
+
+// ArchiveStateHandler serves the ledger entries found in the history archives.
+// This admin HTTP endpoint is documented in services/orbitr/internal/httpx/static/admin_oapi.yml
+type ArchiveStateHandler struct {
+	Reader ArchiveStateReader
+}
+
+// GetLedgerEntry returns the ledger entry with the base64 encoded ledger key
+// given in the `key` parameter as of the `checkpoint` ledger.
+func (handler ArchiveStateHandler) GetLedgerEntry(w http.ResponseWriter, r *http.Request) {
+	encodedKey, err := getString(r, "key")
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	var key xdr.LedgerKey
+	if err = xdr.SafeUnmarshalBase64(encodedKey, &key); err != nil {
+		problem.Render(r.Context(), w, problem.MakeInvalidFieldProblem(
+			"key", errors.New("Ledger key must be a base64 encoded XDR LedgerKey"),
+		))
+		return
+	}
+
+	checkpoint, err := getLedgerSequence(r, "checkpoint")
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	if checkpoint == 0 {
+		problem.Render(r.Context(), w, problem.MakeInvalidFieldProblem(
+			"checkpoint", errors.New("checkpoint is required"),
+		))
+		return
+	}
+
+	entry, err := handler.Reader.LedgerEntry(r.Context(), checkpoint, key)
+	if err != nil {
+		problem.Render(r.Context(), w, archiveStateProblem(err))
+		return
+	}
+
+	response := hProtocol.ArchivedLedgerEntry{
+		Checkpoint:         checkpoint,
+		LastModifiedLedger: uint32(entry.LastModifiedLedgerSeq),
+	}
+	if response.Key, err = xdr.MarshalBase64(key); err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	if response.Entry, err = xdr.MarshalBase64(entry); err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func archiveStateProblem(err error) error {
+	switch err.(type) {
+	case archivestate.ErrNotCheckpoint:
+		return problem.MakeInvalidFieldProblem("checkpoint", err)
+	}
+	switch err {
+	case archivestate.ErrEntryNotFound:
+		return problem.NotFound
+	case archivestate.ErrUnsupportedKeyType:
+		return problem.MakeInvalidFieldProblem("key", err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,110 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/services/orbitr/internal/archivestate"
+	"github.com/lantah/go/xdr"
+)
+
+type mockArchiveStateReader struct {
+	mock.Mock
+}
+
+func (m *mockArchiveStateReader) LedgerEntry(ctx context.Context, checkpoint uint32, key xdr.LedgerKey) (xdr.LedgerEntry, error) {
+	args := m.Called(ctx, checkpoint, key)
+	return args.Get(0).(xdr.LedgerEntry), args.Error(1)
+}
+
+func makeArchiveStateRequest(t *testing.T, query url.Values) *http.Request {
+	request, err := http.NewRequest(http.MethodGet, "/?"+query.Encode(), nil)
+	require.NoError(t, err)
+	return request
+}
+
+func TestArchiveStateHandlerGetLedgerEntry(t *testing.T) {
+	reader := &mockArchiveStateReader{}
+	handler := ArchiveStateHandler{Reader: reader}
+
+	account := xdr.MustAddress("GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML")
+	var key xdr.LedgerKey
+	require.NoError(t, key.SetAccount(account))
+	encodedKey, err := xdr.MarshalBase64(key)
+	require.NoError(t, err)
+	entry := xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 42,
+		Data: xdr.LedgerEntryData{
+			Type:    xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{AccountId: account, Balance: 100},
+		},
+	}
+	encodedEntry, err := xdr.MarshalBase64(entry)
+	require.NoError(t, err)
+
+	reader.On("LedgerEntry", mock.Anything, uint32(63), key).Return(entry, nil).Once()
+	recorder := httptest.NewRecorder()
+	handler.GetLedgerEntry(recorder, makeArchiveStateRequest(t, url.Values{
+		"key": {encodedKey}, "checkpoint": {"63"},
+	}))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response hProtocol.ArchivedLedgerEntry
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, hProtocol.ArchivedLedgerEntry{
+		Checkpoint:         63,
+		Key:                encodedKey,
+		Entry:              encodedEntry,
+		LastModifiedLedger: 42,
+	}, response)
+
+	for _, testCase := range []struct {
+		name   string
+		query  url.Values
+		err    error
+		status int
+	}{
+		{
+			name:   "invalid key",
+			query:  url.Values{"key": {"AAAA"}, "checkpoint": {"63"}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "missing checkpoint",
+			query:  url.Values{"key": {encodedKey}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "not a checkpoint",
+			query:  url.Values{"key": {encodedKey}, "checkpoint": {"100"}},
+			err:    archivestate.ErrNotCheckpoint{Ledger: 100, Previous: 63, Next: 127},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "not found",
+			query:  url.Values{"key": {encodedKey}, "checkpoint": {"63"}},
+			err:    archivestate.ErrEntryNotFound,
+			status: http.StatusNotFound,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			if testCase.err != nil {
+				reader.On("LedgerEntry", mock.Anything, mock.Anything, key).
+					Return(xdr.LedgerEntry{}, testCase.err).Once()
+			}
+			recorder := httptest.NewRecorder()
+			handler.GetLedgerEntry(recorder, makeArchiveStateRequest(t, testCase.query))
+			assert.Equal(t, testCase.status, recorder.Code)
+		})
+	}
+	reader.AssertExpectations(t)
+}
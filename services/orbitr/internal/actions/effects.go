@@ -3,7 +3,9 @@ package actions
 import (
 	"context"
 	"net/http"
+	"strings"
 
+	"github.com/lantah/go/protocols/orbitr/effects"
 	orbitrContext "github.com/lantah/go/services/orbitr/internal/context"
 	"github.com/lantah/go/services/orbitr/internal/db2"
 	"github.com/lantah/go/services/orbitr/internal/db2/history"
@@ -16,11 +18,44 @@ import (
 
 // EffectsQuery query struct for effects end-points
 type EffectsQuery struct {
-	AccountID       string `schema:"account_id" valid:"accountID,optional"`
-	OperationID     uint64 `schema:"op_id" valid:"-"`
-	LiquidityPoolID string `schema:"liquidity_pool_id" valid:"sha256,optional"`
-	TxHash          string `schema:"tx_id" valid:"transactionHash,optional"`
-	LedgerID        uint32 `schema:"ledger_id" valid:"-"`
+	HistoryFilterQueryParams `valid:"optional"`
+	AccountID                string `schema:"account_id" valid:"accountID,optional"`
+	OperationID              uint64 `schema:"op_id" valid:"-"`
+	LiquidityPoolID          string `schema:"liquidity_pool_id" valid:"sha256,optional"`
+	TxHash                   string `schema:"tx_id" valid:"transactionHash,optional"`
+	LedgerID                 uint32 `schema:"ledger_id" valid:"-"`
+	TypeFilter               string `schema:"type" valid:"-"`
+}
+
+// effectTypesByName maps the effect type names used in resources back to
+// their numeric type.
+var effectTypesByName = func() map[string]history.EffectType {
+	types := make(map[string]history.EffectType, len(effects.EffectTypeNames))
+	for effectType, name := range effects.EffectTypeNames {
+		types[name] = history.EffectType(effectType)
+	}
+	return types
+}()
+
+// Types returns the effect types given in the comma-separated `type`
+// parameter, or nil if it wasn't provided.
+func (qp EffectsQuery) Types() ([]history.EffectType, error) {
+	if len(qp.TypeFilter) == 0 {
+		return nil, nil
+	}
+
+	var types []history.EffectType
+	for _, name := range strings.Split(qp.TypeFilter, ",") {
+		effectType, ok := effectTypesByName[strings.TrimSpace(name)]
+		if !ok {
+			return nil, problem.MakeInvalidFieldProblem(
+				"type",
+				errors.Errorf("Unknown effect type: %s", name),
+			)
+		}
+		types = append(types, effectType)
+	}
+	return types, nil
 }
 
 // Validate runs extra validations on query parameters
@@ -43,7 +78,12 @@ func (qp EffectsQuery) Validate() error {
 			errors.New("Use a single filter for effects, you can only use one of account_id, op_id, tx_id or ledger_id"),
 		)
 	}
-	return nil
+
+	if _, err := qp.Types(); err != nil {
+		return err
+	}
+
+	return qp.HistoryFilterQueryParams.Validate()
 }
 
 type GetEffectsHandler struct {
@@ -95,23 +135,37 @@ func (handler GetEffectsHandler) GetResourcePage(w HeaderWriter, r *http.Request
 }
 
 func loadEffectRecords(ctx context.Context, hq *history.Q, qp EffectsQuery, pq db2.PageQuery) ([]history.Effect, error) {
-	effects := hq.Effects()
+	query := hq.Effects()
 
 	switch {
 	case qp.AccountID != "":
-		effects.ForAccount(ctx, qp.AccountID)
+		query.ForAccount(ctx, qp.AccountID)
 	case qp.LiquidityPoolID != "":
-		effects.ForLiquidityPool(ctx, pq, qp.LiquidityPoolID)
+		query.ForLiquidityPool(ctx, pq, qp.LiquidityPoolID)
 	case qp.OperationID > 0:
-		effects.ForOperation(int64(qp.OperationID))
+		query.ForOperation(int64(qp.OperationID))
 	case qp.LedgerID > 0:
-		effects.ForLedger(ctx, int32(qp.LedgerID))
+		query.ForLedger(ctx, int32(qp.LedgerID))
 	case qp.TxHash != "":
-		effects.ForTransaction(ctx, qp.TxHash)
+		query.ForTransaction(ctx, qp.TxHash)
+	}
+
+	types, err := qp.Types()
+	if err != nil {
+		return nil, err
+	}
+	if len(types) > 0 {
+		query.ForTypes(types...)
+	}
+	if asset := qp.Asset(); asset != nil {
+		query.ForAsset(*asset)
+	}
+	if after, before := qp.TimeBounds(); !after.IsZero() || !before.IsZero() {
+		query.ForCloseTimeRange(ctx, after, before)
 	}
 
 	var result []history.Effect
-	err := effects.Page(pq).Select(ctx, &result)
+	err = query.Page(pq).Select(ctx, &result)
 
 	return result, err
 }
@@ -3,11 +3,14 @@ package actions
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
 	"github.com/lantah/go/support/http/httptest"
 	"github.com/lantah/go/support/render/problem"
+	"github.com/lantah/go/xdr"
 )
 
 func TestEffectsQuery_BadOperationID(t *testing.T) {
@@ -36,3 +39,34 @@ func TestEffectsQuery_BadOperationID(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, called)
 }
+
+func TestEffectsQuery_HistoryFilters(t *testing.T) {
+	s := httptest.NewServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		qp := EffectsQuery{}
+		err := getParams(&qp, r)
+		if r.URL.Query().Get("type") == "foo" {
+			p, ok := err.(*problem.P)
+			if assert.True(t, ok) {
+				assert.Equal(t, "type", p.Extras["invalid_field"])
+				assert.Equal(t, "Unknown effect type: foo", p.Extras["reason"])
+			}
+			return
+		}
+
+		assert.NoError(t, err)
+		types, err := qp.Types()
+		assert.NoError(t, err)
+		assert.Equal(t, []history.EffectType{history.EffectAccountCredited, history.EffectAccountDebited}, types)
+		assert.Equal(t, xdr.MustNewNativeAsset(), *qp.Asset())
+		after, before := qp.TimeBounds()
+		assert.Equal(t, time.Date(2019, 10, 31, 13, 19, 45, 0, time.UTC), after)
+		assert.True(t, before.IsZero())
+	}))
+	defer s.Close()
+
+	_, err := http.Get(s.URL + "/?type=account_credited,account_debited&asset=native&created_after=2019-10-31T13:19:45Z")
+	assert.NoError(t, err)
+
+	_, err = http.Get(s.URL + "/?type=foo")
+	assert.NoError(t, err)
+}
@@ -8,6 +8,8 @@ import (
 	hProtocol "github.com/lantah/go/protocols/orbitr"
 	orbitrContext "github.com/lantah/go/services/orbitr/internal/context"
 	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/services/orbitr/internal/ingest/filters"
+	"github.com/lantah/go/strkey"
 	"github.com/lantah/go/support/render/problem"
 )
 
@@ -114,6 +116,109 @@ func (handler FilterConfigHandler) UpdateAssetConfig(w http.ResponseWriter, r *h
 	}
 }
 
+func (handler FilterConfigHandler) GetOperationConfig(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := orbitrContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	config, err := historyQ.GetOperationFilterConfig(r.Context())
+
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	responsePayload := handler.operationConfigResource(config)
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler FilterConfigHandler) GetContractConfig(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := orbitrContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	config, err := historyQ.GetContractFilterConfig(r.Context())
+
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	responsePayload := handler.contractConfigResource(config)
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler FilterConfigHandler) UpdateOperationConfig(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := orbitrContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	filterRequest, err := handler.operationFilterResource(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	filterConfig := history.OperationFilterConfig{}
+	filterConfig.Enabled = *filterRequest.Enabled
+	filterConfig.Whitelist = filterRequest.Whitelist
+	filterConfig.Blacklist = filterRequest.Blacklist
+
+	config, err := historyQ.UpdateOperationFilterConfig(r.Context(), filterConfig)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	responsePayload := handler.operationConfigResource(config)
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func (handler FilterConfigHandler) UpdateContractConfig(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := orbitrContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	filterRequest, err := handler.contractFilterResource(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	filterConfig := history.ContractFilterConfig{}
+	filterConfig.Enabled = *filterRequest.Enabled
+	filterConfig.Whitelist = filterRequest.Whitelist
+
+	config, err := historyQ.UpdateContractFilterConfig(r.Context(), filterConfig)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	responsePayload := handler.contractConfigResource(config)
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(responsePayload); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
 func (handler FilterConfigHandler) assetFilterResource(r *http.Request) (hProtocol.AssetFilterConfig, error) {
 	var filterRequest hProtocol.AssetFilterConfig
 	dec := json.NewDecoder(r.Body)
@@ -134,6 +239,39 @@ func (handler FilterConfigHandler) accountFilterResource(r *http.Request) (hProt
 	return filterRequest, nil
 }
 
+func (handler FilterConfigHandler) operationFilterResource(r *http.Request) (hProtocol.OperationFilterConfig, error) {
+	var filterRequest hProtocol.OperationFilterConfig
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&filterRequest); err != nil {
+		p := problem.NewProblemWithInvalidField(problem.BadRequest, "reason", fmt.Errorf("invalid json for operation filter config %v", err.Error()))
+		return hProtocol.OperationFilterConfig{}, p
+	}
+	if _, err := filters.ParseOperationTypes(filterRequest.Whitelist); err != nil {
+		return hProtocol.OperationFilterConfig{}, problem.MakeInvalidFieldProblem("whitelist", err)
+	}
+	if _, err := filters.ParseOperationTypes(filterRequest.Blacklist); err != nil {
+		return hProtocol.OperationFilterConfig{}, problem.MakeInvalidFieldProblem("blacklist", err)
+	}
+	return filterRequest, nil
+}
+
+func (handler FilterConfigHandler) contractFilterResource(r *http.Request) (hProtocol.ContractFilterConfig, error) {
+	var filterRequest hProtocol.ContractFilterConfig
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&filterRequest); err != nil {
+		p := problem.NewProblemWithInvalidField(problem.BadRequest, "reason", fmt.Errorf("invalid json for contract filter config %v", err.Error()))
+		return hProtocol.ContractFilterConfig{}, p
+	}
+	for _, contractID := range filterRequest.Whitelist {
+		if _, err := strkey.Decode(strkey.VersionByteContract, contractID); err != nil {
+			return hProtocol.ContractFilterConfig{}, problem.MakeInvalidFieldProblem(
+				"whitelist", fmt.Errorf("invalid contract id %q", contractID),
+			)
+		}
+	}
+	return filterRequest, nil
+}
+
 func (handler FilterConfigHandler) assetConfigResource(config history.AssetFilterConfig) hProtocol.AssetFilterConfig {
 	return hProtocol.AssetFilterConfig{
 		Whitelist:    config.Whitelist,
@@ -149,3 +287,20 @@ func (handler FilterConfigHandler) accountConfigResource(config history.AccountF
 		LastModified: config.LastModified,
 	}
 }
+
+func (handler FilterConfigHandler) operationConfigResource(config history.OperationFilterConfig) hProtocol.OperationFilterConfig {
+	return hProtocol.OperationFilterConfig{
+		Whitelist:    config.Whitelist,
+		Blacklist:    config.Blacklist,
+		Enabled:      &config.Enabled,
+		LastModified: config.LastModified,
+	}
+}
+
+func (handler FilterConfigHandler) contractConfigResource(config history.ContractFilterConfig) hProtocol.ContractFilterConfig {
+	return hProtocol.ContractFilterConfig{
+		Whitelist:    config.Whitelist,
+		Enabled:      &config.Enabled,
+		LastModified: config.LastModified,
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	hProtocol "github.com/lantah/go/protocols/orbitr"
 	"github.com/lantah/go/services/orbitr/internal/db2/history"
 	"github.com/lantah/go/services/orbitr/internal/test"
@@ -244,3 +246,128 @@ func TestUpdateAccountFilterConfig(t *testing.T) {
 	tt.Assert.True(filterCfgResource.LastModified > 0)
 	tt.Assert.ElementsMatch(filterCfgResource.Whitelist, []string{"4", "5", "6"})
 }
+
+func TestUpdateOperationFilterConfig(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetOrbitRDB(t, tt.OrbitRDB)
+
+	q := &history.Q{SessionInterface: tt.OrbitRSession()}
+
+	handler := &FilterConfigHandler{}
+	recorder := httptest.NewRecorder()
+	request := makeRequest(
+		t,
+		map[string]string{},
+		map[string]string{},
+		q,
+	)
+
+	request.Body = ioutil.NopCloser(strings.NewReader(`
+	    {
+			"whitelist": ["payment", "invoke_host_function"],
+			"blacklist": ["bump_sequence"],
+			"enabled": true
+		}`))
+
+	handler.UpdateOperationConfig(
+		recorder,
+		request,
+	)
+
+	resp := recorder.Result()
+	tt.Assert.Equal(http.StatusOK, resp.StatusCode)
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	tt.Assert.NoError(err)
+
+	var filterCfgResource hProtocol.OperationFilterConfig
+	json.Unmarshal(raw, &filterCfgResource)
+	tt.Assert.NoError(err)
+
+	tt.Assert.Equal(*filterCfgResource.Enabled, true)
+	tt.Assert.True(filterCfgResource.LastModified > 0)
+	tt.Assert.ElementsMatch(filterCfgResource.Whitelist, []string{"payment", "invoke_host_function"})
+	tt.Assert.ElementsMatch(filterCfgResource.Blacklist, []string{"bump_sequence"})
+}
+
+func TestUpdateContractFilterConfig(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetOrbitRDB(t, tt.OrbitRDB)
+
+	q := &history.Q{SessionInterface: tt.OrbitRSession()}
+
+	handler := &FilterConfigHandler{}
+	recorder := httptest.NewRecorder()
+	request := makeRequest(
+		t,
+		map[string]string{},
+		map[string]string{},
+		q,
+	)
+
+	request.Body = ioutil.NopCloser(strings.NewReader(`
+	    {
+			"whitelist": ["CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABSC4", "CDLZFC3SYJYDZT7K67VZ75HPJVIEUVNIXF47ZG2FB2RMQQVU2HHGCYSC"],
+			"enabled": true
+		}`))
+
+	handler.UpdateContractConfig(
+		recorder,
+		request,
+	)
+
+	resp := recorder.Result()
+	tt.Assert.Equal(http.StatusOK, resp.StatusCode)
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	tt.Assert.NoError(err)
+
+	var filterCfgResource hProtocol.ContractFilterConfig
+	json.Unmarshal(raw, &filterCfgResource)
+	tt.Assert.NoError(err)
+
+	tt.Assert.Equal(*filterCfgResource.Enabled, true)
+	tt.Assert.True(filterCfgResource.LastModified > 0)
+	tt.Assert.ElementsMatch(filterCfgResource.Whitelist, []string{
+		"CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABSC4",
+		"CDLZFC3SYJYDZT7K67VZ75HPJVIEUVNIXF47ZG2FB2RMQQVU2HHGCYSC",
+	})
+}
+
+func TestInvalidUpdateOperationAndContractFilterConfig(t *testing.T) {
+	handler := &FilterConfigHandler{}
+	q := &history.Q{}
+
+	for _, testCase := range []struct {
+		name   string
+		body   string
+		update func(w http.ResponseWriter, r *http.Request)
+	}{
+		{
+			name:   "missing operation blacklist",
+			body:   `{"whitelist": ["payment"], "enabled": true}`,
+			update: handler.UpdateOperationConfig,
+		},
+		{
+			name:   "unknown operation type",
+			body:   `{"whitelist": ["pay"], "blacklist": [], "enabled": true}`,
+			update: handler.UpdateOperationConfig,
+		},
+		{
+			name:   "invalid contract id",
+			body:   `{"whitelist": ["GD6WNNTW664WH7FXC5RUMUTF7P5QSURC2IT36VOQEEGFZ4UWUEQGECAL"], "enabled": true}`,
+			update: handler.UpdateContractConfig,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request := makeRequest(t, map[string]string{}, map[string]string{}, q)
+			request.Body = ioutil.NopCloser(strings.NewReader(testCase.body))
+
+			testCase.update(recorder, request)
+			assert.Equal(t, http.StatusBadRequest, recorder.Result().StatusCode)
+		})
+	}
+}
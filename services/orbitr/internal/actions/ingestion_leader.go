@@ -0,0 +1,80 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/services/orbitr/internal/ingest"
+	"github.com/lantah/go/support/render/problem"
+)
+
+// LeaderElector reports and hands off the ingestion lease, it is implemented
+// by ingest.LeaderElector.
+type LeaderElector interface {
+	Status() ingest.LeaderStatus
+	RequestHandoff(ctx context.Context, to string) (history.IngestionLease, error)
+}
+
+// IngestionLeaderHandler serves the ingestion leader election admin endpoints,
+// which are documented in services/orbitr/internal/httpx/static/admin_oapi.yml
+type IngestionLeaderHandler struct {
+	Elector LeaderElector
+}
+
+// GetLeader returns the ingestion lease as last seen by this instance.
+func (handler IngestionLeaderHandler) GetLeader(w http.ResponseWriter, r *http.Request) {
+	status := handler.Elector.Status()
+	handler.render(w, r, ingestionLeaderResource(status.Identity, status.IsLeader, status.Lease))
+}
+
+// RequestHandoff requests the leader to step down once it has finished
+// ingesting the current ledger.
+func (handler IngestionLeaderHandler) RequestHandoff(w http.ResponseWriter, r *http.Request) {
+	var request hProtocol.IngestionLeaderHandoffRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		problem.Render(r.Context(), w, problem.NewProblemWithInvalidField(
+			problem.BadRequest, "reason", fmt.Errorf("invalid json for handoff request %v", err.Error()),
+		))
+		return
+	}
+
+	lease, err := handler.Elector.RequestHandoff(r.Context(), request.To)
+	if err == ingest.ErrHandoffToLeader {
+		err = problem.MakeInvalidFieldProblem("to", err)
+	}
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	status := handler.Elector.Status()
+	w.WriteHeader(http.StatusAccepted)
+	handler.render(w, r, ingestionLeaderResource(status.Identity, status.IsLeader, lease))
+}
+
+func (handler IngestionLeaderHandler) render(w http.ResponseWriter, r *http.Request, response interface{}) {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func ingestionLeaderResource(identity string, isLeader bool, lease history.IngestionLease) hProtocol.IngestionLeader {
+	resource := hProtocol.IngestionLeader{
+		Identity:     identity,
+		IsLeader:     isLeader,
+		LeaderID:     lease.LeaderID,
+		FencingToken: lease.FencingToken,
+		AcquiredAt:   lease.AcquiredAt,
+		HeartbeatAt:  lease.HeartbeatAt,
+		ExpiresAt:    lease.ExpiresAt,
+	}
+	if lease.HandoffTo.Valid {
+		resource.HandoffTo = &lease.HandoffTo.String
+	}
+	return resource
+}
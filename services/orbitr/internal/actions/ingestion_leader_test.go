@@ -0,0 +1,93 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/services/orbitr/internal/ingest"
+)
+
+type mockLeaderElector struct {
+	mock.Mock
+}
+
+func (m *mockLeaderElector) Status() ingest.LeaderStatus {
+	args := m.Called()
+	return args.Get(0).(ingest.LeaderStatus)
+}
+
+func (m *mockLeaderElector) RequestHandoff(ctx context.Context, to string) (history.IngestionLease, error) {
+	args := m.Called(ctx, to)
+	return args.Get(0).(history.IngestionLease), args.Error(1)
+}
+
+func TestIngestionLeaderHandlerGetLeader(t *testing.T) {
+	elector := &mockLeaderElector{}
+	handler := IngestionLeaderHandler{Elector: elector}
+	elector.On("Status").Return(ingest.LeaderStatus{
+		Identity: "orbitr-1",
+		IsLeader: true,
+		Lease:    history.IngestionLease{LeaderID: "orbitr-1", FencingToken: 4},
+	}).Once()
+
+	recorder := httptest.NewRecorder()
+	handler.GetLeader(recorder, makeReingestRequest(t, http.MethodGet, "", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var leader hProtocol.IngestionLeader
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &leader))
+	assert.Equal(t, hProtocol.IngestionLeader{
+		Identity:     "orbitr-1",
+		IsLeader:     true,
+		LeaderID:     "orbitr-1",
+		FencingToken: 4,
+	}, leader)
+	elector.AssertExpectations(t)
+}
+
+func TestIngestionLeaderHandlerRequestHandoff(t *testing.T) {
+	elector := &mockLeaderElector{}
+	handler := IngestionLeaderHandler{Elector: elector}
+	elector.On("Status").Return(ingest.LeaderStatus{Identity: "orbitr-2"})
+
+	elector.On("RequestHandoff", mock.Anything, "orbitr-2").Return(history.IngestionLease{
+		LeaderID:     "orbitr-1",
+		FencingToken: 4,
+		HandoffTo:    null.StringFrom("orbitr-2"),
+	}, nil).Once()
+	recorder := httptest.NewRecorder()
+	handler.RequestHandoff(recorder, makeReingestRequest(t, http.MethodPost, `{"to": "orbitr-2"}`, nil))
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+	var leader hProtocol.IngestionLeader
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &leader))
+	assert.Equal(t, "orbitr-1", leader.LeaderID)
+	require.NotNil(t, leader.HandoffTo)
+	assert.Equal(t, "orbitr-2", *leader.HandoffTo)
+
+	elector.On("RequestHandoff", mock.Anything, "orbitr-1").Return(history.IngestionLease{}, ingest.ErrHandoffToLeader).Once()
+	recorder = httptest.NewRecorder()
+	handler.RequestHandoff(recorder, makeReingestRequest(t, http.MethodPost, `{"to": "orbitr-1"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// an empty body hands off to any other instance
+	elector.On("RequestHandoff", mock.Anything, "").Return(history.IngestionLease{
+		LeaderID:  "orbitr-1",
+		HandoffTo: null.StringFrom(""),
+	}, nil).Once()
+	recorder = httptest.NewRecorder()
+	handler.RequestHandoff(recorder, makeReingestRequest(t, http.MethodPost, "", nil))
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+	elector.AssertExpectations(t)
+}
@@ -0,0 +1,93 @@
+package actions
+
+import (
+	"net/http"
+
+	protocol "github.com/lantah/go/protocols/orbitr"
+	orbitrContext "github.com/lantah/go/services/orbitr/internal/context"
+	"github.com/lantah/go/services/orbitr/internal/ledger"
+	"github.com/lantah/go/services/orbitr/internal/resourceadapter"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/support/render/hal"
+	"github.com/lantah/go/support/render/problem"
+	"github.com/lantah/go/xdr"
+)
+
+// LedgerEntryChangesQuery query struct for the ledger_entry_changes end-point
+type LedgerEntryChangesQuery struct {
+	Key string `schema:"key" valid:"-"`
+}
+
+// LedgerKey returns the base64 encoded XDR of the ledger key given in the
+// `key` parameter, normalized so that it matches the stored keys.
+func (qp LedgerEntryChangesQuery) LedgerKey() (string, error) {
+	var key xdr.LedgerKey
+	if err := xdr.SafeUnmarshalBase64(qp.Key, &key); err != nil {
+		return "", problem.MakeInvalidFieldProblem(
+			"key",
+			errors.New("Ledger key must be a base64 encoded XDR LedgerKey"),
+		)
+	}
+	return xdr.MarshalBase64(key)
+}
+
+// Validate runs extra validations on query parameters
+func (qp LedgerEntryChangesQuery) Validate() error {
+	if qp.Key == "" {
+		return problem.MakeInvalidFieldProblem(
+			"key",
+			errors.New("Ledger key is required"),
+		)
+	}
+	_, err := qp.LedgerKey()
+	return err
+}
+
+// GetLedgerEntryChangesHandler is the action handler for the
+// /ledger_entry_changes endpoint
+type GetLedgerEntryChangesHandler struct {
+	LedgerState *ledger.State
+}
+
+// GetResourcePage returns a page of the changes of a ledger entry.
+func (handler GetLedgerEntryChangesHandler) GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
+	ctx := r.Context()
+	pq, err := GetPageQuery(handler.LedgerState, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateCursorWithinHistory(handler.LedgerState, pq)
+	if err != nil {
+		return nil, err
+	}
+
+	qp := LedgerEntryChangesQuery{}
+	err = getParams(&qp, r)
+	if err != nil {
+		return nil, err
+	}
+	key, err := qp.LedgerKey()
+	if err != nil {
+		return nil, err
+	}
+
+	historyQ, err := orbitrContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := historyQ.LedgerEntryChangesForKey(ctx, key, pq)
+	if err != nil {
+		return nil, errors.Wrap(err, "loading ledger entry changes")
+	}
+
+	var result []hal.Pageable
+	for _, record := range records {
+		var change protocol.LedgerEntryChange
+		resourceadapter.PopulateLedgerEntryChange(ctx, &change, record)
+		result = append(result, change)
+	}
+
+	return result, nil
+}
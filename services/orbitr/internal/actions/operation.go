@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/lantah/go/protocols/orbitr/operations"
 	orbitrContext "github.com/lantah/go/services/orbitr/internal/context"
 	"github.com/lantah/go/services/orbitr/internal/db2/history"
 	"github.com/lantah/go/services/orbitr/internal/ledger"
@@ -14,6 +16,7 @@ import (
 	"github.com/lantah/go/support/render/hal"
 	supportProblem "github.com/lantah/go/support/render/problem"
 	"github.com/lantah/go/toid"
+	"github.com/lantah/go/xdr"
 )
 
 // Joinable query struct for join query parameter
@@ -29,12 +32,46 @@ func (qp Joinable) IncludeTransactions() bool {
 // OperationsQuery query struct for operations end-points
 type OperationsQuery struct {
 	Joinable                  `valid:"optional"`
+	HistoryFilterQueryParams  `valid:"optional"`
 	AccountID                 string `schema:"account_id" valid:"accountID,optional"`
 	ClaimableBalanceID        string `schema:"claimable_balance_id" valid:"claimableBalanceID,optional"`
 	LiquidityPoolID           string `schema:"liquidity_pool_id" valid:"sha256,optional"`
+	MuxedAccountID            string `schema:"muxed_account_id" valid:"muxedAccountID,optional"`
 	TransactionHash           string `schema:"tx_id" valid:"transactionHash,optional"`
 	IncludeFailedTransactions bool   `schema:"include_failed" valid:"-"`
 	LedgerID                  uint32 `schema:"ledger_id" valid:"-"`
+	TypeFilter                string `schema:"type" valid:"-"`
+}
+
+// operationTypesByName maps the operation type names used in resources back to
+// their xdr type.
+var operationTypesByName = func() map[string]xdr.OperationType {
+	types := make(map[string]xdr.OperationType, len(operations.TypeNames))
+	for opType, name := range operations.TypeNames {
+		types[name] = opType
+	}
+	return types
+}()
+
+// Types returns the operation types given in the comma-separated `type`
+// parameter, or nil if it wasn't provided.
+func (qp OperationsQuery) Types() ([]xdr.OperationType, error) {
+	if len(qp.TypeFilter) == 0 {
+		return nil, nil
+	}
+
+	var types []xdr.OperationType
+	for _, name := range strings.Split(qp.TypeFilter, ",") {
+		opType, ok := operationTypesByName[strings.TrimSpace(name)]
+		if !ok {
+			return nil, supportProblem.MakeInvalidFieldProblem(
+				"type",
+				errors.Errorf("Unknown operation type: %s", name),
+			)
+		}
+		types = append(types, opType)
+	}
+	return types, nil
 }
 
 // Validate runs extra validations on query parameters
@@ -43,6 +80,7 @@ func (qp OperationsQuery) Validate() error {
 		qp.AccountID,
 		qp.ClaimableBalanceID,
 		qp.LiquidityPoolID,
+		qp.MuxedAccountID,
 		qp.LedgerID,
 		qp.TransactionHash,
 	)
@@ -58,7 +96,11 @@ func (qp OperationsQuery) Validate() error {
 		)
 	}
 
-	return nil
+	if _, err := qp.Types(); err != nil {
+		return err
+	}
+
+	return qp.HistoryFilterQueryParams.Validate()
 }
 
 // GetOperationsHandler is the action handler for all end-points returning a list of operations.
@@ -101,11 +143,27 @@ func (handler GetOperationsHandler) GetResourcePage(w HeaderWriter, r *http.Requ
 		query.ForClaimableBalance(ctx, qp.ClaimableBalanceID)
 	case qp.LiquidityPoolID != "":
 		query.ForLiquidityPool(ctx, qp.LiquidityPoolID)
+	case qp.MuxedAccountID != "":
+		query.ForMuxedAccount(ctx, qp.MuxedAccountID)
 	case qp.LedgerID > 0:
 		query.ForLedger(ctx, int32(qp.LedgerID))
 	case qp.TransactionHash != "":
 		query.ForTransaction(ctx, qp.TransactionHash)
 	}
+
+	types, err := qp.Types()
+	if err != nil {
+		return nil, err
+	}
+	if len(types) > 0 {
+		query.ForTypes(types...)
+	}
+	if asset := qp.Asset(); asset != nil {
+		query.ForAsset(*asset)
+	}
+	if after, before := qp.TimeBounds(); !after.IsZero() || !before.IsZero() {
+		query.ForCloseTimeRange(ctx, after, before)
+	}
 	// When querying operations for transaction return both successful
 	// and failed operations. We assume that because the user is querying
 	// this specific transactions, they knows its status.
@@ -640,6 +640,168 @@ func TestGetOperationsOnlyPayments(t *testing.T) {
 	tt.Assert.Equal("10.000000", record.SourceAmount)
 }
 
+func TestGetOperationsHistoryFilters(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	tt.Scenario("base")
+
+	q := &history.Q{tt.OrbitRSession()}
+	handler := GetOperationsHandler{}
+
+	testCases := []struct {
+		desc     string
+		query    map[string]string
+		expected int
+	}{
+		{
+			desc:     "single type",
+			query:    map[string]string{"type": "create_account"},
+			expected: 3,
+		},
+		{
+			desc:     "multiple types",
+			query:    map[string]string{"type": "create_account,payment"},
+			expected: 4,
+		},
+		{
+			desc:     "type without matches",
+			query:    map[string]string{"type": "path_payment_strict_send,manage_sell_offer"},
+			expected: 0,
+		},
+		{
+			desc: "type and account",
+			query: map[string]string{
+				"type":       "payment",
+				"account_id": "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU",
+			},
+			expected: 1,
+		},
+		{
+			desc:     "native asset",
+			query:    map[string]string{"asset": "native"},
+			expected: 1,
+		},
+		{
+			desc:     "credit asset",
+			query:    map[string]string{"asset": "USD:GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"},
+			expected: 0,
+		},
+		{
+			desc:     "created after",
+			query:    map[string]string{"created_after": "2019-10-31T13:19:45Z"},
+			expected: 1,
+		},
+		{
+			desc:     "created before",
+			query:    map[string]string{"created_before": "2019-10-31T13:19:46Z"},
+			expected: 3,
+		},
+		{
+			desc: "created after and before",
+			query: map[string]string{
+				"created_after":  "2019-10-31T13:19:44Z",
+				"created_before": "2019-10-31T13:19:46Z",
+			},
+			expected: 3,
+		},
+		{
+			desc:     "created after last ledger",
+			query:    map[string]string{"created_after": "2019-11-01T00:00:00Z"},
+			expected: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			records, err := handler.GetResourcePage(
+				httptest.NewRecorder(),
+				makeRequest(
+					t, tc.query, map[string]string{}, q,
+				),
+			)
+			tt.Assert.NoError(err)
+			tt.Assert.Len(records, tc.expected)
+		})
+	}
+}
+
+func TestGetOperationsHistoryFiltersValidation(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		query  map[string]string
+		field  string
+		reason string
+	}{
+		{
+			desc:   "unknown type",
+			query:  map[string]string{"type": "payment,foo"},
+			field:  "type",
+			reason: "Unknown operation type: foo",
+		},
+		{
+			desc:   "invalid asset",
+			query:  map[string]string{"asset": "USD"},
+			field:  "asset",
+			reason: customTagsErrorMessages["asset"],
+		},
+		{
+			desc:   "invalid time",
+			query:  map[string]string{"created_after": "1572527986"},
+			field:  "created_after",
+			reason: customTagsErrorMessages["rfc3339"],
+		},
+		{
+			desc: "empty time range",
+			query: map[string]string{
+				"created_after":  "2019-10-31T13:19:46Z",
+				"created_before": "2019-10-31T13:19:46Z",
+			},
+			field:  "created_before",
+			reason: "created_before must be later than created_after",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			qp := OperationsQuery{}
+			err := getParams(&qp, makeRequest(t, tc.query, map[string]string{}, nil))
+			p, ok := err.(*supportProblem.P)
+			if assert.True(t, ok) {
+				assert.Equal(t, "bad_request", p.Type)
+				assert.Equal(t, tc.field, p.Extras["invalid_field"])
+				assert.Equal(t, tc.reason, p.Extras["reason"])
+			}
+		})
+	}
+}
+
+func TestOperationsQueryMuxedAccount(t *testing.T) {
+	muxedAddress := "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK"
+
+	qp := OperationsQuery{}
+	err := getParams(&qp, makeRequest(t, map[string]string{}, map[string]string{"muxed_account_id": muxedAddress}, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, muxedAddress, qp.MuxedAccountID)
+
+	qp = OperationsQuery{}
+	err = getParams(&qp, makeRequest(t, map[string]string{}, map[string]string{"muxed_account_id": "GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ"}, nil))
+	p, ok := err.(*supportProblem.P)
+	if assert.True(t, ok) {
+		assert.Equal(t, "muxed_account_id", p.Extras["invalid_field"])
+		assert.Equal(t, customTagsErrorMessages["muxedAccountID"], p.Extras["reason"])
+	}
+
+	qp = OperationsQuery{}
+	err = getParams(&qp, makeRequest(
+		t,
+		map[string]string{"account_id": "GA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVSGZ"},
+		map[string]string{"muxed_account_id": muxedAddress},
+		nil,
+	))
+	p, ok = err.(*supportProblem.P)
+	if assert.True(t, ok) {
+		assert.Equal(t, "filters", p.Extras["invalid_field"])
+	}
+}
+
 func TestOperation_CreatedAt(t *testing.T) {
 	tt := test.Start(t)
 	defer tt.Finish()
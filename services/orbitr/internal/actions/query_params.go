@@ -3,6 +3,7 @@ package actions
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/lantah/go/support/errors"
 	"github.com/lantah/go/support/render/problem"
@@ -142,3 +143,52 @@ func (q SellingBuyingAssetQueryParams) Buying() (*xdr.Asset, error) {
 
 	return &buying, nil
 }
+
+// HistoryFilterQueryParams query struct for the asset and ledger close time
+// filters shared by the operations, payments and effects end-points
+type HistoryFilterQueryParams struct {
+	AssetFilter   string `schema:"asset" valid:"asset,optional"`
+	CreatedAfter  string `schema:"created_after" valid:"rfc3339,optional"`
+	CreatedBefore string `schema:"created_before" valid:"rfc3339,optional"`
+}
+
+// Validate runs custom validations on the time bounds
+func (q HistoryFilterQueryParams) Validate() error {
+	after, before := q.TimeBounds()
+	if !after.IsZero() && !before.IsZero() && !after.Before(before) {
+		return problem.MakeInvalidFieldProblem(
+			"created_before",
+			errors.New("created_before must be later than created_after"),
+		)
+	}
+	return nil
+}
+
+// Asset returns the asset filter or nil if it wasn't provided.
+func (q HistoryFilterQueryParams) Asset() *xdr.Asset {
+	if len(q.AssetFilter) == 0 {
+		return nil
+	}
+
+	if strings.ToLower(q.AssetFilter) == "native" {
+		asset := xdr.MustNewNativeAsset()
+		return &asset
+	}
+
+	parts := strings.Split(q.AssetFilter, ":")
+	asset := xdr.MustNewCreditAsset(parts[0], parts[1])
+	return &asset
+}
+
+// TimeBounds returns the ledger close time bounds. A bound which wasn't
+// provided is returned as the zero time.
+func (q HistoryFilterQueryParams) TimeBounds() (after time.Time, before time.Time) {
+	// Both values have already been validated as RFC 3339 timestamps.
+	if len(q.CreatedAfter) > 0 {
+		after, _ = time.Parse(time.RFC3339, q.CreatedAfter)
+	}
+	if len(q.CreatedBefore) > 0 {
+		before, _ = time.Parse(time.RFC3339, q.CreatedBefore)
+	}
+	return after, before
+}
@@ -0,0 +1,274 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	orbitrContext "github.com/lantah/go/services/orbitr/internal/context"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/services/orbitr/internal/ingest"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/support/render/problem"
+)
+
+// ReingestJobManager enqueues and tracks reingestion jobs, it is implemented
+// by ingest.ReingestJobManager.
+type ReingestJobManager interface {
+	Enqueue(ctx context.Context, ranges []history.LedgerRange, force bool) (ingest.ReingestJob, error)
+	Jobs() []ingest.ReingestJob
+	Job(id string) (ingest.ReingestJob, error)
+	Cancel(id string) error
+}
+
+// these admin HTTP endpoints are documented in services/orbitr/internal/httpx/static/admin_oapi.yml
+type ReingestHandler struct {
+	Jobs ReingestJobManager
+}
+
+// GetLedgerGaps returns the ranges of ledgers missing from history, limited to
+// [start, end] when both query parameters are set.
+func (handler ReingestHandler) GetLedgerGaps(w http.ResponseWriter, r *http.Request) {
+	historyQ, err := orbitrContext.HistoryQFromRequest(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	start, err := getLedgerSequence(r, "start")
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+	end, err := getLedgerSequence(r, "end")
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	var gaps []history.LedgerRange
+	switch {
+	case start == 0 && end == 0:
+		gaps, err = historyQ.GetLedgerGaps(r.Context())
+	case start == 0 || end == 0:
+		err = problem.NewProblemWithInvalidField(problem.BadRequest, "end", errors.New("start and end must be set together"))
+	case start > end:
+		err = problem.NewProblemWithInvalidField(problem.BadRequest, "end", errors.New("end must be greater than or equal to start"))
+	default:
+		gaps, err = historyQ.GetLedgerGapsInRange(r.Context(), start, end)
+	}
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	handler.render(w, r, hProtocol.LedgerGaps{Gaps: ledgerRangesResource(gaps)})
+}
+
+// EnqueueJob enqueues a job reingesting the requested ranges or, if
+// fill_gaps is set, the gaps detected in history (within the requested
+// ranges, if any).
+func (handler ReingestHandler) EnqueueJob(w http.ResponseWriter, r *http.Request) {
+	var request hProtocol.ReingestRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		problem.Render(r.Context(), w, problem.NewProblemWithInvalidField(
+			problem.BadRequest, "reason", fmt.Errorf("invalid json for reingest request %v", err.Error()),
+		))
+		return
+	}
+
+	ranges := make([]history.LedgerRange, 0, len(request.Ranges))
+	for _, r := range request.Ranges {
+		ranges = append(ranges, history.LedgerRange{StartSequence: r.Start, EndSequence: r.End})
+	}
+
+	if request.FillGaps {
+		historyQ, err := orbitrContext.HistoryQFromRequest(r)
+		if err != nil {
+			problem.Render(r.Context(), w, err)
+			return
+		}
+		if ranges, err = handler.detectGaps(r.Context(), historyQ, ranges); err != nil {
+			problem.Render(r.Context(), w, err)
+			return
+		}
+		if len(ranges) == 0 {
+			problem.Render(r.Context(), w, problem.NewProblemWithInvalidField(
+				problem.BadRequest, "fill_gaps", errors.New("no gaps detected"),
+			))
+			return
+		}
+	} else if len(ranges) == 0 {
+		problem.Render(r.Context(), w, problem.NewProblemWithInvalidField(
+			problem.BadRequest, "ranges", errors.New("ranges are required unless fill_gaps is set"),
+		))
+		return
+	}
+
+	job, err := handler.Jobs.Enqueue(r.Context(), ranges, request.Force)
+	if err != nil {
+		problem.Render(r.Context(), w, enqueueProblem(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	handler.render(w, r, reingestJobResource(job))
+}
+
+// GetJobs returns all the reingestion jobs.
+func (handler ReingestHandler) GetJobs(w http.ResponseWriter, r *http.Request) {
+	jobs := handler.Jobs.Jobs()
+	response := hProtocol.ReingestJobs{Jobs: make([]hProtocol.ReingestJob, 0, len(jobs))}
+	for _, job := range jobs {
+		response.Jobs = append(response.Jobs, reingestJobResource(job))
+	}
+	handler.render(w, r, response)
+}
+
+// GetJob returns the reingestion job with the given id.
+func (handler ReingestHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	id, err := getStringFromURLParam(r, "id")
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	job, err := handler.Jobs.Job(id)
+	if err != nil {
+		problem.Render(r.Context(), w, reingestJobProblem(err))
+		return
+	}
+	handler.render(w, r, reingestJobResource(job))
+}
+
+// CancelJob cancels a queued reingestion job or stops a running one.
+func (handler ReingestHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	id, err := getStringFromURLParam(r, "id")
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	if err = handler.Jobs.Cancel(id); err != nil {
+		problem.Render(r.Context(), w, reingestJobProblem(err))
+		return
+	}
+
+	job, err := handler.Jobs.Job(id)
+	if err != nil {
+		problem.Render(r.Context(), w, reingestJobProblem(err))
+		return
+	}
+	handler.render(w, r, reingestJobResource(job))
+}
+
+func (handler ReingestHandler) detectGaps(
+	ctx context.Context,
+	historyQ *history.Q,
+	ranges []history.LedgerRange,
+) ([]history.LedgerRange, error) {
+	if len(ranges) == 0 {
+		return historyQ.GetLedgerGaps(ctx)
+	}
+
+	var gaps []history.LedgerRange
+	for _, r := range ranges {
+		if r.StartSequence > r.EndSequence {
+			return nil, problem.NewProblemWithInvalidField(
+				problem.BadRequest, "ranges", fmt.Errorf("invalid range: %v from > to", r),
+			)
+		}
+		rangeGaps, err := historyQ.GetLedgerGapsInRange(ctx, r.StartSequence, r.EndSequence)
+		if err != nil {
+			return nil, err
+		}
+		gaps = append(gaps, rangeGaps...)
+	}
+	return gaps, nil
+}
+
+func (handler ReingestHandler) render(w http.ResponseWriter, r *http.Request, response interface{}) {
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		problem.Render(r.Context(), w, err)
+	}
+}
+
+func enqueueProblem(err error) error {
+	switch err.(type) {
+	case ingest.ErrInvalidReingestRanges:
+		return problem.MakeInvalidFieldProblem("ranges", err)
+	case ingest.ErrReingestRangeConflict:
+		return problem.P{
+			Type:   "reingest_range_conflict",
+			Title:  "Reingest Range Conflict",
+			Status: http.StatusConflict,
+			Detail: err.Error() + ". Set force to reingest the range anyway.",
+		}
+	}
+	if err == ingest.ErrReingestQueueFull {
+		return problem.P{
+			Type:   "reingest_queue_full",
+			Title:  "Reingest Queue Full",
+			Status: http.StatusServiceUnavailable,
+			Detail: err.Error(),
+		}
+	}
+	return err
+}
+
+func reingestJobProblem(err error) error {
+	switch err {
+	case ingest.ErrReingestJobNotFound:
+		return problem.NotFound
+	case ingest.ErrReingestJobFinished:
+		return problem.MakeInvalidFieldProblem("id", err)
+	default:
+		return err
+	}
+}
+
+func getLedgerSequence(r *http.Request, name string) (uint32, error) {
+	value, err := getString(r, name)
+	if err != nil || value == "" {
+		return 0, err
+	}
+	sequence, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, problem.MakeInvalidFieldProblem(name, errors.New("must be a ledger sequence"))
+	}
+	return uint32(sequence), nil
+}
+
+func ledgerRangesResource(ranges []history.LedgerRange) []hProtocol.LedgerRange {
+	resource := make([]hProtocol.LedgerRange, 0, len(ranges))
+	for _, r := range ranges {
+		resource = append(resource, hProtocol.LedgerRange{Start: r.StartSequence, End: r.EndSequence})
+	}
+	return resource
+}
+
+func reingestJobResource(job ingest.ReingestJob) hProtocol.ReingestJob {
+	optionalTime := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+		return &t
+	}
+	return hProtocol.ReingestJob{
+		ID:              job.ID,
+		Status:          string(job.Status),
+		Ranges:          ledgerRangesResource(job.Ranges),
+		Force:           job.Force,
+		TotalLedgers:    job.TotalLedgers,
+		IngestedLedgers: job.IngestedLedgers,
+		CurrentLedger:   job.CurrentLedger,
+		Error:           job.Error,
+		CreatedAt:       job.CreatedAt,
+		StartedAt:       optionalTime(job.StartedAt),
+		FinishedAt:      optionalTime(job.FinishedAt),
+	}
+}
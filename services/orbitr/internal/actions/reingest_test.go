@@ -0,0 +1,206 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	hProtocol "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/services/orbitr/internal/ingest"
+	"github.com/lantah/go/support/render/problem"
+)
+
+type mockReingestJobManager struct {
+	mock.Mock
+}
+
+func (m *mockReingestJobManager) Enqueue(ctx context.Context, ranges []history.LedgerRange, force bool) (ingest.ReingestJob, error) {
+	args := m.Called(ctx, ranges, force)
+	return args.Get(0).(ingest.ReingestJob), args.Error(1)
+}
+
+func (m *mockReingestJobManager) Jobs() []ingest.ReingestJob {
+	args := m.Called()
+	return args.Get(0).([]ingest.ReingestJob)
+}
+
+func (m *mockReingestJobManager) Job(id string) (ingest.ReingestJob, error) {
+	args := m.Called(id)
+	return args.Get(0).(ingest.ReingestJob), args.Error(1)
+}
+
+func (m *mockReingestJobManager) Cancel(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func makeReingestRequest(t *testing.T, method, body string, routeParams map[string]string) *http.Request {
+	request, err := http.NewRequest(method, "/", strings.NewReader(body))
+	require.NoError(t, err)
+
+	chiRouteContext := chi.NewRouteContext()
+	for key, value := range routeParams {
+		chiRouteContext.URLParams.Add(key, value)
+	}
+	return request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, chiRouteContext))
+}
+
+func decodeProblem(t *testing.T, recorder *httptest.ResponseRecorder) problem.P {
+	var p problem.P
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+	return p
+}
+
+func TestReingestHandlerEnqueueJob(t *testing.T) {
+	jobs := &mockReingestJobManager{}
+	handler := ReingestHandler{Jobs: jobs}
+	createdAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ranges := []history.LedgerRange{{StartSequence: 10, EndSequence: 20}}
+	jobs.On("Enqueue", mock.Anything, ranges, true).Return(ingest.ReingestJob{
+		ID:           "1",
+		Ranges:       ranges,
+		Force:        true,
+		Status:       ingest.ReingestJobQueued,
+		TotalLedgers: 11,
+		CreatedAt:    createdAt,
+	}, nil).Once()
+
+	recorder := httptest.NewRecorder()
+	handler.EnqueueJob(recorder, makeReingestRequest(
+		t, http.MethodPost, `{"ranges": [{"start": 10, "end": 20}], "force": true}`, nil,
+	))
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+	var job hProtocol.ReingestJob
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &job))
+	assert.Equal(t, hProtocol.ReingestJob{
+		ID:           "1",
+		Status:       "queued",
+		Ranges:       []hProtocol.LedgerRange{{Start: 10, End: 20}},
+		Force:        true,
+		TotalLedgers: 11,
+		CreatedAt:    createdAt,
+	}, job)
+	jobs.AssertExpectations(t)
+}
+
+func TestReingestHandlerEnqueueJobErrors(t *testing.T) {
+	jobs := &mockReingestJobManager{}
+	handler := ReingestHandler{Jobs: jobs}
+
+	for _, testCase := range []struct {
+		name   string
+		body   string
+		err    error
+		status int
+		typ    string
+	}{
+		{
+			name:   "invalid json",
+			body:   `{"ranges": 1}`,
+			status: http.StatusBadRequest,
+			typ:    "bad_request",
+		},
+		{
+			name:   "no ranges",
+			body:   `{"force": true}`,
+			status: http.StatusBadRequest,
+			typ:    "bad_request",
+		},
+		{
+			name:   "conflict",
+			body:   `{"ranges": [{"start": 10, "end": 20}]}`,
+			err:    ingest.ErrReingestRangeConflict{},
+			status: http.StatusConflict,
+			typ:    "reingest_range_conflict",
+		},
+		{
+			name:   "queue full",
+			body:   `{"ranges": [{"start": 10, "end": 20}]}`,
+			err:    ingest.ErrReingestQueueFull,
+			status: http.StatusServiceUnavailable,
+			typ:    "reingest_queue_full",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			if testCase.err != nil {
+				jobs.On("Enqueue", mock.Anything, mock.Anything, false).
+					Return(ingest.ReingestJob{}, testCase.err).Once()
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.EnqueueJob(recorder, makeReingestRequest(t, http.MethodPost, testCase.body, nil))
+			assert.Equal(t, testCase.status, recorder.Code)
+			assert.Equal(t, problem.DefaultServiceHost+testCase.typ, decodeProblem(t, recorder).Type)
+		})
+	}
+	jobs.AssertExpectations(t)
+}
+
+func TestReingestHandlerGetJobs(t *testing.T) {
+	jobs := &mockReingestJobManager{}
+	handler := ReingestHandler{Jobs: jobs}
+	startedAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	jobs.On("Jobs").Return([]ingest.ReingestJob{
+		{
+			ID:              "1",
+			Ranges:          []history.LedgerRange{{StartSequence: 10, EndSequence: 20}},
+			Status:          ingest.ReingestJobRunning,
+			TotalLedgers:    11,
+			IngestedLedgers: 3,
+			CurrentLedger:   12,
+			StartedAt:       startedAt,
+		},
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.GetJobs(recorder, makeReingestRequest(t, http.MethodGet, "", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response hProtocol.ReingestJobs
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Len(t, response.Jobs, 1)
+	assert.Equal(t, "running", response.Jobs[0].Status)
+	assert.Equal(t, uint32(3), response.Jobs[0].IngestedLedgers)
+	assert.Equal(t, uint32(12), response.Jobs[0].CurrentLedger)
+	assert.Equal(t, startedAt, *response.Jobs[0].StartedAt)
+	assert.Nil(t, response.Jobs[0].FinishedAt)
+}
+
+func TestReingestHandlerGetAndCancelJob(t *testing.T) {
+	jobs := &mockReingestJobManager{}
+	handler := ReingestHandler{Jobs: jobs}
+
+	jobs.On("Job", "2").Return(ingest.ReingestJob{}, ingest.ErrReingestJobNotFound)
+	recorder := httptest.NewRecorder()
+	handler.GetJob(recorder, makeReingestRequest(t, http.MethodGet, "", map[string]string{"id": "2"}))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	jobs.On("Cancel", "1").Return(nil).Once()
+	jobs.On("Job", "1").Return(ingest.ReingestJob{ID: "1", Status: ingest.ReingestJobCancelled}, nil)
+	recorder = httptest.NewRecorder()
+	handler.CancelJob(recorder, makeReingestRequest(t, http.MethodDelete, "", map[string]string{"id": "1"}))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var job hProtocol.ReingestJob
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &job))
+	assert.Equal(t, "cancelled", job.Status)
+
+	jobs.On("Cancel", "1").Return(ingest.ErrReingestJobFinished).Once()
+	recorder = httptest.NewRecorder()
+	handler.CancelJob(recorder, makeReingestRequest(t, http.MethodDelete, "", map[string]string{"id": "1"}))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	jobs.AssertExpectations(t)
+}
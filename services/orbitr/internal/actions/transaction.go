@@ -60,6 +60,7 @@ type TransactionsQuery struct {
 	AccountID                 string `schema:"account_id" valid:"accountID,optional"`
 	ClaimableBalanceID        string `schema:"claimable_balance_id" valid:"claimableBalanceID,optional"`
 	LiquidityPoolID           string `schema:"liquidity_pool_id" valid:"sha256,optional"`
+	MuxedAccountID            string `schema:"muxed_account_id" valid:"muxedAccountID,optional"`
 	IncludeFailedTransactions bool   `schema:"include_failed" valid:"-"`
 	LedgerID                  uint32 `schema:"ledger_id" valid:"-"`
 }
@@ -70,6 +71,7 @@ func (qp TransactionsQuery) Validate() error {
 		qp.AccountID,
 		qp.ClaimableBalanceID,
 		qp.LiquidityPoolID,
+		qp.MuxedAccountID,
 		qp.LedgerID,
 	)
 
@@ -150,6 +152,8 @@ func loadTransactionRecords(ctx context.Context, hq *history.Q, qp TransactionsQ
 		txs.ForClaimableBalance(ctx, qp.ClaimableBalanceID)
 	case qp.LiquidityPoolID != "":
 		txs.ForLiquidityPool(ctx, qp.LiquidityPoolID)
+	case qp.MuxedAccountID != "":
+		txs.ForMuxedAccount(ctx, qp.MuxedAccountID)
 	case qp.LedgerID > 0:
 		txs.ForLedger(ctx, int32(qp.LedgerID))
 	}
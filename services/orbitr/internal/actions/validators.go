@@ -10,6 +10,7 @@ import (
 
 	"github.com/lantah/go/amount"
 	"github.com/lantah/go/services/orbitr/internal/assets"
+	"github.com/lantah/go/strkey"
 	"github.com/lantah/go/support/errors"
 	"github.com/lantah/go/xdr"
 )
@@ -25,6 +26,7 @@ func init() {
 	govalidator.TagMap["assetType"] = isAssetType
 	govalidator.TagMap["asset"] = isAsset
 	govalidator.TagMap["claimableBalanceID"] = isClaimableBalanceID
+	govalidator.TagMap["muxedAccountID"] = isMuxedAccountID
 	govalidator.TagMap["transactionHash"] = isTransactionHash
 	govalidator.TagMap["sha256"] = govalidator.IsSHA256
 	govalidator.TagMap["tradeType"] = isTradeType
@@ -38,8 +40,10 @@ var customTagsErrorMessages = map[string]string{
 	"bool":                 "Filter should be true or false",
 	"claimable_balance_id": "Claimable Balance ID must be the hex-encoded XDR representation of a Claimable Balance ID",
 	"ledger_id":            "Ledger ID must be an integer higher than 0",
+	"muxedAccountID":       "Muxed account ID must start with `M` and contain 69 alphanum characters",
 	"offer_id":             "Offer ID must be an integer higher than 0",
 	"op_id":                "Operation ID must be an integer higher than 0",
+	"rfc3339":              "Timestamp must be an RFC 3339 date-time, e.g. 2019-10-31T13:19:46Z",
 	"transactionHash":      "Transaction hash must be a hex-encoded, lowercase SHA-256 hash",
 	"tradeType":            "Trade type must be all, orderbook, or liquidity_pool",
 }
@@ -139,6 +143,14 @@ func isAccountID(str string) bool {
 	return true
 }
 
+func isMuxedAccountID(str string) bool {
+	if _, err := strkey.Decode(strkey.VersionByteMuxedAccount, str); err != nil {
+		return false
+	}
+
+	return true
+}
+
 func isTransactionHash(str string) bool {
 	decoded, err := hex.DecodeString(str)
 	if err != nil {
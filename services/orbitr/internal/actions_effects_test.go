@@ -94,6 +94,35 @@ func TestEffectActions_Index(t *testing.T) {
 			ht.Assert.PageOf(3, w.Body)
 		}
 
+		// filtered by type, asset and close time
+		w = ht.Get("/effects?type=account_debited")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(4, w.Body)
+		}
+
+		w = ht.Get("/effects?type=account_created,signer_created")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(6, w.Body)
+		}
+
+		w = ht.Get("/effects?asset=native")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(5, w.Body)
+		}
+
+		w = ht.Get("/effects?created_after=2019-10-31T13:19:45Z")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(2, w.Body)
+		}
+
+		w = ht.Get("/ledgers/2/effects?type=account_credited")
+		if ht.Assert.Equal(200, w.Code) {
+			ht.Assert.PageOf(0, w.Body)
+		}
+
+		w = ht.Get("/effects?type=foo")
+		ht.Assert.Equal(400, w.Code)
+
 		// Check extra params
 		w = ht.Get("/ledgers/100/effects?account_id=GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
 		ht.Assert.Equal(400, w.Code)
@@ -36,13 +36,13 @@ func mockPathFindingClient(
 	session *db.Session,
 ) test.RequestHelper {
 	router := chi.NewRouter()
-	findPaths := httpx.ObjectActionHandler{actions.FindPathsHandler{
+	findPaths := httpx.ObjectActionHandler{Action: actions.FindPathsHandler{
 		PathFinder:           finder,
 		MaxAssetsParamLength: maxAssetsParamLength,
 		MaxPathLength:        3,
 		SetLastLedgerHeader:  true,
 	}}
-	findFixedPaths := httpx.ObjectActionHandler{actions.FindFixedPathsHandler{
+	findFixedPaths := httpx.ObjectActionHandler{Action: actions.FindFixedPathsHandler{
 		PathFinder:           finder,
 		MaxAssetsParamLength: maxAssetsParamLength,
 		MaxPathLength:        3,
@@ -15,6 +15,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/lantah/go/clients/gravity"
+	"github.com/lantah/go/services/orbitr/internal/archivestate"
 	"github.com/lantah/go/services/orbitr/internal/corestate"
 	"github.com/lantah/go/services/orbitr/internal/db2/history"
 	"github.com/lantah/go/services/orbitr/internal/httpx"
@@ -39,6 +40,8 @@ type App struct {
 	webServer       *httpx.Server
 	historyQ        *history.Q
 	primaryHistoryQ *history.Q
+	replicaQs       []*history.Q
+	replicaRouter   *httpx.ReplicaRouter
 	ctx             context.Context
 	cancel          func()
 	orbitrVersion  string
@@ -48,6 +51,10 @@ type App struct {
 	paths           paths.Finder
 	ingester        ingest.System
 	reaper          *reap.System
+	reingestJobs    *ingest.ReingestJobManager
+	gapFiller       *ingest.GapFiller
+	leaderElector   *ingest.LeaderElector
+	archiveState    *archivestate.Reader
 	ticks           *time.Ticker
 	ledgerState     *ledger.State
 
@@ -107,6 +114,14 @@ func (a *App) Serve() error {
 		}()
 	}
 
+	if a.replicaRouter != nil {
+		wg.Add(1)
+		go func() {
+			a.replicaRouter.Run(a.ctx)
+			wg.Done()
+		}()
+	}
+
 	if a.reaper != nil {
 		wg.Add(1)
 		go func() {
@@ -115,6 +130,30 @@ func (a *App) Serve() error {
 		}()
 	}
 
+	if a.reingestJobs != nil {
+		wg.Add(1)
+		go func() {
+			a.reingestJobs.Run()
+			wg.Done()
+		}()
+	}
+
+	if a.leaderElector != nil {
+		wg.Add(1)
+		go func() {
+			a.leaderElector.Run()
+			wg.Done()
+		}()
+	}
+
+	if a.gapFiller != nil {
+		wg.Add(1)
+		go func() {
+			a.gapFiller.Run()
+			wg.Done()
+		}()
+	}
+
 	// configure shutdown signal handler
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
@@ -163,9 +202,25 @@ func (a *App) waitForDone() {
 	if a.ingester != nil {
 		a.ingester.Shutdown()
 	}
+	// the lease is released once ingestion has stopped so that another
+	// instance can take over right away
+	if a.leaderElector != nil {
+		a.leaderElector.Shutdown()
+	}
 	if a.reaper != nil {
 		a.reaper.Shutdown()
 	}
+	if a.gapFiller != nil {
+		a.gapFiller.Shutdown()
+	}
+	if a.reingestJobs != nil {
+		a.reingestJobs.Shutdown()
+	}
+	if a.archiveState != nil {
+		if err := a.archiveState.Close(); err != nil {
+			log.Warnf("could not remove archive state bucket indexes: %s", err)
+		}
+	}
 	a.ticks.Stop()
 }
 
@@ -174,6 +229,9 @@ func (a *App) waitForDone() {
 // closed" errors.
 func (a *App) CloseDB() {
 	a.historyQ.SessionInterface.Close()
+	for _, q := range a.replicaQs {
+		q.SessionInterface.Close()
+	}
 }
 
 // HistoryQ returns a helper object for performing sql queries against the
@@ -495,7 +553,10 @@ func (a *App) init() error {
 	initSubmissionSystem(a)
 
 	// reaper
-	a.reaper = reap.New(a.config.HistoryRetentionCount, a.OrbitRSession(), a.ledgerState)
+	initReaper(a)
+
+	// point in time ledger entries
+	initArchiveState(a)
 
 	// go metrics
 	initGoMetrics(a)
@@ -538,13 +599,26 @@ func (a *App) init() error {
 				HTTP: &http.Client{Timeout: infoRequestTimeout},
 				URL:  a.config.GravityURL,
 			},
-			cache: newHealthCache(healthCacheTTL),
+			cache:         newHealthCache(healthCacheTTL),
+			leaderElector: a.leaderElector,
 		},
 	}
 
 	if a.primaryHistoryQ != nil {
 		routerConfig.PrimaryDBSession = a.primaryHistoryQ.SessionInterface
 	}
+	if a.replicaRouter != nil {
+		routerConfig.Replicas = a.replicaRouter
+	}
+	if a.reingestJobs != nil {
+		routerConfig.ReingestJobs = a.reingestJobs
+	}
+	if a.archiveState != nil {
+		routerConfig.ArchiveState = a.archiveState
+	}
+	if a.leaderElector != nil {
+		routerConfig.LeaderElector = a.leaderElector
+	}
 
 	var err error
 	config := httpx.ServerConfig{
@@ -0,0 +1,279 @@
+package archivestate
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/lantah/go/historyarchive"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/xdr"
+)
+
+const (
+	// offsetEntrySize is the estimated memory used by an entry of
+	// bucketIndex.offsets, map overhead included.
+	offsetEntrySize = 40
+	// collisionEntrySize is the estimated memory used by an entry of
+	// bucketIndex.collisions, excluding its key.
+	collisionEntrySize = 64
+)
+
+// bucketIndex locates the indexed entries of a bucket, which are either live
+// (LIVEENTRY or INITENTRY) or removed (DEADENTRY). The entries are stored in a
+// local file and only their offsets are kept in memory, keyed by a hash of
+// their binary ledger key.
+type bucketIndex struct {
+	hash historyarchive.Hash
+	path string
+	// offsets maps the hashes of the binary ledger keys to the offsets of
+	// their entries in the file.
+	offsets map[uint64]int64
+	// collisions maps the binary ledger keys whose hash collides with the
+	// one of another key of the bucket to the offsets of their entries.
+	collisions map[string]int64
+	// size is the estimated memory used by the index.
+	size int64
+
+	// refs and evicted are guarded by the lock of the indexCache.
+	refs    int
+	evicted bool
+}
+
+func hashKey(binaryKey []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(binaryKey)
+	return h.Sum64()
+}
+
+// indexWriter writes the indexed entries of a bucket to the file of a
+// bucketIndex as they are read from the bucket.
+type indexWriter struct {
+	index  *bucketIndex
+	file   *os.File
+	buf    *bufio.Writer
+	offset int64
+}
+
+func newIndexWriter(dir string, hash historyarchive.Hash) (*indexWriter, error) {
+	file, err := os.CreateTemp(dir, "bucket-"+hash.String()+"-")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create bucket index file")
+	}
+	return &indexWriter{
+		index: &bucketIndex{
+			hash:    hash,
+			path:    file.Name(),
+			offsets: map[uint64]int64{},
+		},
+		file: file,
+		buf:  bufio.NewWriter(file),
+	}, nil
+}
+
+func (w *indexWriter) add(binaryKey []byte, entry xdr.BucketEntry) error {
+	encoded, err := entry.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "Error encoding bucket entry")
+	}
+
+	// keys are unique within a bucket, a known hash is a collision
+	keyHash := hashKey(binaryKey)
+	if _, ok := w.index.offsets[keyHash]; ok {
+		if w.index.collisions == nil {
+			w.index.collisions = map[string]int64{}
+		}
+		w.index.collisions[string(binaryKey)] = w.offset
+		w.index.size += collisionEntrySize + int64(len(binaryKey))
+	} else {
+		w.index.offsets[keyHash] = w.offset
+		w.index.size += offsetEntrySize
+	}
+
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(encoded)))
+	if _, err = w.buf.Write(length[:]); err != nil {
+		return errors.Wrap(err, "could not write bucket index file")
+	}
+	if _, err = w.buf.Write(encoded); err != nil {
+		return errors.Wrap(err, "could not write bucket index file")
+	}
+	w.offset += int64(len(length) + len(encoded))
+	return nil
+}
+
+// finish closes the file and returns the index, or removes the file if err
+// is not nil.
+func (w *indexWriter) finish(err error) (*bucketIndex, error) {
+	if err == nil {
+		err = w.buf.Flush()
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(w.index.path)
+		return nil, err
+	}
+	return w.index, nil
+}
+
+// lookup returns the entry with the given binary ledger key, ok is false if
+// the bucket does not contain it.
+func (i *bucketIndex) lookup(binaryKey []byte) (entry xdr.BucketEntry, ok bool, err error) {
+	offset, ok := i.collisions[string(binaryKey)]
+	if !ok {
+		offset, ok = i.offsets[hashKey(binaryKey)]
+	}
+	if !ok {
+		return xdr.BucketEntry{}, false, nil
+	}
+
+	file, err := os.Open(i.path)
+	if err != nil {
+		return xdr.BucketEntry{}, false, errors.Wrap(err, "could not open bucket index file")
+	}
+	defer file.Close()
+
+	var length [4]byte
+	if _, err = file.ReadAt(length[:], offset); err != nil {
+		return xdr.BucketEntry{}, false, errors.Wrap(err, "could not read bucket index file")
+	}
+	encoded := make([]byte, binary.BigEndian.Uint32(length[:]))
+	if _, err = file.ReadAt(encoded, offset+int64(len(length))); err != nil && err != io.EOF {
+		return xdr.BucketEntry{}, false, errors.Wrap(err, "could not read bucket index file")
+	}
+	if err = entry.UnmarshalBinary(encoded); err != nil {
+		return xdr.BucketEntry{}, false, errors.Wrap(err, "could not decode bucket index entry")
+	}
+
+	// the key may only share its hash with a key of the bucket
+	key, err := bucketEntryKey(entry)
+	if err != nil {
+		return xdr.BucketEntry{}, false, err
+	}
+	entryKey, err := key.MarshalBinary()
+	if err != nil {
+		return xdr.BucketEntry{}, false, errors.Wrap(err, "Error encoding ledger key")
+	}
+	if !bytes.Equal(entryKey, binaryKey) {
+		return xdr.BucketEntry{}, false, nil
+	}
+	return entry, true, nil
+}
+
+func bucketEntryKey(entry xdr.BucketEntry) (xdr.LedgerKey, error) {
+	if entry.Type == xdr.BucketEntryTypeDeadentry {
+		return *entry.DeadEntry, nil
+	}
+	key, err := entry.LiveEntry.LedgerKey()
+	if err != nil {
+		return xdr.LedgerKey{}, errors.Wrap(err, "Error getting ledger key")
+	}
+	return key, nil
+}
+
+// indexBuild is a bucket being indexed, other lookups of the same bucket
+// wait for it to be done.
+type indexBuild struct {
+	done chan struct{}
+	err  error
+}
+
+// indexCache is an LRU cache of bucket indexes bounded by their estimated
+// memory use. The file of an evicted index is removed once it is no longer
+// used by any lookup.
+type indexCache struct {
+	lock     sync.Mutex
+	maxBytes int64
+	bytes    int64
+	lru      *list.List
+	items    map[historyarchive.Hash]*list.Element
+	building map[historyarchive.Hash]*indexBuild
+}
+
+func newIndexCache(maxBytes int64) *indexCache {
+	return &indexCache{
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		items:    map[historyarchive.Hash]*list.Element{},
+		building: map[historyarchive.Hash]*indexBuild{},
+	}
+}
+
+// acquire returns the cached index of the bucket, which must be released
+// once it is no longer used. Otherwise, if the bucket is not being indexed,
+// it returns a new indexBuild which must be passed to finish.
+func (c *indexCache) acquire(hash historyarchive.Hash) (*bucketIndex, *indexBuild, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, ok := c.items[hash]; ok {
+		c.lru.MoveToFront(element)
+		index := element.Value.(*bucketIndex)
+		index.refs++
+		return index, nil, true
+	}
+	if build, ok := c.building[hash]; ok {
+		return nil, build, false
+	}
+	build := &indexBuild{done: make(chan struct{})}
+	c.building[hash] = build
+	return nil, build, true
+}
+
+// finish adds the index built by build to the cache, acquired, and wakes up
+// the lookups waiting for it.
+func (c *indexCache) finish(hash historyarchive.Hash, build *indexBuild, index *bucketIndex, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.building, hash)
+	build.err = err
+	close(build.done)
+	if err != nil {
+		return
+	}
+
+	index.refs++
+	c.items[hash] = c.lru.PushFront(index)
+	c.bytes += index.size
+	// the newest index is kept even if it is larger than the cache
+	for c.bytes > c.maxBytes && c.lru.Len() > 1 {
+		c.evict(c.lru.Back())
+	}
+}
+
+func (c *indexCache) evict(element *list.Element) {
+	index := element.Value.(*bucketIndex)
+	c.lru.Remove(element)
+	delete(c.items, index.hash)
+	c.bytes -= index.size
+	index.evicted = true
+	if index.refs == 0 {
+		os.Remove(index.path)
+	}
+}
+
+// release releases an index returned by acquire or finish.
+func (c *indexCache) release(index *bucketIndex) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	index.refs--
+	if index.evicted && index.refs == 0 {
+		os.Remove(index.path)
+	}
+}
+
+// len returns the number of cached indexes.
+func (c *indexCache) len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.Len()
+}
@@ -0,0 +1,236 @@
+// Package archivestate looks up the state of ledger entries at past
+// checkpoints from the buckets of history archives.
+//
+// Unlike ingest.CheckpointChangeReader, which streams the entire ledger state
+// of a checkpoint, Reader only reads the buckets of the checkpoint bucket list
+// until it finds the requested key, newest bucket first. Buckets are immutable
+// and addressed by hash, so the entries of the indexed types found in a bucket
+// are written to a local file the first time it is read. Only the offsets of
+// the entries are kept in memory, in an LRU cache bounded by size, and later
+// lookups in the same bucket, at any checkpoint, do not download it again.
+package archivestate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lantah/go/historyarchive"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/xdr"
+)
+
+var (
+	// ErrEntryNotFound is returned when the ledger entry does not exist at
+	// the requested checkpoint.
+	ErrEntryNotFound = errors.New("ledger entry not found")
+	// ErrUnsupportedKeyType is returned when the type of the ledger key is
+	// not one of IndexedTypes.
+	ErrUnsupportedKeyType = errors.New("unsupported ledger key type")
+)
+
+// ErrNotCheckpoint is returned when the requested ledger is not a checkpoint
+// ledger.
+type ErrNotCheckpoint struct {
+	Ledger, Previous, Next uint32
+}
+
+func (e ErrNotCheckpoint) Error() string {
+	return fmt.Sprintf("%d is not a checkpoint ledger, try %d or %d", e.Ledger, e.Previous, e.Next)
+}
+
+// IndexedTypes are the types of the ledger entries which can be looked up.
+// Indexing is limited to accounts and trust lines to bound the size of the
+// bucket indexes.
+var IndexedTypes = []xdr.LedgerEntryType{
+	xdr.LedgerEntryTypeAccount,
+	xdr.LedgerEntryTypeTrustline,
+}
+
+func isIndexed(entryType xdr.LedgerEntryType) bool {
+	for _, indexed := range IndexedTypes {
+		if indexed == entryType {
+			return true
+		}
+	}
+	return false
+}
+
+// Reader looks up ledger entries at checkpoints of a history archive.
+type Reader struct {
+	archive historyarchive.ArchiveInterface
+	dir     string
+	indexes *indexCache
+}
+
+// NewReader returns a Reader caching bucket indexes using at most cacheBytes
+// bytes of memory. The indexed entries of the cached buckets are stored in a
+// temporary directory which is removed by Close.
+func NewReader(archive historyarchive.ArchiveInterface, cacheBytes int64) (*Reader, error) {
+	dir, err := os.MkdirTemp("", "orbitr-archive-state")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create bucket index directory")
+	}
+	return &Reader{archive: archive, dir: dir, indexes: newIndexCache(cacheBytes)}, nil
+}
+
+// Close removes the files of the bucket indexes. The Reader must not be used
+// afterwards.
+func (r *Reader) Close() error {
+	return os.RemoveAll(r.dir)
+}
+
+// LedgerEntry returns the ledger entry with the given key as of the given
+// checkpoint ledger.
+func (r *Reader) LedgerEntry(ctx context.Context, checkpoint uint32, key xdr.LedgerKey) (xdr.LedgerEntry, error) {
+	if !isIndexed(key.Type) {
+		return xdr.LedgerEntry{}, ErrUnsupportedKeyType
+	}
+
+	manager := r.archive.GetCheckpointManager()
+	if !manager.IsCheckpoint(checkpoint) {
+		return xdr.LedgerEntry{}, ErrNotCheckpoint{
+			Ledger:   checkpoint,
+			Previous: manager.PrevCheckpoint(checkpoint),
+			Next:     manager.NextCheckpoint(checkpoint),
+		}
+	}
+
+	binaryKey, err := key.MarshalBinary()
+	if err != nil {
+		return xdr.LedgerEntry{}, errors.Wrap(err, "could not encode ledger key")
+	}
+
+	has, err := r.archive.GetCheckpointHAS(checkpoint)
+	if err != nil {
+		return xdr.LedgerEntry{}, errors.Wrapf(err, "unable to get checkpoint HAS at ledger sequence %d", checkpoint)
+	}
+
+	// buckets are ordered from the newest to the oldest, so the first bucket
+	// containing the key has the state of the entry at the checkpoint
+	for _, level := range has.CurrentBuckets {
+		for _, hashString := range []string{level.Curr, level.Snap} {
+			hash, err := historyarchive.DecodeHash(hashString)
+			if err != nil {
+				return xdr.LedgerEntry{}, errors.Wrap(err, "Error decoding bucket hash")
+			}
+			if hash.IsZero() {
+				continue
+			}
+
+			entry, ok, err := r.lookup(ctx, hash, binaryKey)
+			if err != nil {
+				return xdr.LedgerEntry{}, err
+			}
+			if !ok {
+				continue
+			}
+			if entry.Type == xdr.BucketEntryTypeDeadentry {
+				return xdr.LedgerEntry{}, ErrEntryNotFound
+			}
+			return *entry.LiveEntry, nil
+		}
+	}
+
+	return xdr.LedgerEntry{}, ErrEntryNotFound
+}
+
+// lookup returns the entry of the bucket with the given binary ledger key,
+// indexing the bucket first if it is not cached. Lookups of different buckets
+// do not wait for each other.
+func (r *Reader) lookup(ctx context.Context, hash historyarchive.Hash, binaryKey []byte) (xdr.BucketEntry, bool, error) {
+	for {
+		index, build, owner := r.indexes.acquire(hash)
+		if index != nil {
+			defer r.indexes.release(index)
+			return index.lookup(binaryKey)
+		}
+
+		if !owner {
+			// another lookup is indexing the bucket
+			select {
+			case <-ctx.Done():
+				return xdr.BucketEntry{}, false, ctx.Err()
+			case <-build.done:
+			}
+			if build.err != nil && build.err != context.Canceled && build.err != context.DeadlineExceeded {
+				return xdr.BucketEntry{}, false, build.err
+			}
+			// retry, the bucket is now cached or the other lookup was
+			// cancelled
+			continue
+		}
+
+		index, err := r.buildBucketIndex(ctx, hash)
+		r.indexes.finish(hash, build, index, err)
+		if err != nil {
+			return xdr.BucketEntry{}, false, err
+		}
+		defer r.indexes.release(index)
+		return index.lookup(binaryKey)
+	}
+}
+
+func (r *Reader) buildBucketIndex(ctx context.Context, hash historyarchive.Hash) (index *bucketIndex, err error) {
+	stream, err := r.archive.GetXdrStreamForHash(hash)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get xdr stream for hash '%s'", hash.String())
+	}
+	stream.SetExpectedHash(hash)
+
+	writer, err := newIndexWriter(r.dir, hash)
+	if err != nil {
+		stream.Close()
+		return nil, err
+	}
+	err = readBucket(ctx, stream, writer)
+	// Close validates the hash of the bucket, which must have been read
+	// entirely.
+	if closeErr := stream.Close(); closeErr != nil && err == nil {
+		err = errors.Wrapf(closeErr, "Error closing xdr stream of bucket %s", hash.String())
+	}
+	return writer.finish(err)
+}
+
+func readBucket(ctx context.Context, stream *historyarchive.XdrStream, writer *indexWriter) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var entry xdr.BucketEntry
+		if err := stream.ReadOne(&entry); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "Error reading from xdr stream of bucket %s", writer.index.hash.String())
+		}
+
+		switch entry.Type {
+		case xdr.BucketEntryTypeMetaentry:
+			continue
+		case xdr.BucketEntryTypeLiveentry, xdr.BucketEntryTypeInitentry:
+			if !isIndexed(entry.LiveEntry.Data.Type) {
+				continue
+			}
+		case xdr.BucketEntryTypeDeadentry:
+			if !isIndexed(entry.DeadEntry.Type) {
+				continue
+			}
+		default:
+			return errors.Errorf("Unknown BucketEntryType=%d", entry.Type)
+		}
+
+		key, err := bucketEntryKey(entry)
+		if err != nil {
+			return err
+		}
+		binaryKey, err := key.MarshalBinary()
+		if err != nil {
+			return errors.Wrap(err, "Error encoding ledger key")
+		}
+		if err = writer.add(binaryKey, entry); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package archivestate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lantah/go/historyarchive"
+	"github.com/lantah/go/xdr"
+)
+
+var (
+	accountA = xdr.MustAddress("GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML")
+	accountB = xdr.MustAddress("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB")
+)
+
+func accountEntry(account xdr.AccountId, balance xdr.Int64) xdr.BucketEntry {
+	return xdr.BucketEntry{
+		Type: xdr.BucketEntryTypeLiveentry,
+		LiveEntry: &xdr.LedgerEntry{
+			LastModifiedLedgerSeq: 10,
+			Data: xdr.LedgerEntryData{
+				Type:    xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{AccountId: account, Balance: balance},
+			},
+		},
+	}
+}
+
+func accountKey(account xdr.AccountId) xdr.LedgerKey {
+	var key xdr.LedgerKey
+	if err := key.SetAccount(account); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// testArchive serves buckets made of the given entries and counts how many
+// times each of them is downloaded.
+type testArchive struct {
+	historyarchive.MockArchive
+	buckets   map[historyarchive.Hash][]byte
+	downloads map[historyarchive.Hash]int
+}
+
+func (a *testArchive) addBucket(entries ...xdr.BucketEntry) string {
+	b := &bytes.Buffer{}
+	for _, entry := range entries {
+		if err := xdr.MarshalFramed(b, entry); err != nil {
+			panic(err)
+		}
+	}
+	hash := historyarchive.Hash(sha256.Sum256(b.Bytes()))
+	a.buckets[hash] = b.Bytes()
+	return hash.String()
+}
+
+func (a *testArchive) GetXdrStreamForHash(hash historyarchive.Hash) (*historyarchive.XdrStream, error) {
+	a.downloads[hash]++
+	return historyarchive.NewXdrStream(ioutil.NopCloser(bytes.NewReader(a.buckets[hash]))), nil
+}
+
+// checkpointHAS returns a HAS whose bucket list is made of the given curr and
+// snap bucket hashes, level by level.
+func checkpointHAS(buckets ...string) historyarchive.HistoryArchiveState {
+	var has historyarchive.HistoryArchiveState
+	zero := historyarchive.Hash{}.String()
+	for i := range has.CurrentBuckets {
+		has.CurrentBuckets[i].Curr, has.CurrentBuckets[i].Snap = zero, zero
+		if 2*i < len(buckets) {
+			has.CurrentBuckets[i].Curr = buckets[2*i]
+		}
+		if 2*i+1 < len(buckets) {
+			has.CurrentBuckets[i].Snap = buckets[2*i+1]
+		}
+	}
+	return has
+}
+
+func newTestArchive() *testArchive {
+	archive := &testArchive{
+		buckets:   map[historyarchive.Hash][]byte{},
+		downloads: map[historyarchive.Hash]int{},
+	}
+	archive.On("GetCheckpointManager").Return(historyarchive.NewCheckpointManager(64))
+	return archive
+}
+
+func TestLedgerEntry(t *testing.T) {
+	ctx := context.Background()
+	archive := newTestArchive()
+
+	oldest := archive.addBucket(
+		xdr.BucketEntry{Type: xdr.BucketEntryTypeMetaentry, MetaEntry: &xdr.BucketMetadata{LedgerVersion: 20}},
+		accountEntry(accountA, 100),
+		accountEntry(accountB, 200),
+	)
+	newer := archive.addBucket(accountEntry(accountA, 50))
+	newest := archive.addBucket(xdr.BucketEntry{
+		Type:      xdr.BucketEntryTypeDeadentry,
+		DeadEntry: &[]xdr.LedgerKey{accountKey(accountB)}[0],
+	})
+	zero := historyarchive.Hash{}.String()
+
+	archive.On("GetCheckpointHAS", uint32(63)).Return(checkpointHAS(oldest), nil)
+	archive.On("GetCheckpointHAS", uint32(127)).Return(checkpointHAS(newest, newer, oldest, zero), nil)
+
+	reader, err := NewReader(archive, 1<<20)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	entry, err := reader.LedgerEntry(ctx, 63, accountKey(accountA))
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Int64(100), entry.Data.Account.Balance)
+
+	entry, err = reader.LedgerEntry(ctx, 127, accountKey(accountA))
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Int64(50), entry.Data.Account.Balance)
+
+	entry, err = reader.LedgerEntry(ctx, 63, accountKey(accountB))
+	require.NoError(t, err)
+	assert.Equal(t, xdr.Int64(200), entry.Data.Account.Balance)
+
+	_, err = reader.LedgerEntry(ctx, 127, accountKey(accountB))
+	assert.Equal(t, ErrEntryNotFound, err)
+
+	// every bucket is only downloaded once
+	for _, hash := range []string{oldest, newer, newest} {
+		decoded, err := historyarchive.DecodeHash(hash)
+		require.NoError(t, err)
+		assert.Equal(t, 1, archive.downloads[decoded])
+	}
+
+	_, err = reader.LedgerEntry(ctx, 100, accountKey(accountA))
+	assert.EqualError(t, err, "100 is not a checkpoint ledger, try 63 or 127")
+
+	var offerKey xdr.LedgerKey
+	require.NoError(t, offerKey.SetOffer(accountA, 1))
+	_, err = reader.LedgerEntry(ctx, 63, offerKey)
+	assert.Equal(t, ErrUnsupportedKeyType, err)
+}
+
+func TestLedgerEntryCorruptedBucket(t *testing.T) {
+	archive := newTestArchive()
+	bucket := archive.addBucket(accountEntry(accountA, 100))
+	hash, err := historyarchive.DecodeHash(bucket)
+	require.NoError(t, err)
+	// the bucket content no longer matches its hash
+	tampered, err := historyarchive.DecodeHash(archive.addBucket(accountEntry(accountA, 1000)))
+	require.NoError(t, err)
+	archive.buckets[hash] = archive.buckets[tampered]
+
+	archive.On("GetCheckpointHAS", uint32(63)).Return(checkpointHAS(bucket), nil)
+
+	reader, err := NewReader(archive, 1<<20)
+	require.NoError(t, err)
+	defer reader.Close()
+	_, err = reader.LedgerEntry(context.Background(), 63, accountKey(accountA))
+	assert.ErrorContains(t, err, "Error closing xdr stream of bucket")
+	assert.Equal(t, 0, reader.indexes.len())
+	files, err := os.ReadDir(reader.dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestLedgerEntryCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	archive := newTestArchive()
+	bucketA := archive.addBucket(accountEntry(accountA, 100))
+	bucketB := archive.addBucket(accountEntry(accountB, 200))
+	archive.On("GetCheckpointHAS", uint32(63)).Return(checkpointHAS(bucketA), nil)
+	archive.On("GetCheckpointHAS", uint32(127)).Return(checkpointHAS(bucketB), nil)
+
+	// the cache only fits the index of one bucket with one entry
+	reader, err := NewReader(archive, offsetEntrySize)
+	require.NoError(t, err)
+
+	for _, checkpoint := range []uint32{63, 127, 63} {
+		_, err = reader.LedgerEntry(ctx, checkpoint, accountKey(accountA))
+		if checkpoint == 127 {
+			assert.Equal(t, ErrEntryNotFound, err)
+		} else {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 1, reader.indexes.len())
+		// the files of evicted indexes are removed
+		files, err := os.ReadDir(reader.dir)
+		require.NoError(t, err)
+		assert.Len(t, files, 1)
+	}
+
+	hashA, err := historyarchive.DecodeHash(bucketA)
+	require.NoError(t, err)
+	assert.Equal(t, 2, archive.downloads[hashA])
+
+	require.NoError(t, reader.Close())
+	_, err = os.Stat(reader.dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLedgerEntryConcurrentLookups(t *testing.T) {
+	ctx := context.Background()
+	archive := newTestArchive()
+	bucket := archive.addBucket(accountEntry(accountA, 100), accountEntry(accountB, 200))
+	archive.On("GetCheckpointHAS", uint32(63)).Return(checkpointHAS(bucket), nil)
+
+	reader, err := NewReader(archive, 1<<20)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry, err := reader.LedgerEntry(ctx, 63, accountKey(accountB))
+			assert.NoError(t, err)
+			assert.Equal(t, xdr.Int64(200), entry.Data.Account.Balance)
+		}()
+	}
+	wg.Wait()
+
+	hash, err := historyarchive.DecodeHash(bucket)
+	require.NoError(t, err)
+	assert.Equal(t, 1, archive.downloads[hash])
+}
+
+func TestBucketIndexKeyMismatch(t *testing.T) {
+	writer, err := newIndexWriter(t.TempDir(), historyarchive.Hash{})
+	require.NoError(t, err)
+	keyA, err := accountKey(accountA).MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, writer.add(keyA, accountEntry(accountA, 100)))
+	index, err := writer.finish(nil)
+	require.NoError(t, err)
+
+	entry, ok, err := index.lookup(keyA)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, xdr.Int64(100), entry.LiveEntry.Data.Account.Balance)
+
+	// a key sharing its hash with a key of the bucket is not found
+	keyB, err := accountKey(accountB).MarshalBinary()
+	require.NoError(t, err)
+	index.offsets[hashKey(keyB)] = 0
+	_, ok, err = index.lookup(keyB)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/lantah/go/ingest/ledgerbackend"
+	"github.com/lantah/go/xdr"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stellar/throttled"
@@ -18,6 +19,9 @@ type Config struct {
 	HistoryArchiveURLs []string
 	Port               uint
 	AdminPort          uint
+	// ReplicaDatabaseURLs are read replicas serving read-only API queries
+	// once they have replayed the requested ledger.
+	ReplicaDatabaseURLs []string
 
 	EnableCaptiveCoreIngestion  bool
 	EnableIngestionFiltering    bool
@@ -78,6 +82,14 @@ type Config struct {
 	// determining a "retention duration", each ledger roughly corresponds to 10
 	// seconds of real time.
 	HistoryRetentionCount uint
+	// ReapedHistoryArchiveURL is the file:// or s3:// URL where the reaper
+	// archives history before deleting it. Reaped history is not archived when
+	// empty.
+	ReapedHistoryArchiveURL string
+	// ReapedHistoryArchiveS3Region and ReapedHistoryArchiveS3Endpoint configure
+	// the S3 client used when ReapedHistoryArchiveURL is an s3:// URL.
+	ReapedHistoryArchiveS3Region   string
+	ReapedHistoryArchiveS3Endpoint string
 	// StaleThreshold represents the number of ledgers a history database may be
 	// out-of-date by before orbitr begins to respond with an error to history
 	// requests.
@@ -96,9 +108,41 @@ type Config struct {
 	// IngestStateVerificationTimeout configures a timeout on the state verification routine.
 	// If IngestStateVerificationTimeout is set to 0 the timeout is disabled.
 	IngestStateVerificationTimeout time.Duration
+	// IngestStateVerificationShards enables incremental state verification
+	// when greater than 0. Each eligible checkpoint then verifies the entries
+	// of a single type in one of IngestStateVerificationShards shards, rotating
+	// through all of them, instead of the entire state.
+	IngestStateVerificationShards uint
 	// IngestEnableExtendedLogLedgerStats enables extended ledger stats in
 	// logging.
 	IngestEnableExtendedLogLedgerStats bool
+	// IngestHistorySinkURL is the URL of the history sink the ingested
+	// ledgers, transactions, operations, effects and trades are published to,
+	// in addition to the history tables. Nothing is published when empty.
+	IngestHistorySinkURL string
+	// IngestLedgerEntryChanges are the types of the ledger entries whose raw
+	// changes are stored by ingestion and served by /ledger_entry_changes.
+	IngestLedgerEntryChanges []xdr.LedgerEntryType
+	// HistoryGapDetectionInterval is how often ingesting instances check
+	// history for gaps. 0 disables gap detection.
+	HistoryGapDetectionInterval time.Duration
+	// HistoryGapFilling enables reingesting the gaps detected in history.
+	HistoryGapFilling bool
+	// HistoryGapFillingMaxLedgers is the maximum number of ledgers reingested
+	// every HistoryGapDetectionInterval. 0 means unlimited.
+	HistoryGapFillingMaxLedgers uint
+	// IngestLeaderElection restricts ingestion to the instance holding the
+	// ingestion lease instead of any instance getting the next ledger first.
+	IngestLeaderElection bool
+	// IngestLeaderID identifies this instance in the ingestion lease.
+	IngestLeaderID string
+	// IngestLeaderLeaseTTL is how long the ingestion lease is held without
+	// being renewed.
+	IngestLeaderLeaseTTL time.Duration
+	// ArchiveStateCacheSize is the memory, in megabytes, used by the bucket
+	// indexes cached by the admin endpoint looking up ledger entries at past
+	// checkpoints. 0 disables the endpoint.
+	ArchiveStateCacheSize uint
 	// ApplyMigrations will apply pending migrations to the orbitr database
 	// before starting the orbitr service
 	ApplyMigrations bool
@@ -0,0 +1,57 @@
+package history
+
+import (
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/xdr"
+)
+
+// detailsAssetPrefixes lists the prefixes under which operation and effect
+// details store the type, code and issuer of the assets they involve (for
+// example `asset_code`, `source_asset_code`, `selling_asset_code` or
+// `sold_asset_code`).
+var detailsAssetPrefixes = []string{
+	"",
+	"source_",
+	"selling_",
+	"buying_",
+	"sold_",
+	"bought_",
+}
+
+// detailsAssetFilter builds a predicate matching rows of `column` (a jsonb
+// details column) which reference `asset`. Every alternative is expressed as a
+// jsonb containment check so that it can be served by a `jsonb_path_ops` GIN
+// index on the column.
+func detailsAssetFilter(column string, asset xdr.Asset) (sq.Or, error) {
+	var assetType, code, issuer string
+	if err := asset.Extract(&assetType, &code, &issuer); err != nil {
+		return nil, errors.Wrap(err, "could not extract asset")
+	}
+
+	documents := make([]map[string]string, 0, len(detailsAssetPrefixes)+1)
+	for _, prefix := range detailsAssetPrefixes {
+		document := map[string]string{prefix + "asset_type": assetType}
+		if asset.Type != xdr.AssetTypeAssetTypeNative {
+			document[prefix+"asset_code"] = code
+			document[prefix+"asset_issuer"] = issuer
+		}
+		documents = append(documents, document)
+	}
+	// Claimable balance operations and effects use the canonical asset form.
+	documents = append(documents, map[string]string{"asset": asset.StringCanonical()})
+
+	predicate := make(sq.Or, 0, len(documents))
+	for _, document := range documents {
+		serialized, err := json.Marshal(document)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not serialize asset filter")
+		}
+		predicate = append(predicate, sq.Expr(column+" @> ?::jsonb", string(serialized)))
+	}
+
+	return predicate, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/lantah/go/services/orbitr/internal/db2"
 	"github.com/lantah/go/support/errors"
 	"github.com/lantah/go/toid"
+	"github.com/lantah/go/xdr"
 )
 
 // UnmarshalDetails unmarshals the details of this effect into `dest`
@@ -188,6 +190,55 @@ func (q *EffectsQ) ForTransaction(ctx context.Context, hash string) *EffectsQ {
 	return q
 }
 
+// ForTypes filters the query to only effects of the given types.
+func (q *EffectsQ) ForTypes(types ...EffectType) *EffectsQ {
+	if q.Err != nil {
+		return q
+	}
+
+	q.sql = q.sql.Where(sq.Eq{"heff.type": types})
+	return q
+}
+
+// ForAsset filters the query to only effects whose details reference the
+// given asset.
+func (q *EffectsQ) ForAsset(asset xdr.Asset) *EffectsQ {
+	if q.Err != nil {
+		return q
+	}
+
+	var predicate sq.Or
+	predicate, q.Err = detailsAssetFilter("heff.details", asset)
+	if q.Err != nil {
+		return q
+	}
+
+	q.sql = q.sql.Where(predicate)
+	return q
+}
+
+// ForCloseTimeRange filters the query to only effects in ledgers closed
+// strictly between `after` and `before`. A zero time leaves the corresponding
+// bound open.
+func (q *EffectsQ) ForCloseTimeRange(ctx context.Context, after, before time.Time) *EffectsQ {
+	if q.Err != nil {
+		return q
+	}
+
+	var start, end int64
+	start, end, q.Err = q.parent.IDRangeForCloseTime(ctx, after, before)
+	if q.Err != nil {
+		return q
+	}
+
+	q.sql = q.sql.Where(
+		"heff.history_operation_id >= ? AND heff.history_operation_id < ?",
+		start,
+		end,
+	)
+	return q
+}
+
 // Page specifies the paging constraints for the query being built by `q`.
 func (q *EffectsQ) Page(page db2.PageQuery) *EffectsQ {
 	if q.Err != nil {
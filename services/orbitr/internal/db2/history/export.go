@@ -0,0 +1,140 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/lantah/go/support/errors"
+)
+
+// ExportedTable is a history table exported when history is archived.
+type ExportedTable struct {
+	Name string
+	// Column is the toid column used to select the rows of a ledger range.
+	Column string
+	// Lookups maps the columns referencing rows of lookup tables (like
+	// history_accounts) to the name of the lookup table.
+	Lookups map[string]string
+}
+
+// ExportedTables are the history tables exported when history is archived.
+// history_trades_60000 is not exported as it can be rebuilt from
+// history_trades.
+var ExportedTables = []ExportedTable{
+	{Name: "history_ledgers", Column: "id"},
+	{Name: "history_transactions", Column: "id"},
+	{
+		Name: "history_transaction_participants", Column: "history_transaction_id",
+		Lookups: map[string]string{"history_account_id": "history_accounts"},
+	},
+	{Name: "history_transaction_muxed_participants", Column: "history_transaction_id"},
+	{
+		Name: "history_transaction_claimable_balances", Column: "history_transaction_id",
+		Lookups: map[string]string{"history_claimable_balance_id": "history_claimable_balances"},
+	},
+	{
+		Name: "history_transaction_liquidity_pools", Column: "history_transaction_id",
+		Lookups: map[string]string{"history_liquidity_pool_id": "history_liquidity_pools"},
+	},
+	{Name: "history_operations", Column: "id"},
+	{
+		Name: "history_operation_participants", Column: "history_operation_id",
+		Lookups: map[string]string{"history_account_id": "history_accounts"},
+	},
+	{Name: "history_operation_muxed_participants", Column: "history_operation_id"},
+	{
+		Name: "history_operation_claimable_balances", Column: "history_operation_id",
+		Lookups: map[string]string{"history_claimable_balance_id": "history_claimable_balances"},
+	},
+	{
+		Name: "history_operation_liquidity_pools", Column: "history_operation_id",
+		Lookups: map[string]string{"history_liquidity_pool_id": "history_liquidity_pools"},
+	},
+	{
+		Name: "history_effects", Column: "history_operation_id",
+		Lookups: map[string]string{"history_account_id": "history_accounts"},
+	},
+	{
+		Name: "history_trades", Column: "history_operation_id",
+		Lookups: map[string]string{
+			"base_account_id":           "history_accounts",
+			"counter_account_id":        "history_accounts",
+			"base_asset_id":             "history_assets",
+			"counter_asset_id":          "history_assets",
+			"base_liquidity_pool_id":    "history_liquidity_pools",
+			"counter_liquidity_pool_id": "history_liquidity_pools",
+		},
+	},
+	{Name: "history_ledger_entry_changes", Column: "history_transaction_id"},
+}
+
+// ExportHistoryRows calls the callback with the JSON representation of every
+// row of the given history table whose toid column is within [start, end).
+func (q *Q) ExportHistoryRows(
+	ctx context.Context,
+	table ExportedTable,
+	start, end int64,
+	callback func(json.RawMessage) error,
+) error {
+	rows, err := q.QueryRaw(ctx, fmt.Sprintf(
+		"SELECT row_to_json(t) FROM %s t WHERE t.%s >= ? AND t.%s < ?",
+		table.Name, table.Column, table.Column,
+	), start, end)
+	if err != nil {
+		return errors.Wrapf(err, "could not export %s", table.Name)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row json.RawMessage
+		if err = rows.Scan(&row); err != nil {
+			return errors.Wrapf(err, "could not scan %s row", table.Name)
+		}
+		if err = callback(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ExportLookupRows returns the JSON representation of the rows of the given
+// lookup table with the given ids.
+func (q *Q) ExportLookupRows(ctx context.Context, table string, ids []int64) ([]json.RawMessage, error) {
+	var rows []json.RawMessage
+	if len(ids) == 0 {
+		return rows, nil
+	}
+
+	err := q.SelectRaw(ctx, &rows, fmt.Sprintf(
+		"SELECT row_to_json(t) FROM %s t WHERE t.id = ANY(?)", table,
+	), pq.Array(ids))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not export %s", table)
+	}
+	return rows, nil
+}
+
+// ImportHistoryRows inserts rows previously returned by ExportHistoryRows
+// into the given history table.
+func (q *Q) ImportHistoryRows(ctx context.Context, table ExportedTable, rows []json.RawMessage) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	batch, err := json.Marshal(rows)
+	if err != nil {
+		return errors.Wrapf(err, "could not encode %s rows", table.Name)
+	}
+
+	_, err = q.ExecRaw(ctx, fmt.Sprintf(
+		"INSERT INTO %s SELECT * FROM json_populate_recordset(NULL::%s, ?)",
+		table.Name, table.Name,
+	), string(batch))
+	if err != nil {
+		return errors.Wrapf(err, "could not import %s rows", table.Name)
+	}
+	return nil
+}
@@ -9,11 +9,14 @@ import (
 )
 
 const (
-	assetFilterRulesTableName   = "asset_filter_rules"
-	accountFilterRulesTableName = "account_filter_rules"
-	whitelistColumnName         = "whitelist"
-	enabledColumnName           = "enabled"
-	lastModifiedColumnName      = "last_modified"
+	assetFilterRulesTableName     = "asset_filter_rules"
+	accountFilterRulesTableName   = "account_filter_rules"
+	operationFilterRulesTableName = "operation_filter_rules"
+	contractFilterRulesTableName  = "contract_filter_rules"
+	whitelistColumnName           = "whitelist"
+	blacklistColumnName           = "blacklist"
+	enabledColumnName             = "enabled"
+	lastModifiedColumnName        = "last_modified"
 )
 
 type AssetFilterConfig struct {
@@ -28,11 +31,31 @@ type AccountFilterConfig struct {
 	LastModified int64          `db:"last_modified"`
 }
 
+// OperationFilterConfig lists operation types by the names used in orbitr's
+// JSON responses, e.g. "payment" or "invoke_host_function".
+type OperationFilterConfig struct {
+	Enabled      bool           `db:"enabled"`
+	Whitelist    pq.StringArray `db:"whitelist"`
+	Blacklist    pq.StringArray `db:"blacklist"`
+	LastModified int64          `db:"last_modified"`
+}
+
+// ContractFilterConfig lists contract ids as strkeys (C...).
+type ContractFilterConfig struct {
+	Enabled      bool           `db:"enabled"`
+	Whitelist    pq.StringArray `db:"whitelist"`
+	LastModified int64          `db:"last_modified"`
+}
+
 type QFilter interface {
 	GetAccountFilterConfig(ctx context.Context) (AccountFilterConfig, error)
 	GetAssetFilterConfig(ctx context.Context) (AssetFilterConfig, error)
 	UpdateAssetFilterConfig(ctx context.Context, config AssetFilterConfig) (AssetFilterConfig, error)
 	UpdateAccountFilterConfig(ctx context.Context, config AccountFilterConfig) (AccountFilterConfig, error)
+	GetOperationFilterConfig(ctx context.Context) (OperationFilterConfig, error)
+	GetContractFilterConfig(ctx context.Context) (ContractFilterConfig, error)
+	UpdateOperationFilterConfig(ctx context.Context, config OperationFilterConfig) (OperationFilterConfig, error)
+	UpdateContractFilterConfig(ctx context.Context, config ContractFilterConfig) (ContractFilterConfig, error)
 }
 
 func (q *Q) GetAccountFilterConfig(ctx context.Context) (AccountFilterConfig, error) {
@@ -91,6 +114,63 @@ func (q *Q) UpdateAccountFilterConfig(ctx context.Context, config AccountFilterC
 	return q.GetAccountFilterConfig(ctx)
 }
 
+func (q *Q) GetOperationFilterConfig(ctx context.Context) (OperationFilterConfig, error) {
+	filterConfig := OperationFilterConfig{}
+	sql := sq.Select("*").From(operationFilterRulesTableName)
+	err := q.Get(ctx, &filterConfig, sql)
+
+	return filterConfig, err
+}
+
+func (q *Q) GetContractFilterConfig(ctx context.Context) (ContractFilterConfig, error) {
+	filterConfig := ContractFilterConfig{}
+	sql := sq.Select("*").From(contractFilterRulesTableName)
+	err := q.Get(ctx, &filterConfig, sql)
+
+	return filterConfig, err
+}
+
+func (q *Q) UpdateOperationFilterConfig(ctx context.Context, config OperationFilterConfig) (OperationFilterConfig, error) {
+	updateCols := map[string]interface{}{
+		lastModifiedColumnName: sq.Expr(`extract(epoch from now() at time zone 'utc')`),
+		enabledColumnName:      config.Enabled,
+		whitelistColumnName:    config.Whitelist,
+		blacklistColumnName:    config.Blacklist,
+	}
+
+	sqlUpdate := sq.Update(operationFilterRulesTableName).SetMap(updateCols)
+
+	rowCnt, err := q.checkForError(sqlUpdate, ctx)
+	if err != nil {
+		return OperationFilterConfig{}, err
+	}
+
+	if rowCnt < 1 {
+		return OperationFilterConfig{}, sql.ErrNoRows
+	}
+	return q.GetOperationFilterConfig(ctx)
+}
+
+func (q *Q) UpdateContractFilterConfig(ctx context.Context, config ContractFilterConfig) (ContractFilterConfig, error) {
+	updateCols := map[string]interface{}{
+		lastModifiedColumnName: sq.Expr(`extract(epoch from now() at time zone 'utc')`),
+		enabledColumnName:      config.Enabled,
+		whitelistColumnName:    config.Whitelist,
+	}
+
+	sqlUpdate := sq.Update(contractFilterRulesTableName).SetMap(updateCols)
+
+	rowCnt, err := q.checkForError(sqlUpdate, ctx)
+	if err != nil {
+		return ContractFilterConfig{}, err
+	}
+
+	if rowCnt < 1 {
+		return ContractFilterConfig{}, sql.ErrNoRows
+	}
+	return q.GetContractFilterConfig(ctx)
+}
+
 func (q *Q) checkForError(builder sq.Sqlizer, ctx context.Context) (int64, error) {
 	result, err := q.Exec(ctx, builder)
 	if err != nil {
@@ -43,3 +43,44 @@ func TestAccountFilterConfig(t *testing.T) {
 	tt.Assert.Equal(fc1Result.Enabled, true)
 	tt.Assert.ElementsMatch(fc1Result.Whitelist, []string{"1", "2"})
 }
+
+func TestOperationFilterConfig(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetOrbitRDB(t, tt.OrbitRDB)
+	q := &Q{tt.OrbitRSession()}
+
+	fc1Result, err := q.GetOperationFilterConfig(tt.Ctx)
+	assert.NoError(t, err)
+	tt.Assert.Equal(fc1Result.Enabled, false)
+	tt.Assert.Len(fc1Result.Whitelist, 0)
+	tt.Assert.Len(fc1Result.Blacklist, 0)
+
+	fc1Result.Enabled = true
+	fc1Result.Whitelist = append(fc1Result.Whitelist, "payment")
+	fc1Result.Blacklist = append(fc1Result.Blacklist, "inflation", "bump_sequence")
+	fc1Result, err = q.UpdateOperationFilterConfig(tt.Ctx, fc1Result)
+	assert.NoError(t, err)
+	tt.Assert.Equal(fc1Result.Enabled, true)
+	tt.Assert.ElementsMatch(fc1Result.Whitelist, []string{"payment"})
+	tt.Assert.ElementsMatch(fc1Result.Blacklist, []string{"inflation", "bump_sequence"})
+}
+
+func TestContractFilterConfig(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetOrbitRDB(t, tt.OrbitRDB)
+	q := &Q{tt.OrbitRSession()}
+
+	fc1Result, err := q.GetContractFilterConfig(tt.Ctx)
+	assert.NoError(t, err)
+	tt.Assert.Equal(fc1Result.Enabled, false)
+	tt.Assert.Len(fc1Result.Whitelist, 0)
+
+	fc1Result.Enabled = true
+	fc1Result.Whitelist = append(fc1Result.Whitelist, "1", "2")
+	fc1Result, err = q.UpdateContractFilterConfig(tt.Ctx, fc1Result)
+	assert.NoError(t, err)
+	tt.Assert.Equal(fc1Result.Enabled, true)
+	tt.Assert.ElementsMatch(fc1Result.Whitelist, []string{"1", "2"})
+}
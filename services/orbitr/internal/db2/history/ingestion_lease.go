@@ -0,0 +1,140 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+
+	"github.com/lantah/go/support/errors"
+)
+
+// IngestionLease is a row of data from the `ingestion_leases` table. The
+// instance identified by LeaderID is the leader until ExpiresAt unless it
+// renews the lease. FencingToken is incremented every time the lease is
+// acquired by an instance which was not holding it. HandoffTo is set when the
+// leader is requested to step down, in favour of the given instance when not
+// empty.
+type IngestionLease struct {
+	Name         string      `db:"name"`
+	LeaderID     string      `db:"leader_id"`
+	FencingToken int64       `db:"fencing_token"`
+	AcquiredAt   time.Time   `db:"acquired_at"`
+	HeartbeatAt  time.Time   `db:"heartbeat_at"`
+	ExpiresAt    time.Time   `db:"expires_at"`
+	HandoffTo    null.String `db:"handoff_to"`
+}
+
+// QIngestionLeases defines ingestion_leases related queries.
+type QIngestionLeases interface {
+	AcquireIngestionLease(ctx context.Context, name, leaderID string, ttl time.Duration) (IngestionLease, bool, error)
+	ReleaseIngestionLease(ctx context.Context, name, leaderID string, fencingToken int64) error
+	GetIngestionLease(ctx context.Context, name string) (IngestionLease, error)
+	RequestIngestionLeaseHandoff(ctx context.Context, name, to string) (IngestionLease, error)
+	CheckIngestionLeaseFencingToken(ctx context.Context, name string, fencingToken int64) (bool, error)
+}
+
+const nowUTC = "(now() at time zone 'utc')"
+
+// acquireIngestionLeaseSQL creates or takes over the lease, or renews it when
+// leaderID is already holding it. An expired lease can be taken over by any
+// instance unless a handoff was requested, in which case only the requested
+// instance (or any instance but the former leader when handoff_to is empty)
+// can take it over for one more ttl, so that handoffs do not depend on the
+// requested instance being up.
+const acquireIngestionLeaseSQL = `
+INSERT INTO ingestion_leases AS l
+	(name, leader_id, fencing_token, acquired_at, heartbeat_at, expires_at)
+VALUES
+	(?, ?, 1, ` + nowUTC + `, ` + nowUTC + `, ` + nowUTC + ` + interval '1 second' * ?)
+ON CONFLICT (name) DO UPDATE SET
+	fencing_token = CASE WHEN l.leader_id = EXCLUDED.leader_id AND l.expires_at > ` + nowUTC + `
+		THEN l.fencing_token ELSE l.fencing_token + 1 END,
+	acquired_at = CASE WHEN l.leader_id = EXCLUDED.leader_id AND l.expires_at > ` + nowUTC + `
+		THEN l.acquired_at ELSE EXCLUDED.acquired_at END,
+	handoff_to = CASE WHEN l.leader_id = EXCLUDED.leader_id AND l.expires_at > ` + nowUTC + `
+		THEN l.handoff_to ELSE NULL END,
+	leader_id = EXCLUDED.leader_id,
+	heartbeat_at = EXCLUDED.heartbeat_at,
+	expires_at = EXCLUDED.expires_at
+WHERE (l.leader_id = EXCLUDED.leader_id AND l.expires_at > ` + nowUTC + `)
+	OR (l.expires_at <= ` + nowUTC + ` AND (
+		l.handoff_to IS NULL OR
+		l.handoff_to = EXCLUDED.leader_id OR
+		(l.handoff_to = '' AND l.leader_id <> EXCLUDED.leader_id) OR
+		l.expires_at <= ` + nowUTC + ` - interval '1 second' * ?
+	))
+RETURNING *`
+
+// AcquireIngestionLease acquires or renews the lease with the given name for
+// ttl on behalf of leaderID. It returns the lease and true if leaderID is
+// holding it or the current lease and false if it is held by another
+// instance.
+func (q *Q) AcquireIngestionLease(ctx context.Context, name, leaderID string, ttl time.Duration) (IngestionLease, bool, error) {
+	var lease IngestionLease
+	err := q.GetRaw(ctx, &lease, acquireIngestionLeaseSQL, name, leaderID, ttl.Seconds(), ttl.Seconds())
+	if err == nil {
+		return lease, true, nil
+	}
+	if !q.NoRows(err) {
+		return IngestionLease{}, false, errors.Wrap(err, "could not acquire ingestion lease")
+	}
+
+	lease, err = q.GetIngestionLease(ctx, name)
+	return lease, false, err
+}
+
+// ReleaseIngestionLease expires the lease if it is still held by leaderID
+// with the given fencing token.
+func (q *Q) ReleaseIngestionLease(ctx context.Context, name, leaderID string, fencingToken int64) error {
+	sql := sq.Update("ingestion_leases").
+		Set("expires_at", sq.Expr(nowUTC)).
+		Where(sq.Eq{"name": name, "leader_id": leaderID, "fencing_token": fencingToken})
+	_, err := q.Exec(ctx, sql)
+	return err
+}
+
+// GetIngestionLease returns the lease with the given name.
+func (q *Q) GetIngestionLease(ctx context.Context, name string) (IngestionLease, error) {
+	var lease IngestionLease
+	sql := sq.Select("*").From("ingestion_leases").Where("name = ?", name)
+	err := q.Get(ctx, &lease, sql)
+	return lease, err
+}
+
+// RequestIngestionLeaseHandoff requests the leader holding the lease to step
+// down in favour of the instance identified by to, or of any other instance
+// when to is empty.
+func (q *Q) RequestIngestionLeaseHandoff(ctx context.Context, name, to string) (IngestionLease, error) {
+	var lease IngestionLease
+	sql := sq.Update("ingestion_leases").
+		Set("handoff_to", to).
+		Where("name = ?", name).
+		Suffix("RETURNING *")
+	err := q.Get(ctx, &lease, sql)
+	return lease, err
+}
+
+// CheckIngestionLeaseFencingToken returns true if the lease still has the
+// given fencing token. It must be called in a transaction: the lease row is
+// locked until the end of the transaction, so the lease cannot change hands
+// before the transaction is committed.
+func (q *Q) CheckIngestionLeaseFencingToken(ctx context.Context, name string, fencingToken int64) (bool, error) {
+	if tx := q.GetTx(); tx == nil {
+		return false, errors.New("cannot be called outside of a transaction")
+	}
+
+	var current int64
+	sql := sq.Select("fencing_token").
+		From("ingestion_leases").
+		Where("name = ?", name).
+		Suffix("FOR SHARE")
+	if err := q.Get(ctx, &current, sql); err != nil {
+		if q.NoRows(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "could not get ingestion lease fencing token")
+	}
+	return current == fencingToken, nil
+}
@@ -0,0 +1,60 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lantah/go/services/orbitr/internal/test"
+)
+
+func TestIngestionLease(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetOrbitRDB(t, tt.OrbitRDB)
+	q := &Q{tt.OrbitRSession()}
+
+	lease, acquired, err := q.AcquireIngestionLease(tt.Ctx, "ingestion", "a", time.Minute)
+	tt.Assert.NoError(err)
+	tt.Assert.True(acquired)
+	tt.Assert.Equal("a", lease.LeaderID)
+	tt.Assert.Equal(int64(1), lease.FencingToken)
+
+	// b cannot take over a lease which has not expired
+	lease, acquired, err = q.AcquireIngestionLease(tt.Ctx, "ingestion", "b", time.Minute)
+	tt.Assert.NoError(err)
+	tt.Assert.False(acquired)
+	tt.Assert.Equal("a", lease.LeaderID)
+
+	// renewing keeps the fencing token
+	renewed, acquired, err := q.AcquireIngestionLease(tt.Ctx, "ingestion", "a", time.Minute)
+	tt.Assert.NoError(err)
+	tt.Assert.True(acquired)
+	tt.Assert.Equal(int64(1), renewed.FencingToken)
+	tt.Assert.Equal(lease.AcquiredAt, renewed.AcquiredAt)
+
+	// c is requested to take over, b cannot take over the released lease
+	lease, err = q.RequestIngestionLeaseHandoff(tt.Ctx, "ingestion", "c")
+	tt.Assert.NoError(err)
+	tt.Assert.Equal("c", lease.HandoffTo.String)
+	tt.Assert.NoError(q.ReleaseIngestionLease(tt.Ctx, "ingestion", "a", 1))
+	_, acquired, err = q.AcquireIngestionLease(tt.Ctx, "ingestion", "b", time.Minute)
+	tt.Assert.NoError(err)
+	tt.Assert.False(acquired)
+
+	lease, acquired, err = q.AcquireIngestionLease(tt.Ctx, "ingestion", "c", time.Minute)
+	tt.Assert.NoError(err)
+	tt.Assert.True(acquired)
+	tt.Assert.Equal("c", lease.LeaderID)
+	tt.Assert.Equal(int64(2), lease.FencingToken)
+	tt.Assert.False(lease.HandoffTo.Valid)
+
+	// the fencing token of a is not valid anymore
+	tt.Assert.NoError(q.Begin(tt.Ctx))
+	defer q.Rollback()
+	valid, err := q.CheckIngestionLeaseFencingToken(tt.Ctx, "ingestion", 1)
+	tt.Assert.NoError(err)
+	tt.Assert.False(valid)
+	valid, err = q.CheckIngestionLeaseFencingToken(tt.Ctx, "ingestion", 2)
+	tt.Assert.NoError(err)
+	tt.Assert.True(valid)
+}
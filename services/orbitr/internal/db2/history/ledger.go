@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"sort"
 	"time"
 
@@ -68,6 +69,46 @@ func (q *Q) LedgerCapacityUsageStats(ctx context.Context, currentSeq int32, dest
 	`, currentSeq-ledgers, currentSeq)
 }
 
+// IDRangeForCloseTime returns the [start, end) range of total order ids
+// (see the `toid` package) spanning every ledger closed strictly after `after`
+// and strictly before `before`. A zero time leaves the corresponding side of
+// the range open.
+func (q *Q) IDRangeForCloseTime(ctx context.Context, after, before time.Time) (int64, int64, error) {
+	start, end := int64(0), int64(math.MaxInt64)
+
+	if !after.IsZero() {
+		var seq null.Int
+		err := q.GetRaw(ctx, &seq,
+			`SELECT MIN(sequence) FROM history_ledgers WHERE closed_at > $1`,
+			after.UTC(),
+		)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "could not load first ledger closed after time bound")
+		}
+		if seq.Valid {
+			start = toid.New(int32(seq.Int64), 0, 0).ToInt64()
+		} else {
+			// No ledger was closed after the bound yet, so the range is empty.
+			start = end
+		}
+	}
+
+	if !before.IsZero() {
+		var seq null.Int
+		err := q.GetRaw(ctx, &seq,
+			`SELECT MAX(sequence) FROM history_ledgers WHERE closed_at < $1`,
+			before.UTC(),
+		)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "could not load last ledger closed before time bound")
+		}
+		// A missing ledger yields toid(1, 0, 0) which no operation precedes.
+		end = toid.New(int32(seq.Int64)+1, 0, 0).ToInt64()
+	}
+
+	return start, end, nil
+}
+
 // Page specifies the paging constraints for the query being built by `q`.
 func (q *LedgersQ) Page(page db2.PageQuery) *LedgersQ {
 	if q.Err != nil {
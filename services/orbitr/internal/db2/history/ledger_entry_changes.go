@@ -0,0 +1,157 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+
+	"github.com/lantah/go/services/orbitr/internal/db2"
+	"github.com/lantah/go/support/db"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/toid"
+	"github.com/lantah/go/xdr"
+)
+
+// LedgerEntryTypesByName maps the names used to configure and serve ledger
+// entry changes to ledger entry types.
+var LedgerEntryTypesByName = map[string]xdr.LedgerEntryType{
+	"account":           xdr.LedgerEntryTypeAccount,
+	"trustline":         xdr.LedgerEntryTypeTrustline,
+	"offer":             xdr.LedgerEntryTypeOffer,
+	"data":              xdr.LedgerEntryTypeData,
+	"claimable_balance": xdr.LedgerEntryTypeClaimableBalance,
+	"liquidity_pool":    xdr.LedgerEntryTypeLiquidityPool,
+	"contract_data":     xdr.LedgerEntryTypeContractData,
+	"contract_code":     xdr.LedgerEntryTypeContractCode,
+	"config_setting":    xdr.LedgerEntryTypeConfigSetting,
+	"expiration":        xdr.LedgerEntryTypeExpiration,
+}
+
+// LedgerEntryChange is a row of data from the `history_ledger_entry_changes`
+// table. LedgerKey, PreEntry and PostEntry are base64 encoded XDR. Order is the
+// position of the change among the changes of its transaction and
+// OperationIndex is null for changes made by the transaction itself rather
+// than by one of its operations.
+type LedgerEntryChange struct {
+	TransactionID  int64                     `db:"history_transaction_id"`
+	Order          int32                     `db:"order"`
+	OperationIndex null.Int                  `db:"operation_index"`
+	LedgerKey      string                    `db:"ledger_key"`
+	EntryType      xdr.LedgerEntryType       `db:"entry_type"`
+	ChangeType     xdr.LedgerEntryChangeType `db:"change_type"`
+	PreEntry       null.String               `db:"pre_entry"`
+	PostEntry      null.String               `db:"post_entry"`
+}
+
+// LedgerEntryChangeWithTransaction is a LedgerEntryChange along with the hash
+// of its transaction and the close time of its ledger.
+type LedgerEntryChangeWithTransaction struct {
+	LedgerEntryChange
+	TransactionHash string    `db:"transaction_hash"`
+	LedgerCloseTime time.Time `db:"closed_at"`
+}
+
+// LedgerSequence returns the sequence of the ledger the change was applied in.
+func (r *LedgerEntryChange) LedgerSequence() int32 {
+	return toid.Parse(r.TransactionID).LedgerSequence
+}
+
+// PagingToken returns a cursor for this ledger entry change.
+func (r *LedgerEntryChange) PagingToken() string {
+	return fmt.Sprintf("%d-%d", r.TransactionID, r.Order)
+}
+
+// LedgerEntryChangeBatchInsertBuilder is used to insert ledger entry changes
+// into the history_ledger_entry_changes table
+type LedgerEntryChangeBatchInsertBuilder interface {
+	Add(ctx context.Context, change LedgerEntryChange) error
+	Exec(ctx context.Context) error
+}
+
+type ledgerEntryChangeBatchInsertBuilder struct {
+	builder db.BatchInsertBuilder
+}
+
+// QLedgerEntryChanges defines history_ledger_entry_changes related queries.
+type QLedgerEntryChanges interface {
+	NewLedgerEntryChangeBatchInsertBuilder(maxBatchSize int) LedgerEntryChangeBatchInsertBuilder
+}
+
+// NewLedgerEntryChangeBatchInsertBuilder constructs a new
+// LedgerEntryChangeBatchInsertBuilder instance
+func (q *Q) NewLedgerEntryChangeBatchInsertBuilder(maxBatchSize int) LedgerEntryChangeBatchInsertBuilder {
+	return &ledgerEntryChangeBatchInsertBuilder{
+		builder: db.BatchInsertBuilder{
+			Table:        q.GetTable("history_ledger_entry_changes"),
+			MaxBatchSize: maxBatchSize,
+		},
+	}
+}
+
+// Add adds a new ledger entry change to the batch
+func (i *ledgerEntryChangeBatchInsertBuilder) Add(ctx context.Context, change LedgerEntryChange) error {
+	return i.builder.Row(ctx, map[string]interface{}{
+		"history_transaction_id": change.TransactionID,
+		"\"order\"":              change.Order,
+		"operation_index":        change.OperationIndex,
+		"ledger_key":             change.LedgerKey,
+		"entry_type":             change.EntryType,
+		"change_type":            change.ChangeType,
+		"pre_entry":              change.PreEntry,
+		"post_entry":             change.PostEntry,
+	})
+}
+
+// Exec flushes all pending ledger entry changes to the db
+func (i *ledgerEntryChangeBatchInsertBuilder) Exec(ctx context.Context) error {
+	return i.builder.Exec(ctx)
+}
+
+// LedgerEntryChangesForKey returns a page of the changes of the ledger entry
+// with the given base64 encoded ledger key.
+func (q *Q) LedgerEntryChangesForKey(ctx context.Context, ledgerKey string, page db2.PageQuery) ([]LedgerEntryChangeWithTransaction, error) {
+	txID, order, err := page.CursorInt64Pair(db2.DefaultPairSep)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse cursor")
+	}
+	if order > math.MaxInt32 {
+		order = math.MaxInt32
+	}
+
+	sql := sq.Select("hlec.*", "ht.transaction_hash", "hl.closed_at").
+		From("history_ledger_entry_changes hlec").
+		Join("history_transactions ht ON ht.id = hlec.history_transaction_id").
+		Join("history_ledgers hl ON hl.sequence = ht.ledger_sequence").
+		Where("hlec.ledger_key = ?", ledgerKey)
+
+	switch page.Order {
+	case "asc":
+		sql = sql.
+			Where(`(
+					 hlec.history_transaction_id >= ?
+				AND (
+					 hlec.history_transaction_id > ? OR
+					(hlec.history_transaction_id = ? AND hlec.order > ?)
+				))`, txID, txID, txID, order).
+			OrderBy("hlec.history_transaction_id asc, hlec.order asc")
+	case "desc":
+		sql = sql.
+			Where(`(
+					 hlec.history_transaction_id <= ?
+				AND (
+					 hlec.history_transaction_id < ? OR
+					(hlec.history_transaction_id = ? AND hlec.order < ?)
+				))`, txID, txID, txID, order).
+			OrderBy("hlec.history_transaction_id desc, hlec.order desc")
+	default:
+		return nil, errors.Errorf("invalid order: %s", page.Order)
+	}
+
+	var changes []LedgerEntryChangeWithTransaction
+	err = q.Select(ctx, &changes, sql.Limit(page.Limit))
+	return changes, err
+}
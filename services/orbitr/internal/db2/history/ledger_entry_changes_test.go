@@ -0,0 +1,80 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+
+	"github.com/lantah/go/services/orbitr/internal/db2"
+	"github.com/lantah/go/services/orbitr/internal/test"
+	"github.com/lantah/go/xdr"
+)
+
+func TestLedgerEntryChangesForKey(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetOrbitRDB(t, tt.OrbitRDB)
+	q := &Q{tt.OrbitRSession()}
+	fixture := FeeBumpScenario(tt, q, true)
+
+	created := LedgerEntryChange{
+		TransactionID: fixture.Transaction.ID,
+		Order:         1,
+		LedgerKey:     "key1",
+		EntryType:     xdr.LedgerEntryTypeAccount,
+		ChangeType:    xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+		PostEntry:     null.StringFrom("post1"),
+	}
+	other := LedgerEntryChange{
+		TransactionID:  fixture.Transaction.ID,
+		Order:          2,
+		OperationIndex: null.IntFrom(0),
+		LedgerKey:      "key2",
+		EntryType:      xdr.LedgerEntryTypeTrustline,
+		ChangeType:     xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+		PreEntry:       null.StringFrom("pre2"),
+		PostEntry:      null.StringFrom("post2"),
+	}
+	removed := LedgerEntryChange{
+		TransactionID:  fixture.Transaction.ID,
+		Order:          3,
+		OperationIndex: null.IntFrom(0),
+		LedgerKey:      "key1",
+		EntryType:      xdr.LedgerEntryTypeAccount,
+		ChangeType:     xdr.LedgerEntryChangeTypeLedgerEntryRemoved,
+		PreEntry:       null.StringFrom("post1"),
+	}
+
+	builder := q.NewLedgerEntryChangeBatchInsertBuilder(2)
+	for _, change := range []LedgerEntryChange{created, other, removed} {
+		tt.Assert.NoError(builder.Add(tt.Ctx, change))
+	}
+	tt.Assert.NoError(builder.Exec(tt.Ctx))
+
+	changes, err := q.LedgerEntryChangesForKey(tt.Ctx, "key1", db2.PageQuery{Order: "asc", Limit: 10})
+	tt.Assert.NoError(err)
+	tt.Assert.Len(changes, 2)
+	tt.Assert.Equal(created, changes[0].LedgerEntryChange)
+	tt.Assert.Equal(removed, changes[1].LedgerEntryChange)
+	tt.Assert.Equal(fixture.OuterHash, changes[0].TransactionHash)
+	tt.Assert.Equal(fixture.Ledger.ClosedAt.Unix(), changes[0].LedgerCloseTime.Unix())
+	tt.Assert.Equal(fixture.Ledger.Sequence, changes[0].LedgerSequence())
+
+	changes, err = q.LedgerEntryChangesForKey(tt.Ctx, "key1", db2.PageQuery{
+		Cursor: changes[0].PagingToken(),
+		Order:  "asc",
+		Limit:  10,
+	})
+	tt.Assert.NoError(err)
+	tt.Assert.Len(changes, 1)
+	tt.Assert.Equal(removed, changes[0].LedgerEntryChange)
+
+	changes, err = q.LedgerEntryChangesForKey(tt.Ctx, "key1", db2.PageQuery{Order: "desc", Limit: 1})
+	tt.Assert.NoError(err)
+	tt.Assert.Len(changes, 1)
+	tt.Assert.Equal(removed, changes[0].LedgerEntryChange)
+
+	changes, err = q.LedgerEntryChangesForKey(tt.Ctx, "key3", db2.PageQuery{Order: "asc", Limit: 10})
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(changes)
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"math/rand"
 	"testing"
 	"time"
@@ -54,6 +55,40 @@ func TestLedgerQueries(t *testing.T) {
 	}
 }
 
+func TestIDRangeForCloseTime(t *testing.T) {
+	tt := test.Start(t)
+	tt.Scenario("base")
+	defer tt.Finish()
+	q := &Q{tt.OrbitRSession()}
+
+	ledger2Close := time.Date(2019, 10, 31, 13, 19, 45, 0, time.UTC)
+	ledger3Close := ledger2Close.Add(time.Second)
+
+	start, end, err := q.IDRangeForCloseTime(tt.Ctx, time.Time{}, time.Time{})
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(0), start)
+	tt.Assert.Equal(int64(math.MaxInt64), end)
+
+	start, end, err = q.IDRangeForCloseTime(tt.Ctx, ledger2Close.Add(-time.Second), ledger3Close)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(toid.New(2, 0, 0).ToInt64(), start)
+	tt.Assert.Equal(toid.New(3, 0, 0).ToInt64(), end)
+
+	start, _, err = q.IDRangeForCloseTime(tt.Ctx, ledger2Close, time.Time{})
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(toid.New(3, 0, 0).ToInt64(), start)
+
+	// no ledger closed after the last one
+	start, end, err = q.IDRangeForCloseTime(tt.Ctx, ledger3Close, time.Time{})
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(end, start)
+
+	// ledger 1 closed at the unix epoch
+	_, end, err = q.IDRangeForCloseTime(tt.Ctx, time.Time{}, time.Unix(0, 0))
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(toid.New(1, 0, 0).ToInt64(), end)
+}
+
 func TestInsertLedger(t *testing.T) {
 	tt := test.Start(t)
 	defer tt.Finish()
@@ -262,12 +262,14 @@ type AccountEntry struct {
 type IngestionQ interface {
 	QAccounts
 	QFilter
+	QIngestionLeases
 	QAssetStats
 	QClaimableBalances
 	QHistoryClaimableBalances
 	QData
 	QEffects
 	QLedgers
+	QLedgerEntryChanges
 	QLiquidityPools
 	QHistoryLiquidityPools
 	QOffers
@@ -277,7 +279,10 @@ type IngestionQ interface {
 	// duplicate method CreateAccounts
 	NewTransactionParticipantsBatchInsertBuilder(maxBatchSize int) TransactionParticipantsBatchInsertBuilder
 	NewOperationParticipantBatchInsertBuilder(maxBatchSize int) OperationParticipantBatchInsertBuilder
+	NewTransactionMuxedParticipantsBatchInsertBuilder(maxBatchSize int) TransactionMuxedParticipantsBatchInsertBuilder
+	NewOperationMuxedParticipantBatchInsertBuilder(maxBatchSize int) OperationMuxedParticipantBatchInsertBuilder
 	QSigners
+	QStateVerification
 	//QTrades
 	NewTradeBatchInsertBuilder(maxBatchSize int) TradeBatchInsertBuilder
 	RebuildTradeAggregationTimes(ctx context.Context, from, to strtime.Millis, roundingSlippageFilter int) error
@@ -305,6 +310,7 @@ type IngestionQ interface {
 	DeleteRangeAll(ctx context.Context, start, end int64) error
 	DeleteTransactionsFilteredTmpOlderThan(ctx context.Context, howOldInSeconds uint64) (int64, error)
 	TryStateVerificationLock(ctx context.Context) (bool, error)
+	EnsureHistoryPartitions(ctx context.Context, ledger uint32) error
 }
 
 // QAccounts defines account related queries.
@@ -1100,16 +1106,19 @@ func constructReapLookupTablesQuery(table string, historyTables []tableObjectFie
 func (q *Q) DeleteRangeAll(ctx context.Context, start, end int64) error {
 	for table, column := range map[string]string{
 		"history_effects":                        "history_operation_id",
+		"history_ledger_entry_changes":           "history_transaction_id",
 		"history_ledgers":                        "id",
 		"history_operation_claimable_balances":   "history_operation_id",
 		"history_operation_participants":         "history_operation_id",
 		"history_operation_liquidity_pools":      "history_operation_id",
+		"history_operation_muxed_participants":   "history_operation_id",
 		"history_operations":                     "id",
 		"history_trades":                         "history_operation_id",
 		"history_trades_60000":                   "open_ledger_toid",
 		"history_transaction_claimable_balances": "history_transaction_id",
 		"history_transaction_participants":       "history_transaction_id",
 		"history_transaction_liquidity_pools":    "history_transaction_id",
+		"history_transaction_muxed_participants": "history_transaction_id",
 		"history_transactions":                   "id",
 	} {
 		err := q.DeleteRange(ctx, start, end, table, column)
@@ -0,0 +1,42 @@
+package history
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTransactionMuxedParticipantsBatchInsertBuilder is a mock implementation of the
+// TransactionMuxedParticipantsBatchInsertBuilder interface
+type MockTransactionMuxedParticipantsBatchInsertBuilder struct {
+	mock.Mock
+}
+
+// Add mock
+func (m *MockTransactionMuxedParticipantsBatchInsertBuilder) Add(ctx context.Context, transactionID int64, muxedAccount string) error {
+	a := m.Called(ctx, transactionID, muxedAccount)
+	return a.Error(0)
+}
+
+// Exec mock
+func (m *MockTransactionMuxedParticipantsBatchInsertBuilder) Exec(ctx context.Context) error {
+	a := m.Called(ctx)
+	return a.Error(0)
+}
+
+// MockOperationMuxedParticipantBatchInsertBuilder is a mock implementation of the
+// OperationMuxedParticipantBatchInsertBuilder interface
+type MockOperationMuxedParticipantBatchInsertBuilder struct {
+	mock.Mock
+}
+
+// Add mock
+func (m *MockOperationMuxedParticipantBatchInsertBuilder) Add(ctx context.Context, operationID int64, muxedAccount string) error {
+	a := m.Called(ctx, operationID, muxedAccount)
+	return a.Error(0)
+}
+
+// Exec mock
+func (m *MockOperationMuxedParticipantBatchInsertBuilder) Exec(ctx context.Context) error {
+	a := m.Called(ctx)
+	return a.Error(0)
+}
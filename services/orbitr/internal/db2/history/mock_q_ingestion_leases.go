@@ -0,0 +1,38 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockQIngestionLeases is a mock implementation of the QIngestionLeases interface
+type MockQIngestionLeases struct {
+	mock.Mock
+}
+
+func (m *MockQIngestionLeases) AcquireIngestionLease(ctx context.Context, name, leaderID string, ttl time.Duration) (IngestionLease, bool, error) {
+	a := m.Called(ctx, name, leaderID, ttl)
+	return a.Get(0).(IngestionLease), a.Bool(1), a.Error(2)
+}
+
+func (m *MockQIngestionLeases) ReleaseIngestionLease(ctx context.Context, name, leaderID string, fencingToken int64) error {
+	a := m.Called(ctx, name, leaderID, fencingToken)
+	return a.Error(0)
+}
+
+func (m *MockQIngestionLeases) GetIngestionLease(ctx context.Context, name string) (IngestionLease, error) {
+	a := m.Called(ctx, name)
+	return a.Get(0).(IngestionLease), a.Error(1)
+}
+
+func (m *MockQIngestionLeases) RequestIngestionLeaseHandoff(ctx context.Context, name, to string) (IngestionLease, error) {
+	a := m.Called(ctx, name, to)
+	return a.Get(0).(IngestionLease), a.Error(1)
+}
+
+func (m *MockQIngestionLeases) CheckIngestionLeaseFencingToken(ctx context.Context, name string, fencingToken int64) (bool, error) {
+	a := m.Called(ctx, name, fencingToken)
+	return a.Bool(0), a.Error(1)
+}
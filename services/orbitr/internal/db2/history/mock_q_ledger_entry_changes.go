@@ -0,0 +1,34 @@
+package history
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockQLedgerEntryChanges is a mock implementation of the QLedgerEntryChanges interface
+type MockQLedgerEntryChanges struct {
+	mock.Mock
+}
+
+func (m *MockQLedgerEntryChanges) NewLedgerEntryChangeBatchInsertBuilder(maxBatchSize int) LedgerEntryChangeBatchInsertBuilder {
+	a := m.Called(maxBatchSize)
+	return a.Get(0).(LedgerEntryChangeBatchInsertBuilder)
+}
+
+// MockLedgerEntryChangeBatchInsertBuilder LedgerEntryChangeBatchInsertBuilder mock
+type MockLedgerEntryChangeBatchInsertBuilder struct {
+	mock.Mock
+}
+
+// Add mock
+func (m *MockLedgerEntryChangeBatchInsertBuilder) Add(ctx context.Context, change LedgerEntryChange) error {
+	a := m.Called(ctx, change)
+	return a.Error(0)
+}
+
+// Exec mock
+func (m *MockLedgerEntryChangeBatchInsertBuilder) Exec(ctx context.Context) error {
+	a := m.Called(ctx)
+	return a.Error(0)
+}
@@ -41,3 +41,15 @@ func (m *MockQParticipants) NewOperationParticipantBatchInsertBuilder(maxBatchSi
 	a := m.Called(maxBatchSize)
 	return a.Get(0).(OperationParticipantBatchInsertBuilder)
 }
+
+// NewTransactionMuxedParticipantsBatchInsertBuilder mock
+func (m *MockQParticipants) NewTransactionMuxedParticipantsBatchInsertBuilder(maxBatchSize int) TransactionMuxedParticipantsBatchInsertBuilder {
+	a := m.Called(maxBatchSize)
+	return a.Get(0).(TransactionMuxedParticipantsBatchInsertBuilder)
+}
+
+// NewOperationMuxedParticipantBatchInsertBuilder mock
+func (m *MockQParticipants) NewOperationMuxedParticipantBatchInsertBuilder(maxBatchSize int) OperationMuxedParticipantBatchInsertBuilder {
+	a := m.Called(maxBatchSize)
+	return a.Get(0).(OperationMuxedParticipantBatchInsertBuilder)
+}
@@ -0,0 +1,29 @@
+package history
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lantah/go/xdr"
+)
+
+// MockQStateVerification is a mock implementation of the QStateVerification interface
+type MockQStateVerification struct {
+	mock.Mock
+}
+
+func (m *MockQStateVerification) CountLedgerEntriesInShard(ctx context.Context, entryType xdr.LedgerEntryType, shard, shardCount uint32) (int, error) {
+	a := m.Called(ctx, entryType, shard, shardCount)
+	return a.Get(0).(int), a.Error(1)
+}
+
+func (m *MockQStateVerification) GetStateVerificationCoverage(ctx context.Context, shardCount uint32) ([]StateVerificationCoverage, error) {
+	a := m.Called(ctx, shardCount)
+	return a.Get(0).([]StateVerificationCoverage), a.Error(1)
+}
+
+func (m *MockQStateVerification) RecordStateVerification(ctx context.Context, coverage StateVerificationCoverage) error {
+	a := m.Called(ctx, coverage)
+	return a.Error(0)
+}
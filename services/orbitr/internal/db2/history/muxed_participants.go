@@ -0,0 +1,78 @@
+package history
+
+import (
+	"context"
+
+	"github.com/lantah/go/support/db"
+)
+
+// TransactionMuxedParticipantsBatchInsertBuilder is used to insert muxed
+// transaction participants into the history_transaction_muxed_participants
+// table
+type TransactionMuxedParticipantsBatchInsertBuilder interface {
+	Add(ctx context.Context, transactionID int64, muxedAccount string) error
+	Exec(ctx context.Context) error
+}
+
+type transactionMuxedParticipantsBatchInsertBuilder struct {
+	builder db.BatchInsertBuilder
+}
+
+// NewTransactionMuxedParticipantsBatchInsertBuilder constructs a new
+// TransactionMuxedParticipantsBatchInsertBuilder instance
+func (q *Q) NewTransactionMuxedParticipantsBatchInsertBuilder(maxBatchSize int) TransactionMuxedParticipantsBatchInsertBuilder {
+	return &transactionMuxedParticipantsBatchInsertBuilder{
+		builder: db.BatchInsertBuilder{
+			Table:        q.GetTable("history_transaction_muxed_participants"),
+			MaxBatchSize: maxBatchSize,
+		},
+	}
+}
+
+// Add adds a new muxed transaction participant to the batch
+func (i *transactionMuxedParticipantsBatchInsertBuilder) Add(ctx context.Context, transactionID int64, muxedAccount string) error {
+	return i.builder.Row(ctx, map[string]interface{}{
+		"history_transaction_id": transactionID,
+		"muxed_account":          muxedAccount,
+	})
+}
+
+// Exec flushes all pending muxed transaction participants to the db
+func (i *transactionMuxedParticipantsBatchInsertBuilder) Exec(ctx context.Context) error {
+	return i.builder.Exec(ctx)
+}
+
+// OperationMuxedParticipantBatchInsertBuilder is used to insert muxed
+// operation participants into the history_operation_muxed_participants table
+type OperationMuxedParticipantBatchInsertBuilder interface {
+	Add(ctx context.Context, operationID int64, muxedAccount string) error
+	Exec(ctx context.Context) error
+}
+
+type operationMuxedParticipantBatchInsertBuilder struct {
+	builder db.BatchInsertBuilder
+}
+
+// NewOperationMuxedParticipantBatchInsertBuilder constructs a new
+// OperationMuxedParticipantBatchInsertBuilder instance
+func (q *Q) NewOperationMuxedParticipantBatchInsertBuilder(maxBatchSize int) OperationMuxedParticipantBatchInsertBuilder {
+	return &operationMuxedParticipantBatchInsertBuilder{
+		builder: db.BatchInsertBuilder{
+			Table:        q.GetTable("history_operation_muxed_participants"),
+			MaxBatchSize: maxBatchSize,
+		},
+	}
+}
+
+// Add adds a new muxed operation participant to the batch
+func (i *operationMuxedParticipantBatchInsertBuilder) Add(ctx context.Context, operationID int64, muxedAccount string) error {
+	return i.builder.Row(ctx, map[string]interface{}{
+		"history_operation_id": operationID,
+		"muxed_account":        muxedAccount,
+	})
+}
+
+// Exec flushes all pending muxed operation participants to the db
+func (i *operationMuxedParticipantBatchInsertBuilder) Exec(ctx context.Context) error {
+	return i.builder.Exec(ctx)
+}
@@ -0,0 +1,66 @@
+package history
+
+import (
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lantah/go/services/orbitr/internal/test"
+)
+
+type muxedParticipant struct {
+	ID           int64  `db:"id"`
+	MuxedAccount string `db:"muxed_account"`
+}
+
+func getMuxedParticipants(tt *test.T, q *Q, table, idColumn string) []muxedParticipant {
+	var participants []muxedParticipant
+	sql := sq.Select(idColumn+" AS id", "muxed_account").
+		From(table).
+		OrderBy("(" + idColumn + ", muxed_account) asc")
+
+	err := q.Select(tt.Ctx, &participants, sql)
+	if err != nil {
+		tt.T.Fatal(err)
+	}
+
+	return participants
+}
+
+func TestMuxedParticipantsBatch(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetOrbitRDB(t, tt.OrbitRDB)
+	q := &Q{tt.OrbitRSession()}
+
+	first := "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK"
+	second := "MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJUAAAAAAAAAAAACJUQ"
+
+	transactionBatch := q.NewTransactionMuxedParticipantsBatchInsertBuilder(0)
+	tt.Assert.NoError(transactionBatch.Add(tt.Ctx, 1, first))
+	tt.Assert.NoError(transactionBatch.Add(tt.Ctx, 1, second))
+	tt.Assert.NoError(transactionBatch.Add(tt.Ctx, 2, first))
+	tt.Assert.NoError(transactionBatch.Exec(tt.Ctx))
+
+	operationBatch := q.NewOperationMuxedParticipantBatchInsertBuilder(0)
+	tt.Assert.NoError(operationBatch.Add(tt.Ctx, 2, second))
+	tt.Assert.NoError(operationBatch.Exec(tt.Ctx))
+
+	tt.Assert.Equal(
+		[]muxedParticipant{
+			{ID: 1, MuxedAccount: first},
+			{ID: 1, MuxedAccount: second},
+			{ID: 2, MuxedAccount: first},
+		},
+		getMuxedParticipants(tt, q, "history_transaction_muxed_participants", "history_transaction_id"),
+	)
+	tt.Assert.Equal(
+		[]muxedParticipant{
+			{ID: 2, MuxedAccount: second},
+		},
+		getMuxedParticipants(tt, q, "history_operation_muxed_participants", "history_operation_id"),
+	)
+
+	var transactions []Transaction
+	tt.Assert.NoError(q.Transactions().ForMuxedAccount(tt.Ctx, second).Select(tt.Ctx, &transactions))
+	tt.Assert.Empty(transactions)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"strings"
 	"text/template"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/lantah/go/services/orbitr/internal/db2"
@@ -153,6 +154,20 @@ func (q *OperationsQ) ForAccount(ctx context.Context, aid string) *OperationsQ {
 	return q
 }
 
+// ForMuxedAccount filters the operations collection to a specific muxed
+// account, specified by its M-address.
+func (q *OperationsQ) ForMuxedAccount(ctx context.Context, address string) *OperationsQ {
+	q.sql = q.sql.Join(
+		"history_operation_muxed_participants homp ON "+
+			"homp.history_operation_id = hop.id",
+	).Where("homp.muxed_account = ?", address)
+
+	// in order to use history_operation_muxed_participants index
+	q.opIdCol = "homp.history_operation_id"
+
+	return q
+}
+
 // ForClaimableBalance filters the query to only operations pertaining to a
 // claimable balance, specified by the claimable balance's hex-encoded id.
 func (q *OperationsQ) ForClaimableBalance(ctx context.Context, cbID string) *OperationsQ {
@@ -252,6 +267,53 @@ func (q *OperationsQ) OnlyPayments() *OperationsQ {
 	return q
 }
 
+// ForTypes filters the query being built to only include operations of the
+// given types.
+func (q *OperationsQ) ForTypes(types ...xdr.OperationType) *OperationsQ {
+	if q.Err != nil {
+		return q
+	}
+
+	q.sql = q.sql.Where(sq.Eq{"hop.type": types})
+	return q
+}
+
+// ForAsset filters the query being built to only include operations whose
+// details reference the given asset.
+func (q *OperationsQ) ForAsset(asset xdr.Asset) *OperationsQ {
+	if q.Err != nil {
+		return q
+	}
+
+	var predicate sq.Or
+	predicate, q.Err = detailsAssetFilter("hop.details", asset)
+	if q.Err != nil {
+		return q
+	}
+
+	q.sql = q.sql.Where(predicate)
+	return q
+}
+
+// ForCloseTimeRange filters the query being built to only include operations
+// in ledgers closed strictly between `after` and `before`. A zero time leaves
+// the corresponding bound open.
+func (q *OperationsQ) ForCloseTimeRange(ctx context.Context, after, before time.Time) *OperationsQ {
+	if q.Err != nil {
+		return q
+	}
+
+	var start, end int64
+	start, end, q.Err = q.parent.IDRangeForCloseTime(ctx, after, before)
+	if q.Err != nil {
+		return q
+	}
+
+	// Filter on opIdCol so that the participant indexes can still be used.
+	q.sql = q.sql.Where(q.opIdCol+" >= ? AND "+q.opIdCol+" < ?", start, end)
+	return q
+}
+
 // IncludeFailed changes the query to include failed transactions.
 func (q *OperationsQ) IncludeFailed() *OperationsQ {
 	q.includeFailed = true
@@ -11,6 +11,8 @@ type QParticipants interface {
 	QCreateAccountsHistory
 	NewTransactionParticipantsBatchInsertBuilder(maxBatchSize int) TransactionParticipantsBatchInsertBuilder
 	NewOperationParticipantBatchInsertBuilder(maxBatchSize int) OperationParticipantBatchInsertBuilder
+	NewTransactionMuxedParticipantsBatchInsertBuilder(maxBatchSize int) TransactionMuxedParticipantsBatchInsertBuilder
+	NewOperationMuxedParticipantBatchInsertBuilder(maxBatchSize int) OperationMuxedParticipantBatchInsertBuilder
 }
 
 // TransactionParticipantsBatchInsertBuilder is used to insert transaction participants into the
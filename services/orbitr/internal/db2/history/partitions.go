@@ -0,0 +1,218 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/toid"
+)
+
+// HistoryPartitionLedgers is the number of ledgers covered by each partition
+// of the partitioned history tables.
+const HistoryPartitionLedgers = 100_000
+
+// partitionCreationLock is the key of the advisory lock serializing the
+// creation of partitions by concurrent ingestion workers.
+const partitionCreationLock = 0x6f72626974720001
+
+// partitionedHistoryTables maps the history tables partitioned by ledger range
+// to the toid column they are partitioned on.
+var partitionedHistoryTables = map[string]string{
+	"history_effects":                        "history_operation_id",
+	"history_ledger_entry_changes":           "history_transaction_id",
+	"history_operation_claimable_balances":   "history_operation_id",
+	"history_operation_liquidity_pools":      "history_operation_id",
+	"history_operation_muxed_participants":   "history_operation_id",
+	"history_operation_participants":         "history_operation_id",
+	"history_operations":                     "id",
+	"history_trades":                         "history_operation_id",
+	"history_transaction_claimable_balances": "history_transaction_id",
+	"history_transaction_liquidity_pools":    "history_transaction_id",
+	"history_transaction_muxed_participants": "history_transaction_id",
+	"history_transaction_participants":       "history_transaction_id",
+	"history_transactions":                   "id",
+}
+
+// HistoryPartition is a partition of a partitioned history table. Start and
+// End are the toid bounds of the partition (inclusive and exclusive,
+// respectively). The default partition has both bounds set to zero.
+type HistoryPartition struct {
+	Table   string `db:"table_name"`
+	Name    string `db:"name"`
+	Bound   string `db:"bound"`
+	Start   int64  `db:"-"`
+	End     int64  `db:"-"`
+	Default bool   `db:"-"`
+}
+
+var partitionBoundRegexp = regexp.MustCompile(`^FOR VALUES FROM \((.+)\) TO \((.+)\)$`)
+
+func parsePartitionBoundValue(value string) (int64, error) {
+	switch value {
+	case "MINVALUE":
+		return math.MinInt64, nil
+	case "MAXVALUE":
+		return math.MaxInt64, nil
+	}
+	return strconv.ParseInt(strings.Trim(value, "'"), 10, 64)
+}
+
+func (p *HistoryPartition) parseBound() error {
+	if p.Bound == "DEFAULT" {
+		p.Default = true
+		return nil
+	}
+
+	matches := partitionBoundRegexp.FindStringSubmatch(p.Bound)
+	if matches == nil {
+		return errors.Errorf("unexpected bound of partition %s: %s", p.Name, p.Bound)
+	}
+
+	var err error
+	if p.Start, err = parsePartitionBoundValue(matches[1]); err != nil {
+		return errors.Wrapf(err, "could not parse lower bound of partition %s", p.Name)
+	}
+	if p.End, err = parsePartitionBoundValue(matches[2]); err != nil {
+		return errors.Wrapf(err, "could not parse upper bound of partition %s", p.Name)
+	}
+	return nil
+}
+
+// HistoryPartitionStart returns the first ledger of the partition containing
+// the given ledger.
+func HistoryPartitionStart(ledger uint32) uint32 {
+	return ledger - ledger%HistoryPartitionLedgers
+}
+
+func partitionName(table string, startLedger uint32) string {
+	return fmt.Sprintf("%s_p%d", table, startLedger)
+}
+
+func ledgerToid(ledger uint32) int64 {
+	return int64(ledger) << toid.LedgerShift
+}
+
+// HistoryPartitions returns the partitions of all the partitioned history
+// tables, sorted by table and lower bound.
+func (q *Q) HistoryPartitions(ctx context.Context) ([]HistoryPartition, error) {
+	tables := make([]string, 0, len(partitionedHistoryTables))
+	for table := range partitionedHistoryTables {
+		tables = append(tables, table)
+	}
+
+	var partitions []HistoryPartition
+	sql := sq.Select(
+		"parent.relname AS table_name",
+		"child.relname AS name",
+		"pg_get_expr(child.relpartbound, child.oid) AS bound",
+	).
+		From("pg_inherits").
+		Join("pg_class parent ON pg_inherits.inhparent = parent.oid").
+		Join("pg_class child ON pg_inherits.inhrelid = child.oid").
+		Where("parent.relname = ANY(?)", pq.Array(tables))
+	if err := q.Select(ctx, &partitions, sql); err != nil {
+		return nil, errors.Wrap(err, "could not select history partitions")
+	}
+
+	for i := range partitions {
+		if err := partitions[i].parseBound(); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		if partitions[i].Table != partitions[j].Table {
+			return partitions[i].Table < partitions[j].Table
+		}
+		return partitions[i].Start < partitions[j].Start
+	})
+	return partitions, nil
+}
+
+// EnsureHistoryPartitions creates, for every partitioned history table, the
+// partitions covering the given ledger and the following partition range (so
+// that the next partition exists before ingestion reaches it). Ranges already
+// covered by a partition, including the legacy partition created by the
+// migration, are skipped.
+func (q *Q) EnsureHistoryPartitions(ctx context.Context, ledger uint32) error {
+	partitions, err := q.HistoryPartitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	covered := func(table string, start, end int64) bool {
+		for _, partition := range partitions {
+			if partition.Table == table && !partition.Default &&
+				partition.Start < end && start < partition.End {
+				return true
+			}
+		}
+		return false
+	}
+
+	locked := false
+	first := HistoryPartitionStart(ledger)
+	for _, startLedger := range []uint32{first, first + HistoryPartitionLedgers} {
+		start, end := ledgerToid(startLedger), ledgerToid(startLedger+HistoryPartitionLedgers)
+		for table := range partitionedHistoryTables {
+			if covered(table, start, end) {
+				continue
+			}
+
+			if !locked {
+				if _, err = q.ExecRaw(ctx, "SELECT pg_advisory_xact_lock(?)", partitionCreationLock); err != nil {
+					return errors.Wrap(err, "could not acquire partition creation lock")
+				}
+				locked = true
+			}
+
+			_, err = q.ExecRaw(ctx, fmt.Sprintf(
+				"CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM (%d) TO (%d)",
+				partitionName(table, startLedger), table, start, end,
+			))
+			if err != nil {
+				return errors.Wrapf(err, "could not create partition of %s for ledger %d", table, startLedger)
+			}
+		}
+	}
+
+	return nil
+}
+
+// DropHistoryPartitionsBefore detaches and drops the partitions of the
+// partitioned history tables which only contain ledgers older than the given
+// ledger. It returns the names of the dropped partitions.
+func (q *Q) DropHistoryPartitionsBefore(ctx context.Context, ledger uint32) ([]string, error) {
+	partitions, err := q.HistoryPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var dropped []string
+	bound := ledgerToid(ledger)
+	for _, partition := range partitions {
+		if partition.Default || partition.End > bound {
+			continue
+		}
+
+		_, err = q.ExecRaw(ctx, fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", partition.Table, partition.Name))
+		if err != nil {
+			return dropped, errors.Wrapf(err, "could not detach partition %s", partition.Name)
+		}
+		_, err = q.ExecRaw(ctx, fmt.Sprintf("DROP TABLE %s", partition.Name))
+		if err != nil {
+			return dropped, errors.Wrapf(err, "could not drop partition %s", partition.Name)
+		}
+		dropped = append(dropped, partition.Name)
+	}
+
+	return dropped, nil
+}
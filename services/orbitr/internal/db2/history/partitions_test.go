@@ -0,0 +1,116 @@
+package history
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lantah/go/services/orbitr/internal/test"
+	"github.com/lantah/go/toid"
+)
+
+func TestHistoryPartitionParseBound(t *testing.T) {
+	for _, testCase := range []struct {
+		bound   string
+		start   int64
+		end     int64
+		isDflt  bool
+		invalid bool
+	}{
+		{bound: "DEFAULT", isDflt: true},
+		{bound: "FOR VALUES FROM (MINVALUE) TO ('429496729600000')", start: math.MinInt64, end: 429496729600000},
+		{bound: "FOR VALUES FROM (429496729600000) TO (858993459200000)", start: 429496729600000, end: 858993459200000},
+		{bound: "FOR VALUES FROM ('0') TO (MAXVALUE)", start: 0, end: math.MaxInt64},
+		{bound: "FOR VALUES IN (1)", invalid: true},
+	} {
+		partition := HistoryPartition{Name: "history_effects_p0", Bound: testCase.bound}
+		err := partition.parseBound()
+		if testCase.invalid {
+			assert.Error(t, err, testCase.bound)
+			continue
+		}
+		assert.NoError(t, err, testCase.bound)
+		assert.Equal(t, testCase.isDflt, partition.Default, testCase.bound)
+		assert.Equal(t, testCase.start, partition.Start, testCase.bound)
+		assert.Equal(t, testCase.end, partition.End, testCase.bound)
+	}
+}
+
+func TestHistoryPartitionStart(t *testing.T) {
+	assert.Equal(t, uint32(0), HistoryPartitionStart(1))
+	assert.Equal(t, uint32(100_000), HistoryPartitionStart(100_000))
+	assert.Equal(t, uint32(3_600_000), HistoryPartitionStart(3_612_345))
+}
+
+func TestEnsureAndDropHistoryPartitions(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetOrbitRDB(t, tt.OrbitRDB)
+	q := &Q{tt.OrbitRSession()}
+
+	partitions, err := q.HistoryPartitions(tt.Ctx)
+	tt.Assert.NoError(err)
+	// every table starts with its legacy and default partitions
+	tt.Assert.Len(partitions, 2*len(partitionedHistoryTables))
+
+	tt.Assert.NoError(q.Begin(tt.Ctx))
+	tt.Assert.NoError(q.EnsureHistoryPartitions(tt.Ctx, 250_000))
+	tt.Assert.NoError(q.Commit())
+
+	partitions, err = q.HistoryPartitions(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(partitions, 4*len(partitionedHistoryTables))
+
+	var operations []HistoryPartition
+	for _, partition := range partitions {
+		if partition.Table == "history_operations" {
+			operations = append(operations, partition)
+		}
+	}
+	tt.Assert.Equal([]HistoryPartition{
+		{
+			Table: "history_operations", Name: "history_operations_default",
+			Bound: "DEFAULT", Default: true,
+		},
+		{
+			Table: "history_operations", Name: "history_operations_legacy",
+			Bound: operations[1].Bound, Start: math.MinInt64, End: toid.New(100_000, 0, 0).ToInt64(),
+		},
+		{
+			Table: "history_operations", Name: "history_operations_p200000",
+			Bound: operations[2].Bound, Start: toid.New(200_000, 0, 0).ToInt64(), End: toid.New(300_000, 0, 0).ToInt64(),
+		},
+		{
+			Table: "history_operations", Name: "history_operations_p300000",
+			Bound: operations[3].Bound, Start: toid.New(300_000, 0, 0).ToInt64(), End: toid.New(400_000, 0, 0).ToInt64(),
+		},
+	}, operations)
+
+	// ensuring partitions is idempotent
+	tt.Assert.NoError(q.EnsureHistoryPartitions(tt.Ctx, 299_999))
+	partitions, err = q.HistoryPartitions(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(partitions, 4*len(partitionedHistoryTables))
+
+	// rows are routed to the new partitions
+	_, err = q.ExecRaw(tt.Ctx, "INSERT INTO history_operations (id, transaction_id, application_order, type, source_account) VALUES (?, ?, 1, 0, 'G')",
+		toid.New(250_000, 1, 1).ToInt64(), toid.New(250_000, 1, 0).ToInt64())
+	tt.Assert.NoError(err)
+	var count int
+	tt.Assert.NoError(q.GetRaw(tt.Ctx, &count, "SELECT COUNT(*) FROM history_operations_p200000"))
+	tt.Assert.Equal(1, count)
+
+	dropped, err := q.DropHistoryPartitionsBefore(tt.Ctx, 299_999)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(dropped, len(partitionedHistoryTables))
+	tt.Assert.Contains(dropped, "history_operations_legacy")
+
+	dropped, err = q.DropHistoryPartitionsBefore(tt.Ctx, 300_000)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(dropped, len(partitionedHistoryTables))
+	tt.Assert.Contains(dropped, "history_operations_p200000")
+
+	tt.Assert.NoError(q.GetRaw(tt.Ctx, &count, "SELECT COUNT(*) FROM history_operations"))
+	tt.Assert.Equal(0, count)
+}
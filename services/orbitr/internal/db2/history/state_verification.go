@@ -0,0 +1,113 @@
+package history
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/xdr"
+)
+
+// StateVerificationCoverage is a row of data from the
+// `state_verification_coverage` table. It holds the outcome of the latest
+// verification of a shard of the entries of a given type.
+type StateVerificationCoverage struct {
+	EntryType      string      `db:"entry_type"`
+	Shard          uint32      `db:"shard"`
+	ShardCount     uint32      `db:"shard_count"`
+	LedgerSequence uint32      `db:"ledger_sequence"`
+	VerifiedAt     time.Time   `db:"verified_at"`
+	Success        bool        `db:"success"`
+	Error          null.String `db:"error"`
+}
+
+// QStateVerification defines the queries used by incremental state
+// verification.
+type QStateVerification interface {
+	CountLedgerEntriesInShard(ctx context.Context, entryType xdr.LedgerEntryType, shard, shardCount uint32) (int, error)
+	GetStateVerificationCoverage(ctx context.Context, shardCount uint32) ([]StateVerificationCoverage, error)
+	RecordStateVerification(ctx context.Context, coverage StateVerificationCoverage) error
+}
+
+// stateShardKeys are the state tables and the column, or expression, which
+// the shard of each row is computed from. It must match the key passed to
+// LedgerEntryShard for the entries in the history archives.
+var stateShardKeys = map[xdr.LedgerEntryType]struct {
+	table   string
+	key     string
+	deleted bool
+}{
+	xdr.LedgerEntryTypeAccount:          {table: "accounts", key: "account_id"},
+	xdr.LedgerEntryTypeData:             {table: "accounts_data", key: "ledger_key"},
+	xdr.LedgerEntryTypeOffer:            {table: "offers", key: "offer_id::text", deleted: true},
+	xdr.LedgerEntryTypeTrustline:        {table: "trust_lines", key: "ledger_key"},
+	xdr.LedgerEntryTypeClaimableBalance: {table: "claimable_balances", key: "id"},
+	xdr.LedgerEntryTypeLiquidityPool:    {table: "liquidity_pools", key: "id", deleted: true},
+}
+
+// LedgerEntryShard returns the shard, out of shardCount, of the entry with the
+// given key. It is the first 4 bytes of the md5 hash of the key modulo
+// shardCount so that it can be computed by postgres as well.
+func LedgerEntryShard(key string, shardCount uint32) uint32 {
+	hash := md5.Sum([]byte(key))
+	return binary.BigEndian.Uint32(hash[:4]) % shardCount
+}
+
+// CountLedgerEntriesInShard returns the number of entries of the given type
+// stored in the database whose key belongs to the given shard.
+func (q *Q) CountLedgerEntriesInShard(ctx context.Context, entryType xdr.LedgerEntryType, shard, shardCount uint32) (int, error) {
+	shardKey, ok := stateShardKeys[entryType]
+	if !ok {
+		return 0, errors.Errorf("ledger entries of type %s are not stored in the database", entryType)
+	}
+
+	sql := sq.Select("count(*)").From(shardKey.table).Where(
+		fmt.Sprintf("('x' || substr(md5(%s), 1, 8))::bit(32)::bigint %% ? = ?", shardKey.key),
+		shardCount, shard,
+	)
+	if shardKey.deleted {
+		sql = sql.Where("deleted = ?", false)
+	}
+
+	var count int
+	if err := q.Get(ctx, &count, sql); err != nil {
+		return 0, errors.Wrap(err, "could not run select query")
+	}
+
+	return count, nil
+}
+
+// GetStateVerificationCoverage returns the latest verification of every
+// shard verified when the entries were split in shardCount shards.
+func (q *Q) GetStateVerificationCoverage(ctx context.Context, shardCount uint32) ([]StateVerificationCoverage, error) {
+	var coverage []StateVerificationCoverage
+	sql := sq.Select("*").
+		From("state_verification_coverage").
+		Where("shard_count = ?", shardCount).
+		OrderBy("entry_type", "shard")
+	err := q.Select(ctx, &coverage, sql)
+	return coverage, err
+}
+
+// RecordStateVerification stores the outcome of the verification of a shard,
+// replacing the previous one.
+func (q *Q) RecordStateVerification(ctx context.Context, coverage StateVerificationCoverage) error {
+	sql := sq.Insert("state_verification_coverage").
+		Columns("entry_type", "shard", "shard_count", "ledger_sequence", "verified_at", "success", "error").
+		Values(
+			coverage.EntryType, coverage.Shard, coverage.ShardCount, coverage.LedgerSequence,
+			coverage.VerifiedAt, coverage.Success, coverage.Error,
+		).
+		Suffix("ON CONFLICT (entry_type, shard, shard_count) DO UPDATE SET " +
+			"ledger_sequence=EXCLUDED.ledger_sequence, verified_at=EXCLUDED.verified_at, " +
+			"success=EXCLUDED.success, error=EXCLUDED.error")
+
+	_, err := q.Exec(ctx, sql)
+	return err
+}
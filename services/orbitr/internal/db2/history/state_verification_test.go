@@ -0,0 +1,84 @@
+package history
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/lantah/go/services/orbitr/internal/test"
+	"github.com/lantah/go/xdr"
+)
+
+func TestCountLedgerEntriesInShard(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetOrbitRDB(t, tt.OrbitRDB)
+	q := &Q{tt.OrbitRSession()}
+
+	shardCount := uint32(4)
+	expected := map[uint32]int{}
+	var offers []Offer
+	for id := int64(1); id <= 20; id++ {
+		offer := eurOffer
+		offer.OfferID = id
+		offer.Deleted = id == 20
+		offers = append(offers, offer)
+		if !offer.Deleted {
+			expected[LedgerEntryShard(strconv.FormatInt(id, 10), shardCount)]++
+		}
+	}
+	tt.Assert.NoError(q.UpsertOffers(tt.Ctx, offers))
+
+	total := 0
+	for shard := uint32(0); shard < shardCount; shard++ {
+		count, err := q.CountLedgerEntriesInShard(tt.Ctx, xdr.LedgerEntryTypeOffer, shard, shardCount)
+		tt.Assert.NoError(err)
+		tt.Assert.Equal(expected[shard], count)
+		total += count
+	}
+	tt.Assert.Equal(19, total)
+
+	_, err := q.CountLedgerEntriesInShard(tt.Ctx, xdr.LedgerEntryTypeContractCode, 0, shardCount)
+	tt.Assert.EqualError(err, "ledger entries of type LedgerEntryTypeContractCode are not stored in the database")
+}
+
+func TestStateVerificationCoverage(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetOrbitRDB(t, tt.OrbitRDB)
+	q := &Q{tt.OrbitRSession()}
+
+	verifiedAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	accounts := StateVerificationCoverage{
+		EntryType:      "accounts",
+		Shard:          1,
+		ShardCount:     2,
+		LedgerSequence: 63,
+		VerifiedAt:     verifiedAt,
+		Success:        true,
+	}
+	tt.Assert.NoError(q.RecordStateVerification(tt.Ctx, accounts))
+	tt.Assert.NoError(q.RecordStateVerification(tt.Ctx, StateVerificationCoverage{
+		EntryType:      "accounts",
+		Shard:          0,
+		ShardCount:     1,
+		LedgerSequence: 63,
+		VerifiedAt:     verifiedAt,
+		Success:        true,
+	}))
+
+	coverage, err := q.GetStateVerificationCoverage(tt.Ctx, 2)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]StateVerificationCoverage{accounts}, coverage)
+
+	accounts.LedgerSequence = 127
+	accounts.Success = false
+	accounts.Error = null.StringFrom("state verification of accounts shard 1/2 failed")
+	tt.Assert.NoError(q.RecordStateVerification(tt.Ctx, accounts))
+
+	coverage, err = q.GetStateVerificationCoverage(tt.Ctx, 2)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal([]StateVerificationCoverage{accounts}, coverage)
+}
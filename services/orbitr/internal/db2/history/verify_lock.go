@@ -36,3 +36,33 @@ func (q *Q) TryStateVerificationLock(ctx context.Context) (bool, error) {
 	}
 	return acquired[0], nil
 }
+
+// gapFillingLockId is the objid for the advisory lock acquired while
+// detecting and filling history gaps. Like stateVerificationLockId, its value
+// is arbitrary but must be the same on all ingesting nodes.
+const gapFillingLockId = 73897214
+
+// TryGapFillingLock attempts to acquire the gap filling lock which gives the
+// ingesting node exclusive access to detect and fill gaps in history.
+// TryGapFillingLock returns true if the lock was acquired or false if the
+// lock could not be acquired because it is held by another node.
+func (q *Q) TryGapFillingLock(ctx context.Context) (bool, error) {
+	if tx := q.GetTx(); tx == nil {
+		return false, errors.New("cannot be called outside of a transaction")
+	}
+
+	var acquired []bool
+	err := q.SelectRaw(
+		context.WithValue(ctx, &db.QueryTypeContextKey, db.AdvisoryLockQueryType),
+		&acquired,
+		"SELECT pg_try_advisory_xact_lock(?)",
+		gapFillingLockId,
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "error acquiring advisory lock for gap filling")
+	}
+	if len(acquired) != 1 {
+		return false, errors.New("invalid response from advisory lock")
+	}
+	return acquired[0], nil
+}
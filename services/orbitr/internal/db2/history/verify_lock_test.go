@@ -45,3 +45,38 @@ func TestTryStateVerificationLock(t *testing.T) {
 
 	tt.Assert.NoError(otherQ.Rollback())
 }
+
+func TestTryGapFillingLock(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetOrbitRDB(t, tt.OrbitRDB)
+	q := &Q{tt.OrbitRSession()}
+	otherQ := &Q{q.Clone()}
+
+	_, err := q.TryGapFillingLock(context.Background())
+	tt.Assert.EqualError(err, "cannot be called outside of a transaction")
+
+	tt.Assert.NoError(q.Begin(tt.Ctx))
+	ok, err := q.TryGapFillingLock(context.Background())
+	tt.Assert.NoError(err)
+	tt.Assert.True(ok)
+
+	// the state verification lock is independent
+	tt.Assert.NoError(otherQ.Begin(tt.Ctx))
+	ok, err = otherQ.TryStateVerificationLock(context.Background())
+	tt.Assert.NoError(err)
+	tt.Assert.True(ok)
+
+	// lock is already held by q so we will not succeed
+	ok, err = otherQ.TryGapFillingLock(context.Background())
+	tt.Assert.NoError(err)
+	tt.Assert.False(ok)
+
+	tt.Assert.NoError(q.Rollback())
+
+	ok, err = otherQ.TryGapFillingLock(context.Background())
+	tt.Assert.NoError(err)
+	tt.Assert.True(ok)
+
+	tt.Assert.NoError(otherQ.Rollback())
+}
@@ -61,7 +61,14 @@
 // migrations/62_claimable_balance_claimants.sql (1.428kB)
 // migrations/63_add_contract_id_to_asset_stats.sql (153B)
 // migrations/64_add_payment_flag_history_ops.sql (300B)
+// migrations/65_history_details_asset_indexes.sql (344B)
+// migrations/66_history_muxed_participants.sql (1.12kB)
+// migrations/67_partition_history_tables.sql (5.35kB)
+// migrations/68_operation_contract_filter_rules.sql (652B)
+// migrations/69_state_verification_coverage.sql (720B)
 // migrations/6_create_assets_table.sql (366B)
+// migrations/70_history_ledger_entry_changes.sql (1.216kB)
+// migrations/71_ingestion_leases.sql (814B)
 // migrations/7_modify_trades_table.sql (2.303kB)
 // migrations/8_add_aggregators.sql (907B)
 // migrations/8_create_asset_stats_table.sql (441B)
@@ -1354,6 +1361,106 @@ func migrations64_add_payment_flag_history_opsSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations65_history_details_asset_indexesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd2\xd5\x55\xd0\xce\xcd\x4c\x2f\x4a\x2c\x49\x55\x08\x2d\xe0\xe2\x72\x0e\x72\x75\x0c\x71\x55\xf0\xf4\x73\x71\x8d\x50\x50\xca\xcc\x4b\x49\xad\x88\xcf\xc8\x2c\x2e\xc9\x2f\xaa\x8c\xcf\x2f\x48\x2d\x4a\x2c\xc9\xcc\xcf\x2b\x8e\xcf\xcf\x8b\x4f\x49\x2d\x49\xcc\xcc\x29\x56\x52\xf0\xf7\x53\xc0\x54\xa1\x10\x1a\xec\xe9\xe7\xae\x90\x9e\x99\xa7\xa0\x01\x55\xa9\x90\x55\x9c\x9f\x97\x14\x5f\x90\x58\x92\x11\x9f\x5f\x50\xac\x69\x8d\xd7\xb2\xd4\xb4\xb4\xd4\xe4\x12\x9c\x36\x41\xa5\x89\xb2\x86\x0b\xd9\x93\x2e\xf9\xe5\x79\x5c\x5c\x2e\x41\xfe\x01\xa4\x78\xd2\x1a\x8f\x0e\x2c\x2e\xb5\xe6\x02\x0c\x00\x46\x1b\x96\x5d\x58\x01\x00\x00")
+
+func migrations65_history_details_asset_indexesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations65_history_details_asset_indexesSql,
+		"migrations/65_history_details_asset_indexes.sql",
+	)
+}
+
+func migrations65_history_details_asset_indexesSql() (*asset, error) {
+	bytes, err := migrations65_history_details_asset_indexesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/65_history_details_asset_indexes.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe0, 0x38, 0x9, 0x93, 0x9b, 0x4d, 0x82, 0x60, 0x27, 0x2a, 0x35, 0xb4, 0xb6, 0xc2, 0xbf, 0xa5, 0x3c, 0x95, 0x2, 0x73, 0xb7, 0xa7, 0x95, 0x8, 0xad, 0xbe, 0x2f, 0x9a, 0x21, 0xcf, 0xa2, 0x20}}
+	return a, nil
+}
+
+var _migrations66_history_muxed_participantsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xbc\x93\x41\x4b\xc3\x40\x10\x85\xef\xfb\x2b\x86\x9e\x1a\x6c\xaf\x82\xe4\x54\x4d\x90\x40\xd8\x68\x4d\xc0\x5b\x98\xee\x2e\xe9\x1c\xba\x1b\x76\xb7\xda\xfe\x7b\xb1\xd2\xba\xc1\x98\xc6\x1c\xbc\xbf\xf7\xe6\x9b\x79\xcc\x72\x09\x37\x3b\x6a\x2c\x7a\x05\x55\xcb\xd8\xc3\x3a\x5d\x95\x29\x94\xab\xfb\x3c\x85\x2d\x39\x6f\xec\xb1\x36\xad\xb2\xe8\xc9\xe8\x7a\xb7\x3f\x28\x59\xb7\x68\x3d\x09\x6a\x51\x7b\x07\x73\x06\x00\x3d\x52\x92\xb0\xa1\x86\xb4\x07\x5e\x94\xc0\xab\x3c\x5f\x9c\x94\x5f\x11\x28\x84\xd9\x6b\x0f\x62\x8b\x16\x85\x57\x16\xde\xd0\x1e\x49\x37\xf3\xdb\xbb\xe8\xe2\x60\x51\x7c\x41\xaa\x78\xf6\x5c\xa5\x90\xf1\x24\x7d\x85\x19\x69\xa9\x0e\xf5\x18\xc0\xda\xe8\xf3\xb4\x6f\xdd\x0c\x0a\x3e\x6e\xbd\xea\x25\xe3\x8f\xb0\xf1\x56\x29\x98\x77\xd8\x17\xbd\x4b\x47\xf1\x19\x78\x12\x69\x18\x35\x11\xf2\xa7\x83\x64\x14\xff\x52\xad\xb7\xa8\x1d\x8a\x71\xe5\x86\xe2\xff\xac\x77\x18\x32\x2c\x38\x50\x76\xae\x37\x9c\x30\xaa\xe4\x30\xe2\x6a\xcd\xc3\xf3\x3e\x89\xbb\x71\x93\x61\xfb\x3c\x27\x3a\x16\x7e\x76\x62\xde\x35\x63\xc9\xba\x78\xfa\xcb\x67\x0b\x74\x02\xa5\x8a\xfb\x8c\x57\x18\x05\x3a\x81\x52\xc5\xec\x63\x00\x88\xed\xe8\x37\x60\x04\x00\x00")
+
+func migrations66_history_muxed_participantsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations66_history_muxed_participantsSql,
+		"migrations/66_history_muxed_participants.sql",
+	)
+}
+
+func migrations66_history_muxed_participantsSql() (*asset, error) {
+	bytes, err := migrations66_history_muxed_participantsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/66_history_muxed_participants.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x68, 0x11, 0xf9, 0xff, 0x7e, 0x14, 0xd2, 0x7f, 0xc4, 0xdc, 0xa2, 0x50, 0x89, 0x28, 0x29, 0xf2, 0xb, 0xff, 0xad, 0x9d, 0xdd, 0x7e, 0x6c, 0xc4, 0x93, 0xe3, 0xc7, 0x92, 0xb1, 0x98, 0x1c, 0xbc}}
+	return a, nil
+}
+
+var _migrations67_partition_history_tablesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xbc\x57\x5b\x73\xdb\xba\x11\x7e\xd7\xaf\xd8\x07\x7b\x28\x26\xb2\xea\xa4\xa7\xe9\xc4\x3a\x4e\x87\x96\x68\x87\x3d\x0a\x99\xa1\xa8\x34\x99\xa6\xa3\x40\xc4\x8a\xc2\x84\x02\x18\x12\xba\x78\x9a\xfe\xf7\x0e\x40\xd0\xa4\x14\xf9\x1a\xe5\xe8\x45\xc4\x02\xfb\x61\xaf\xd8\xdd\x93\x13\x78\xbe\x60\x49\x4e\x24\xc2\x38\x6b\xb5\x4e\x4e\xa0\x2f\xf8\x0a\x73\x59\x00\x81\x39\x2b\xa4\xc8\xaf\x41\x92\x69\x8a\xc0\xb8\x14\x40\xcc\x22\x23\xb9\x64\x92\x09\x8e\x14\xa6\xd7\x90\x22\x4d\x30\x87\x9c\xf0\x04\x41\x70\x90\x73\x54\x58\x09\x5b\x21\x07\x29\x18\x85\x58\xa4\xcb\x05\xef\x42\x34\x47\xc0\x0d\x2b\x24\xe3\x89\xc1\x9a\x62\x2c\x16\x58\x28\x26\xf8\xf2\xbb\xa6\xbd\x99\xa4\x98\x90\xf8\xfa\x4b\x7d\x51\x47\x01\xc6\x62\x85\xb9\xe2\xc4\x15\xe6\x37\xd7\x2e\x33\x90\x42\xb3\x23\xa7\x20\x66\xfa\xf3\xc5\xe9\x69\xe7\xf4\xf4\x74\x47\xb4\x19\x30\x59\x00\xc7\x35\x16\x52\x01\xe6\x62\xdd\x81\x42\x31\x13\x09\x5c\x00\x25\x92\xc0\x9c\x14\x20\x05\x4c\x11\x62\x91\x31\xa4\x5d\xf0\x64\x01\x8c\x53\xdc\x60\x01\x24\x47\x20\x52\x92\x78\x8e\xd4\xdc\xab\x90\xf0\xdb\x92\xad\x48\x8a\x5c\xde\x9c\x34\x92\x34\x6d\x55\x99\xb2\x90\x48\xb4\xa8\x53\x54\xea\xe4\x38\x5d\xb2\x54\x76\x21\x14\xeb\x42\xa1\x89\xa5\x2c\x18\xd5\x02\x93\x34\xad\xad\x50\x68\x15\x97\x19\x30\xbe\x6d\x2f\x8a\x33\xb2\x4c\x65\xc3\x60\xdd\x56\xd3\xbb\x23\x49\x24\x2e\x90\xcb\x0b\x4c\x18\x6f\xf5\x43\xd7\x89\x5c\xb8\x1c\xfb\xfd\xc8\x0b\xfc\x9a\x6b\x62\x9c\x3e\xd1\xb8\x6d\x39\x4d\x41\xe2\x46\x76\x94\x03\xf5\x97\x0d\xa1\x1b\x8d\x43\x7f\x04\x2b\xe5\x57\x67\x04\x47\x47\x2d\x80\x81\xdb\x1f\x3a\xa1\xdb\x02\x00\x28\x7d\xa7\x4f\xc3\xd9\x39\x28\x8c\xef\xdf\xc1\x32\x3e\xb5\x7a\xfa\xd0\x54\x2c\x39\x85\x29\x4b\x18\x97\x25\x85\xd1\x0d\xe4\x18\x8b\x9c\x96\xeb\x58\xf0\xc6\xfa\xc2\xbd\xf2\x7c\x4d\x77\x3f\xba\xfd\x71\xe4\xc2\x4c\xe4\x0b\x22\xdb\x96\x33\x8c\xdc\x10\x22\xe7\x62\xe8\xc2\xb1\x07\xa1\xeb\x3b\xef\x5c\x88\x02\x38\xf6\xac\x8e\xba\xbd\x63\x24\xb2\x7b\xfb\xf8\x35\x0d\xc0\x32\x26\xb9\xc1\x69\x0f\xbd\x3f\xf4\x87\xe7\xf7\x87\xe3\x81\xe7\x5f\xc1\xc0\xbd\x74\xc6\xc3\x68\xd4\x20\xf5\x03\x7f\x14\x85\x8e\xe7\x6f\x51\x47\x51\x10\x3a\x57\xae\x0d\xef\x9d\x30\xf2\xb4\x85\x2f\x3e\x41\xe8\xf8\x57\x2e\xb4\x8f\x3d\xdb\xea\x98\x5b\x1b\xd2\x69\x13\x6b\xb2\xdd\x6b\xe9\x7f\x95\x8d\x73\x8c\xbf\x42\x2c\x78\x21\x73\xc2\xb8\x4a\x4b\x4a\x91\x02\x29\xc0\x0f\x22\xf8\xe0\x0c\xbd\x81\x8e\xc8\x32\x52\x15\x7d\x45\x52\x46\x9b\x2c\x9d\x0a\x6c\x3d\x67\xf1\x1c\xd6\x62\x99\x52\xc8\x72\x5c\xa9\x58\x2d\x23\x59\xc5\xa0\x0a\xa7\xca\x73\xca\xf7\xb0\x66\x72\x2e\x96\xb2\x04\x24\xd2\x9c\x59\x74\x35\xdc\x65\x10\xaa\x3b\xc0\xf8\x04\x60\xe4\x0e\xdd\x7e\xa4\x68\x9c\x2c\x10\x2e\xc3\xe0\x1d\x64\xc9\xa4\x96\xc3\x9c\xfb\xd7\x5b\x37\x74\xd5\xb1\x1c\x95\x9c\xe7\xe6\xce\xb3\xb3\x1c\x93\x38\x25\x45\x01\x8e\x3f\x50\xfb\xf2\x3a\x43\x38\x07\x2b\xb6\x34\x45\xa9\x1b\x0b\x6e\x84\x41\xaa\xe1\x86\x41\xf0\xde\xe0\xde\x13\x15\x83\x30\x78\xdf\x70\x56\x23\x36\x62\xc1\xbb\x46\xea\x2a\x40\xfc\x81\x46\xee\xb5\xf6\xc5\x8b\x65\x34\xed\x07\xce\xd0\x1d\xf5\xdd\xf6\x82\x6c\x94\x4f\x3b\x70\x6a\x97\x6a\x6b\xec\x58\xd4\x71\x07\x9e\x1f\x05\x65\xc4\x37\x83\xff\xec\x1c\xda\xed\xf2\xf3\xcd\x1b\xf8\xeb\x4b\x1b\xfe\x02\x2f\x4e\xd5\x0f\x9e\xc3\x0b\x1b\x9e\x55\xab\x67\xf0\xdb\xcb\xd7\xbf\xbd\x7e\xf5\xf7\x97\xaf\x5f\xd5\xb1\xa1\x9e\xd2\x78\x27\x3e\x20\x45\x59\x80\x13\x45\x4e\xff\x6d\x23\xf8\x8a\xaf\x2c\x83\x22\x26\x9c\x57\x9e\xd6\xe9\xdd\x6d\x3d\xc0\x72\xce\x60\xb0\x6d\x38\xe8\xbf\x75\xfb\x7f\xa8\x38\x86\xdf\xe1\xb8\xb0\xad\x3a\x82\x4d\xfc\xe8\x6c\xd7\x8a\x55\x86\xd0\x0b\xbb\xf7\xa0\xfb\x76\x85\x3f\xf6\x74\xb4\x7d\x70\x86\x63\x77\x54\x5a\xb8\xfd\xce\xf3\xf5\xda\x56\x79\xde\x2e\x85\xd8\xca\xa5\x47\x5c\xb8\x37\x34\x6e\xd7\xe8\x16\xd0\xdd\xe7\xa3\x96\x3f\xb8\x54\x6b\xf3\x76\x94\x2f\x52\x89\x67\xde\xec\x92\x54\x25\xbd\x52\x55\xbd\x84\xbb\x89\xc5\xba\x39\xa6\x3a\xb5\x9c\x11\xa8\xff\x8e\x4a\xaf\x04\xe5\x44\x97\x1b\x8a\xb3\x36\xeb\x0a\x46\x6d\x70\x46\x40\x71\x66\xb8\xab\x3c\xd4\x87\x60\x03\xff\x0c\x3c\x5f\xad\xcb\x54\x63\x10\xf8\xa0\xd9\xe0\x1c\x36\x5d\x7d\x48\xe7\xa5\xe1\x2e\x93\x55\x6f\xdc\x96\xae\x0f\xcb\x43\xcf\x1f\xb8\x1f\xf7\xbc\xce\x8c\x6e\xba\xa5\x32\x29\xce\x64\xbb\x5e\xfe\xed\x95\xbd\x55\x34\xec\xde\x0e\x7e\x8e\x09\x6e\xb2\x49\x8e\x59\x4a\x62\xd4\x9c\x14\x67\x1d\xb0\x94\x4a\xed\xcf\xa3\xe7\x9f\xbb\xf6\x3f\x2c\x85\xd1\x70\x21\x58\xe6\x80\xde\xa8\x1c\x01\xd6\x0f\x89\xaf\xbf\x7b\xad\xa3\x23\x18\x3a\xfe\xd5\xd8\xb9\x72\x21\x4b\xb3\xa4\xf8\x96\xf6\xf6\x97\x55\x97\xd3\x56\xcb\xb8\xea\xb6\x62\x6a\x55\x4b\x9c\xcd\x30\x96\x85\x12\xa6\x22\x89\x0c\x73\xa2\x0b\x30\xd3\x21\xf6\x50\xa8\x9a\x2f\x4e\x09\x5b\xa8\xcd\xc9\x94\xa4\x84\xc7\x78\x60\xfc\x94\x7d\x5b\x32\xca\xe4\xf5\x24\x13\x22\x3d\x30\xf8\x62\xb9\x41\x3a\xd1\x0c\x31\xcb\x08\x3f\xb4\x71\x7e\x19\xb2\x96\xf3\x71\xbc\x32\x27\xf4\x30\xce\x91\x39\xe1\x05\x89\x1f\xe0\xfe\xe6\xc9\xa7\xdf\x71\x47\x08\x1c\xe6\x82\xbb\xc3\xe0\x30\x77\xfc\x52\xf4\x46\x38\xb4\x74\x5d\xb9\xbf\xc3\xd6\xdd\xb5\xea\x95\x15\x4f\xf3\x6d\x19\x88\x35\x37\x23\x59\xc6\xcc\x7c\x94\x8b\x75\xa1\x87\x82\x1a\x0f\xe9\xce\xa0\x36\x25\xf1\xd7\x6a\x5a\xcb\x31\x59\xa6\x24\x2f\x77\x14\x16\xe1\x54\x35\xd5\x39\x12\x89\x05\xb0\x7a\xae\x79\xd4\xb8\xb0\xe4\xf7\x0d\x0c\x0f\x18\x13\x9a\x1a\xfc\x30\x2b\x34\x36\xcd\xc0\x40\x71\x56\xe8\x99\xe2\xdf\xff\xb9\x21\xe8\xf5\xf6\x74\x60\x9c\x46\xf2\x9c\x5c\x4f\x48\x92\xb4\xf7\x57\x49\xd3\x93\x29\xd0\xd6\xcf\xd6\xc9\x3d\x55\x52\x4e\xd3\xba\x44\x9a\xca\x7e\x4f\x1b\xb2\x6f\x6e\x69\x58\xc1\xee\xed\x03\xf9\x95\xc3\xcb\xf6\x90\xd2\x10\xa5\x1a\x52\xf6\x29\xe5\xf9\x23\x37\x8c\x4a\xe3\x1e\x7b\x95\x37\x9e\x35\x1a\xe3\x87\x29\x66\xe9\xec\xb9\xd1\xa7\xef\x8c\xfa\xce\xc0\xb5\x76\x39\xab\x96\xc9\x55\x2d\xa3\x0a\x08\xcf\x07\x27\x0c\x9d\x4f\x75\x7b\xae\x5c\xdc\x01\xeb\xbf\xff\xb3\xec\x7d\x7d\xca\x4e\x1f\x51\xf7\x10\x81\x3f\xfc\xb4\xdd\x48\xd4\xfd\xc2\x9f\xd1\x45\x2c\xf9\xbd\x0f\x4f\xd5\x47\xd8\xbd\x47\x30\xdd\xd9\x31\x3c\x11\x69\xb7\x30\x3c\x11\x66\xcf\xf3\xff\x44\xa4\x03\x60\x3c\x92\xcf\x54\xf5\xc7\xf2\xdc\x55\xbf\x9f\x8c\xf5\x53\xee\xb8\xa7\x1e\x3f\x19\xeb\x20\x28\xc5\x9e\xca\x7a\x3b\x82\xc4\x8d\xb4\x7b\xad\xff\x0f\x00\xe1\x8f\xd1\x0f\xe6\x14\x00\x00")
+
+func migrations67_partition_history_tablesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations67_partition_history_tablesSql,
+		"migrations/67_partition_history_tables.sql",
+	)
+}
+
+func migrations67_partition_history_tablesSql() (*asset, error) {
+	bytes, err := migrations67_partition_history_tablesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/67_partition_history_tables.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xc, 0x2, 0x7b, 0xa5, 0xb9, 0x4d, 0x9f, 0x7e, 0x80, 0xc4, 0xdd, 0x75, 0xf1, 0xf9, 0x81, 0xb4, 0x7b, 0xc3, 0xb8, 0x9f, 0xa8, 0x60, 0x97, 0x80, 0x95, 0xf0, 0x87, 0x5, 0xd0, 0x2c, 0xc5, 0xbf}}
+	return a, nil
+}
+
+var _migrations68_operation_contract_filter_rulesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xb4\x92\x31\x6f\xf2\x30\x10\x86\x77\xff\x8a\x77\x03\xf4\x7d\x91\xd8\x33\xd1\x92\x01\x29\x0a\x15\x84\x2e\x55\x85\x0e\xe7\x42\xac\x3a\x36\xb2\x8f\x32\x54\xfd\xef\x15\xa4\x8d\xda\x2a\xa5\x53\x17\x4f\x8f\xee\x7d\xee\xf5\x25\x09\xfe\xb5\x66\x1f\x48\x18\x9b\x83\x52\xb7\xab\x6c\x56\x66\x28\x67\x37\x79\x06\x7f\xe0\x40\x62\xbc\xdb\xd6\xc6\x0a\x87\x6d\x38\x5a\x8e\x18\x2b\x00\x60\x47\x3b\xcb\x15\x76\xde\x5b\x14\xcb\x12\xc5\x26\xcf\x51\x71\x4d\x47\x2b\xa8\xc9\x46\xfe\x7f\x01\x4f\x8d\x11\xb6\x26\x0a\x9e\x29\xe8\x86\xc2\xc3\x63\xcf\x77\xc4\xce\x92\x7e\xba\x4a\x58\x8a\xb2\x6d\x7d\x65\x6a\x73\x8e\x34\x7b\xe3\xa4\x47\xd4\x24\xfd\x26\xae\xbd\x93\x40\x5a\xfe\xd8\xfb\x77\xab\x24\x81\x71\x91\x83\x40\x1a\xee\x43\x2a\x13\x3b\x87\x28\xe7\xde\x6b\x1f\xc0\xa4\x1b\x38\x3e\xa1\x33\x86\x69\x0f\x96\x5b\x76\x72\xe9\x5f\x2d\x8a\x75\xb6\x2a\xb1\x28\xca\xe5\x4f\xbf\x72\x3f\xcb\x37\xd9\x1a\xe3\x6e\x03\x8c\x5e\x5e\x47\x1f\xef\x74\x92\x7e\x99\x30\x5c\xcf\xe0\x80\xe9\xfb\x16\xfd\x91\xcc\xfd\xc9\x29\x35\x5f\x2d\xef\xae\x1f\x89\xa6\xa8\xa9\xe2\xf4\x33\x3a\x9c\xab\x29\x6a\xaa\x38\x55\x6f\x03\x00\x92\xa5\xa4\x0f\x8c\x02\x00\x00")
+
+func migrations68_operation_contract_filter_rulesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations68_operation_contract_filter_rulesSql,
+		"migrations/68_operation_contract_filter_rules.sql",
+	)
+}
+
+func migrations68_operation_contract_filter_rulesSql() (*asset, error) {
+	bytes, err := migrations68_operation_contract_filter_rulesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/68_operation_contract_filter_rules.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x69, 0xcc, 0xf7, 0x86, 0x96, 0x77, 0x22, 0x80, 0xf4, 0x9, 0xa2, 0x56, 0x59, 0xac, 0xb7, 0x3, 0x28, 0xa8, 0xc2, 0x27, 0xf0, 0xc0, 0x4f, 0x8d, 0x5f, 0x57, 0x41, 0x27, 0xa5, 0xf9, 0xab, 0xf2}}
+	return a, nil
+}
+
+var _migrations69_state_verification_coverageSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x52\xb1\x6e\xdb\x30\x14\xdc\xf9\x15\x37\xda\xa8\x9d\xa1\x1d\x33\xb9\x8d\x87\xa0\x6e\x12\x18\xce\x90\x49\x78\xa6\xce\x22\x61\x89\x54\xc9\x27\xa7\xea\xd7\x17\x8c\xdc\x36\x35\x1a\x20\x93\xa0\x77\xf7\xee\xde\x1d\xb8\x5c\xe2\x43\xe7\x9b\x24\x4a\x3c\xf6\xc6\x2c\x97\xb8\x0d\x36\xb1\x63\x50\x69\x91\xb5\x00\x27\x26\x7f\xf0\x56\xd4\xc7\x00\xeb\x68\x8f\x19\x82\xec\x43\xd3\x12\x0c\x9a\x46\xe8\xd8\x73\x81\x98\xca\xdc\x49\xaa\x11\x0f\x45\xcb\x6b\xc6\x91\x63\x5e\x40\x14\x3c\x31\x8d\x60\xeb\x1b\xbf\x6f\x39\x09\xf5\xd1\x07\xbd\xc2\xce\xf9\x0c\x95\x32\x3e\x92\x7d\x86\x3a\x22\x0e\x6a\x63\x47\xc4\x43\xf9\x2d\x72\xad\x28\xb3\xfe\x7b\x4f\x3c\x80\x62\xdd\xd9\x36\x47\xa8\x93\x0b\x8a\x95\x80\x14\x5f\xa2\xa8\x4b\x71\x68\x5c\x11\x93\xb6\x3d\x4b\x77\x90\x50\x17\x0f\xd8\x78\x62\x92\xe6\xb7\xe7\x39\x7f\xd9\xdf\x13\x3e\xe4\x9e\x56\x59\x5f\x99\x2f\xdb\xf5\x6a\xb7\xc6\x6e\xf5\x79\xb3\x9e\x48\xd5\x6b\xc7\xea\x8f\xce\xcc\x00\x98\x3a\xaa\x4a\x47\xb0\x4e\x92\x58\x65\xc2\x49\xd2\xe8\x43\x33\xfb\xf4\x71\x8e\xbb\xfb\x1d\xee\x1e\x37\x9b\xc5\x0b\x7d\x8a\xe2\x83\xb2\x61\xfa\x1f\x56\xd9\x38\x04\x7d\x83\xd1\xb2\x6e\x98\xaa\xcc\xef\x03\x83\xe5\x1b\xac\xe9\x5c\xd6\x95\x28\xd4\x77\xcc\x2a\x5d\x8f\x67\xaf\x2e\x0e\xd3\x04\x3f\x63\xe0\xc5\x56\x1e\xac\x65\xce\xd8\xc7\xd8\x52\xc2\x05\xca\x94\x62\x82\xf2\x87\x4e\x1e\x0f\xdb\xdb\x6f\xab\xed\x13\xbe\xae\x9f\x30\xfb\xdb\xc1\x62\x0a\x71\xfe\x4c\x59\xe6\x66\x7e\x6d\xcc\xeb\xd7\x78\x13\x9f\x83\x31\x37\xdb\xfb\x87\x77\xd4\x6c\x25\x5b\xa9\x79\x6d\x7e\x0d\x00\xb6\x9d\x43\x73\xd0\x02\x00\x00")
+
+func migrations69_state_verification_coverageSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations69_state_verification_coverageSql,
+		"migrations/69_state_verification_coverage.sql",
+	)
+}
+
+func migrations69_state_verification_coverageSql() (*asset, error) {
+	bytes, err := migrations69_state_verification_coverageSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/69_state_verification_coverage.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x2e, 0x24, 0x98, 0x57, 0xe3, 0x54, 0x86, 0x99, 0xbd, 0xfe, 0xed, 0x8, 0x2d, 0x17, 0xe9, 0x9e, 0xe0, 0x7f, 0x8a, 0x62, 0x4b, 0xa9, 0x8c, 0x37, 0x91, 0x9c, 0xfd, 0xed, 0x43, 0x33, 0x14, 0x32}}
+	return a, nil
+}
+
 var _migrations6_create_assets_tableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x3d\x4f\xc3\x30\x18\x84\x77\xff\x8a\x1b\x1d\x91\x0e\x20\xe8\x92\xc9\x34\x16\x58\x18\xa7\xb8\x31\xa2\x53\xe5\x26\x16\x78\x80\x54\xb6\x11\xca\xbf\x47\xaa\x28\xf9\x50\xe6\x7b\xf4\xbc\xef\xdd\x6a\x85\xab\x4f\xff\x1e\x6c\x72\x30\x27\xb2\xd1\x9c\xd5\x1c\x35\xbb\x97\x1c\x1f\x3e\xa6\x2e\xf4\x07\x1b\xa3\x4b\x11\x94\x00\x80\x6f\xb1\xe3\x5a\x30\x89\xad\x16\xcf\x4c\xef\xf1\xc4\xf7\xc8\xcf\xd9\x19\x3c\xa4\xfe\xe4\xf0\xca\xf4\xe6\x91\x69\xba\xbe\xcd\xa0\xaa\x1a\xca\x48\x39\x86\x9a\xae\x1d\xa0\xeb\x9b\x65\xc8\xc7\xf8\xed\xc2\x3f\x76\xb7\x9e\x63\x46\x89\x17\xc3\xe9\xa0\xcc\x47\x3f\xe4\x13\x4b\x46\xb2\x82\x5c\xfa\x09\x55\xf2\xb7\xbf\xf8\xd8\x5f\xee\x54\x6a\x5e\xd9\xec\x84\x7a\xc0\x31\x05\xe7\x40\x27\xb6\x82\x90\xf1\x74\x65\xf7\xf3\x45\x4a\x5d\x6d\x97\xa7\x6b\x6c\x6c\x6c\xeb\x8a\xdf\x00\x00\x00\xff\xff\xfb\x53\x3e\x81\x6e\x01\x00\x00")
 
 func migrations6_create_assets_tableSqlBytes() ([]byte, error) {
@@ -1374,6 +1481,46 @@ func migrations6_create_assets_tableSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations70_history_ledger_entry_changesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x95\x94\xc1\x72\x9b\x30\x10\x86\xef\x3c\xc5\x4e\x4e\xf6\xd4\xf0\x02\x39\x91\x9a\x64\x3c\xc3\xe0\x94\xc2\x4c\x7b\x62\x04\x2c\x46\x13\x2c\x51\x49\x2e\xe1\xed\xbb\x92\x6c\xc7\x49\x53\x37\xe1\x04\xcb\xee\xbf\xdf\xbf\x2b\x08\x43\xf8\xb2\xe7\x3b\xc5\x0c\x42\x39\x06\x41\x18\x42\xce\x26\x68\x7a\x26\x76\xa8\x41\x76\x60\x7a\x84\x01\xdb\x1d\x2a\x40\x61\x14\xa7\xe8\xd4\x4b\x8d\x60\xe6\x11\x81\x6b\x68\xa4\xe8\xf8\xee\xa0\xb0\x85\x89\x9b\xde\x4a\x84\x21\xb7\xe5\x26\xf4\x85\xa1\x2d\x9c\xc3\xa3\xe8\x0a\xb8\x70\xaa\x52\xb5\x24\x4a\x77\x33\x4c\xa8\x10\xd8\x38\x0e\x9c\x54\xea\x19\xf0\x37\xaa\xd9\x2a\x19\xc5\x84\x66\x8d\xe1\x52\x58\x18\x76\x44\x89\x40\x8e\x48\xd0\x14\xae\xb8\x68\xf1\xd9\x82\x88\xc3\x30\x40\x27\x9d\xe4\xd9\xc1\x9e\xb5\x68\x15\x29\xf6\x56\x8f\x1b\x8d\x43\x07\x8b\x0e\x2d\x94\xc6\x5f\x07\x14\x0d\x92\xcc\xbe\x46\x45\x91\x28\x8a\x96\x40\x4d\x7a\x47\xc9\x08\x40\xa0\x85\xa0\x3a\x2b\x75\x26\xd0\x11\xa4\xfc\x09\xbd\x27\x97\xdd\x73\x6d\xa4\xa2\xa6\xac\x1e\x9c\x61\x63\xf9\x46\xa6\x0c\xb7\x05\xde\xa3\x3a\x8d\xd8\x8a\x79\x5b\xf4\xe4\xb0\xc0\x48\xde\xd2\x60\x87\xc3\x5e\x44\xc1\xd7\x3c\x89\x8b\x04\x8a\xf8\x2e\x4d\x4e\xd2\x95\x2f\xa8\xdc\x64\xab\x93\xd9\x45\x00\x74\x9d\x52\x2e\xbc\x56\x24\x57\xf3\x1d\x17\x06\xb2\x6d\x01\x59\x99\xa6\x2b\x97\x7b\xe3\x96\x70\x43\x2b\x31\x68\x57\xfc\xfa\xed\x5f\x43\xf6\x59\xfe\xe5\x91\xe0\x89\xd6\x67\xf0\xf9\xad\xb0\x07\xf3\x67\xe4\x5d\x6d\xcf\x7c\x2d\x63\x54\xe8\xfd\x39\xfd\x63\x4c\x6a\x73\x11\x0c\x96\xf0\x18\xe7\xc5\xa6\xd8\x6c\x33\xb8\xfb\x09\x79\x9c\x3d\x24\xb0\x78\x7f\x02\xcb\xdb\xe0\xe3\xb3\xac\x5a\xec\xd8\x61\x30\x17\xfa\xdb\xfb\xeb\xd3\x5f\x27\xf7\x71\x99\x16\x2f\x5d\xca\x6c\xf3\xad\x4c\x60\x93\xad\x93\x1f\xe0\x26\x58\x5d\x6d\xe9\x28\x35\x50\xab\xab\x7d\xca\xef\x9b\xec\x01\x6a\xa3\x10\xff\x65\x75\x75\x5a\x2c\x79\x3e\xc2\x7c\x86\xe2\x62\xb5\x9f\x82\x79\xa9\x5b\xc1\xff\xc1\xec\xb9\x3f\xff\x7c\xd6\x72\x12\x41\xb0\xce\xb7\x8f\x1f\x39\xe8\x0d\xd3\x0d\x7d\xd8\xb7\xc1\x1f\x70\xfc\xb5\x45\xc0\x04\x00\x00")
+
+func migrations70_history_ledger_entry_changesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations70_history_ledger_entry_changesSql,
+		"migrations/70_history_ledger_entry_changes.sql",
+	)
+}
+
+func migrations70_history_ledger_entry_changesSql() (*asset, error) {
+	bytes, err := migrations70_history_ledger_entry_changesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/70_history_ledger_entry_changes.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe9, 0x76, 0xe2, 0xa0, 0xc2, 0x0, 0x78, 0x83, 0xe8, 0x7, 0x28, 0x26, 0x5d, 0x7e, 0xfa, 0x2e, 0x56, 0xe4, 0x12, 0x40, 0x6c, 0x43, 0xa0, 0x7e, 0xa9, 0xff, 0xe7, 0xa4, 0x67, 0x5, 0xe, 0xd5}}
+	return a, nil
+}
+
+var _migrations71_ingestion_leasesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x95\x52\x4d\x4f\xe3\x30\x10\xbd\xe7\x57\xbc\x23\x68\x69\x0f\x88\xe5\xc2\xa9\x2c\x3d\x20\xba\x80\xaa\x72\xe0\x14\x4d\x9d\x49\x62\x51\xdb\xc1\x9e\xb6\x9b\xfd\xf5\x8c\x93\x8a\x0a\x96\x3d\x10\x29\x8a\xe2\x99\xf7\x31\xf3\x3c\x99\xe0\x87\xb3\x4d\x24\x61\x3c\x75\x45\x31\x99\xe0\xd6\x37\x9c\xc4\xfa\x06\xd6\x27\x21\x6f\x38\x81\x37\x6c\x04\x84\x0d\x53\xc5\x11\xeb\x1e\x6d\xd8\x54\xb9\x67\x38\x4b\x8c\x7d\x6b\x4d\x0b\x69\xb9\x47\x64\xcf\x7b\x90\x64\x32\xde\x71\xd4\x66\xa6\x28\x6b\x26\x99\x62\xd5\x32\x6a\xf6\x26\x63\x25\xbc\xb0\x87\x4d\x2a\x64\x22\x3b\xf6\xc2\xd5\x01\x21\xd6\x71\x66\x1b\xd9\x33\x93\x69\x29\x1b\x83\x7e\xaa\x84\x14\xb4\x4a\xd9\x52\x1d\xa2\x53\x4b\x07\x67\xa3\x8d\xca\x56\xf0\x41\xf2\x6b\x0d\xc3\x0a\x36\x21\x49\xe6\xcb\x4c\xa3\x61\x43\x3e\xb7\x98\xe0\x9c\xd6\xed\x38\x74\xf0\x20\xdf\xbb\x10\x79\x3a\x08\x85\xba\x2e\x25\x64\x8b\x89\x15\x1f\x74\xb6\xd7\x2d\x7f\xa0\xca\xaa\x5a\x48\xc2\x1d\xaa\xb0\xf7\x67\xca\x85\x9a\x76\x61\x1b\x11\xea\x61\x86\xc6\xee\xf2\x9c\x87\x6d\xaa\x47\xfd\xcb\xda\xec\x3a\xe9\xa7\xc5\xaf\xe5\x7c\xb6\x9a\x63\x35\xbb\x5e\xcc\x8f\x46\xca\xc1\x66\xc2\x49\x01\x7d\x3c\xe9\x3e\x74\x05\x91\x8c\xa8\xe0\x8e\x62\xaf\x9d\x27\x97\x17\xa7\xb8\x7f\x58\xe1\xfe\x69\xb1\xc0\xe3\xf2\xf6\xf7\x6c\xf9\x8c\xbb\xf9\xf3\xd9\x00\x1a\xdd\x95\xba\x8c\x7f\x91\xe7\x3f\x2f\x8f\xd0\xb1\xfd\x10\x4b\x39\xc6\xb2\xb6\x8d\xf5\xf2\xa9\x85\xcc\xeb\xd6\x46\xae\x4a\x5d\x7d\x8e\x48\x27\x72\x1d\xf6\x56\xda\xb0\x1d\x4f\xf0\x37\x78\xfe\x84\x7a\x8f\xff\x7b\x30\xfe\xd3\xa9\x56\xfa\xa6\xd6\x31\xb5\xaf\x87\x2e\x4e\xaf\x86\x6b\xfe\x7e\xed\x6f\x34\xb4\xa2\xb8\x59\x3e\x3c\xfe\x2f\x02\x43\xc9\xe8\x26\xaf\x8a\x37\x3e\x2d\xd3\xae\x2e\x03\x00\x00")
+
+func migrations71_ingestion_leasesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations71_ingestion_leasesSql,
+		"migrations/71_ingestion_leases.sql",
+	)
+}
+
+func migrations71_ingestion_leasesSql() (*asset, error) {
+	bytes, err := migrations71_ingestion_leasesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/71_ingestion_leases.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xab, 0xc3, 0xef, 0xa7, 0x0, 0x9e, 0xbf, 0x8f, 0x89, 0xdd, 0x54, 0x2d, 0x2c, 0x7b, 0x7, 0xc7, 0x69, 0xa5, 0x1b, 0x7e, 0x6a, 0xa7, 0x2e, 0xc0, 0x98, 0x5f, 0x36, 0xb3, 0x8d, 0x59, 0x62, 0x6b}}
+	return a, nil
+}
+
 var _migrations7_modify_trades_tableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xc4\x54\x4d\x8f\xda\x30\x14\xbc\xe7\x57\x3c\xed\x29\x51\xc3\xaa\xad\xda\xbd\x6c\x55\x09\x58\x97\x46\x65\xc3\x36\x04\xa9\xb7\xc8\x89\xdf\x06\xab\xc1\x8e\x6c\xa7\x88\x7f\x5f\x05\x08\xcd\x27\xb0\xbb\x87\x5e\x93\x99\x79\x6f\xec\xf1\x8c\x46\xf0\x6e\xc3\x53\x45\x0d\xc2\x2a\xb7\x46\x23\x60\x4a\xe6\x60\xd6\x08\x32\x63\x60\x14\x65\xa8\xc1\xd0\x38\xc3\x5b\xc8\x0b\x03\x14\x04\x6e\x41\x0a\x04\x2e\x20\xcf\x68\x82\xd6\x43\xb0\x78\x82\x70\x3c\x99\x13\x58\x73\x6d\xa4\xda\x45\x07\xde\xbd\x35\x0d\xc8\x38\x24\xbd\x3f\xc1\xb6\x00\xe0\xf4\x51\xe6\xa8\xa8\xe1\x52\x44\x9c\xc1\xc4\x9b\x79\x7e\x08\xfe\x22\x04\x7f\x35\x9f\xbb\x7b\xe4\x8d\x54\x0c\xd5\x0d\x78\x7e\x48\x66\x24\x68\xfd\xcd\x90\xa5\xa8\xa2\x24\x93\x1a\x59\x44\x0d\x84\xde\x23\x59\x86\xe3\xc7\xa7\x16\x50\x3e\x3f\xa3\x1a\x1c\x12\x53\x8d\x11\x4d\x12\x59\x08\xd3\x03\x82\x80\x7c\x23\x01\xf1\xa7\x64\x79\xda\xfc\x88\xd6\x36\x67\x4e\x5d\x44\x6b\xbc\x5a\xa2\xc4\x76\x04\x36\xa5\x6c\x87\x3e\xfd\x4e\xa6\x3f\xc0\xae\x43\xbe\xc2\xfb\x23\x71\xbf\x09\xaa\x37\x3b\x38\xe9\xbc\xc1\xc4\x49\xe3\xac\x8f\x16\xea\x9f\x95\xbd\x41\xae\x23\x8d\x59\x86\x0a\x26\x8b\xc5\x9c\x8c\xfd\xc3\xbf\x3d\xd7\x6e\x1e\xf3\x97\xce\xd2\x8e\xe5\xdc\x5b\x55\x04\x57\xbe\xf7\x73\x45\xc0\xf3\x1f\xc8\x2f\x58\x1b\xc5\xa2\x9c\x33\x58\xf8\xed\x54\xae\x96\x9e\x3f\x83\xd8\x28\x44\xb0\xfb\xc2\xe9\x56\x41\x74\x4e\xf1\xae\x8b\x52\xae\x22\xc3\x37\x18\x65\x52\xfe\x2e\xf2\xc1\x09\x93\x30\x20\xa4\x69\xc1\xed\x38\x70\x3b\xb1\xee\x1d\x5a\xd1\xae\x1a\xd9\x39\xa5\x3e\xc5\xeb\x1d\x5c\xb5\x60\xbc\x8b\xf6\xcf\xee\xd2\x79\x57\x6f\xb3\xbc\x37\xab\x5e\x4d\x0f\x72\x2b\x1a\xe5\x24\x70\x8b\xaa\xea\x25\x85\x5c\x68\x53\xe2\xaa\xde\x92\x02\x6f\x87\x7b\x09\x12\xaa\x13\xca\xf0\xd5\xfd\x14\xf3\x94\x0b\x33\xd0\x4f\x5c\x18\x4c\x51\x0d\xd5\x4e\x2f\xf7\x10\xf2\xc1\xdf\x71\xb1\x3b\x47\x96\x19\x3b\x5e\xa7\xd9\xe5\x08\xc9\x9a\x2a\x9a\x18\x54\xf0\x87\xaa\x1d\x17\xa9\x7d\xf7\xc9\x19\xe6\x70\xad\x0b\x54\x3d\xac\xcf\x77\x67\x58\x89\x64\x7d\x93\x3e\x7c\xec\xe7\x1c\x5e\x77\x6b\xfd\xaa\x03\xea\x90\x5a\x01\xc8\x22\x5d\x9b\x97\x1a\x6b\xb0\x5e\x60\xad\xc1\xbb\xda\x5c\xc5\x3a\x6b\xaf\x09\x2a\x0d\xfe\x87\x62\x7a\xc5\x13\x6c\x8b\x94\x1a\xe5\x55\x5d\x92\x68\xe5\xd1\x6d\xc7\xc6\xed\xa6\x6f\x60\xda\xe1\xe4\x2e\xcd\xeb\x04\xc5\xed\xde\xa6\xdb\x17\x0c\xe7\xfe\x6f\x00\x00\x00\xff\xff\x2a\xff\xe8\x4a\xff\x08\x00\x00")
 
 func migrations7_modify_trades_tableSqlBytes() ([]byte, error) {
@@ -1606,7 +1753,14 @@ var _bindata = map[string]func() (*asset, error){
 	"migrations/62_claimable_balance_claimants.sql":                      migrations62_claimable_balance_claimantsSql,
 	"migrations/63_add_contract_id_to_asset_stats.sql":                   migrations63_add_contract_id_to_asset_statsSql,
 	"migrations/64_add_payment_flag_history_ops.sql":                     migrations64_add_payment_flag_history_opsSql,
+	"migrations/65_history_details_asset_indexes.sql":                    migrations65_history_details_asset_indexesSql,
+	"migrations/66_history_muxed_participants.sql":                       migrations66_history_muxed_participantsSql,
+	"migrations/67_partition_history_tables.sql":                         migrations67_partition_history_tablesSql,
+	"migrations/68_operation_contract_filter_rules.sql":                  migrations68_operation_contract_filter_rulesSql,
+	"migrations/69_state_verification_coverage.sql":                      migrations69_state_verification_coverageSql,
 	"migrations/6_create_assets_table.sql":                               migrations6_create_assets_tableSql,
+	"migrations/70_history_ledger_entry_changes.sql":                     migrations70_history_ledger_entry_changesSql,
+	"migrations/71_ingestion_leases.sql":                                 migrations71_ingestion_leasesSql,
 	"migrations/7_modify_trades_table.sql":                               migrations7_modify_trades_tableSql,
 	"migrations/8_add_aggregators.sql":                                   migrations8_add_aggregatorsSql,
 	"migrations/8_create_asset_stats_table.sql":                          migrations8_create_asset_stats_tableSql,
@@ -1718,7 +1872,14 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"62_claimable_balance_claimants.sql":                      {migrations62_claimable_balance_claimantsSql, map[string]*bintree{}},
 		"63_add_contract_id_to_asset_stats.sql":                   {migrations63_add_contract_id_to_asset_statsSql, map[string]*bintree{}},
 		"64_add_payment_flag_history_ops.sql":                     {migrations64_add_payment_flag_history_opsSql, map[string]*bintree{}},
+		"65_history_details_asset_indexes.sql":                    {migrations65_history_details_asset_indexesSql, map[string]*bintree{}},
+		"66_history_muxed_participants.sql":                       {migrations66_history_muxed_participantsSql, map[string]*bintree{}},
+		"67_partition_history_tables.sql":                         {migrations67_partition_history_tablesSql, map[string]*bintree{}},
+		"68_operation_contract_filter_rules.sql":                  {migrations68_operation_contract_filter_rulesSql, map[string]*bintree{}},
+		"69_state_verification_coverage.sql":                      {migrations69_state_verification_coverageSql, map[string]*bintree{}},
 		"6_create_assets_table.sql":                               {migrations6_create_assets_tableSql, map[string]*bintree{}},
+		"70_history_ledger_entry_changes.sql":                     {migrations70_history_ledger_entry_changesSql, map[string]*bintree{}},
+		"71_ingestion_leases.sql":                                 {migrations71_ingestion_leasesSql, map[string]*bintree{}},
 		"7_modify_trades_table.sql":                               {migrations7_modify_trades_tableSql, map[string]*bintree{}},
 		"8_add_aggregators.sql":                                   {migrations8_add_aggregatorsSql, map[string]*bintree{}},
 		"8_create_asset_stats_table.sql":                          {migrations8_create_asset_stats_tableSql, map[string]*bintree{}},
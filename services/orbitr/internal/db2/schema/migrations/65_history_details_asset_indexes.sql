@@ -0,0 +1,9 @@
+-- +migrate Up
+
+CREATE INDEX "index_history_operations_on_details" ON history_operations USING gin (details jsonb_path_ops);
+CREATE INDEX "index_history_effects_on_details" ON history_effects USING gin (details jsonb_path_ops);
+
+-- +migrate Down
+
+DROP INDEX "index_history_operations_on_details";
+DROP INDEX "index_history_effects_on_details";
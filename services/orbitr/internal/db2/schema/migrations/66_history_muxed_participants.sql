@@ -0,0 +1,22 @@
+-- +migrate Up
+
+CREATE TABLE history_operation_muxed_participants (
+    history_operation_id bigint NOT NULL,
+    muxed_account character varying(69) NOT NULL
+);
+
+CREATE UNIQUE INDEX "index_history_operation_muxed_participants_on_account_operation" ON history_operation_muxed_participants USING btree (muxed_account, history_operation_id);
+CREATE INDEX "index_history_operation_muxed_participants_on_operation_id" ON history_operation_muxed_participants USING btree (history_operation_id);
+
+CREATE TABLE history_transaction_muxed_participants (
+    history_transaction_id bigint NOT NULL,
+    muxed_account character varying(69) NOT NULL
+);
+
+CREATE UNIQUE INDEX "index_history_transaction_muxed_participants_on_account_transaction" ON history_transaction_muxed_participants USING btree (muxed_account, history_transaction_id);
+CREATE INDEX "index_history_transaction_muxed_participants_on_transaction_id" ON history_transaction_muxed_participants USING btree (history_transaction_id);
+
+-- +migrate Down
+
+DROP TABLE history_operation_muxed_participants cascade;
+DROP TABLE history_transaction_muxed_participants cascade;
@@ -0,0 +1,111 @@
+-- +migrate Up
+
+-- Converts a history table into a table partitioned by ledger range on the
+-- given toid column. The existing table becomes the `<table>_legacy` partition,
+-- covering every ledger up to the end of the 100,000 ledger range of its newest
+-- row, so that no data has to be copied. Its indexes are attached to the
+-- equivalent indexes of the partitioned table instead of being rebuilt. Rows
+-- outside of all partitions end up in the `<table>_default` partition.
+-- +migrate StatementBegin
+CREATE FUNCTION partition_history_table(tbl text, col text) RETURNS void AS $$
+  DECLARE
+    legacy text := tbl || '_legacy';
+    bound bigint;
+    idx record;
+    con record;
+  BEGIN
+    EXECUTE format('ALTER TABLE %I RENAME TO %I', tbl, legacy);
+    EXECUTE format(
+      'CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS INCLUDING CONSTRAINTS INCLUDING STORAGE) PARTITION BY RANGE (%I)',
+      tbl, legacy, col
+    );
+
+    -- Check constraints added as NOT VALID are copied as valid constraints,
+    -- which would prevent attaching the legacy table without validating them.
+    FOR con IN
+      SELECT conname FROM pg_constraint
+      WHERE conrelid = legacy::regclass AND contype = 'c' AND NOT convalidated
+    LOOP
+      EXECUTE format('ALTER TABLE %I DROP CONSTRAINT %I', tbl, con.conname);
+    END LOOP;
+
+    EXECUTE format('SELECT COALESCE(max(%I), 0) FROM %I', col, legacy) INTO bound;
+    bound := ((bound >> 32) / 100000 + 1) * 100000 * 4294967296;
+
+    -- The check constraint lets ATTACH PARTITION skip scanning the table.
+    EXECUTE format('ALTER TABLE %I ADD CONSTRAINT %I CHECK (%I < %s)', legacy, legacy || '_bound', col, bound);
+    EXECUTE format('ALTER TABLE %I ATTACH PARTITION %I FOR VALUES FROM (MINVALUE) TO (%s)', tbl, legacy, bound);
+    EXECUTE format('ALTER TABLE %I DROP CONSTRAINT %I', legacy, legacy || '_bound');
+    EXECUTE format('CREATE TABLE %I PARTITION OF %I DEFAULT', tbl || '_default', tbl);
+
+    FOR idx IN
+      SELECT i.relname AS name, pg_get_indexdef(i.oid) AS def
+      FROM pg_index x JOIN pg_class i ON i.oid = x.indexrelid
+      WHERE x.indrelid = legacy::regclass
+    LOOP
+      EXECUTE format('ALTER INDEX %I RENAME TO %I', idx.name, left(idx.name, 56) || '_legacy');
+      EXECUTE regexp_replace(idx.def, ' ON (\S+\.)?' || legacy || ' ', ' ON ' || tbl || ' ');
+    END LOOP;
+  END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+SELECT partition_history_table('history_effects', 'history_operation_id');
+SELECT partition_history_table('history_operation_claimable_balances', 'history_operation_id');
+SELECT partition_history_table('history_operation_liquidity_pools', 'history_operation_id');
+SELECT partition_history_table('history_operation_muxed_participants', 'history_operation_id');
+SELECT partition_history_table('history_operation_participants', 'history_operation_id');
+SELECT partition_history_table('history_operations', 'id');
+SELECT partition_history_table('history_trades', 'history_operation_id');
+SELECT partition_history_table('history_transaction_claimable_balances', 'history_transaction_id');
+SELECT partition_history_table('history_transaction_liquidity_pools', 'history_transaction_id');
+SELECT partition_history_table('history_transaction_muxed_participants', 'history_transaction_id');
+SELECT partition_history_table('history_transaction_participants', 'history_transaction_id');
+SELECT partition_history_table('history_transactions', 'id');
+
+DROP FUNCTION partition_history_table(text, text);
+
+-- +migrate Down
+
+-- Copies the rows of a partitioned history table back into a regular table
+-- and recreates its indexes.
+-- +migrate StatementBegin
+CREATE FUNCTION unpartition_history_table(tbl text) RETURNS void AS $$
+  DECLARE
+    partitioned text := tbl || '_partitioned';
+    defs text[];
+    def text;
+  BEGIN
+    SELECT array_agg(pg_get_indexdef(i.oid)) INTO defs
+    FROM pg_index x JOIN pg_class i ON i.oid = x.indexrelid
+    WHERE x.indrelid = tbl::regclass;
+
+    EXECUTE format('ALTER TABLE %I RENAME TO %I', tbl, partitioned);
+    EXECUTE format(
+      'CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS INCLUDING CONSTRAINTS INCLUDING STORAGE)',
+      tbl, partitioned
+    );
+    EXECUTE format('INSERT INTO %I SELECT * FROM %I', tbl, partitioned);
+    EXECUTE format('DROP TABLE %I CASCADE', partitioned);
+
+    FOREACH def IN ARRAY COALESCE(defs, '{}') LOOP
+      EXECUTE regexp_replace(def, ' ON ONLY (\S+\.)?' || tbl || ' ', ' ON ' || tbl || ' ');
+    END LOOP;
+  END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+SELECT unpartition_history_table('history_effects');
+SELECT unpartition_history_table('history_operation_claimable_balances');
+SELECT unpartition_history_table('history_operation_liquidity_pools');
+SELECT unpartition_history_table('history_operation_muxed_participants');
+SELECT unpartition_history_table('history_operation_participants');
+SELECT unpartition_history_table('history_operations');
+SELECT unpartition_history_table('history_trades');
+SELECT unpartition_history_table('history_transaction_claimable_balances');
+SELECT unpartition_history_table('history_transaction_liquidity_pools');
+SELECT unpartition_history_table('history_transaction_muxed_participants');
+SELECT unpartition_history_table('history_transaction_participants');
+SELECT unpartition_history_table('history_transactions');
+
+DROP FUNCTION unpartition_history_table(text);
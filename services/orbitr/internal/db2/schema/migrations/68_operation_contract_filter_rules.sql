@@ -0,0 +1,23 @@
+-- +migrate Up
+
+CREATE TABLE operation_filter_rules (
+    enabled bool NOT NULL default false,
+    whitelist varchar[] NOT NULL,
+    blacklist varchar[] NOT NULL,
+    last_modified bigint NOT NULL
+);
+
+CREATE TABLE contract_filter_rules (
+    enabled bool NOT NULL default false,
+    whitelist varchar[] NOT NULL,
+    last_modified bigint NOT NULL
+);
+
+-- insert the default disabled state for each new filter implementation
+INSERT INTO operation_filter_rules VALUES (false, '{}', '{}', 0);
+INSERT INTO contract_filter_rules VALUES (false, '{}', 0);
+
+-- +migrate Down
+
+DROP TABLE operation_filter_rules cascade;
+DROP TABLE contract_filter_rules cascade;
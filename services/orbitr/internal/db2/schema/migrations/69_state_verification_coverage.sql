@@ -0,0 +1,20 @@
+-- +migrate Up
+
+-- Incremental state verification checks a single entry type, or a shard of
+-- its keys, at every eligible checkpoint. This table keeps the outcome of the
+-- latest verification of each shard so that verification can rotate through
+-- all of them and the coverage of the state can be inspected.
+CREATE TABLE state_verification_coverage (
+    entry_type character varying(32) NOT NULL,
+    shard integer NOT NULL,
+    shard_count integer NOT NULL,
+    ledger_sequence integer NOT NULL,
+    verified_at timestamp without time zone NOT NULL,
+    success boolean NOT NULL,
+    error text,
+    PRIMARY KEY (entry_type, shard, shard_count)
+);
+
+-- +migrate Down
+
+DROP TABLE state_verification_coverage cascade;
@@ -0,0 +1,27 @@
+-- +migrate Up
+
+-- Raw changes of the ledger entries whose type is configured with
+-- --ingest-ledger-entry-changes, in the order they were applied by every
+-- transaction of a ledger. operation_index is null for the changes made by the
+-- transaction itself (fees, sequence numbers, ...) rather than one of its
+-- operations. Like the other history tables, it is partitioned by ranges of
+-- ledgers on its toid column.
+CREATE TABLE history_ledger_entry_changes (
+    history_transaction_id bigint NOT NULL,
+    "order" integer NOT NULL,
+    operation_index integer,
+    ledger_key text NOT NULL,
+    entry_type integer NOT NULL,
+    change_type integer NOT NULL,
+    pre_entry text,
+    post_entry text
+) PARTITION BY RANGE (history_transaction_id);
+
+CREATE TABLE history_ledger_entry_changes_default PARTITION OF history_ledger_entry_changes DEFAULT;
+
+CREATE UNIQUE INDEX index_history_ledger_entry_changes_on_ids ON history_ledger_entry_changes USING btree (history_transaction_id, "order");
+CREATE INDEX index_history_ledger_entry_changes_on_ledger_key ON history_ledger_entry_changes USING btree (ledger_key, history_transaction_id, "order");
+
+-- +migrate Down
+
+DROP TABLE history_ledger_entry_changes cascade;
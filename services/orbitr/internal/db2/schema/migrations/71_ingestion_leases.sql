@@ -0,0 +1,20 @@
+-- +migrate Up
+
+-- Ingesting instances elect a leader by holding a lease which they renew at
+-- every heartbeat. The fencing token is incremented every time the lease
+-- changes hands so that a former leader which did not notice it lost the
+-- lease cannot commit ingestion anymore. handoff_to is set to request the
+-- leader to step down, in favour of the given instance when not empty.
+CREATE TABLE ingestion_leases (
+    name character varying(64) NOT NULL PRIMARY KEY,
+    leader_id character varying(256) NOT NULL,
+    fencing_token bigint NOT NULL,
+    acquired_at timestamp without time zone NOT NULL,
+    heartbeat_at timestamp without time zone NOT NULL,
+    expires_at timestamp without time zone NOT NULL,
+    handoff_to character varying(256)
+);
+
+-- +migrate Down
+
+DROP TABLE ingestion_leases cascade;
@@ -8,18 +8,21 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 
 	"github.com/lantah/go/ingest/ledgerbackend"
 	"github.com/lantah/go/network"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
 	"github.com/lantah/go/services/orbitr/internal/db2/schema"
 	apkg "github.com/lantah/go/support/app"
 	support "github.com/lantah/go/support/config"
 	"github.com/lantah/go/support/db"
 	"github.com/lantah/go/support/errors"
 	"github.com/lantah/go/support/log"
+	"github.com/lantah/go/xdr"
 	"github.com/stellar/throttled"
 )
 
@@ -56,6 +59,30 @@ const (
 	EnableIngestionFilteringFlagName = "exp-enable-ingestion-filtering"
 	// DisableTxSubFlagName is the command line flag for disabling transaction submission feature of OrbitR
 	DisableTxSubFlagName = "disable-tx-sub"
+	// ReplicaDatabaseURLsFlagName is the command line flag for specifying the read-replica database URLs
+	ReplicaDatabaseURLsFlagName = "replica-database-urls"
+	// ReapedHistoryArchiveURLFlagName is the command line flag for specifying where reaped history is archived
+	ReapedHistoryArchiveURLFlagName = "reaped-history-archive-url"
+	// ReapedHistoryArchiveS3RegionFlagName is the command line flag for specifying the S3 region of the reaped history archive
+	ReapedHistoryArchiveS3RegionFlagName = "reaped-history-archive-s3-region"
+	// ReapedHistoryArchiveS3EndpointFlagName is the command line flag for specifying the S3 endpoint of the reaped history archive
+	ReapedHistoryArchiveS3EndpointFlagName = "reaped-history-archive-s3-endpoint"
+	// HistoryGapDetectionIntervalFlagName is the command line flag for specifying how often history is checked for gaps
+	HistoryGapDetectionIntervalFlagName = "history-gap-detection-interval"
+	// HistoryGapFillingFlagName is the command line flag for enabling the reingestion of history gaps
+	HistoryGapFillingFlagName = "history-gap-filling"
+	// HistoryGapFillingMaxLedgersFlagName is the command line flag for specifying the maximum number of ledgers reingested every gap detection interval
+	HistoryGapFillingMaxLedgersFlagName = "history-gap-filling-max-ledgers"
+	// IngestHistorySinkURLFlagName is the command line flag for specifying the history sink ingested rows are published to
+	IngestHistorySinkURLFlagName = "ingest-history-sink-url"
+	// IngestLedgerEntryChangesFlagName is the command line flag for specifying the types of the ledger entries whose changes are stored
+	IngestLedgerEntryChangesFlagName = "ingest-ledger-entry-changes"
+	// IngestLeaderElectionFlagName is the command line flag for enabling ingestion leader election
+	IngestLeaderElectionFlagName = "ingest-leader-election"
+	// IngestLeaderIDFlagName is the command line flag for specifying the identity of the instance in the ingestion lease
+	IngestLeaderIDFlagName = "ingest-leader-id"
+	// RoundingSlippageFilterFlagName is the command line flag for specifying the trade aggregations rounding slippage filter
+	RoundingSlippageFilterFlagName = "rounding-slippage-filter"
 
 	captiveCoreMigrationHint = "If you are migrating from OrbitR 1.x.y, start with the Migration Guide here: https://developers.stellar.org/docs/run-api-server/migrating/"
 	// LantahPubnet is a constant representing the Stellar public network
@@ -64,6 +91,28 @@ const (
 	LantahTestnet = "testnet"
 )
 
+// parseLedgerEntryTypes parses a comma separated list of ledger entry type
+// names.
+func parseLedgerEntryTypes(list string) ([]xdr.LedgerEntryType, error) {
+	var entryTypes []xdr.LedgerEntryType
+	seen := map[xdr.LedgerEntryType]bool{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		entryType, ok := history.LedgerEntryTypesByName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown ledger entry type %q", name)
+		}
+		if !seen[entryType] {
+			seen[entryType] = true
+			entryTypes = append(entryTypes, entryType)
+		}
+	}
+	return entryTypes, nil
+}
+
 // validateBothOrNeither ensures that both options are provided, if either is provided.
 func validateBothOrNeither(option1, option2 string) error {
 	arg1, arg2 := viper.GetString(option1), viper.GetString(option2)
@@ -141,6 +190,24 @@ func Flags() (*Config, support.ConfigOptions) {
 			Required:  false,
 			Usage:     "orbitr postgres read-replica to connect with, when set it will return stale history error when replica is behind primary",
 		},
+		&support.ConfigOption{
+			Name:      ReplicaDatabaseURLsFlagName,
+			ConfigKey: &config.ReplicaDatabaseURLs,
+			OptType:   types.String,
+			Required:  false,
+			CustomSetValue: func(co *support.ConfigOption) error {
+				stringOfUrls := viper.GetString(co.Name)
+				urlStrings := strings.Split(stringOfUrls, ",")
+				//urlStrings contains a single empty value when stringOfUrls is empty
+				if len(urlStrings) == 1 && urlStrings[0] == "" {
+					*(co.ConfigKey.(*[]string)) = []string{}
+				} else {
+					*(co.ConfigKey.(*[]string)) = urlStrings
+				}
+				return nil
+			},
+			Usage: "comma-separated list of orbitr postgres read-replicas to connect with, read-only API queries are served by a replica which has replayed the requested ledger and by the primary database otherwise",
+		},
 		&support.ConfigOption{
 			Name:        GravityBinaryPathName,
 			OptType:     types.String,
@@ -520,6 +587,28 @@ func Flags() (*Config, support.ConfigOptions) {
 			FlagDefault: uint(0),
 			Usage:       "the minimum number of ledgers to maintain within orbitr's history tables.  0 signifies an unlimited number of ledgers will be retained",
 		},
+		&support.ConfigOption{
+			Name:      ReapedHistoryArchiveURLFlagName,
+			ConfigKey: &config.ReapedHistoryArchiveURL,
+			OptType:   types.String,
+			Required:  false,
+			Usage: "file:// or s3:// URL where history is archived before being reaped, " +
+				"reaped history is permanently deleted when not set",
+		},
+		&support.ConfigOption{
+			Name:      ReapedHistoryArchiveS3RegionFlagName,
+			ConfigKey: &config.ReapedHistoryArchiveS3Region,
+			OptType:   types.String,
+			Required:  false,
+			Usage:     "S3 region of the reaped history archive",
+		},
+		&support.ConfigOption{
+			Name:      ReapedHistoryArchiveS3EndpointFlagName,
+			ConfigKey: &config.ReapedHistoryArchiveS3Endpoint,
+			OptType:   types.String,
+			Required:  false,
+			Usage:     "S3 endpoint of the reaped history archive, for S3-compatible stores",
+		},
 		&support.ConfigOption{
 			Name:        "history-stale-threshold",
 			ConfigKey:   &config.StaleThreshold,
@@ -559,6 +648,17 @@ func Flags() (*Config, support.ConfigOptions) {
 			Usage: "defines an upper bound in minutes for on how long state verification is allowed to run. " +
 				"A value of 0 disables the timeout.",
 		},
+		&support.ConfigOption{
+			Name:        "ingest-state-verification-shards",
+			ConfigKey:   &config.IngestStateVerificationShards,
+			OptType:     types.Uint,
+			FlagDefault: uint(0),
+			Usage: "enables incremental state verification when greater than 0. Every state verification then only checks " +
+				"a single ledger entry type (accounts, data, offers, trust lines, claimable balances or liquidity pools) " +
+				"whose keys hash to one of the given number of shards, rotating through all of them. " +
+				"The outcome of each shard verification is recorded in the state_verification_coverage table. " +
+				"A value of 0 verifies the entire state every time.",
+		},
 		&support.ConfigOption{
 			Name:        "ingest-enable-extended-log-ledger-stats",
 			ConfigKey:   &config.IngestEnableExtendedLogLedgerStats,
@@ -566,6 +666,91 @@ func Flags() (*Config, support.ConfigOptions) {
 			FlagDefault: false,
 			Usage:       "enables extended ledger stats in the log (ledger entry changes and operations stats)",
 		},
+		&support.ConfigOption{
+			Name:      IngestHistorySinkURLFlagName,
+			ConfigKey: &config.IngestHistorySinkURL,
+			OptType:   types.String,
+			Required:  false,
+			Usage: "file:// URL of a directory where the ingested ledgers, transactions, operations, effects and trades " +
+				"are appended as newline delimited JSON logs, one per kind of row, in addition to being written to the " +
+				"history tables",
+		},
+		&support.ConfigOption{
+			Name:        IngestLedgerEntryChangesFlagName,
+			OptType:     types.String,
+			FlagDefault: "",
+			Required:    false,
+			Usage: "comma separated list of the types of the ledger entries whose raw changes are stored and served by " +
+				"/ledger_entry_changes (account, trustline, offer, data, claimable_balance, liquidity_pool, contract_data, " +
+				"contract_code, config_setting, expiration)",
+			CustomSetValue: func(opt *support.ConfigOption) error {
+				entryTypes, err := parseLedgerEntryTypes(viper.GetString(opt.Name))
+				if err != nil {
+					return fmt.Errorf("invalid %s: %v", opt.Name, err)
+				}
+				config.IngestLedgerEntryChanges = entryTypes
+				return nil
+			},
+		},
+		&support.ConfigOption{
+			Name:           HistoryGapDetectionIntervalFlagName,
+			ConfigKey:      &config.HistoryGapDetectionInterval,
+			OptType:        types.Int,
+			FlagDefault:    0,
+			CustomSetValue: support.SetDurationMinutes,
+			Usage: "defines in minutes how often ingesting instances check history for gaps and export them " +
+				"in the orbitr_ingest_history_gaps metric. A value of 0 disables gap detection.",
+		},
+		&support.ConfigOption{
+			Name:        HistoryGapFillingFlagName,
+			ConfigKey:   &config.HistoryGapFilling,
+			OptType:     types.Bool,
+			FlagDefault: false,
+			Usage: "reingests the gaps detected in history in the background, requires captive core ingestion and --" +
+				HistoryGapDetectionIntervalFlagName + ". When several instances are ingesting, enable --" +
+				IngestLeaderElectionFlagName + " so that only the leader detects and reingests gaps",
+		},
+		&support.ConfigOption{
+			Name:        HistoryGapFillingMaxLedgersFlagName,
+			ConfigKey:   &config.HistoryGapFillingMaxLedgers,
+			OptType:     types.Uint,
+			FlagDefault: uint(10000),
+			Usage: "the maximum number of ledgers reingested every gap detection interval, " +
+				"it limits the load gap filling puts on live ingestion. 0 signifies no limit",
+		},
+		&support.ConfigOption{
+			Name:        IngestLeaderElectionFlagName,
+			ConfigKey:   &config.IngestLeaderElection,
+			OptType:     types.Bool,
+			FlagDefault: false,
+			Usage: "only lets the instance holding the ingestion lease ingest ledgers. The other ingesting instances " +
+				"do not read ledgers from their ledger backend, they follow the last ledger ingested by the leader " +
+				"and take over when the leader stops renewing the lease or hands it off",
+		},
+		&support.ConfigOption{
+			Name:        IngestLeaderIDFlagName,
+			ConfigKey:   &config.IngestLeaderID,
+			OptType:     types.String,
+			FlagDefault: "",
+			Usage:       "identity of this instance in the ingestion lease, must be unique among ingesting instances. Defaults to the host name",
+		},
+		&support.ConfigOption{
+			Name:           "ingest-leader-lease-ttl",
+			ConfigKey:      &config.IngestLeaderLeaseTTL,
+			OptType:        types.Int,
+			FlagDefault:    30,
+			CustomSetValue: support.SetDuration,
+			Usage:          "defines how long (in seconds) the ingestion lease is held without being renewed, it is renewed every third of this duration",
+		},
+		&support.ConfigOption{
+			Name:        "archive-state-cache-size",
+			ConfigKey:   &config.ArchiveStateCacheSize,
+			OptType:     types.Uint,
+			FlagDefault: uint(0),
+			Usage: "enables the admin endpoint looking up accounts and trust lines at past checkpoints in the " +
+				"history archives, caching bucket indexes in at most this many megabytes of memory. The indexed " +
+				"entries of the cached buckets are stored in a temporary directory. 0 disables the endpoint",
+		},
 		&support.ConfigOption{
 			Name:        "apply-migrations",
 			ConfigKey:   &config.ApplyMigrations,
@@ -599,7 +784,7 @@ func Flags() (*Config, support.ConfigOptions) {
 			Usage:       "determines if OrbitR instance is behind AWS load balances like ELB or ALB, in such case client IP in the logs will be replaced with the last IP in X-Forwarded-For header (cannot be used with --behind-cloudflare)",
 		},
 		&support.ConfigOption{
-			Name:        "rounding-slippage-filter",
+			Name:        RoundingSlippageFilterFlagName,
 			ConfigKey:   &config.RoundingSlippageFilter,
 			OptType:     types.Int,
 			FlagDefault: 1000,
@@ -828,10 +1013,39 @@ func ApplyFlags(config *Config, flags support.ConfigOptions, options ApplyOption
 		return err
 	}
 
+	if config.RoDatabaseURL != "" && len(config.ReplicaDatabaseURLs) > 0 {
+		return fmt.Errorf("invalid config: --ro-database-url and --%s cannot be used together", ReplicaDatabaseURLsFlagName)
+	}
+
 	if options.AlwaysIngest {
 		config.Ingest = true
 	}
 
+	if config.HistoryGapFilling {
+		if config.HistoryGapDetectionInterval == 0 {
+			return fmt.Errorf("invalid config: --%s requires --%s", HistoryGapFillingFlagName, HistoryGapDetectionIntervalFlagName)
+		}
+		if !config.Ingest || !config.EnableCaptiveCoreIngestion {
+			return fmt.Errorf("invalid config: --%s requires captive core ingestion", HistoryGapFillingFlagName)
+		}
+	}
+
+	if config.IngestLeaderElection {
+		if !config.Ingest {
+			return fmt.Errorf("invalid config: --%s requires --ingest", IngestLeaderElectionFlagName)
+		}
+		if config.IngestLeaderLeaseTTL < 3*time.Second {
+			return fmt.Errorf("invalid config: ingest-leader-lease-ttl must be at least 3 seconds")
+		}
+		if config.IngestLeaderID == "" {
+			hostname, err := os.Hostname()
+			if err != nil {
+				return fmt.Errorf("cannot get host name, set --%s: %v", IngestLeaderIDFlagName, err)
+			}
+			config.IngestLeaderID = hostname
+		}
+	}
+
 	if config.Ingest {
 		// Migrations should be checked as early as possible. Apply and check
 		// only on ingesting instances which are required to have write-access
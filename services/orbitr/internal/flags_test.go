@@ -6,6 +6,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/lantah/go/xdr"
 )
 
 func Test_createCaptiveCoreDefaultConfig(t *testing.T) {
@@ -131,3 +133,20 @@ func Test_createCaptiveCoreConfig(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseLedgerEntryTypes(t *testing.T) {
+	entryTypes, err := parseLedgerEntryTypes("account, trustline,,contract_data,account")
+	require.NoError(t, err)
+	assert.Equal(t, []xdr.LedgerEntryType{
+		xdr.LedgerEntryTypeAccount,
+		xdr.LedgerEntryTypeTrustline,
+		xdr.LedgerEntryTypeContractData,
+	}, entryTypes)
+
+	entryTypes, err = parseLedgerEntryTypes("")
+	require.NoError(t, err)
+	assert.Empty(t, entryTypes)
+
+	_, err = parseLedgerEntryTypes("account,trustlines")
+	assert.EqualError(t, err, `unknown ledger entry type "trustlines"`)
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/lantah/go/protocols/gravity"
+	"github.com/lantah/go/services/orbitr/internal/ingest"
 	"github.com/lantah/go/support/clock"
 	"github.com/lantah/go/support/db"
 	"github.com/lantah/go/support/log"
@@ -50,16 +51,30 @@ func newHealthCache(ttl time.Duration) *healthCache {
 }
 
 type healthCheck struct {
-	session db.SessionInterface
-	ctx     context.Context
-	core    gravityClient
-	cache   *healthCache
+	session       db.SessionInterface
+	ctx           context.Context
+	core          gravityClient
+	cache         *healthCache
+	leaderElector *ingest.LeaderElector
 }
 
 type healthResponse struct {
 	DatabaseConnected bool `json:"database_connected"`
 	CoreUp            bool `json:"core_up"`
 	CoreSynced        bool `json:"core_synced"`
+	// IngestionLeader is only reported when ingestion leader election is
+	// enabled. It does not affect the status code: instances which are not
+	// leading are healthy.
+	IngestionLeader *ingestionLeaderHealth `json:"ingestion_leader,omitempty"`
+}
+
+type ingestionLeaderHealth struct {
+	Identity     string    `json:"identity"`
+	IsLeader     bool      `json:"is_leader"`
+	LeaderID     string    `json:"leader_id,omitempty"`
+	FencingToken int64     `json:"fencing_token,omitempty"`
+	HeartbeatAt  time.Time `json:"heartbeat_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
 }
 
 func (h healthCheck) runCheck() healthResponse {
@@ -79,6 +94,17 @@ func (h healthCheck) runCheck() healthResponse {
 	} else {
 		response.CoreSynced = resp.IsSynced()
 	}
+	if h.leaderElector != nil {
+		status := h.leaderElector.Status()
+		response.IngestionLeader = &ingestionLeaderHealth{
+			Identity:     status.Identity,
+			IsLeader:     status.IsLeader,
+			LeaderID:     status.Lease.LeaderID,
+			FencingToken: status.Lease.FencingToken,
+			HeartbeatAt:  status.Lease.HeartbeatAt,
+			ExpiresAt:    status.Lease.ExpiresAt,
+		}
+	}
 
 	return response
 }
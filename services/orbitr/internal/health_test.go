@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/lantah/go/protocols/gravity"
+	"github.com/lantah/go/services/orbitr/internal/ingest"
 	"github.com/lantah/go/support/clock"
 	"github.com/lantah/go/support/clock/clocktest"
 	"github.com/lantah/go/support/db"
@@ -146,6 +147,36 @@ func TestHealthCheck(t *testing.T) {
 	}
 }
 
+func TestHealthCheckIngestionLeader(t *testing.T) {
+	ctx := context.Background()
+	session := &db.MockSession{}
+	session.On("Ping", ctx, dbPingTimeout).Return(nil).Once()
+	core := &mockGravity{}
+	synced := &gravity.InfoResponse{}
+	synced.Info.State = "Synced!"
+	core.On("Info", ctx).Return(synced, nil).Once()
+
+	h := healthCheck{
+		session: session,
+		ctx:     ctx,
+		core:    core,
+		cache:   newHealthCache(healthCacheTTL),
+		leaderElector: ingest.NewLeaderElector(ingest.LeaderElectorConfig{
+			Identity: "orbitr-1",
+			LeaseTTL: 30 * time.Second,
+		}),
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, nil)
+	// instances which are not leading are healthy
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response healthResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, &ingestionLeaderHealth{Identity: "orbitr-1"}, response.IngestionLeader)
+}
+
 func TestHealthCheckCache(t *testing.T) {
 	cachedResponse := healthResponse{
 		DatabaseConnected: false,
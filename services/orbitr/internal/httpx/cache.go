@@ -0,0 +1,69 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lantah/go/services/orbitr/internal/actions"
+	"github.com/lantah/go/support/render/httpjson"
+)
+
+// CachePolicy determines the caching headers sent along successful JSON
+// responses.
+type CachePolicy int
+
+const (
+	// NoCache keeps the headers set by requestCacheHeadersMiddleware and does
+	// not send an ETag.
+	NoCache CachePolicy = iota
+	// StateCache is used for resources reflecting the latest ingested ledger.
+	// They can be cached for about the time it takes to close a ledger.
+	StateCache
+	// HistoryCache is used for history resources which rarely change once they
+	// have been ingested. They still change when history is reaped, restored
+	// or reingested, so they are only cached for a bounded time.
+	HistoryCache
+)
+
+const (
+	stateCacheMaxAge   = 5 * time.Second
+	historyCacheMaxAge = 10 * time.Minute
+)
+
+func (p CachePolicy) cacheControl() string {
+	switch p {
+	case StateCache:
+		return fmt.Sprintf("public, max-age=%d", int(stateCacheMaxAge.Seconds()))
+	case HistoryCache:
+		return fmt.Sprintf("public, max-age=%d", int(historyCacheMaxAge.Seconds()))
+	default:
+		return ""
+	}
+}
+
+// pagePolicy returns the policy to use for a page of records rendered by a
+// handler configured with p. A history page is only final once it is full and
+// starts at an explicit cursor: otherwise records ingested later (or, for the
+// `now` cursor, the passing of time) can change its contents.
+func (p CachePolicy) pagePolicy(r *http.Request, records, limit int) CachePolicy {
+	if p != HistoryCache {
+		return p
+	}
+	cursor := r.URL.Query().Get(actions.ParamCursor)
+	if cursor == "" || cursor == "now" || records < limit {
+		return StateCache
+	}
+	return HistoryCache
+}
+
+// renderCacheable renders data as HAL JSON with the caching headers of
+// policy.
+func renderCacheable(w http.ResponseWriter, r *http.Request, data interface{}, policy CachePolicy) {
+	if policy != NoCache {
+		// The same URL can also be streamed, which must not be served from a
+		// cached JSON response.
+		w.Header().Add("Vary", "Accept")
+	}
+	httpjson.RenderCacheable(w, r, data, httpjson.HALJSON, policy.cacheControl())
+}
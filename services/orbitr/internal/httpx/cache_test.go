@@ -0,0 +1,106 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lantah/go/services/orbitr/internal/actions"
+	"github.com/lantah/go/services/orbitr/internal/ledger"
+	"github.com/lantah/go/services/orbitr/internal/render/sse"
+)
+
+type staticObjectAction struct {
+	value string
+}
+
+func (action staticObjectAction) GetResource(
+	w actions.HeaderWriter,
+	r *http.Request,
+) (interface{}, error) {
+	return map[string]string{"value": action.value}, nil
+}
+
+func serveCached(handler http.Handler, request *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	requestCacheHeadersMiddleware(handler).ServeHTTP(w, request)
+	return w
+}
+
+func TestObjectActionHandlerCacheHeaders(t *testing.T) {
+	t.Run("no cache", func(t *testing.T) {
+		handler := ObjectActionHandler{Action: staticObjectAction{"a"}}
+		w := serveCached(handler, streamRequest(t, ""))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-cache, no-store, max-age=0", w.Header().Get("Cache-Control"))
+		assert.Empty(t, w.Header().Get("ETag"))
+	})
+
+	t.Run("history", func(t *testing.T) {
+		handler := ObjectActionHandler{Action: staticObjectAction{"a"}, Cache: HistoryCache}
+		w := serveCached(handler, streamRequest(t, ""))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "public, max-age=600", w.Header().Get("Cache-Control"))
+		assert.Equal(t, "Accept", w.Header().Get("Vary"))
+		etag := w.Header().Get("ETag")
+		assert.Regexp(t, `^"[0-9a-f]{64}"$`, etag)
+
+		other := serveCached(ObjectActionHandler{Action: staticObjectAction{"b"}, Cache: HistoryCache}, streamRequest(t, ""))
+		assert.NotEqual(t, etag, other.Header().Get("ETag"))
+
+		for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+			request := streamRequest(t, "")
+			request.Header.Set("If-None-Match", ifNoneMatch)
+			w = serveCached(handler, request)
+			assert.Equal(t, http.StatusNotModified, w.Code, ifNoneMatch)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			assert.Empty(t, w.Body.String())
+		}
+
+		request := streamRequest(t, "")
+		request.Header.Set("If-None-Match", `"other"`)
+		w = serveCached(handler, request)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, w.Body.String())
+	})
+
+	t.Run("state", func(t *testing.T) {
+		handler := ObjectActionHandler{Action: staticObjectAction{"a"}, Cache: StateCache}
+		w := serveCached(handler, streamRequest(t, ""))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "public, max-age=5", w.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+	})
+}
+
+func TestHistoryPageCacheHeaders(t *testing.T) {
+	action := &testPageAction{
+		objects: map[uint32][]string{
+			3: {"a", "b", "c"},
+		},
+		ledgerSource: ledger.NewTestingSource(3),
+	}
+	handler := streamableHistoryPageHandler(&ledger.State{}, action, sse.StreamHandler{})
+
+	for _, testCase := range []struct {
+		query        string
+		cacheControl string
+	}{
+		// the first page changes as history is reaped
+		{"limit=2", "public, max-age=5"},
+		// a partial page can still grow
+		{"limit=5&cursor=1", "public, max-age=5"},
+		{"limit=2&cursor=1", "public, max-age=600"},
+	} {
+		w := serveCached(handler, streamRequest(t, testCase.query))
+		assert.Equal(t, http.StatusOK, w.Code, testCase.query)
+		assert.Equal(t, testCase.cacheControl, w.Header().Get("Cache-Control"), testCase.query)
+		assert.NotEmpty(t, w.Header().Get("ETag"), testCase.query)
+	}
+
+	handler = streamableStatePageHandler(&ledger.State{}, action, sse.StreamHandler{})
+	w := serveCached(handler, streamRequest(t, "limit=2&cursor=1"))
+	assert.Equal(t, "public, max-age=5", w.Header().Get("Cache-Control"))
+}
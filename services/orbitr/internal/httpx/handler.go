@@ -14,7 +14,6 @@ import (
 	"github.com/lantah/go/support/db"
 	"github.com/lantah/go/support/errors"
 	"github.com/lantah/go/support/render/hal"
-	"github.com/lantah/go/support/render/httpjson"
 	"github.com/lantah/go/support/render/problem"
 )
 
@@ -27,6 +26,8 @@ type objectAction interface {
 
 type ObjectActionHandler struct {
 	Action objectAction
+	// Cache determines the caching headers sent along the resource.
+	Cache CachePolicy
 }
 
 func (handler ObjectActionHandler) ServeHTTP(
@@ -41,11 +42,7 @@ func (handler ObjectActionHandler) ServeHTTP(
 			return
 		}
 
-		httpjson.Render(
-			w,
-			response,
-			httpjson.HALJSON,
-		)
+		renderCacheable(w, r, response, handler.Cache)
 		return
 	}
 
@@ -79,11 +76,7 @@ func (handler streamableObjectActionHandler) ServeHTTP(
 			return
 		}
 
-		httpjson.Render(
-			w,
-			response,
-			httpjson.HALJSON,
-		)
+		renderCacheable(w, r, response, StateCache)
 		return
 	case render.MimeEventStream:
 		handler.renderStream(w, r)
@@ -157,10 +150,11 @@ type pageActionHandler struct {
 	streamHandler  sse.StreamHandler
 	repeatableRead bool
 	ledgerState    *ledger.State
+	cache          CachePolicy
 }
 
 func restPageHandler(ledgerState *ledger.State, action pageAction) pageActionHandler {
-	return pageActionHandler{action: action, ledgerState: ledgerState}
+	return pageActionHandler{action: action, ledgerState: ledgerState, cache: StateCache}
 }
 
 // streamableStatePageHandler creates a streamable page handler than generates
@@ -176,6 +170,7 @@ func streamableStatePageHandler(
 		streamable:     true,
 		streamHandler:  streamHandler,
 		repeatableRead: true,
+		cache:          StateCache,
 	}
 }
 
@@ -192,6 +187,7 @@ func streamableHistoryPageHandler(
 		streamable:     true,
 		streamHandler:  streamHandler,
 		repeatableRead: false,
+		cache:          HistoryCache,
 	}
 }
 
@@ -209,11 +205,7 @@ func (handler pageActionHandler) renderPage(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	httpjson.Render(
-		w,
-		page,
-		httpjson.HALJSON,
-	)
+	renderCacheable(w, r, page, handler.cache.pagePolicy(r, len(records), int(page.Limit)))
 }
 
 func (handler pageActionHandler) renderStream(w http.ResponseWriter, r *http.Request) {
@@ -233,6 +233,13 @@ func recoverMiddleware(h http.Handler) http.Handler {
 // is not in a stale state, which is when the difference between latest core
 // ledger and latest history ledger is higher than the given threshold
 func NewHistoryMiddleware(ledgerState *ledger.State, staleThreshold int32, session db.SessionInterface) func(http.Handler) http.Handler {
+	return newHistoryMiddleware(ledgerState, staleThreshold, session, nil)
+}
+
+// newHistoryMiddleware is NewHistoryMiddleware which, when replicas is not
+// nil, serves the request from a read replica which has caught up with the
+// latest history ledger.
+func newHistoryMiddleware(ledgerState *ledger.State, staleThreshold int32, session db.SessionInterface, replicas *ReplicaRouter) func(http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -255,7 +262,11 @@ func NewHistoryMiddleware(ledgerState *ledger.State, staleThreshold int32, sessi
 				}
 			}
 
-			requestSession := session.Clone()
+			requestSession := session
+			if replicas != nil {
+				requestSession = replicas.Session(uint32(ledgerState.CurrentStatus().HistoryLatest))
+			}
+			requestSession = requestSession.Clone()
 			h.ServeHTTP(w, r.WithContext(
 				context.WithValue(
 					ctx,
@@ -275,6 +286,10 @@ func NewHistoryMiddleware(ledgerState *ledger.State, staleThreshold int32, sessi
 type StateMiddleware struct {
 	OrbitRSession      db.SessionInterface
 	NoStateVerification bool
+	// Replicas, when set, routes the request to a read replica which has
+	// caught up with the latest history ledger.
+	Replicas    *ReplicaRouter
+	LedgerState *ledger.State
 }
 
 func ingestionStatus(ctx context.Context, q *history.Q) (uint32, bool, error) {
@@ -313,7 +328,11 @@ func (m *StateMiddleware) WrapFunc(h http.HandlerFunc) http.HandlerFunc {
 		if chiRoute != nil {
 			ctx = context.WithValue(ctx, &db.RouteContextKey, sanitizeMetricRoute(chiRoute.RoutePattern()))
 		}
-		session := m.OrbitRSession.Clone()
+		session := m.OrbitRSession
+		if m.Replicas != nil {
+			session = m.Replicas.Session(uint32(m.LedgerState.CurrentStatus().HistoryLatest))
+		}
+		session = session.Clone()
 		q := &history.Q{session}
 		sseRequest := render.Negotiate(r) == render.MimeEventStream
 
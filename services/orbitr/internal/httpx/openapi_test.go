@@ -0,0 +1,242 @@
+package httpx
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	orbitr "github.com/lantah/go/protocols/orbitr"
+	"github.com/lantah/go/protocols/orbitr/base"
+	"github.com/lantah/go/protocols/orbitr/effects"
+	"github.com/lantah/go/protocols/orbitr/operations"
+	"github.com/lantah/go/services/orbitr/internal/ledger"
+	"github.com/lantah/go/services/orbitr/internal/paths"
+	"github.com/lantah/go/support/render/hal"
+	"github.com/lantah/go/support/render/problem"
+)
+
+type openAPISchema struct {
+	Ref                  string                    `yaml:"$ref"`
+	AllOf                []*openAPISchema          `yaml:"allOf"`
+	Properties           map[string]*openAPISchema `yaml:"properties"`
+	Items                *openAPISchema            `yaml:"items"`
+	AdditionalProperties interface{}               `yaml:"additionalProperties"`
+	Discriminator        struct {
+		Mapping map[string]string `yaml:"mapping"`
+	} `yaml:"discriminator"`
+}
+
+type openAPISpec struct {
+	Paths      map[string]map[string]interface{} `yaml:"paths"`
+	Components struct {
+		Schemas map[string]*openAPISchema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+func loadOpenAPISpec(t *testing.T) *openAPISpec {
+	contents, err := staticFiles.ReadFile("static/orbitr_oapi.yml")
+	require.NoError(t, err)
+	var spec openAPISpec
+	require.NoError(t, yaml.Unmarshal(contents, &spec))
+	return &spec
+}
+
+func (s *openAPISpec) resolve(schema *openAPISchema) *openAPISchema {
+	for schema != nil && schema.Ref != "" {
+		schema = s.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// property looks up a property of schema, following references and allOf
+// compositions.
+func (s *openAPISpec) property(schema *openAPISchema, name string) *openAPISchema {
+	schema = s.resolve(schema)
+	if schema == nil {
+		return nil
+	}
+	if property, ok := schema.Properties[name]; ok {
+		return property
+	}
+	for _, part := range schema.AllOf {
+		if property := s.property(part, name); property != nil {
+			return property
+		}
+	}
+	return nil
+}
+
+// documentedPackages lists the packages whose structs are described field by
+// field in the specification. Structs from other packages (timestamps, XDR
+// values) are documented as opaque values.
+var documentedPackages = []string{
+	"github.com/lantah/go/protocols/orbitr",
+	"github.com/lantah/go/support/render",
+}
+
+func isDocumentedStruct(typ reflect.Type) bool {
+	for _, pkg := range documentedPackages {
+		if strings.HasPrefix(typ.PkgPath(), pkg) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFields asserts that every json field of typ is documented in schema,
+// descending into nested structs, slices and maps.
+func (s *openAPISpec) checkFields(t *testing.T, path string, typ reflect.Type, schema *openAPISchema) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	schema = s.resolve(schema)
+	if !assert.NotNil(t, schema, "no schema for %s", path) {
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		if assert.NotNil(t, schema.Items, "no items schema for %s", path) {
+			s.checkFields(t, path+"[]", typ.Elem(), schema.Items)
+		}
+		return
+	case reflect.Map:
+		if elem, ok := schema.AdditionalProperties.(map[string]interface{}); ok && typ.Elem().Kind() != reflect.Interface {
+			encoded, err := yaml.Marshal(elem)
+			require.NoError(t, err)
+			var elemSchema openAPISchema
+			require.NoError(t, yaml.Unmarshal(encoded, &elemSchema))
+			s.checkFields(t, path+"{}", typ.Elem(), &elemSchema)
+		}
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+	if !isDocumentedStruct(typ) {
+		return
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" {
+			s.checkFields(t, path, field.Type, schema)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = field.Name
+		}
+		property := s.property(schema, name)
+		if assert.NotNil(t, property, "field %s.%s is missing from the OpenAPI specification", path, name) {
+			s.checkFields(t, path+"."+name, field.Type, property)
+		}
+	}
+}
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	spec := loadOpenAPISpec(t)
+
+	router, err := NewRouter(
+		&RouterConfig{
+			PathFinder:         &paths.MockFinder{},
+			PrometheusRegistry: prometheus.NewRegistry(),
+			FriendbotURL:       &url.URL{Scheme: "https", Host: "friendbot.example.com"},
+			HealthCheck:        http.NotFoundHandler(),
+		},
+		&ServerMetrics{},
+		&ledger.State{},
+	)
+	require.NoError(t, err)
+
+	err = chi.Walk(router.Mux, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		// chi.Walk leaves the wildcards of mounted subrouters in the pattern
+		route = strings.ReplaceAll(sanitizeMetricRoute(route), "/*/", "/")
+		route = strings.TrimSuffix(strings.TrimSuffix(route, "/*"), "/")
+		if route == "" {
+			route = "/"
+		}
+		operations, ok := spec.Paths[route]
+		if assert.True(t, ok, "route %s is missing from the OpenAPI specification", route) {
+			_, ok = operations[strings.ToLower(method)]
+			assert.True(t, ok, "%s %s is missing from the OpenAPI specification", method, route)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestOpenAPISpecCoversResources(t *testing.T) {
+	spec := loadOpenAPISpec(t)
+
+	for name, resource := range map[string]interface{}{
+		"Link":                 hal.Link{},
+		"PageLinks":            hal.Links{},
+		"Asset":                base.Asset{},
+		"Price":                base.Price{},
+		"AssetAmount":          base.AssetAmount{},
+		"LiquidityPoolOrAsset": base.LiquidityPoolOrAsset{},
+		"Problem":              problem.P{},
+		"Root":                 orbitr.Root{},
+		"Account":              orbitr.Account{},
+		"AccountData":          orbitr.AccountData{},
+		"AssetStat":            orbitr.AssetStat{},
+		"ClaimableBalance":     orbitr.ClaimableBalance{},
+		"LedgerEntryChange":    orbitr.LedgerEntryChange{},
+		"LiquidityPool":        orbitr.LiquidityPool{},
+		"Offer":                orbitr.Offer{},
+		"OrderBookSummary":     orbitr.OrderBookSummary{},
+		"Path":                 orbitr.Path{},
+		"Ledger":               orbitr.Ledger{},
+		"Transaction":          orbitr.Transaction{},
+		"Trade":                orbitr.Trade{},
+		"TradeAggregation":     orbitr.TradeAggregation{},
+		"FeeStats":             orbitr.FeeStats{},
+		"Operation":            operations.Base{},
+		"Effect":               effects.Base{},
+	} {
+		schema, ok := spec.Components.Schemas[name]
+		if assert.True(t, ok, "schema %s is missing from the OpenAPI specification", name) {
+			spec.checkFields(t, name, reflect.TypeOf(resource), schema)
+		}
+	}
+
+	operationSchema := spec.Components.Schemas["Operation"]
+	require.NotNil(t, operationSchema)
+	for operationType, typeName := range operations.TypeNames {
+		operation, err := operations.UnmarshalOperation(int32(operationType), []byte("{}"))
+		require.NoError(t, err)
+		schema := operationSchema
+		if ref, ok := operationSchema.Discriminator.Mapping[typeName]; ok {
+			schema = &openAPISchema{Ref: ref}
+		}
+		spec.checkFields(t, typeName, reflect.TypeOf(operation), schema)
+	}
+
+	effectSchema := spec.Components.Schemas["Effect"]
+	require.NotNil(t, effectSchema)
+	for _, typeName := range effects.EffectTypeNames {
+		effect, err := effects.UnmarshalEffect(typeName, []byte("{}"))
+		require.NoError(t, err)
+		schema := effectSchema
+		if ref, ok := effectSchema.Discriminator.Mapping[typeName]; ok {
+			schema = &openAPISchema{Ref: ref}
+		}
+		spec.checkFields(t, typeName, reflect.TypeOf(effect), schema)
+	}
+}
@@ -0,0 +1,181 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/support/db"
+	"github.com/lantah/go/support/log"
+)
+
+// replicaPollInterval is how often the last ledger replayed by each replica
+// is checked.
+const replicaPollInterval = time.Second
+
+// Replica is a read replica of the OrbitR database.
+type Replica struct {
+	// Name identifies the replica in metrics and logs.
+	Name    string
+	Session db.SessionInterface
+
+	// replayed is the last ledger the replica has replayed when it was last
+	// polled, or 0 if it could not be polled.
+	replayed uint32
+}
+
+// ReplicaRouter routes read-only API queries to read replicas. A replica is
+// only used when the last ledger it has replayed (as stored in the key_value
+// store) is at least the ledger requested, otherwise queries fall back to the
+// primary database. The last ledger of the primary database and of each
+// replica is polled in the background by Run so that routing a request does
+// not query the replicas.
+type ReplicaRouter struct {
+	primary  db.SessionInterface
+	replicas []*Replica
+	next     uint32
+	// latest is the last ledger ingested in the primary database when it
+	// was last polled, or 0 if it could not be polled.
+	latest uint32
+
+	lagGauge         *prometheus.GaugeVec
+	fallbacksCounter prometheus.Counter
+}
+
+// NewReplicaRouter constructs a ReplicaRouter which falls back to primary
+// when none of the given replica sessions has caught up. Replicas are named
+// after their position in the list.
+func NewReplicaRouter(primary db.SessionInterface, replicaSessions []db.SessionInterface) *ReplicaRouter {
+	replicas := make([]*Replica, 0, len(replicaSessions))
+	for i, session := range replicaSessions {
+		replicas = append(replicas, &Replica{
+			Name:    fmt.Sprintf("replica_%d", i),
+			Session: session,
+		})
+	}
+
+	return &ReplicaRouter{
+		primary:  primary,
+		replicas: replicas,
+		lagGauge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "orbitr", Subsystem: "db", Name: "replica_lag_ledgers",
+				Help: "Number of ledgers a read replica was behind the primary database when last polled",
+			},
+			[]string{"replica"},
+		),
+		fallbacksCounter: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: "orbitr", Subsystem: "db", Name: "replica_fallbacks_total",
+				Help: "Number of requests served from the primary database because no read replica had caught up",
+			},
+		),
+	}
+}
+
+// RegisterMetrics registers the replica lag and fallback metrics.
+func (r *ReplicaRouter) RegisterMetrics(registry *prometheus.Registry) {
+	registry.MustRegister(r.lagGauge)
+	registry.MustRegister(r.fallbacksCounter)
+}
+
+// Run polls the last ledger ingested in the primary database and replayed by
+// each replica until ctx is done. Each database is polled independently so
+// that a slow replica does not delay the others.
+func (r *ReplicaRouter) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		pollEvery(ctx, r.pollPrimary)
+	}()
+	for _, replica := range r.replicas {
+		wg.Add(1)
+		go func(replica *Replica) {
+			defer wg.Done()
+			pollEvery(ctx, func(ctx context.Context) { r.poll(ctx, replica) })
+		}(replica)
+	}
+	wg.Wait()
+}
+
+// pollEvery calls poll every replicaPollInterval until ctx is done.
+func pollEvery(ctx context.Context, poll func(ctx context.Context)) {
+	ticker := time.NewTicker(replicaPollInterval)
+	defer ticker.Stop()
+	for {
+		poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lastLedger returns the last ledger ingested in the database of session, or
+// 0 if it could not be queried.
+func lastLedger(ctx context.Context, session db.SessionInterface, name string) uint32 {
+	ctx, cancel := context.WithTimeout(ctx, replicaPollInterval)
+	defer cancel()
+
+	q := &history.Q{SessionInterface: session.Clone()}
+	ledger, err := q.GetLastLedgerIngestNonBlocking(ctx)
+	if err != nil {
+		if ctx.Err() != context.Canceled {
+			log.Ctx(ctx).WithField("db", name).WithError(err).
+				Warn("could not get last ingested ledger")
+		}
+		return 0
+	}
+	return ledger
+}
+
+func (r *ReplicaRouter) pollPrimary(ctx context.Context) {
+	atomic.StoreUint32(&r.latest, lastLedger(ctx, r.primary, "primary"))
+}
+
+// poll updates the last ledger replayed by replica and, if the primary
+// database has been polled, its lag.
+func (r *ReplicaRouter) poll(ctx context.Context, replica *Replica) {
+	replayed := lastLedger(ctx, replica.Session, replica.Name)
+	atomic.StoreUint32(&replica.replayed, replayed)
+
+	latest := atomic.LoadUint32(&r.latest)
+	if replayed == 0 || latest == 0 {
+		return
+	}
+	lag := 0
+	if replayed < latest {
+		lag = int(latest - replayed)
+	}
+	r.lagGauge.With(prometheus.Labels{"replica": replica.Name}).Set(float64(lag))
+}
+
+// Session returns the session which should serve read-only queries requiring
+// data up to (and including) the given ledger. Replicas are tried in round
+// robin order so that the load is spread between them.
+func (r *ReplicaRouter) Session(ledger uint32) db.SessionInterface {
+	if len(r.replicas) > 0 {
+		start := atomic.AddUint32(&r.next, 1)
+		for i := 0; i < len(r.replicas); i++ {
+			replica := r.replicas[(int(start)+i)%len(r.replicas)]
+			if r.caughtUp(replica, ledger) {
+				return replica.Session
+			}
+		}
+	}
+
+	r.fallbacksCounter.Inc()
+	return r.primary
+}
+
+func (r *ReplicaRouter) caughtUp(replica *Replica, ledger uint32) bool {
+	replayed := atomic.LoadUint32(&replica.replayed)
+	return replayed > 0 && replayed >= ledger
+}
@@ -0,0 +1,121 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lantah/go/support/db"
+)
+
+func replicaSession(lastLedger string, err error) *db.MockSession {
+	session := &db.MockSession{}
+	session.On("Clone").Return(session)
+	session.On("Get", mock.Anything, mock.AnythingOfType("*string"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(1).(*string) = lastLedger
+		}).
+		Return(err)
+	return session
+}
+
+// pollAll polls the primary database and every replica of router once, as
+// Run does in the background.
+func pollAll(ctx context.Context, router *ReplicaRouter) {
+	router.pollPrimary(ctx)
+	for _, replica := range router.replicas {
+		router.poll(ctx, replica)
+	}
+}
+
+func TestReplicaRouter(t *testing.T) {
+	ctx := context.Background()
+	primary := replicaSession("100", nil)
+
+	t.Run("caught up replica", func(t *testing.T) {
+		replica := replicaSession("100", nil)
+		router := NewReplicaRouter(primary, []db.SessionInterface{replica})
+		pollAll(ctx, router)
+		assert.Same(t, replica, router.Session(100))
+		assert.Equal(t, float64(0), testutil.ToFloat64(router.lagGauge.With(prometheus.Labels{"replica": "replica_0"})))
+		assert.Equal(t, float64(0), testutil.ToFloat64(router.fallbacksCounter))
+	})
+
+	t.Run("lagging replica", func(t *testing.T) {
+		replica := replicaSession("97", nil)
+		router := NewReplicaRouter(primary, []db.SessionInterface{replica})
+		pollAll(ctx, router)
+		assert.Same(t, primary, router.Session(100))
+		assert.Equal(t, float64(3), testutil.ToFloat64(router.lagGauge.With(prometheus.Labels{"replica": "replica_0"})))
+		assert.Equal(t, float64(1), testutil.ToFloat64(router.fallbacksCounter))
+	})
+
+	t.Run("unavailable replica", func(t *testing.T) {
+		replica := replicaSession("", errors.New("connection refused"))
+		router := NewReplicaRouter(primary, []db.SessionInterface{replica})
+		pollAll(ctx, router)
+		assert.Same(t, primary, router.Session(100))
+		assert.Equal(t, float64(1), testutil.ToFloat64(router.fallbacksCounter))
+	})
+
+	t.Run("reports lag without requests", func(t *testing.T) {
+		replica := replicaSession("95", nil)
+		router := NewReplicaRouter(primary, []db.SessionInterface{replica})
+		pollAll(ctx, router)
+		assert.Equal(t, float64(5), testutil.ToFloat64(router.lagGauge.With(prometheus.Labels{"replica": "replica_0"})))
+		assert.Equal(t, float64(0), testutil.ToFloat64(router.fallbacksCounter))
+	})
+
+	t.Run("skips lagging replicas", func(t *testing.T) {
+		lagging := replicaSession("99", nil)
+		caughtUp := replicaSession("101", nil)
+		router := NewReplicaRouter(primary, []db.SessionInterface{lagging, caughtUp})
+		pollAll(ctx, router)
+		for i := 0; i < 4; i++ {
+			assert.Same(t, caughtUp, router.Session(100))
+		}
+		assert.Equal(t, float64(0), testutil.ToFloat64(router.fallbacksCounter))
+	})
+
+	t.Run("spreads load between replicas", func(t *testing.T) {
+		first := replicaSession("100", nil)
+		second := replicaSession("100", nil)
+		router := NewReplicaRouter(primary, []db.SessionInterface{first, second})
+		pollAll(ctx, router)
+		served := map[db.SessionInterface]int{}
+		for i := 0; i < 4; i++ {
+			served[router.Session(100)]++
+		}
+		assert.Equal(t, map[db.SessionInterface]int{first: 2, second: 2}, served)
+	})
+	t.Run("does not query replicas when routing", func(t *testing.T) {
+		// the mock fails if the replica is queried
+		replica := &db.MockSession{}
+		primary := &db.MockSession{}
+		router := NewReplicaRouter(primary, []db.SessionInterface{replica})
+		assert.Same(t, primary, router.Session(100))
+		replica.AssertExpectations(t)
+	})
+
+	t.Run("polls replicas in the background", func(t *testing.T) {
+		replica := replicaSession("100", nil)
+		router := NewReplicaRouter(primary, []db.SessionInterface{replica})
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			router.Run(runCtx)
+			close(done)
+		}()
+		assert.Eventually(t, func() bool {
+			return router.Session(100) == replica
+		}, time.Second, 10*time.Millisecond)
+		cancel()
+		<-done
+	})
+}
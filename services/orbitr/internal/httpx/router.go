@@ -32,6 +32,10 @@ type RouterConfig struct {
 	PrimaryDBSession db.SessionInterface
 	TxSubmitter      *txsub.System
 	RateQuota        *throttled.RateQuota
+	// Replicas routes read-only API queries to read replicas of DBSession
+	// once they have caught up with the requested ledger, queries are served
+	// by DBSession when it is nil.
+	Replicas *ReplicaRouter
 
 	BehindCloudflare         bool
 	BehindAWSLoadBalancer    bool
@@ -49,6 +53,15 @@ type RouterConfig struct {
 	HealthCheck              http.Handler
 	EnableIngestionFiltering bool
 	DisableTxSub             bool
+	// ReingestJobs runs the reingestion jobs enqueued with the admin API,
+	// the endpoints are disabled when it is nil.
+	ReingestJobs actions.ReingestJobManager
+	// ArchiveState looks up ledger entries at past checkpoints for the admin
+	// API, the endpoint is disabled when it is nil.
+	ArchiveState actions.ArchiveStateReader
+	// LeaderElector reports and hands off the ingestion lease for the admin
+	// API, the endpoints are disabled when it is nil.
+	LeaderElector actions.LeaderElector
 }
 
 type Router struct {
@@ -95,7 +108,7 @@ func (r *Router) addMiddleware(config *RouterConfig,
 	c := cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
 		AllowedHeaders: []string{"*"},
-		ExposedHeaders: []string{"Date", "Latest-Ledger"},
+		ExposedHeaders: []string{"Date", "Latest-Ledger", "ETag"},
 	})
 	r.Use(c.Handler)
 
@@ -130,11 +143,27 @@ func (r *Router) addMiddleware(config *RouterConfig,
 }
 
 func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRateLimiter, ledgerState *ledger.State) {
+	replicaRouter := config.Replicas
+	if replicaRouter != nil {
+		replicaRouter.RegisterMetrics(config.PrometheusRegistry)
+	}
+
 	stateMiddleware := StateMiddleware{
 		OrbitRSession: config.DBSession,
+		Replicas:      replicaRouter,
+		LedgerState:   ledgerState,
 	}
 
 	r.Method(http.MethodGet, "/health", config.HealthCheck)
+	r.Get("/openapi.yml", func(w http.ResponseWriter, r *http.Request) {
+		p, err := staticFiles.ReadFile("static/orbitr_oapi.yml")
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/openapi+yaml")
+		w.Write(p)
+	})
 
 	r.Method(http.MethodGet, "/", ObjectActionHandler{Action: actions.GetRootHandler{
 		LedgerState:       ledgerState,
@@ -149,7 +178,7 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		LedgerSourceFactory: historyLedgerSourceFactory{ledgerState: ledgerState, updateFrequency: config.SSEUpdateFrequency},
 	}
 
-	historyMiddleware := NewHistoryMiddleware(ledgerState, int32(config.StaleThreshold), config.DBSession)
+	historyMiddleware := newHistoryMiddleware(ledgerState, int32(config.StaleThreshold), config.DBSession, replicaRouter)
 	// State endpoints behind stateMiddleware
 	r.Group(func(r chi.Router) {
 		r.Route("/accounts", func(r chi.Router) {
@@ -174,13 +203,13 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 
 		r.Route("/claimable_balances", func(r chi.Router) {
 			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/", restPageHandler(ledgerState, actions.GetClaimableBalancesHandler{LedgerState: ledgerState}))
-			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/{id}", ObjectActionHandler{actions.GetClaimableBalanceByIDHandler{}})
+			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/{id}", ObjectActionHandler{Action: actions.GetClaimableBalanceByIDHandler{}, Cache: StateCache})
 		})
 
 		r.Route("/liquidity_pools", func(r chi.Router) {
 			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/", restPageHandler(ledgerState, actions.GetLiquidityPoolsHandler{LedgerState: ledgerState}))
 			r.Route("/{liquidity_pool_id:\\w+}", func(r chi.Router) {
-				r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/", ObjectActionHandler{actions.GetLiquidityPoolByIDHandler{}})
+				r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/", ObjectActionHandler{Action: actions.GetLiquidityPoolByIDHandler{}, Cache: StateCache})
 				r.With(historyMiddleware).Method(http.MethodGet, "/operations", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
 					LedgerState:  ledgerState,
 					OnlyPayments: false,
@@ -193,25 +222,25 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 
 		r.Route("/offers", func(r chi.Router) {
 			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/", restPageHandler(ledgerState, actions.GetOffersHandler{LedgerState: ledgerState}))
-			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/{offer_id}", ObjectActionHandler{actions.GetOfferByID{}})
+			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/{offer_id}", ObjectActionHandler{Action: actions.GetOfferByID{}, Cache: StateCache})
 		})
 
 		r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/assets", restPageHandler(ledgerState, actions.AssetStatsHandler{LedgerState: ledgerState}))
 
 		if config.PathFinder != nil {
-			findPaths := ObjectActionHandler{actions.FindPathsHandler{
+			findPaths := ObjectActionHandler{Action: actions.FindPathsHandler{
 				StaleThreshold:       config.StaleThreshold,
 				SetLastLedgerHeader:  true,
 				MaxPathLength:        config.MaxPathLength,
 				MaxAssetsParamLength: config.MaxAssetsPerPathRequest,
 				PathFinder:           config.PathFinder,
-			}}
-			findFixedPaths := ObjectActionHandler{actions.FindFixedPathsHandler{
+			}, Cache: StateCache}
+			findFixedPaths := ObjectActionHandler{Action: actions.FindFixedPathsHandler{
 				MaxPathLength:        config.MaxPathLength,
 				SetLastLedgerHeader:  true,
 				MaxAssetsParamLength: config.MaxAssetsPerPathRequest,
 				PathFinder:           config.PathFinder,
-			}}
+			}, Cache: StateCache}
 			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/paths", findPaths)
 			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/paths/strict-receive", findPaths)
 			r.With(stateMiddleware.Wrap).Method(http.MethodGet, "/paths/strict-send", findFixedPaths)
@@ -242,11 +271,23 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, streamHandler))
 		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/transactions", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, streamHandler))
 	})
+	// muxed account actions - history of a single muxed (M...) account
+	r.Group(func(r chi.Router) {
+		r.With(historyMiddleware).Method(http.MethodGet, "/muxed_accounts/{muxed_account_id:\\w+}/operations", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
+			LedgerState:  ledgerState,
+			OnlyPayments: false,
+		}, streamHandler))
+		r.With(historyMiddleware).Method(http.MethodGet, "/muxed_accounts/{muxed_account_id:\\w+}/payments", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
+			LedgerState:  ledgerState,
+			OnlyPayments: true,
+		}, streamHandler))
+		r.With(historyMiddleware).Method(http.MethodGet, "/muxed_accounts/{muxed_account_id:\\w+}/transactions", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, streamHandler))
+	})
 	// ledger actions
 	r.Route("/ledgers", func(r chi.Router) {
 		r.With(historyMiddleware).Method(http.MethodGet, "/", streamableHistoryPageHandler(ledgerState, actions.GetLedgersHandler{LedgerState: ledgerState}, streamHandler))
 		r.Route("/{ledger_id}", func(r chi.Router) {
-			r.With(historyMiddleware).Method(http.MethodGet, "/", ObjectActionHandler{actions.GetLedgerByIDHandler{LedgerState: ledgerState}})
+			r.With(historyMiddleware).Method(http.MethodGet, "/", ObjectActionHandler{Action: actions.GetLedgerByIDHandler{LedgerState: ledgerState}, Cache: HistoryCache})
 			r.With(historyMiddleware).Method(http.MethodGet, "/transactions", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, streamHandler))
 			r.Group(func(r chi.Router) {
 				r.With(historyMiddleware).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, streamHandler))
@@ -275,7 +316,7 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 	r.Route("/transactions", func(r chi.Router) {
 		r.With(historyMiddleware).Method(http.MethodGet, "/", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, streamHandler))
 		r.Route("/{tx_id}", func(r chi.Router) {
-			r.With(historyMiddleware).Method(http.MethodGet, "/", ObjectActionHandler{actions.GetTransactionByHashHandler{}})
+			r.With(historyMiddleware).Method(http.MethodGet, "/", ObjectActionHandler{Action: actions.GetTransactionByHashHandler{}, Cache: HistoryCache})
 			r.With(historyMiddleware).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, streamHandler))
 			r.With(historyMiddleware).Method(http.MethodGet, "/operations", streamableHistoryPageHandler(ledgerState, actions.GetOperationsHandler{
 				LedgerState:  ledgerState,
@@ -294,7 +335,7 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 			LedgerState:  ledgerState,
 			OnlyPayments: false,
 		}, streamHandler))
-		r.With(historyMiddleware).Method(http.MethodGet, "/{id}", ObjectActionHandler{actions.GetOperationByIDHandler{LedgerState: ledgerState}})
+		r.With(historyMiddleware).Method(http.MethodGet, "/{id}", ObjectActionHandler{Action: actions.GetOperationByIDHandler{LedgerState: ledgerState}, Cache: HistoryCache})
 		r.With(historyMiddleware).Method(http.MethodGet, "/{op_id}/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, streamHandler))
 	})
 
@@ -308,16 +349,19 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		// effect actions
 		r.With(historyMiddleware).Method(http.MethodGet, "/effects", streamableHistoryPageHandler(ledgerState, actions.GetEffectsHandler{LedgerState: ledgerState}, streamHandler))
 
+		// ledger entry change actions
+		r.With(historyMiddleware).Method(http.MethodGet, "/ledger_entry_changes", streamableHistoryPageHandler(ledgerState, actions.GetLedgerEntryChangesHandler{LedgerState: ledgerState}, streamHandler))
+
 		// trading related endpoints
 		r.With(historyMiddleware).Method(http.MethodGet, "/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, streamHandler))
-		r.With(historyMiddleware).Method(http.MethodGet, "/trade_aggregations", ObjectActionHandler{actions.GetTradeAggregationsHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}})
+		r.With(historyMiddleware).Method(http.MethodGet, "/trade_aggregations", ObjectActionHandler{Action: actions.GetTradeAggregationsHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, Cache: StateCache})
 		// /offers/{offer_id} has been created above so we need to use absolute
 		// routes here.
 		r.With(historyMiddleware).Method(http.MethodGet, "/offers/{offer_id}/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, streamHandler))
 	})
 
 	// Transaction submission API
-	r.Method(http.MethodPost, "/transactions", ObjectActionHandler{actions.SubmitTransactionHandler{
+	r.Method(http.MethodPost, "/transactions", ObjectActionHandler{Action: actions.SubmitTransactionHandler{
 		Submitter:         config.TxSubmitter,
 		NetworkPassphrase: config.NetworkPassphrase,
 		DisableTxSub:      config.DisableTxSub,
@@ -325,7 +369,7 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 	}})
 
 	// Network state related endpoints
-	r.Method(http.MethodGet, "/fee_stats", ObjectActionHandler{actions.FeeStatsHandler{}})
+	r.Method(http.MethodGet, "/fee_stats", ObjectActionHandler{Action: actions.FeeStatsHandler{}, Cache: StateCache})
 
 	// friendbot
 	if config.FriendbotURL != nil {
@@ -341,7 +385,9 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		problem.Render(request.Context(), w, problem.NotFound)
 	})
 
-	// internal
+	// internal, the admin endpoints write to the database or need the latest
+	// history so they are never routed to read replicas
+	primaryHistoryMiddleware := newHistoryMiddleware(ledgerState, int32(config.StaleThreshold), config.DBSession, nil)
 	r.Internal.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		p, err := staticFiles.ReadFile("static/admin_oapi.yml")
 		if err != nil {
@@ -357,10 +403,33 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 	if config.EnableIngestionFiltering {
 		r.Internal.Route("/ingestion/filters", func(r chi.Router) {
 			handler := actions.FilterConfigHandler{}
-			r.With(historyMiddleware).Put("/asset", handler.UpdateAssetConfig)
-			r.With(historyMiddleware).Put("/account", handler.UpdateAccountConfig)
-			r.With(historyMiddleware).Get("/asset", handler.GetAssetConfig)
-			r.With(historyMiddleware).Get("/account", handler.GetAccountConfig)
+			r.With(primaryHistoryMiddleware).Put("/asset", handler.UpdateAssetConfig)
+			r.With(primaryHistoryMiddleware).Put("/account", handler.UpdateAccountConfig)
+			r.With(primaryHistoryMiddleware).Get("/asset", handler.GetAssetConfig)
+			r.With(primaryHistoryMiddleware).Get("/account", handler.GetAccountConfig)
+			r.With(primaryHistoryMiddleware).Put("/operation", handler.UpdateOperationConfig)
+			r.With(primaryHistoryMiddleware).Put("/contract", handler.UpdateContractConfig)
+			r.With(primaryHistoryMiddleware).Get("/operation", handler.GetOperationConfig)
+			r.With(primaryHistoryMiddleware).Get("/contract", handler.GetContractConfig)
+		})
+	}
+	reingestHandler := actions.ReingestHandler{Jobs: config.ReingestJobs}
+	r.Internal.With(primaryHistoryMiddleware).Get("/ingestion/gaps", reingestHandler.GetLedgerGaps)
+	if config.ReingestJobs != nil {
+		r.Internal.Route("/ingestion/reingest/jobs", func(r chi.Router) {
+			r.With(primaryHistoryMiddleware).Post("/", reingestHandler.EnqueueJob)
+			r.Get("/", reingestHandler.GetJobs)
+			r.Get("/{id}", reingestHandler.GetJob)
+			r.Delete("/{id}", reingestHandler.CancelJob)
 		})
 	}
+	if config.LeaderElector != nil {
+		leaderHandler := actions.IngestionLeaderHandler{Elector: config.LeaderElector}
+		r.Internal.Get("/ingestion/leader", leaderHandler.GetLeader)
+		r.Internal.Post("/ingestion/leader/handoff", leaderHandler.RequestHandoff)
+	}
+	if config.ArchiveState != nil {
+		archiveStateHandler := actions.ArchiveStateHandler{Reader: config.ArchiveState}
+		r.Internal.Get("/archive/ledger_entry", archiveStateHandler.GetLedgerEntry)
+	}
 }
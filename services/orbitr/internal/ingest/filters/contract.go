@@ -0,0 +1,139 @@
+package filters
+
+import (
+	"context"
+
+	"github.com/lantah/go/ingest"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/services/orbitr/internal/ingest/processors"
+	"github.com/lantah/go/support/collections/set"
+	"github.com/lantah/go/xdr"
+)
+
+type contractFilter struct {
+	whitelistedContractsSet set.Set[string]
+	lastModified            int64
+	enabled                 bool
+}
+
+type ContractFilter interface {
+	processors.LedgerTransactionFilterer
+	RefreshContractFilter(filterConfig *history.ContractFilterConfig) error
+}
+
+func NewContractFilter() ContractFilter {
+	return &contractFilter{
+		whitelistedContractsSet: set.Set[string]{},
+	}
+}
+
+func (filter *contractFilter) RefreshContractFilter(filterConfig *history.ContractFilterConfig) error {
+	// only need to re-initialize the filter config state(rules) if its cached version(in  memory)
+	// is older than the incoming config version based on lastModified epoch timestamp
+	if filterConfig.LastModified > filter.lastModified {
+		logger.Infof("New Contract Filter config detected, reloading new config %v ", *filterConfig)
+
+		filter.enabled = filterConfig.Enabled
+		filter.whitelistedContractsSet = listToSet(filterConfig.Whitelist)
+		filter.lastModified = filterConfig.LastModified
+	}
+
+	return nil
+}
+
+// FilterTransaction keeps Soroban transactions invoking a host function which
+// touches a whitelisted contract, either by calling it directly, in one of its
+// authorized sub-invocations or by accessing its data through the footprint.
+// Other transactions are kept, as ingestion drops the transactions rejected by
+// any filter and they are left to the other filters, e.g. the operation
+// filter.
+func (f *contractFilter) FilterTransaction(ctx context.Context, transaction ingest.LedgerTransaction) (bool, error) {
+	// filtering is disabled if the whitelist is empty for now, as that is the only filter rule
+	if len(f.whitelistedContractsSet) == 0 || !f.enabled {
+		return true, nil
+	}
+	if !isSorobanTransaction(transaction.Envelope) {
+		return true, nil
+	}
+
+	for _, operation := range transaction.Envelope.Operations() {
+		op, ok := operation.Body.GetInvokeHostFunctionOp()
+		if !ok {
+			continue
+		}
+		if invokeContract, ok := op.HostFunction.GetInvokeContract(); ok &&
+			f.contractMatchedFilter(invokeContract.ContractAddress) {
+			return true, nil
+		}
+		for _, auth := range op.Auth {
+			if f.invocationMatchedFilter(auth.RootInvocation) {
+				return true, nil
+			}
+		}
+	}
+
+	if sorobanData, ok := transactionSorobanData(transaction.Envelope); ok {
+		footprint := sorobanData.Resources.Footprint
+		for _, keys := range [][]xdr.LedgerKey{footprint.ReadOnly, footprint.ReadWrite} {
+			for _, key := range keys {
+				if contractData, ok := key.GetContractData(); ok && f.contractMatchedFilter(contractData.Contract) {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	logger.Debugf("No match, dropped tx with seq %v ", transaction.Envelope.SeqNum())
+	return false, nil
+}
+
+func (f *contractFilter) invocationMatchedFilter(invocation xdr.SorobanAuthorizedInvocation) bool {
+	if contractFn, ok := invocation.Function.GetContractFn(); ok && f.contractMatchedFilter(contractFn.ContractAddress) {
+		return true
+	}
+	for _, subInvocation := range invocation.SubInvocations {
+		if f.invocationMatchedFilter(subInvocation) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *contractFilter) contractMatchedFilter(address xdr.ScAddress) bool {
+	if address.Type != xdr.ScAddressTypeScAddressTypeContract {
+		return false
+	}
+	contractID, err := address.String()
+	if err != nil {
+		return false
+	}
+	return f.whitelistedContractsSet.Contains(contractID)
+}
+
+func isSorobanTransaction(envelope xdr.TransactionEnvelope) bool {
+	if _, ok := transactionSorobanData(envelope); ok {
+		return true
+	}
+	for _, operation := range envelope.Operations() {
+		switch operation.Body.Type {
+		case xdr.OperationTypeInvokeHostFunction,
+			xdr.OperationTypeBumpFootprintExpiration,
+			xdr.OperationTypeRestoreFootprint:
+			return true
+		}
+	}
+	return false
+}
+
+func transactionSorobanData(envelope xdr.TransactionEnvelope) (xdr.SorobanTransactionData, bool) {
+	var ext xdr.TransactionExt
+	switch envelope.Type {
+	case xdr.EnvelopeTypeEnvelopeTypeTx:
+		ext = envelope.V1.Tx.Ext
+	case xdr.EnvelopeTypeEnvelopeTypeTxFeeBump:
+		ext = envelope.FeeBump.Tx.InnerTx.V1.Tx.Ext
+	default:
+		return xdr.SorobanTransactionData{}, false
+	}
+	return ext.GetSorobanData()
+}
@@ -0,0 +1,246 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lantah/go/ingest"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/services/orbitr/internal/ingest/processors"
+	"github.com/lantah/go/strkey"
+	"github.com/lantah/go/xdr"
+)
+
+func TestContractFilterAllowsOnMatch(t *testing.T) {
+	tt := assert.New(t)
+	ctx := context.Background()
+
+	whitelisted := xdr.Hash{1}
+	other := xdr.Hash{2}
+	filter := NewContractFilter()
+	tt.NoError(filter.RefreshContractFilter(&history.ContractFilterConfig{
+		Whitelist:    []string{contractStrkey(t, whitelisted)},
+		Enabled:      true,
+		LastModified: 1,
+	}))
+
+	for _, testCase := range []struct {
+		name     string
+		tx       ingest.LedgerTransaction
+		expected bool
+	}{
+		{
+			name:     "invoked contract",
+			tx:       getContractTestTx(contractAddress(whitelisted), nil, nil),
+			expected: true,
+		},
+		{
+			name: "authorized sub invocation",
+			tx: getContractTestTx(contractAddress(other), []xdr.SorobanAuthorizedInvocation{
+				{
+					Function: contractFn(other),
+					SubInvocations: []xdr.SorobanAuthorizedInvocation{
+						{Function: contractFn(whitelisted)},
+					},
+				},
+			}, nil),
+			expected: true,
+		},
+		{
+			name: "footprint",
+			tx: getContractTestTx(contractAddress(other), nil, &xdr.LedgerFootprint{
+				ReadOnly: []xdr.LedgerKey{
+					{
+						Type:         xdr.LedgerEntryTypeContractCode,
+						ContractCode: &xdr.LedgerKeyContractCode{Hash: whitelisted},
+					},
+				},
+				ReadWrite: []xdr.LedgerKey{
+					{
+						Type: xdr.LedgerEntryTypeContractData,
+						ContractData: &xdr.LedgerKeyContractData{
+							Contract:   contractAddress(whitelisted),
+							Key:        xdr.ScVal{Type: xdr.ScValTypeScvLedgerKeyContractInstance},
+							Durability: xdr.ContractDataDurabilityPersistent,
+						},
+					},
+				},
+			}),
+			expected: true,
+		},
+		{
+			name: "no match",
+			tx: getContractTestTx(contractAddress(other), []xdr.SorobanAuthorizedInvocation{
+				{Function: contractFn(other)},
+			}, &xdr.LedgerFootprint{
+				ReadOnly: []xdr.LedgerKey{
+					{
+						Type:         xdr.LedgerEntryTypeContractCode,
+						ContractCode: &xdr.LedgerKeyContractCode{Hash: whitelisted},
+					},
+				},
+			}),
+			expected: false,
+		},
+		{
+			name:     "restore footprint",
+			tx:       getOperationTestTx(xdr.OperationTypeRestoreFootprint),
+			expected: false,
+		},
+		{
+			name:     "not a soroban transaction",
+			tx:       getOperationTestTx(xdr.OperationTypePayment),
+			expected: true,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := filter.FilterTransaction(ctx, testCase.tx)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, result)
+		})
+	}
+}
+
+func TestContractAndOperationFilters(t *testing.T) {
+	tt := assert.New(t)
+	ctx := context.Background()
+
+	whitelisted := xdr.Hash{1}
+	contractFilter := NewContractFilter()
+	tt.NoError(contractFilter.RefreshContractFilter(&history.ContractFilterConfig{
+		Whitelist:    []string{contractStrkey(t, whitelisted)},
+		Enabled:      true,
+		LastModified: 1,
+	}))
+	operationFilter := NewOperationFilter()
+	tt.NoError(operationFilter.RefreshOperationFilter(&history.OperationFilterConfig{
+		Whitelist:    []string{"payment", "invoke_host_function"},
+		Enabled:      true,
+		LastModified: 1,
+	}))
+
+	// ingestion keeps the transactions kept by every filter
+	keep := func(tx ingest.LedgerTransaction) bool {
+		for _, filter := range []processors.LedgerTransactionFilterer{operationFilter, contractFilter} {
+			result, err := filter.FilterTransaction(ctx, tx)
+			require.NoError(t, err)
+			if !result {
+				return false
+			}
+		}
+		return true
+	}
+
+	tt.True(keep(getOperationTestTx(xdr.OperationTypePayment)))
+	tt.True(keep(getContractTestTx(contractAddress(whitelisted), nil, nil)))
+	tt.False(keep(getContractTestTx(contractAddress(xdr.Hash{2}), nil, nil)))
+	tt.False(keep(getOperationTestTx(xdr.OperationTypeBumpSequence)))
+}
+
+func TestContractFilterAllowsWhenDisabled(t *testing.T) {
+	tt := assert.New(t)
+	ctx := context.Background()
+
+	filter := NewContractFilter()
+	tt.NoError(filter.RefreshContractFilter(&history.ContractFilterConfig{
+		Whitelist:    []string{contractStrkey(t, xdr.Hash{1})},
+		Enabled:      false,
+		LastModified: 1,
+	}))
+
+	result, err := filter.FilterTransaction(ctx, getContractTestTx(contractAddress(xdr.Hash{2}), nil, nil))
+	tt.NoError(err)
+	tt.True(result)
+}
+
+func TestContractFilterAllowsWhenEmptyWhitelist(t *testing.T) {
+	tt := assert.New(t)
+	ctx := context.Background()
+
+	filter := NewContractFilter()
+	tt.NoError(filter.RefreshContractFilter(&history.ContractFilterConfig{
+		Whitelist:    []string{},
+		Enabled:      true,
+		LastModified: 1,
+	}))
+
+	result, err := filter.FilterTransaction(ctx, getContractTestTx(contractAddress(xdr.Hash{2}), nil, nil))
+	tt.NoError(err)
+	tt.True(result)
+}
+
+func contractStrkey(t *testing.T, contractID xdr.Hash) string {
+	address, err := strkey.Encode(strkey.VersionByteContract, contractID[:])
+	require.NoError(t, err)
+	return address
+}
+
+func contractAddress(contractID xdr.Hash) xdr.ScAddress {
+	return xdr.ScAddress{
+		Type:       xdr.ScAddressTypeScAddressTypeContract,
+		ContractId: &contractID,
+	}
+}
+
+func contractFn(contractID xdr.Hash) xdr.SorobanAuthorizedFunction {
+	return xdr.SorobanAuthorizedFunction{
+		Type: xdr.SorobanAuthorizedFunctionTypeSorobanAuthorizedFunctionTypeContractFn,
+		ContractFn: &xdr.InvokeContractArgs{
+			ContractAddress: contractAddress(contractID),
+			FunctionName:    "transfer",
+		},
+	}
+}
+
+func getContractTestTx(
+	invoked xdr.ScAddress,
+	invocations []xdr.SorobanAuthorizedInvocation,
+	footprint *xdr.LedgerFootprint,
+) ingest.LedgerTransaction {
+	auth := make([]xdr.SorobanAuthorizationEntry, 0, len(invocations))
+	for _, invocation := range invocations {
+		auth = append(auth, xdr.SorobanAuthorizationEntry{
+			Credentials: xdr.SorobanCredentials{
+				Type: xdr.SorobanCredentialsTypeSorobanCredentialsSourceAccount,
+			},
+			RootInvocation: invocation,
+		})
+	}
+
+	tx := xdr.Transaction{
+		SourceAccount: xdr.MustMuxedAddress("GD6WNNTW664WH7FXC5RUMUTF7P5QSURC2IT36VOQEEGFZ4UWUEQGECAL"),
+		Operations: []xdr.Operation{
+			{Body: xdr.OperationBody{
+				Type: xdr.OperationTypeInvokeHostFunction,
+				InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{
+					HostFunction: xdr.HostFunction{
+						Type: xdr.HostFunctionTypeHostFunctionTypeInvokeContract,
+						InvokeContract: &xdr.InvokeContractArgs{
+							ContractAddress: invoked,
+							FunctionName:    "transfer",
+						},
+					},
+					Auth: auth,
+				},
+			}},
+		},
+	}
+	if footprint != nil {
+		tx.Ext = xdr.TransactionExt{
+			V: 1,
+			SorobanData: &xdr.SorobanTransactionData{
+				Resources: xdr.SorobanResources{Footprint: *footprint},
+			},
+		}
+	}
+
+	return ingest.LedgerTransaction{
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1:   &xdr.TransactionV1Envelope{Tx: tx},
+		},
+	}
+}
@@ -18,5 +18,5 @@ func TestItGetsFilters(t *testing.T) {
 	ingestFilters := filtersService.GetFilters(q, tt.Ctx)
 
 	// should be total of filters implemented in the system
-	tt.Assert.Len(ingestFilters, 2)
+	tt.Assert.Len(ingestFilters, 4)
 }
@@ -0,0 +1,106 @@
+package filters
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lantah/go/ingest"
+	"github.com/lantah/go/protocols/orbitr/operations"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/services/orbitr/internal/ingest/processors"
+	"github.com/lantah/go/support/collections/set"
+	"github.com/lantah/go/xdr"
+)
+
+// operationTypesByName maps the operation type names used in orbitr's JSON
+// responses, which are the names used in the operation filter rules, to
+// operation types.
+var operationTypesByName = func() map[string]xdr.OperationType {
+	m := make(map[string]xdr.OperationType, len(operations.TypeNames))
+	for typ, name := range operations.TypeNames {
+		m[name] = typ
+	}
+	return m
+}()
+
+type operationFilter struct {
+	whitelistedTypesSet set.Set[xdr.OperationType]
+	blacklistedTypesSet set.Set[xdr.OperationType]
+	lastModified        int64
+	enabled             bool
+}
+
+type OperationFilter interface {
+	processors.LedgerTransactionFilterer
+	RefreshOperationFilter(filterConfig *history.OperationFilterConfig) error
+}
+
+func NewOperationFilter() OperationFilter {
+	return &operationFilter{
+		whitelistedTypesSet: set.Set[xdr.OperationType]{},
+		blacklistedTypesSet: set.Set[xdr.OperationType]{},
+	}
+}
+
+// ParseOperationTypes converts operation type names, as used in orbitr's JSON
+// responses, into operation types.
+func ParseOperationTypes(names []string) (set.Set[xdr.OperationType], error) {
+	types := set.NewSet[xdr.OperationType](len(names))
+	for _, name := range names {
+		typ, ok := operationTypesByName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown operation type %q", name)
+		}
+		types.Add(typ)
+	}
+	return types, nil
+}
+
+func (filter *operationFilter) RefreshOperationFilter(filterConfig *history.OperationFilterConfig) error {
+	// only need to re-initialize the filter config state(rules) if its cached version(in  memory)
+	// is older than the incoming config version based on lastModified epoch timestamp
+	if filterConfig.LastModified > filter.lastModified {
+		logger.Infof("New Operation Filter config detected, reloading new config %v ", *filterConfig)
+
+		whitelist, err := ParseOperationTypes(filterConfig.Whitelist)
+		if err != nil {
+			return err
+		}
+		blacklist, err := ParseOperationTypes(filterConfig.Blacklist)
+		if err != nil {
+			return err
+		}
+
+		filter.enabled = filterConfig.Enabled
+		filter.whitelistedTypesSet = whitelist
+		filter.blacklistedTypesSet = blacklist
+		filter.lastModified = filterConfig.LastModified
+	}
+
+	return nil
+}
+
+// FilterTransaction drops transactions containing any blacklisted operation
+// type. If the whitelist is not empty, it also drops transactions without any
+// whitelisted operation type.
+func (f *operationFilter) FilterTransaction(ctx context.Context, transaction ingest.LedgerTransaction) (bool, error) {
+	if !f.enabled || (len(f.whitelistedTypesSet) == 0 && len(f.blacklistedTypesSet) == 0) {
+		return true, nil
+	}
+
+	whitelisted := len(f.whitelistedTypesSet) == 0
+	for _, operation := range transaction.Envelope.Operations() {
+		if f.blacklistedTypesSet.Contains(operation.Body.Type) {
+			logger.Debugf("Blacklisted operation type, dropped tx with seq %v ", transaction.Envelope.SeqNum())
+			return false, nil
+		}
+		if f.whitelistedTypesSet.Contains(operation.Body.Type) {
+			whitelisted = true
+		}
+	}
+
+	if !whitelisted {
+		logger.Debugf("No match, dropped tx with seq %v ", transaction.Envelope.SeqNum())
+	}
+	return whitelisted, nil
+}
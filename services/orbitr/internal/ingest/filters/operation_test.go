@@ -0,0 +1,125 @@
+package filters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lantah/go/ingest"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/xdr"
+)
+
+func TestOperationFilterAllowsWhitelistedType(t *testing.T) {
+	tt := assert.New(t)
+	ctx := context.Background()
+
+	filter := NewOperationFilter()
+	tt.NoError(filter.RefreshOperationFilter(&history.OperationFilterConfig{
+		Whitelist:    []string{"payment", "invoke_host_function"},
+		Enabled:      true,
+		LastModified: 1,
+	}))
+
+	result, err := filter.FilterTransaction(ctx, getOperationTestTx(xdr.OperationTypePayment))
+	tt.NoError(err)
+	tt.True(result)
+
+	result, err = filter.FilterTransaction(ctx, getOperationTestTx(xdr.OperationTypeBumpSequence, xdr.OperationTypeInvokeHostFunction))
+	tt.NoError(err)
+	tt.True(result)
+
+	result, err = filter.FilterTransaction(ctx, getOperationTestTx(xdr.OperationTypeBumpSequence))
+	tt.NoError(err)
+	tt.False(result)
+}
+
+func TestOperationFilterDropsBlacklistedType(t *testing.T) {
+	tt := assert.New(t)
+	ctx := context.Background()
+
+	filter := NewOperationFilter()
+	tt.NoError(filter.RefreshOperationFilter(&history.OperationFilterConfig{
+		Whitelist:    []string{"payment"},
+		Blacklist:    []string{"bump_sequence"},
+		Enabled:      true,
+		LastModified: 1,
+	}))
+
+	result, err := filter.FilterTransaction(ctx, getOperationTestTx(xdr.OperationTypePayment, xdr.OperationTypeBumpSequence))
+	tt.NoError(err)
+	tt.False(result)
+
+	tt.NoError(filter.RefreshOperationFilter(&history.OperationFilterConfig{
+		Blacklist:    []string{"bump_sequence"},
+		Enabled:      true,
+		LastModified: 2,
+	}))
+
+	result, err = filter.FilterTransaction(ctx, getOperationTestTx(xdr.OperationTypeCreateAccount))
+	tt.NoError(err)
+	tt.True(result)
+
+	result, err = filter.FilterTransaction(ctx, getOperationTestTx(xdr.OperationTypeBumpSequence))
+	tt.NoError(err)
+	tt.False(result)
+}
+
+func TestOperationFilterAllowsWhenDisabled(t *testing.T) {
+	tt := assert.New(t)
+	ctx := context.Background()
+
+	filter := NewOperationFilter()
+	tt.NoError(filter.RefreshOperationFilter(&history.OperationFilterConfig{
+		Whitelist:    []string{"payment"},
+		Blacklist:    []string{"bump_sequence"},
+		Enabled:      false,
+		LastModified: 1,
+	}))
+
+	result, err := filter.FilterTransaction(ctx, getOperationTestTx(xdr.OperationTypeBumpSequence))
+	tt.NoError(err)
+	tt.True(result)
+}
+
+func TestOperationFilterRejectsUnknownType(t *testing.T) {
+	tt := assert.New(t)
+	ctx := context.Background()
+
+	filter := NewOperationFilter()
+	tt.NoError(filter.RefreshOperationFilter(&history.OperationFilterConfig{
+		Whitelist:    []string{"payment"},
+		Enabled:      true,
+		LastModified: 1,
+	}))
+	tt.EqualError(filter.RefreshOperationFilter(&history.OperationFilterConfig{
+		Whitelist:    []string{"pay"},
+		Enabled:      true,
+		LastModified: 2,
+	}), `unknown operation type "pay"`)
+
+	// the previous config is kept
+	result, err := filter.FilterTransaction(ctx, getOperationTestTx(xdr.OperationTypeBumpSequence))
+	tt.NoError(err)
+	tt.False(result)
+}
+
+func getOperationTestTx(types ...xdr.OperationType) ingest.LedgerTransaction {
+	operations := make([]xdr.Operation, 0, len(types))
+	for _, typ := range types {
+		operations = append(operations, xdr.Operation{Body: xdr.OperationBody{Type: typ}})
+	}
+
+	return ingest.LedgerTransaction{
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: xdr.MustMuxedAddress("GD6WNNTW664WH7FXC5RUMUTF7P5QSURC2IT36VOQEEGFZ4UWUEQGECAL"),
+					Operations:    operations,
+				},
+			},
+		},
+	}
+}
@@ -219,6 +219,9 @@ func (state startState) run(s *system) (transition, error) {
 		// Now it's on by default but the latest history ledger is greater
 		// than the latest ingest ledger. We reset the exp ledger sequence
 		// so init state will rebuild the state correctly.
+		if leader, err := s.holdsIngestionLease(); err != nil || !leader {
+			return start(), err
+		}
 		err = s.historyQ.UpdateLastLedgerIngest(s.ctx, 0)
 		if err != nil {
 			return start(), errors.Wrap(err, updateLastLedgerIngestErrMsg)
@@ -327,6 +330,10 @@ func (b buildState) run(s *system) (transition, error) {
 		return nextFailState, nil
 	}
 
+	if leader, err := s.holdsIngestionLease(); err != nil || !leader {
+		return nextFailState, err
+	}
+
 	if err = s.updateCursor(b.checkpointLedger - 1); err != nil {
 		// Don't return updateCursor error.
 		log.WithError(err).Warn("error updating gravity cursor")
@@ -409,6 +416,21 @@ func (resumeState) GetState() State {
 	return Resume
 }
 
+// followLeader resumes from the last ledger ingested by the ingestion leader
+// once it is past the last ledger processed by this instance.
+func (r resumeState) followLeader(s *system) (transition, error) {
+	lastIngestedLedger, err := s.historyQ.GetLastLedgerIngestNonBlocking(s.ctx)
+	if err != nil {
+		return retryResume(r), errors.Wrap(err, getLastIngestedErrMsg)
+	}
+	if lastIngestedLedger > r.latestSuccessfullyProcessedLedger {
+		return resumeImmediately(lastIngestedLedger), nil
+	}
+	log.WithField("ingestLedger", r.latestSuccessfullyProcessedLedger+1).
+		Debug("Waiting for the ingestion leader to ingest ledger")
+	return retryResume(r), nil
+}
+
 func (r resumeState) run(s *system) (transition, error) {
 	if r.latestSuccessfullyProcessedLedger == 0 {
 		return start(), errors.New("unexpected latestSuccessfullyProcessedLedger value")
@@ -418,6 +440,15 @@ func (r resumeState) run(s *system) (transition, error) {
 
 	ingestLedger := r.latestSuccessfullyProcessedLedger + 1
 
+	// Instances which do not hold the ingestion lease do not get the ledger
+	// from their backend, they follow the last ledger ingested by the leader
+	// so that they resume from it when they take over.
+	if s.config.LeaderElector != nil {
+		if _, ok := s.config.LeaderElector.FencingToken(); !ok {
+			return r.followLeader(s)
+		}
+	}
+
 	err := s.maybePrepareRange(s.ctx, ingestLedger)
 	if err != nil {
 		return start(), err
@@ -466,6 +497,15 @@ func (r resumeState) run(s *system) (transition, error) {
 		return resumeImmediately(lastIngestedLedger), nil
 	}
 
+	// The lease may have been lost, or taken over by another instance, while
+	// waiting for the ledger.
+	if leader, err := s.holdsIngestionLease(); err != nil {
+		return retryResume(r), err
+	} else if !leader {
+		log.WithField("ingestLedger", ingestLedger).Debug("Waiting for the ingestion leader to ingest ledger")
+		return retryResume(r), nil
+	}
+
 	ingestVersion, err := s.historyQ.GetIngestVersion(s.ctx)
 	if err != nil {
 		return retryResume(r), errors.Wrap(err, getIngestVersionErrMsg)
@@ -622,6 +662,10 @@ func (h historyRangeState) run(s *system) (transition, error) {
 		return start(), errors.Wrap(err, getLastIngestedErrMsg)
 	}
 
+	if leader, err := s.holdsIngestionLease(); err != nil || !leader {
+		return start(), err
+	}
+
 	lastHistoryLedger, err := s.historyQ.GetLatestHistoryLedger(s.ctx)
 	if err != nil {
 		return start(), errors.Wrap(err, "could not get latest history ledger")
@@ -746,6 +790,9 @@ func (h reingestHistoryRangeState) ingestRange(s *system, fromLedger, toLedger u
 		if err = runTransactionProcessorsOnLedger(s, ledgerCloseMeta); err != nil {
 			return err
 		}
+		if s.config.reingestProgress != nil {
+			s.config.reingestProgress(cur)
+		}
 	}
 
 	return nil
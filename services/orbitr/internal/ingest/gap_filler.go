@@ -0,0 +1,254 @@
+package ingest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/support/db"
+	"github.com/lantah/go/support/errors"
+	logpkg "github.com/lantah/go/support/log"
+)
+
+type gapFillerQ interface {
+	Begin(ctx context.Context) error
+	Rollback() error
+	TryGapFillingLock(ctx context.Context) (bool, error)
+	GetLedgerGaps(ctx context.Context) ([]history.LedgerRange, error)
+}
+
+type gapFillerLeader interface {
+	FencingToken() (int64, bool)
+}
+
+type gapReingester interface {
+	Enqueue(ctx context.Context, ranges []history.LedgerRange, force bool) (ReingestJob, error)
+	Job(id string) (ReingestJob, error)
+}
+
+// GapFillerConfig configures a GapFiller.
+type GapFillerConfig struct {
+	HistorySession db.SessionInterface
+	// Interval is how often history is checked for gaps.
+	Interval time.Duration
+	// ReingestJobs, if set, reingests the detected gaps. Otherwise gaps are
+	// only reported.
+	ReingestJobs *ReingestJobManager
+	// MaxLedgersPerRun is the maximum number of ledgers reingested every
+	// Interval. It bounds the load gap filling puts on the database and the
+	// ledger backend so that it does not starve live ingestion. 0 means
+	// unlimited.
+	MaxLedgersPerRun uint32
+	// LeaderElector, if set, restricts gap detection and filling to the
+	// ingestion leader.
+	LeaderElector *LeaderElector
+}
+
+// GapFiller periodically looks for gaps in the ledgers ingested into history,
+// exports them as metrics and optionally reingests them. When several
+// instances are ingesting, only the ingestion leader checks for gaps if leader
+// election is enabled, and the gap filling lock keeps instances from checking
+// at the same time. The lock is only held while detecting the gaps and
+// enqueuing their reingestion, the job is then followed through the
+// reingestion job registry and no gaps are enqueued until it has finished.
+type GapFiller struct {
+	historyQ         gapFillerQ
+	leader           gapFillerLeader
+	reingestJobs     gapReingester
+	interval         time.Duration
+	maxLedgersPerRun uint32
+	// jobID is the id of the last reingestion job enqueued, it is only used
+	// by Run.
+	jobID string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	gapsGauge       prometheus.Gauge
+	gapLedgersGauge prometheus.Gauge
+}
+
+// NewGapFiller returns a new GapFiller.
+func NewGapFiller(config GapFillerConfig) *GapFiller {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &GapFiller{
+		historyQ:         &history.Q{SessionInterface: config.HistorySession},
+		interval:         config.Interval,
+		maxLedgersPerRun: config.MaxLedgersPerRun,
+		ctx:              ctx,
+		cancel:           cancel,
+		gapsGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "orbitr", Subsystem: "ingest", Name: "history_gaps",
+			Help: "number of gaps in the ledgers ingested into history, as of the last gap detection",
+		}),
+		gapLedgersGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "orbitr", Subsystem: "ingest", Name: "history_gap_ledgers",
+			Help: "number of ledgers missing from history, as of the last gap detection",
+		}),
+	}
+	if config.ReingestJobs != nil {
+		g.reingestJobs = config.ReingestJobs
+	}
+	if config.LeaderElector != nil {
+		g.leader = config.LeaderElector
+	}
+	return g
+}
+
+// RegisterMetrics registers the prometheus metrics
+func (g *GapFiller) RegisterMetrics(registry *prometheus.Registry) {
+	registry.MustRegister(g.gapsGauge)
+	registry.MustRegister(g.gapLedgersGauge)
+}
+
+// Run checks for gaps right away and then every interval until Shutdown is
+// called.
+func (g *GapFiller) Run() {
+	g.wg.Add(1)
+	defer g.wg.Done()
+
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := g.detectAndFillGaps(g.ctx); err != nil && g.ctx.Err() == nil {
+			log.WithField("subservice", "gap_filler").WithError(err).Error("Error detecting or filling history gaps")
+		}
+
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown stops the gap filler. Reingestion jobs which have already been
+// enqueued are stopped by ReingestJobManager.Shutdown.
+func (g *GapFiller) Shutdown() {
+	g.cancel()
+	g.wg.Wait()
+}
+
+func (g *GapFiller) detectAndFillGaps(ctx context.Context) error {
+	localLog := log.WithField("subservice", "gap_filler")
+
+	if g.leader != nil {
+		if _, ok := g.leader.FencingToken(); !ok {
+			localLog.Debug("Gap detection is running on the ingestion leader")
+			return nil
+		}
+	}
+
+	if finished, err := g.lastJobFinished(); !finished || err != nil {
+		return err
+	}
+
+	if err := g.historyQ.Begin(ctx); err != nil {
+		return errors.Wrap(err, "Error starting a transaction")
+	}
+	// rolling back releases the gap filling lock, the transaction only
+	// reads
+	defer g.historyQ.Rollback()
+
+	ok, err := g.historyQ.TryGapFillingLock(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Error acquiring gap filling lock")
+	}
+	if !ok {
+		localLog.Debug("Gap detection is running on another instance")
+		return nil
+	}
+
+	gaps, err := g.historyQ.GetLedgerGaps(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Error detecting history gaps")
+	}
+
+	var missing uint32
+	for _, gap := range gaps {
+		missing += gap.EndSequence - gap.StartSequence + 1
+	}
+	g.gapsGauge.Set(float64(len(gaps)))
+	g.gapLedgersGauge.Set(float64(missing))
+
+	if len(gaps) == 0 {
+		return nil
+	}
+	localLog.WithFields(logpkg.F{
+		"gaps":            len(gaps),
+		"missing_ledgers": missing,
+	}).Warn("Detected gaps in history")
+	if g.reingestJobs == nil {
+		return nil
+	}
+
+	ranges := limitLedgerRanges(gaps, g.maxLedgersPerRun)
+	job, err := g.reingestJobs.Enqueue(ctx, ranges, false)
+	if err != nil {
+		return errors.Wrap(err, "Error enqueuing the reingestion of history gaps")
+	}
+	g.jobID = job.ID
+	localLog.WithFields(logpkg.F{
+		"job":     job.ID,
+		"ledgers": job.TotalLedgers,
+	}).Info("Reingesting history gaps")
+	return nil
+}
+
+// lastJobFinished returns true if the last reingestion job enqueued has
+// finished, along with an error if it failed. Gaps are not detected while it
+// is queued or running as it would enqueue the same gaps again.
+func (g *GapFiller) lastJobFinished() (bool, error) {
+	if g.jobID == "" {
+		return true, nil
+	}
+
+	job, err := g.reingestJobs.Job(g.jobID)
+	if err == ErrReingestJobNotFound {
+		// finished jobs are eventually pruned
+		g.jobID = ""
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	switch job.Status {
+	case ReingestJobQueued, ReingestJobRunning:
+		log.WithField("subservice", "gap_filler").WithField("job", job.ID).
+			Debug("Waiting for the reingestion of history gaps")
+		return false, nil
+	case ReingestJobFailed:
+		g.jobID = ""
+		return true, errors.Errorf("reingestion job %s failed: %s", job.ID, job.Error)
+	default:
+		g.jobID = ""
+		return true, nil
+	}
+}
+
+// limitLedgerRanges returns the first ranges, in order, containing at most
+// maxLedgers ledgers. The last range returned is shortened if needed.
+func limitLedgerRanges(ranges []history.LedgerRange, maxLedgers uint32) []history.LedgerRange {
+	if maxLedgers == 0 {
+		return ranges
+	}
+
+	var limited []history.LedgerRange
+	remaining := maxLedgers
+	for _, r := range ranges {
+		if remaining == 0 {
+			break
+		}
+		if size := r.EndSequence - r.StartSequence + 1; size > remaining {
+			r.EndSequence = r.StartSequence + remaining - 1
+		}
+		limited = append(limited, r)
+		remaining -= r.EndSequence - r.StartSequence + 1
+	}
+	return limited
+}
@@ -0,0 +1,235 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/support/errors"
+)
+
+type mockGapFillerQ struct {
+	mock.Mock
+}
+
+func (m *mockGapFillerQ) Begin(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *mockGapFillerQ) Rollback() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *mockGapFillerQ) TryGapFillingLock(ctx context.Context) (bool, error) {
+	args := m.Called(ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockGapFillerQ) GetLedgerGaps(ctx context.Context) ([]history.LedgerRange, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]history.LedgerRange), args.Error(1)
+}
+
+type mockGapReingester struct {
+	mock.Mock
+}
+
+func (m *mockGapReingester) Enqueue(ctx context.Context, ranges []history.LedgerRange, force bool) (ReingestJob, error) {
+	args := m.Called(ctx, ranges, force)
+	return args.Get(0).(ReingestJob), args.Error(1)
+}
+
+func (m *mockGapReingester) Job(id string) (ReingestJob, error) {
+	args := m.Called(id)
+	return args.Get(0).(ReingestJob), args.Error(1)
+}
+
+type mockGapFillerLeader struct {
+	isLeader bool
+}
+
+func (m mockGapFillerLeader) FencingToken() (int64, bool) {
+	return 1, m.isLeader
+}
+
+func newTestGapFiller(q *mockGapFillerQ, reingester *mockGapReingester, maxLedgers uint32) *GapFiller {
+	g := NewGapFiller(GapFillerConfig{Interval: time.Minute, MaxLedgersPerRun: maxLedgers})
+	g.historyQ = q
+	if reingester != nil {
+		g.reingestJobs = reingester
+	}
+	return g
+}
+
+func TestGapFillerLockNotAcquired(t *testing.T) {
+	ctx := context.Background()
+	q := &mockGapFillerQ{}
+	q.On("Begin", ctx).Return(nil).Once()
+	q.On("TryGapFillingLock", ctx).Return(false, nil).Once()
+	q.On("Rollback").Return(nil).Once()
+
+	g := newTestGapFiller(q, &mockGapReingester{}, 0)
+	assert.NoError(t, g.detectAndFillGaps(ctx))
+	q.AssertExpectations(t)
+}
+
+func TestGapFillerDetectOnly(t *testing.T) {
+	ctx := context.Background()
+	q := &mockGapFillerQ{}
+	q.On("Begin", ctx).Return(nil).Once()
+	q.On("TryGapFillingLock", ctx).Return(true, nil).Once()
+	q.On("GetLedgerGaps", ctx).Return([]history.LedgerRange{
+		{StartSequence: 10, EndSequence: 19},
+		{StartSequence: 30, EndSequence: 30},
+	}, nil).Once()
+	q.On("Rollback").Return(nil).Once()
+
+	g := newTestGapFiller(q, nil, 0)
+	assert.NoError(t, g.detectAndFillGaps(ctx))
+	assert.Equal(t, float64(2), testutil.ToFloat64(g.gapsGauge))
+	assert.Equal(t, float64(11), testutil.ToFloat64(g.gapLedgersGauge))
+	q.AssertExpectations(t)
+}
+
+func TestGapFillerNotLeader(t *testing.T) {
+	ctx := context.Background()
+	q := &mockGapFillerQ{}
+	g := newTestGapFiller(q, &mockGapReingester{}, 0)
+	g.leader = mockGapFillerLeader{isLeader: false}
+	assert.NoError(t, g.detectAndFillGaps(ctx))
+	q.AssertExpectations(t)
+
+	g.leader = mockGapFillerLeader{isLeader: true}
+	q.On("Begin", ctx).Return(nil).Once()
+	q.On("TryGapFillingLock", ctx).Return(true, nil).Once()
+	q.On("GetLedgerGaps", ctx).Return([]history.LedgerRange{}, nil).Once()
+	q.On("Rollback").Return(nil).Once()
+	assert.NoError(t, g.detectAndFillGaps(ctx))
+	q.AssertExpectations(t)
+}
+
+func TestGapFillerFillGaps(t *testing.T) {
+	ctx := context.Background()
+	q := &mockGapFillerQ{}
+	q.On("Begin", ctx).Return(nil).Once()
+	q.On("TryGapFillingLock", ctx).Return(true, nil).Once()
+	q.On("GetLedgerGaps", ctx).Return([]history.LedgerRange{
+		{StartSequence: 10, EndSequence: 19},
+		{StartSequence: 30, EndSequence: 49},
+		{StartSequence: 60, EndSequence: 69},
+	}, nil).Once()
+	q.On("Rollback").Return(nil).Once()
+
+	reingester := &mockGapReingester{}
+	reingester.On("Enqueue", ctx, []history.LedgerRange{
+		{StartSequence: 10, EndSequence: 19},
+		{StartSequence: 30, EndSequence: 34},
+	}, false).Return(ReingestJob{ID: "1", Status: ReingestJobQueued, TotalLedgers: 15}, nil).Once()
+
+	// the transaction holding the lock ends once the job is enqueued
+	g := newTestGapFiller(q, reingester, 15)
+	assert.NoError(t, g.detectAndFillGaps(ctx))
+	assert.Equal(t, float64(3), testutil.ToFloat64(g.gapsGauge))
+	assert.Equal(t, float64(40), testutil.ToFloat64(g.gapLedgersGauge))
+	q.AssertExpectations(t)
+	reingester.AssertExpectations(t)
+
+	// gaps are not detected again while the job is running
+	reingester.On("Job", "1").Return(ReingestJob{ID: "1", Status: ReingestJobRunning}, nil).Once()
+	assert.NoError(t, g.detectAndFillGaps(ctx))
+	q.AssertExpectations(t)
+	reingester.AssertExpectations(t)
+
+	reingester.On("Job", "1").Return(ReingestJob{ID: "1", Status: ReingestJobSucceeded}, nil).Once()
+	q.On("Begin", ctx).Return(nil).Once()
+	q.On("TryGapFillingLock", ctx).Return(true, nil).Once()
+	q.On("GetLedgerGaps", ctx).Return([]history.LedgerRange{}, nil).Once()
+	q.On("Rollback").Return(nil).Once()
+	assert.NoError(t, g.detectAndFillGaps(ctx))
+	assert.Equal(t, float64(0), testutil.ToFloat64(g.gapsGauge))
+	assert.Empty(t, g.jobID)
+	q.AssertExpectations(t)
+	reingester.AssertExpectations(t)
+}
+
+func TestGapFillerFailedJob(t *testing.T) {
+	ctx := context.Background()
+	q := &mockGapFillerQ{}
+	reingester := &mockGapReingester{}
+	reingester.On("Job", "1").
+		Return(ReingestJob{ID: "1", Status: ReingestJobFailed, Error: "error getting ledger"}, nil).Once()
+
+	g := newTestGapFiller(q, reingester, 0)
+	g.jobID = "1"
+	assert.EqualError(t, g.detectAndFillGaps(ctx), "reingestion job 1 failed: error getting ledger")
+	// the gaps are detected and enqueued again at the next interval
+	assert.Empty(t, g.jobID)
+	q.AssertExpectations(t)
+	reingester.AssertExpectations(t)
+}
+
+func TestGapFillerPrunedJob(t *testing.T) {
+	ctx := context.Background()
+	q := &mockGapFillerQ{}
+	q.On("Begin", ctx).Return(nil).Once()
+	q.On("TryGapFillingLock", ctx).Return(false, nil).Once()
+	q.On("Rollback").Return(nil).Once()
+	reingester := &mockGapReingester{}
+	reingester.On("Job", "1").Return(ReingestJob{}, ErrReingestJobNotFound).Once()
+
+	g := newTestGapFiller(q, reingester, 0)
+	g.jobID = "1"
+	assert.NoError(t, g.detectAndFillGaps(ctx))
+	assert.Empty(t, g.jobID)
+	q.AssertExpectations(t)
+	reingester.AssertExpectations(t)
+}
+
+func TestGapFillerEnqueueError(t *testing.T) {
+	ctx := context.Background()
+	q := &mockGapFillerQ{}
+	q.On("Begin", ctx).Return(nil).Once()
+	q.On("TryGapFillingLock", ctx).Return(true, nil).Once()
+	q.On("GetLedgerGaps", ctx).Return([]history.LedgerRange{
+		{StartSequence: 10, EndSequence: 19},
+	}, nil).Once()
+	q.On("Rollback").Return(nil).Once()
+
+	reingester := &mockGapReingester{}
+	reingester.On("Enqueue", ctx, mock.Anything, false).
+		Return(ReingestJob{}, errors.New("too many queued reingestion jobs")).Once()
+
+	g := newTestGapFiller(q, reingester, 0)
+	assert.EqualError(t, g.detectAndFillGaps(ctx),
+		"Error enqueuing the reingestion of history gaps: too many queued reingestion jobs")
+	q.AssertExpectations(t)
+	reingester.AssertExpectations(t)
+}
+
+func TestLimitLedgerRanges(t *testing.T) {
+	ranges := []history.LedgerRange{
+		{StartSequence: 10, EndSequence: 19},
+		{StartSequence: 30, EndSequence: 49},
+	}
+
+	assert.Equal(t, ranges, limitLedgerRanges(ranges, 0))
+	assert.Equal(t, ranges, limitLedgerRanges(ranges, 30))
+	assert.Equal(t, ranges, limitLedgerRanges(ranges, 100))
+	assert.Equal(t, []history.LedgerRange{
+		{StartSequence: 10, EndSequence: 19},
+	}, limitLedgerRanges(ranges, 10))
+	assert.Equal(t, []history.LedgerRange{
+		{StartSequence: 10, EndSequence: 14},
+	}, limitLedgerRanges(ranges, 5))
+	assert.Equal(t, []history.LedgerRange{
+		{StartSequence: 10, EndSequence: 19},
+		{StartSequence: 30, EndSequence: 30},
+	}, limitLedgerRanges(ranges, 11))
+}
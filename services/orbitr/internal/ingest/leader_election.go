@@ -0,0 +1,267 @@
+package ingest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/support/db"
+	"github.com/lantah/go/support/errors"
+	logpkg "github.com/lantah/go/support/log"
+)
+
+// IngestionLeaseName is the name of the lease held by the leader of the
+// ingesting instances.
+const IngestionLeaseName = "ingestion"
+
+// ErrHandoffToLeader is returned when a handoff to the current leader is
+// requested.
+var ErrHandoffToLeader = errors.New("cannot hand off ingestion leadership to the current leader")
+
+type leaderElectorQ interface {
+	AcquireIngestionLease(ctx context.Context, name, leaderID string, ttl time.Duration) (history.IngestionLease, bool, error)
+	ReleaseIngestionLease(ctx context.Context, name, leaderID string, fencingToken int64) error
+	GetIngestionLease(ctx context.Context, name string) (history.IngestionLease, error)
+	RequestIngestionLeaseHandoff(ctx context.Context, name, to string) (history.IngestionLease, error)
+}
+
+// LeaderElectorConfig configures a LeaderElector.
+type LeaderElectorConfig struct {
+	HistorySession db.SessionInterface
+	// Identity identifies this instance in the lease, it must be unique among
+	// the ingesting instances.
+	Identity string
+	// LeaseTTL is how long the lease is held without being renewed. The
+	// lease is renewed every third of LeaseTTL.
+	LeaseTTL time.Duration
+}
+
+// LeaderStatus is the view of the ingestion lease of a LeaderElector.
+type LeaderStatus struct {
+	Identity string
+	IsLeader bool
+	// Lease is the latest state of the lease known by this instance, it is
+	// zero if no instance has ever held the lease.
+	Lease history.IngestionLease
+}
+
+// LeaderElector elects the instance which ingests ledgers among the ingesting
+// instances sharing a database. Unlike the `FOR UPDATE` lock on the last
+// ingested ledger, which is held by whichever instance gets it first for each
+// ledger, the leader keeps the ingestion lease until it stops renewing it or
+// is requested to hand it off. The fencing token of the lease is checked in
+// the transaction committing every ledger, so a leader which lost the lease
+// without noticing cannot ingest anymore.
+type LeaderElector struct {
+	historyQ          leaderElectorQ
+	identity          string
+	ttl               time.Duration
+	heartbeatInterval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	lock     sync.Mutex
+	lease    history.IngestionLease
+	isLeader bool
+	// validUntil is the local time until which this instance considers it
+	// holds the lease. It is measured from before the lease was renewed so
+	// that it never outlives the lease in the database.
+	validUntil time.Time
+
+	isLeaderGauge prometheus.Gauge
+}
+
+// NewLeaderElector returns a new LeaderElector.
+func NewLeaderElector(config LeaderElectorConfig) *LeaderElector {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &LeaderElector{
+		historyQ:          &history.Q{SessionInterface: config.HistorySession},
+		identity:          config.Identity,
+		ttl:               config.LeaseTTL,
+		heartbeatInterval: config.LeaseTTL / 3,
+		ctx:               ctx,
+		cancel:            cancel,
+		isLeaderGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "orbitr", Subsystem: "ingest", Name: "leader",
+			Help: "equals 1 if this instance holds the ingestion lease, 0 otherwise",
+		}),
+	}
+}
+
+// RegisterMetrics registers the prometheus metrics
+func (e *LeaderElector) RegisterMetrics(registry *prometheus.Registry) {
+	registry.MustRegister(e.isLeaderGauge)
+}
+
+// Run renews or tries to acquire the ingestion lease every heartbeat until
+// Shutdown is called.
+func (e *LeaderElector) Run() {
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.heartbeat(e.ctx); err != nil && e.ctx.Err() == nil {
+			log.WithField("subservice", "leader_election").WithError(err).Error("Error renewing ingestion lease")
+		}
+
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown stops renewing the lease and releases it so that another instance
+// can take over without waiting for it to expire. It must be called after
+// the ingestion system has been shut down.
+func (e *LeaderElector) Shutdown() {
+	e.cancel()
+	e.wg.Wait()
+
+	e.lock.Lock()
+	wasLeader, fencingToken := e.isLeader, e.lease.FencingToken
+	e.stepDown()
+	e.lock.Unlock()
+	if wasLeader {
+		e.release(context.Background(), fencingToken)
+	}
+}
+
+// FencingToken returns the fencing token of the lease and true if this
+// instance holds the lease.
+func (e *LeaderElector) FencingToken() (int64, bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if !e.isLeader || !time.Now().Before(e.validUntil) {
+		return 0, false
+	}
+	return e.lease.FencingToken, true
+}
+
+// Status returns the latest state of the lease known by this instance.
+func (e *LeaderElector) Status() LeaderStatus {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return LeaderStatus{
+		Identity: e.identity,
+		IsLeader: e.isLeader && time.Now().Before(e.validUntil),
+		Lease:    e.lease,
+	}
+}
+
+// RequestHandoff requests the leader to step down in favour of the instance
+// identified by to, or of any other instance when to is empty. The leader
+// finishes ingesting the current ledger and releases the lease at its next
+// heartbeat.
+func (e *LeaderElector) RequestHandoff(ctx context.Context, to string) (history.IngestionLease, error) {
+	return RequestIngestionLeaseHandoff(ctx, e.historyQ, to)
+}
+
+// RequestIngestionLeaseHandoff requests the leader holding the ingestion
+// lease to step down in favour of the instance identified by to, or of any
+// other instance when to is empty.
+func RequestIngestionLeaseHandoff(ctx context.Context, q leaderElectorQ, to string) (history.IngestionLease, error) {
+	lease, err := q.GetIngestionLease(ctx, IngestionLeaseName)
+	if err != nil {
+		return history.IngestionLease{}, errors.Wrap(err, "could not get ingestion lease")
+	}
+	if to != "" && to == lease.LeaderID {
+		return history.IngestionLease{}, ErrHandoffToLeader
+	}
+
+	lease, err = q.RequestIngestionLeaseHandoff(ctx, IngestionLeaseName, to)
+	if err != nil {
+		return history.IngestionLease{}, errors.Wrap(err, "could not request ingestion lease handoff")
+	}
+	return lease, nil
+}
+
+func (e *LeaderElector) heartbeat(ctx context.Context) error {
+	localLog := log.WithField("subservice", "leader_election")
+	start := time.Now()
+
+	lease, acquired, err := e.historyQ.AcquireIngestionLease(ctx, IngestionLeaseName, e.identity, e.ttl)
+	if err != nil {
+		// FencingToken stops reporting the lease once validUntil has passed
+		return err
+	}
+
+	e.lock.Lock()
+	wasLeader := e.isLeader
+	e.lease, e.isLeader = lease, acquired
+	if acquired {
+		e.validUntil = start.Add(e.ttl)
+	}
+
+	handoff := acquired && lease.HandoffTo.Valid && lease.HandoffTo.String != e.identity
+	if handoff {
+		// ingestion stops before the lease is released, outside of the
+		// lock, so that Status does not wait for the release
+		e.stepDown()
+	}
+	if e.isLeader {
+		e.isLeaderGauge.Set(1)
+	} else {
+		e.isLeaderGauge.Set(0)
+	}
+	e.lock.Unlock()
+
+	if handoff {
+		localLog.WithFields(logpkg.F{
+			"fencing_token": lease.FencingToken,
+			"handoff_to":    lease.HandoffTo.String,
+		}).Info("Handing off ingestion leadership")
+		e.release(ctx, lease.FencingToken)
+	} else if acquired && !wasLeader {
+		localLog.WithField("fencing_token", lease.FencingToken).Info("Acquired ingestion leadership")
+	} else if !acquired && wasLeader {
+		localLog.WithField("leader", lease.LeaderID).Warn("Lost ingestion leadership")
+	}
+	return nil
+}
+
+// stepDown stops reporting the lease as held, it must be called with e.lock
+// held.
+func (e *LeaderElector) stepDown() {
+	e.isLeader = false
+	e.validUntil = time.Time{}
+}
+
+// release releases the lease with the given fencing token. It must be called
+// without e.lock held after stepDown: releasing waits for the ingestion
+// transaction holding the lease, if any, to finish.
+func (e *LeaderElector) release(ctx context.Context, fencingToken int64) {
+	err := e.historyQ.ReleaseIngestionLease(ctx, IngestionLeaseName, e.identity, fencingToken)
+	if err != nil {
+		log.WithField("subservice", "leader_election").WithError(err).Warn("Error releasing ingestion lease")
+	}
+}
+
+// holdsIngestionLease returns true if leader election is disabled or if this
+// instance holds the ingestion lease. It must be called in the transaction
+// ingesting ledgers, which then keeps the lease from changing hands until it
+// is committed or rolled back.
+func (s *system) holdsIngestionLease() (bool, error) {
+	if s.config.LeaderElector == nil {
+		return true, nil
+	}
+
+	fencingToken, ok := s.config.LeaderElector.FencingToken()
+	if !ok {
+		return false, nil
+	}
+	valid, err := s.historyQ.CheckIngestionLeaseFencingToken(s.ctx, IngestionLeaseName, fencingToken)
+	if err != nil {
+		return false, errors.Wrap(err, "Error checking ingestion lease")
+	}
+	return valid, nil
+}
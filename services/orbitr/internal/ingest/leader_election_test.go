@@ -0,0 +1,114 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+)
+
+func newTestLeaderElector(q *history.MockQIngestionLeases) *LeaderElector {
+	elector := NewLeaderElector(LeaderElectorConfig{Identity: "a", LeaseTTL: 30 * time.Second})
+	elector.historyQ = q
+	return elector
+}
+
+func TestLeaderElectorHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	q := &history.MockQIngestionLeases{}
+	elector := newTestLeaderElector(q)
+
+	_, ok := elector.FencingToken()
+	assert.False(t, ok)
+
+	lease := history.IngestionLease{Name: IngestionLeaseName, LeaderID: "a", FencingToken: 2}
+	q.On("AcquireIngestionLease", ctx, IngestionLeaseName, "a", 30*time.Second).Return(lease, true, nil).Once()
+	require.NoError(t, elector.heartbeat(ctx))
+	token, ok := elector.FencingToken()
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), token)
+	assert.Equal(t, LeaderStatus{Identity: "a", IsLeader: true, Lease: lease}, elector.Status())
+
+	// the leader steps down when a handoff is requested
+	lease.HandoffTo = null.StringFrom("b")
+	q.On("AcquireIngestionLease", ctx, IngestionLeaseName, "a", 30*time.Second).Return(lease, true, nil).Once()
+	q.On("ReleaseIngestionLease", ctx, IngestionLeaseName, "a", int64(2)).Return(nil).Once()
+	require.NoError(t, elector.heartbeat(ctx))
+	_, ok = elector.FencingToken()
+	assert.False(t, ok)
+
+	lease = history.IngestionLease{Name: IngestionLeaseName, LeaderID: "b", FencingToken: 3}
+	q.On("AcquireIngestionLease", ctx, IngestionLeaseName, "a", 30*time.Second).Return(lease, false, nil).Once()
+	require.NoError(t, elector.heartbeat(ctx))
+	assert.Equal(t, LeaderStatus{Identity: "a", IsLeader: false, Lease: lease}, elector.Status())
+
+	q.AssertExpectations(t)
+}
+
+func TestLeaderElectorExpiredLease(t *testing.T) {
+	elector := newTestLeaderElector(&history.MockQIngestionLeases{})
+	elector.isLeader = true
+	elector.lease = history.IngestionLease{LeaderID: "a", FencingToken: 1}
+	// the lease was not renewed in time
+	elector.validUntil = time.Now().Add(-time.Second)
+
+	_, ok := elector.FencingToken()
+	assert.False(t, ok)
+	assert.False(t, elector.Status().IsLeader)
+}
+
+func TestLeaderElectorStatusDuringRelease(t *testing.T) {
+	ctx := context.Background()
+	q := &history.MockQIngestionLeases{}
+	elector := newTestLeaderElector(q)
+
+	lease := history.IngestionLease{
+		Name:         IngestionLeaseName,
+		LeaderID:     "a",
+		FencingToken: 2,
+		HandoffTo:    null.StringFrom("b"),
+	}
+	releasing := make(chan struct{})
+	released := make(chan struct{})
+	q.On("AcquireIngestionLease", ctx, IngestionLeaseName, "a", 30*time.Second).Return(lease, true, nil).Once()
+	// releasing waits for the ingestion transaction holding the lease
+	q.On("ReleaseIngestionLease", ctx, IngestionLeaseName, "a", int64(2)).Run(func(mock.Arguments) {
+		close(releasing)
+		<-released
+	}).Return(nil).Once()
+
+	done := make(chan struct{})
+	go func() {
+		assert.NoError(t, elector.heartbeat(ctx))
+		close(done)
+	}()
+
+	<-releasing
+	assert.False(t, elector.Status().IsLeader)
+	close(released)
+	<-done
+	q.AssertExpectations(t)
+}
+
+func TestRequestIngestionLeaseHandoff(t *testing.T) {
+	ctx := context.Background()
+	q := &history.MockQIngestionLeases{}
+	lease := history.IngestionLease{Name: IngestionLeaseName, LeaderID: "a", FencingToken: 1}
+	q.On("GetIngestionLease", ctx, IngestionLeaseName).Return(lease, nil)
+
+	_, err := RequestIngestionLeaseHandoff(ctx, q, "a")
+	assert.Equal(t, ErrHandoffToLeader, err)
+
+	lease.HandoffTo = null.StringFrom("b")
+	q.On("RequestIngestionLeaseHandoff", ctx, IngestionLeaseName, "b").Return(lease, nil).Once()
+	updated, err := RequestIngestionLeaseHandoff(ctx, q, "b")
+	require.NoError(t, err)
+	assert.Equal(t, lease, updated)
+	q.AssertExpectations(t)
+}
@@ -18,10 +18,12 @@ import (
 	"github.com/lantah/go/ingest/ledgerbackend"
 	"github.com/lantah/go/services/orbitr/internal/db2/history"
 	"github.com/lantah/go/services/orbitr/internal/ingest/filters"
+	"github.com/lantah/go/services/orbitr/internal/ingest/sink"
 	apkg "github.com/lantah/go/support/app"
 	"github.com/lantah/go/support/db"
 	"github.com/lantah/go/support/errors"
 	logpkg "github.com/lantah/go/support/log"
+	"github.com/lantah/go/xdr"
 )
 
 const (
@@ -99,10 +101,32 @@ type Config struct {
 	CheckpointFrequency                  uint32
 	StateVerificationCheckpointFrequency uint32
 	StateVerificationTimeout             time.Duration
+	// StateVerificationShards enables incremental state verification when
+	// greater than 0: every eligible checkpoint only verifies the entries of
+	// a single type whose keys hash to one of StateVerificationShards shards.
+	StateVerificationShards uint32
 
 	RoundingSlippageFilter int
 
 	EnableIngestionFiltering bool
+
+	// HistorySink, if set, receives the ledgers, transactions, operations,
+	// effects and trades written to the history tables by the processors.
+	HistorySink sink.Publisher
+
+	// LedgerEntryChangeTypes are the types of the ledger entries whose raw
+	// changes are stored in the history_ledger_entry_changes table. No
+	// changes are stored when empty.
+	LedgerEntryChangeTypes []xdr.LedgerEntryType
+
+	// LeaderElector, if set, restricts ingestion to the instance holding the
+	// ingestion lease. Otherwise any instance can ingest the next ledger.
+	LeaderElector *LeaderElector
+
+	// reingestProgress, if set, is called after every ledger processed when
+	// reingesting ranges. It is used by ReingestJobManager to report the
+	// progress of reingestion jobs.
+	reingestProgress func(ledger uint32)
 }
 
 // LocalCaptiveCoreEnabled returns true if configured to run
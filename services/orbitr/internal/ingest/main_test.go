@@ -288,6 +288,9 @@ type mockDBQ struct {
 
 	history.MockQAccounts
 	history.MockQFilter
+	history.MockQIngestionLeases
+	history.MockQStateVerification
+	history.MockQLedgerEntryChanges
 	history.MockQClaimableBalances
 	history.MockQHistoryClaimableBalances
 	history.MockQLiquidityPools
@@ -338,6 +341,11 @@ func (m *mockDBQ) TryStateVerificationLock(ctx context.Context) (bool, error) {
 	return args.Get(0).(bool), args.Error(1)
 }
 
+func (m *mockDBQ) EnsureHistoryPartitions(ctx context.Context, ledger uint32) error {
+	args := m.Called(ctx, ledger)
+	return args.Error(0)
+}
+
 func (m *mockDBQ) GetTx() *sqlx.Tx {
 	args := m.Called()
 	if args.Get(0) == nil {
@@ -423,6 +431,16 @@ func (m *mockDBQ) NewOperationParticipantBatchInsertBuilder(maxBatchSize int) hi
 	return args.Get(0).(history.TransactionParticipantsBatchInsertBuilder)
 }
 
+func (m *mockDBQ) NewTransactionMuxedParticipantsBatchInsertBuilder(maxBatchSize int) history.TransactionMuxedParticipantsBatchInsertBuilder {
+	args := m.Called(maxBatchSize)
+	return args.Get(0).(history.TransactionMuxedParticipantsBatchInsertBuilder)
+}
+
+func (m *mockDBQ) NewOperationMuxedParticipantBatchInsertBuilder(maxBatchSize int) history.OperationMuxedParticipantBatchInsertBuilder {
+	args := m.Called(maxBatchSize)
+	return args.Get(0).(history.OperationMuxedParticipantBatchInsertBuilder)
+}
+
 func (m *mockDBQ) NewTradeBatchInsertBuilder(maxBatchSize int) history.TradeBatchInsertBuilder {
 	args := m.Called(maxBatchSize)
 	return args.Get(0).(history.TradeBatchInsertBuilder)
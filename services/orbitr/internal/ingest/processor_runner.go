@@ -10,6 +10,7 @@ import (
 	"github.com/lantah/go/services/orbitr/internal/db2/history"
 	"github.com/lantah/go/services/orbitr/internal/ingest/filters"
 	"github.com/lantah/go/services/orbitr/internal/ingest/processors"
+	"github.com/lantah/go/services/orbitr/internal/ingest/sink"
 	"github.com/lantah/go/support/errors"
 	"github.com/lantah/go/xdr"
 )
@@ -139,19 +140,34 @@ func (s *ProcessorRunner) buildTransactionProcessor(
 	statsLedgerTransactionProcessor := &statsLedgerTransactionProcessor{
 		StatsLedgerTransactionProcessor: ledgerTransactionStats,
 	}
-	*tradeProcessor = *processors.NewTradeProcessor(s.historyQ, ledger)
+	historySinkQ := s.historySinkQ()
+	*tradeProcessor = *processors.NewTradeProcessor(historySinkQ, ledger)
 	sequence := uint32(ledger.Header.LedgerSeq)
-	return newGroupTransactionProcessors([]orbitrTransactionProcessor{
+	p := []orbitrTransactionProcessor{
 		statsLedgerTransactionProcessor,
-		processors.NewEffectProcessor(s.historyQ, sequence, s.config.NetworkPassphrase),
-		processors.NewLedgerProcessor(s.historyQ, ledger, CurrentVersion),
-		processors.NewOperationProcessor(s.historyQ, sequence, s.config.NetworkPassphrase),
+		processors.NewEffectProcessor(historySinkQ, sequence, s.config.NetworkPassphrase),
+		processors.NewLedgerProcessor(historySinkQ, ledger, CurrentVersion),
+		processors.NewOperationProcessor(historySinkQ, sequence, s.config.NetworkPassphrase),
 		tradeProcessor,
 		processors.NewParticipantsProcessor(s.historyQ, sequence),
-		processors.NewTransactionProcessor(s.historyQ, sequence),
+		processors.NewTransactionProcessor(historySinkQ, sequence),
 		processors.NewClaimableBalancesTransactionProcessor(s.historyQ, sequence),
 		processors.NewLiquidityPoolsTransactionProcessor(s.historyQ, sequence),
-	})
+	}
+	if len(s.config.LedgerEntryChangeTypes) > 0 {
+		p = append(p, processors.NewLedgerEntryChangesProcessor(s.historyQ, sequence, s.config.LedgerEntryChangeTypes))
+	}
+	return newGroupTransactionProcessors(p)
+}
+
+// historySinkQ returns the queries the processors write ledgers,
+// transactions, operations, effects and trades with. They are also published
+// to the history sink when one is configured.
+func (s *ProcessorRunner) historySinkQ() sink.HistoryQ {
+	if s.config.HistorySink == nil {
+		return s.historyQ
+	}
+	return sink.NewHistoryQ(s.historyQ, s.config.HistorySink)
 }
 
 func (s *ProcessorRunner) buildTransactionFilterer() *groupTransactionFilterers {
@@ -353,6 +369,14 @@ func (s *ProcessorRunner) RunTransactionProcessorsOnLedger(ledger xdr.LedgerClos
 		}
 	}
 
+	// History tables are partitioned by ledger range, make sure the partition
+	// receiving this ledger's rows exists before flushing them.
+	err = s.historyQ.EnsureHistoryPartitions(s.ctx, ledger.LedgerSequence())
+	if err != nil {
+		err = errors.Wrap(err, "Error creating history partitions")
+		return
+	}
+
 	err = groupTransactionProcessors.Commit(s.ctx)
 	if err != nil {
 		err = errors.Wrap(err, "Error committing changes from processor")
@@ -319,6 +319,8 @@ func TestProcessorRunnerWithFilterEnabled(t *testing.T) {
 	q.MockQLedgers.On("InsertLedger", ctx, ledger.V0.LedgerHeader, 0, 0, 0, 0, CurrentVersion).
 		Return(int64(1), nil).Once()
 
+	q.On("EnsureHistoryPartitions", ctx, uint32(0)).Return(nil).Once()
+
 	runner := ProcessorRunner{
 		ctx:      ctx,
 		config:   config,
@@ -375,6 +377,8 @@ func TestProcessorRunnerRunAllProcessorsOnLedger(t *testing.T) {
 	q.MockQLedgers.On("InsertLedger", ctx, ledger.V0.LedgerHeader, 0, 0, 0, 0, CurrentVersion).
 		Return(int64(1), nil).Once()
 
+	q.On("EnsureHistoryPartitions", ctx, uint32(0)).Return(nil).Once()
+
 	runner := ProcessorRunner{
 		ctx:      ctx,
 		config:   config,
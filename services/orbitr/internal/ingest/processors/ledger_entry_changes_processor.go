@@ -0,0 +1,160 @@
+package processors
+
+import (
+	"context"
+
+	"github.com/guregu/null"
+
+	"github.com/lantah/go/ingest"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/toid"
+	"github.com/lantah/go/xdr"
+)
+
+// LedgerEntryChangesProcessor stores the raw changes of the ledger entries of
+// the given types, in the order they were applied by every transaction.
+type LedgerEntryChangesProcessor struct {
+	changesQ   history.QLedgerEntryChanges
+	sequence   uint32
+	entryTypes map[xdr.LedgerEntryType]bool
+	batch      history.LedgerEntryChangeBatchInsertBuilder
+}
+
+func NewLedgerEntryChangesProcessor(
+	changesQ history.QLedgerEntryChanges,
+	sequence uint32,
+	entryTypes []xdr.LedgerEntryType,
+) *LedgerEntryChangesProcessor {
+	types := map[xdr.LedgerEntryType]bool{}
+	for _, entryType := range entryTypes {
+		types[entryType] = true
+	}
+	return &LedgerEntryChangesProcessor{
+		changesQ:   changesQ,
+		sequence:   sequence,
+		entryTypes: types,
+		batch:      changesQ.NewLedgerEntryChangeBatchInsertBuilder(maxBatchSize),
+	}
+}
+
+func (p *LedgerEntryChangesProcessor) ProcessTransaction(ctx context.Context, transaction ingest.LedgerTransaction) error {
+	transactionID := toid.New(int32(p.sequence), int32(transaction.Index), 0).ToInt64()
+	order := int32(0)
+	add := func(changes []ingest.Change, operationIndex null.Int) error {
+		for _, change := range changes {
+			order++
+			if !p.entryTypes[change.Type] {
+				continue
+			}
+			row, err := ledgerEntryChangeRow(change, transactionID, order, operationIndex)
+			if err != nil {
+				return err
+			}
+			if err = p.batch.Add(ctx, row); err != nil {
+				return errors.Wrap(err, "Error batch inserting ledger entry change rows")
+			}
+		}
+		return nil
+	}
+
+	if err := add(transaction.GetFeeChanges(), null.Int{}); err != nil {
+		return err
+	}
+
+	before, after, err := transactionLevelChanges(transaction)
+	if err != nil {
+		return err
+	}
+	if err = add(before, null.Int{}); err != nil {
+		return err
+	}
+	for i := range transaction.Envelope.Operations() {
+		changes, err := transaction.GetOperationChanges(uint32(i))
+		if err != nil {
+			return err
+		}
+		if err = add(changes, null.IntFrom(int64(i))); err != nil {
+			return err
+		}
+	}
+	return add(after, null.Int{})
+}
+
+func (p *LedgerEntryChangesProcessor) Commit(ctx context.Context) error {
+	if err := p.batch.Exec(ctx); err != nil {
+		return errors.Wrap(err, "Error flushing ledger entry change batch")
+	}
+
+	return nil
+}
+
+// transactionLevelChanges returns the changes a transaction applied before and
+// after its operations, following the same rules as
+// ingest.LedgerTransaction.GetChanges.
+func transactionLevelChanges(transaction ingest.LedgerTransaction) ([]ingest.Change, []ingest.Change, error) {
+	internalError := transaction.Result.Result.Result.Code == xdr.TransactionResultCodeTxInternalError &&
+		transaction.LedgerVersion <= 12
+
+	var before, after xdr.LedgerEntryChanges
+	switch transaction.UnsafeMeta.V {
+	case 1:
+		before = transaction.UnsafeMeta.MustV1().TxChanges
+	case 2:
+		before = transaction.UnsafeMeta.MustV2().TxChangesBefore
+		after = transaction.UnsafeMeta.MustV2().TxChangesAfter
+	case 3:
+		before = transaction.UnsafeMeta.MustV3().TxChangesBefore
+		after = transaction.UnsafeMeta.MustV3().TxChangesAfter
+	default:
+		return nil, nil, errors.Errorf("unsupported TransactionMeta version %d", transaction.UnsafeMeta.V)
+	}
+
+	if internalError {
+		after = nil
+	}
+	return ingest.GetChangesFromLedgerEntryChanges(before), ingest.GetChangesFromLedgerEntryChanges(after), nil
+}
+
+func ledgerEntryChangeRow(
+	change ingest.Change,
+	transactionID int64,
+	order int32,
+	operationIndex null.Int,
+) (history.LedgerEntryChange, error) {
+	row := history.LedgerEntryChange{
+		TransactionID:  transactionID,
+		Order:          order,
+		OperationIndex: operationIndex,
+		EntryType:      change.Type,
+		ChangeType:     change.LedgerEntryChangeType(),
+	}
+
+	entry := change.Post
+	if entry == nil {
+		entry = change.Pre
+	}
+	key, err := entry.LedgerKey()
+	if err != nil {
+		return row, errors.Wrap(err, "could not get ledger key")
+	}
+	if row.LedgerKey, err = xdr.MarshalBase64(key); err != nil {
+		return row, errors.Wrap(err, "could not encode ledger key")
+	}
+
+	if change.Pre != nil {
+		encoded, err := xdr.MarshalBase64(change.Pre)
+		if err != nil {
+			return row, errors.Wrap(err, "could not encode pre entry")
+		}
+		row.PreEntry = null.StringFrom(encoded)
+	}
+	if change.Post != nil {
+		encoded, err := xdr.MarshalBase64(change.Post)
+		if err != nil {
+			return row, errors.Wrap(err, "could not encode post entry")
+		}
+		row.PostEntry = null.StringFrom(encoded)
+	}
+	return row, nil
+}
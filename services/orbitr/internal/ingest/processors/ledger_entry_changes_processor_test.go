@@ -0,0 +1,154 @@
+//lint:file-ignore U1001 Ignore all unused code, staticcheck doesn't understand testify/suite
+
+package processors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/lantah/go/ingest"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/toid"
+	"github.com/lantah/go/xdr"
+)
+
+type LedgerEntryChangesProcessorTestSuiteLedger struct {
+	suite.Suite
+	ctx                    context.Context
+	processor              *LedgerEntryChangesProcessor
+	mockQ                  *history.MockQLedgerEntryChanges
+	mockBatchInsertBuilder *history.MockLedgerEntryChangeBatchInsertBuilder
+	sequence               uint32
+}
+
+func TestLedgerEntryChangesProcessorTestSuiteLedger(t *testing.T) {
+	suite.Run(t, new(LedgerEntryChangesProcessorTestSuiteLedger))
+}
+
+func (s *LedgerEntryChangesProcessorTestSuiteLedger) SetupTest() {
+	s.ctx = context.Background()
+	s.sequence = 20
+	s.mockQ = &history.MockQLedgerEntryChanges{}
+	s.mockBatchInsertBuilder = &history.MockLedgerEntryChangeBatchInsertBuilder{}
+
+	s.mockQ.
+		On("NewLedgerEntryChangeBatchInsertBuilder", maxBatchSize).
+		Return(s.mockBatchInsertBuilder).Once()
+
+	s.processor = NewLedgerEntryChangesProcessor(
+		s.mockQ,
+		s.sequence,
+		[]xdr.LedgerEntryType{xdr.LedgerEntryTypeAccount},
+	)
+}
+
+func (s *LedgerEntryChangesProcessorTestSuiteLedger) TearDownTest() {
+	s.mockQ.AssertExpectations(s.T())
+	s.mockBatchInsertBuilder.AssertExpectations(s.T())
+}
+
+func ledgerEntryChangeTestAccount(balance xdr.Int64, seqNum xdr.SequenceNumber) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 20,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{
+				AccountId: xdr.MustAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"),
+				Balance:   balance,
+				SeqNum:    seqNum,
+			},
+		},
+	}
+}
+
+func ledgerEntryChangeTestUpdate(pre, post xdr.LedgerEntry) []xdr.LedgerEntryChange {
+	return []xdr.LedgerEntryChange{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &pre},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &post},
+	}
+}
+
+func (s *LedgerEntryChangesProcessorTestSuiteLedger) expectedRow(
+	tx ingest.LedgerTransaction,
+	order int32,
+	operationIndex null.Int,
+	pre, post xdr.LedgerEntry,
+) history.LedgerEntryChange {
+	key, err := post.LedgerKey()
+	s.Require().NoError(err)
+	encodedKey, err := xdr.MarshalBase64(key)
+	s.Require().NoError(err)
+	encodedPre, err := xdr.MarshalBase64(pre)
+	s.Require().NoError(err)
+	encodedPost, err := xdr.MarshalBase64(post)
+	s.Require().NoError(err)
+	return history.LedgerEntryChange{
+		TransactionID:  toid.New(int32(s.sequence), int32(tx.Index), 0).ToInt64(),
+		Order:          order,
+		OperationIndex: operationIndex,
+		LedgerKey:      encodedKey,
+		EntryType:      xdr.LedgerEntryTypeAccount,
+		ChangeType:     xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+		PreEntry:       null.StringFrom(encodedPre),
+		PostEntry:      null.StringFrom(encodedPost),
+	}
+}
+
+func (s *LedgerEntryChangesProcessorTestSuiteLedger) TestAddChanges() {
+	trustLine := xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 20,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeTrustline,
+			TrustLine: &xdr.TrustLineEntry{
+				AccountId: xdr.MustAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"),
+				Asset:     xdr.MustNewCreditAsset("USD", "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY").ToTrustLineAsset(),
+				Limit:     1000,
+			},
+		},
+	}
+	accounts := []xdr.LedgerEntry{
+		ledgerEntryChangeTestAccount(100, 1),
+		ledgerEntryChangeTestAccount(90, 1),
+		ledgerEntryChangeTestAccount(90, 2),
+		ledgerEntryChangeTestAccount(80, 2),
+	}
+
+	tx := createTransaction(true, 1)
+	tx.Index = 3
+	tx.FeeChanges = ledgerEntryChangeTestUpdate(accounts[0], accounts[1])
+	tx.UnsafeMeta.V2.TxChangesBefore = ledgerEntryChangeTestUpdate(accounts[1], accounts[2])
+	tx.UnsafeMeta.V2.Operations[0].Changes = append(
+		xdr.LedgerEntryChanges{{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: &trustLine}},
+		ledgerEntryChangeTestUpdate(accounts[2], accounts[3])...,
+	)
+
+	// the trust line change is skipped but still counted in the order of the
+	// transaction changes
+	s.mockBatchInsertBuilder.On("Add", s.ctx, s.expectedRow(tx, 1, null.Int{}, accounts[0], accounts[1])).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Add", s.ctx, s.expectedRow(tx, 2, null.Int{}, accounts[1], accounts[2])).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Add", s.ctx, s.expectedRow(tx, 4, null.IntFrom(0), accounts[2], accounts[3])).Return(nil).Once()
+	s.mockBatchInsertBuilder.On("Exec", s.ctx).Return(nil).Once()
+
+	s.Assert().NoError(s.processor.ProcessTransaction(s.ctx, tx))
+	s.Assert().NoError(s.processor.Commit(s.ctx))
+}
+
+func (s *LedgerEntryChangesProcessorTestSuiteLedger) TestAddChangesFails() {
+	tx := createTransaction(true, 0)
+	tx.FeeChanges = ledgerEntryChangeTestUpdate(ledgerEntryChangeTestAccount(100, 1), ledgerEntryChangeTestAccount(90, 1))
+	s.mockBatchInsertBuilder.On("Add", s.ctx, s.expectedRow(tx, 1, null.Int{}, ledgerEntryChangeTestAccount(100, 1), ledgerEntryChangeTestAccount(90, 1))).
+		Return(errors.New("transient error")).Once()
+
+	err := s.processor.ProcessTransaction(s.ctx, tx)
+	s.Assert().EqualError(err, "Error batch inserting ledger entry change rows: transient error")
+}
+
+func (s *LedgerEntryChangesProcessorTestSuiteLedger) TestExecFails() {
+	s.mockBatchInsertBuilder.On("Exec", s.ctx).Return(errors.New("transient error")).Once()
+	err := s.processor.Commit(s.ctx)
+	s.Assert().EqualError(err, "Error flushing ledger entry change batch: transient error")
+}
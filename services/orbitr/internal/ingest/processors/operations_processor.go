@@ -1002,6 +1002,48 @@ func (operation *transactionOperationWrapper) Participants() ([]xdr.AccountId, e
 	return dedupeParticipants(participants), nil
 }
 
+// MuxedParticipants returns the M-addresses of the muxed accounts taking part
+// in the operation. Only the accounts which can be expressed as muxed accounts
+// in the operation body (source, payment destinations, merge destination and
+// clawback source) are considered.
+func (operation *transactionOperationWrapper) MuxedParticipants() []string {
+	accounts := []xdr.MuxedAccount{*operation.SourceAccount()}
+	op := operation.operation
+
+	switch operation.OperationType() {
+	case xdr.OperationTypePayment:
+		accounts = append(accounts, op.Body.MustPaymentOp().Destination)
+	case xdr.OperationTypePathPaymentStrictReceive:
+		accounts = append(accounts, op.Body.MustPathPaymentStrictReceiveOp().Destination)
+	case xdr.OperationTypePathPaymentStrictSend:
+		accounts = append(accounts, op.Body.MustPathPaymentStrictSendOp().Destination)
+	case xdr.OperationTypeAccountMerge:
+		accounts = append(accounts, op.Body.MustDestination())
+	case xdr.OperationTypeClawback:
+		accounts = append(accounts, op.Body.MustClawbackOp().From)
+	}
+
+	return muxedAddresses(accounts)
+}
+
+// muxedAddresses returns the deduplicated M-addresses of the accounts in `in`
+// which are muxed, skipping the plain ed25519 ones.
+func muxedAddresses(in []xdr.MuxedAccount) (out []string) {
+	set := map[string]struct{}{}
+	for _, account := range in {
+		if account.Type != xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
+			continue
+		}
+		address := account.Address()
+		if _, ok := set[address]; ok {
+			continue
+		}
+		set[address] = struct{}{}
+		out = append(out, address)
+	}
+	return
+}
+
 // dedupeParticipants remove any duplicate ids from `in`
 func dedupeParticipants(in []xdr.AccountId) (out []xdr.AccountId) {
 	set := map[string]xdr.AccountId{}
@@ -19,13 +19,17 @@ type ParticipantsProcessor struct {
 	participantsQ  history.QParticipants
 	sequence       uint32
 	participantSet map[string]participant
+	// muxedParticipantSet is keyed by M-address. The accountID of its
+	// entries is unused as muxed participants are stored by address.
+	muxedParticipantSet map[string]participant
 }
 
 func NewParticipantsProcessor(participantsQ history.QParticipants, sequence uint32) *ParticipantsProcessor {
 	return &ParticipantsProcessor{
-		participantsQ:  participantsQ,
-		sequence:       sequence,
-		participantSet: map[string]participant{},
+		participantsQ:       participantsQ,
+		sequence:            sequence,
+		participantSet:      map[string]participant{},
+		muxedParticipantSet: map[string]participant{},
 	}
 }
 
@@ -186,6 +190,27 @@ func (p *ParticipantsProcessor) addOperationsParticipants(
 	return nil
 }
 
+func (p *ParticipantsProcessor) addMuxedParticipants(
+	muxedParticipantSet map[string]participant,
+	sequence uint32,
+	transaction ingest.LedgerTransaction,
+) {
+	transactionID := toid.New(int32(sequence), int32(transaction.Index), 0).ToInt64()
+	for _, address := range MuxedParticipantsForTransaction(sequence, transaction) {
+		entry := muxedParticipantSet[address]
+		entry.addTransactionID(transactionID)
+		muxedParticipantSet[address] = entry
+	}
+
+	for operationID, addresses := range operationsMuxedParticipants(transaction, sequence) {
+		for _, address := range addresses {
+			entry := muxedParticipantSet[address]
+			entry.addOperationID(operationID)
+			muxedParticipantSet[address] = entry
+		}
+	}
+}
+
 func (p *ParticipantsProcessor) insertDBTransactionParticipants(ctx context.Context, participantSet map[string]participant) error {
 	batch := p.participantsQ.NewTransactionParticipantsBatchInsertBuilder(maxBatchSize)
 
@@ -220,6 +245,32 @@ func (p *ParticipantsProcessor) insertDBOperationsParticipants(ctx context.Conte
 	return nil
 }
 
+func (p *ParticipantsProcessor) insertDBMuxedParticipants(ctx context.Context, muxedParticipantSet map[string]participant) error {
+	transactionBatch := p.participantsQ.NewTransactionMuxedParticipantsBatchInsertBuilder(maxBatchSize)
+	operationBatch := p.participantsQ.NewOperationMuxedParticipantBatchInsertBuilder(maxBatchSize)
+
+	for address, entry := range muxedParticipantSet {
+		for transactionID := range entry.transactionSet {
+			if err := transactionBatch.Add(ctx, transactionID, address); err != nil {
+				return errors.Wrap(err, "could not insert muxed transaction participant in db")
+			}
+		}
+		for operationID := range entry.operationSet {
+			if err := operationBatch.Add(ctx, operationID, address); err != nil {
+				return errors.Wrap(err, "could not insert muxed operation participant in db")
+			}
+		}
+	}
+
+	if err := transactionBatch.Exec(ctx); err != nil {
+		return errors.Wrap(err, "could not flush muxed transaction participants to db")
+	}
+	if err := operationBatch.Exec(ctx); err != nil {
+		return errors.Wrap(err, "could not flush muxed operation participants to db")
+	}
+	return nil
+}
+
 func (p *ParticipantsProcessor) ProcessTransaction(ctx context.Context, transaction ingest.LedgerTransaction) (err error) {
 	err = p.addTransactionParticipants(p.participantSet, p.sequence, transaction)
 	if err != nil {
@@ -231,6 +282,8 @@ func (p *ParticipantsProcessor) ProcessTransaction(ctx context.Context, transact
 		return err
 	}
 
+	p.addMuxedParticipants(p.muxedParticipantSet, p.sequence, transaction)
+
 	return nil
 }
 
@@ -249,6 +302,12 @@ func (p *ParticipantsProcessor) Commit(ctx context.Context) (err error) {
 		}
 	}
 
+	if len(p.muxedParticipantSet) > 0 {
+		if err = p.insertDBMuxedParticipants(ctx, p.muxedParticipantSet); err != nil {
+			return err
+		}
+	}
+
 	return err
 }
 
@@ -294,3 +353,53 @@ func ParticipantsForTransaction(
 
 	return dedupeParticipants(participants), nil
 }
+
+// MuxedParticipantsForTransaction returns the M-addresses of the muxed accounts
+// taking part in the transaction, either as its source, fee bump source or in
+// any of its operations.
+func MuxedParticipantsForTransaction(
+	sequence uint32,
+	transaction ingest.LedgerTransaction,
+) []string {
+	accounts := []xdr.MuxedAccount{transaction.Envelope.SourceAccount()}
+	if transaction.Envelope.IsFeeBump() {
+		accounts = append(accounts, transaction.Envelope.FeeBumpAccount())
+	}
+	addresses := muxedAddresses(accounts)
+
+	seen := set.Set[string]{}
+	for _, address := range addresses {
+		seen.Add(address)
+	}
+	for _, ops := range operationsMuxedParticipants(transaction, sequence) {
+		for _, address := range ops {
+			if !seen.Contains(address) {
+				seen.Add(address)
+				addresses = append(addresses, address)
+			}
+		}
+	}
+
+	return addresses
+}
+
+// operationsMuxedParticipants returns a map with the muxed participants of
+// every operation in the transaction
+func operationsMuxedParticipants(transaction ingest.LedgerTransaction, sequence uint32) map[int64][]string {
+	participants := map[int64][]string{}
+
+	for opi, op := range transaction.Envelope.Operations() {
+		operation := transactionOperationWrapper{
+			index:          uint32(opi),
+			transaction:    transaction,
+			operation:      op,
+			ledgerSequence: sequence,
+		}
+
+		if p := operation.MuxedParticipants(); len(p) > 0 {
+			participants[operation.ID()] = p
+		}
+	}
+
+	return participants
+}
@@ -226,6 +226,70 @@ func (s *ParticipantsProcessorTestSuiteLedger) TestIngestParticipantsSucceeds()
 	s.Assert().NoError(err)
 }
 
+func (s *ParticipantsProcessorTestSuiteLedger) TestMuxedParticipants() {
+	source := xdr.MustMuxedAddress("MA7QYNF7SOWQ3GLR2BGMZEHXAVIRZA4KVWLTJJFC7MGXUA74P7UJVAAAAAAAAAAAAAJLK")
+	destination, err := xdr.MuxedAccountFromAccountId(s.addresses[1], 420)
+	s.Assert().NoError(err)
+	addresses := []string{source.ToAccountId().Address(), s.addresses[1]}
+	addressToID := map[string]int64{
+		addresses[0]: 1,
+		addresses[1]: s.addressToID[addresses[1]],
+	}
+
+	tx := createTransaction(true, 1)
+	tx.Index = 1
+	tx.Envelope.V1.Tx.SourceAccount = source
+	tx.Envelope.Operations()[0].Body = xdr.OperationBody{
+		Type: xdr.OperationTypePayment,
+		PaymentOp: &xdr.PaymentOp{
+			Destination: destination,
+			Asset:       xdr.MustNewNativeAsset(),
+			Amount:      100,
+		},
+	}
+	txID := toid.New(20, 1, 0).ToInt64()
+	opID := toid.New(20, 1, 1).ToInt64()
+
+	mockTransactionMuxedBatchInsertBuilder := &history.MockTransactionMuxedParticipantsBatchInsertBuilder{}
+	mockOperationMuxedBatchInsertBuilder := &history.MockOperationMuxedParticipantBatchInsertBuilder{}
+	defer mockTransactionMuxedBatchInsertBuilder.AssertExpectations(s.T())
+	defer mockOperationMuxedBatchInsertBuilder.AssertExpectations(s.T())
+
+	s.mockQ.On("CreateAccounts", s.ctx, mock.AnythingOfType("[]string"), maxBatchSize).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(1).([]string)
+			s.Assert().ElementsMatch(
+				addresses,
+				arg,
+			)
+		}).Return(addressToID, nil).Once()
+	s.mockQ.On("NewTransactionParticipantsBatchInsertBuilder", maxBatchSize).
+		Return(s.mockBatchInsertBuilder).Once()
+	s.mockQ.On("NewOperationParticipantBatchInsertBuilder", maxBatchSize).
+		Return(s.mockOperationsBatchInsertBuilder).Once()
+	s.mockQ.On("NewTransactionMuxedParticipantsBatchInsertBuilder", maxBatchSize).
+		Return(mockTransactionMuxedBatchInsertBuilder).Once()
+	s.mockQ.On("NewOperationMuxedParticipantBatchInsertBuilder", maxBatchSize).
+		Return(mockOperationMuxedBatchInsertBuilder).Once()
+
+	for _, address := range addresses {
+		s.mockBatchInsertBuilder.On("Add", s.ctx, txID, addressToID[address]).Return(nil).Once()
+		s.mockOperationsBatchInsertBuilder.On("Add", s.ctx, opID, addressToID[address]).Return(nil).Once()
+	}
+	for _, address := range []string{source.Address(), destination.Address()} {
+		mockTransactionMuxedBatchInsertBuilder.On("Add", s.ctx, txID, address).Return(nil).Once()
+		mockOperationMuxedBatchInsertBuilder.On("Add", s.ctx, opID, address).Return(nil).Once()
+	}
+
+	s.mockBatchInsertBuilder.On("Exec", s.ctx).Return(nil).Once()
+	s.mockOperationsBatchInsertBuilder.On("Exec", s.ctx).Return(nil).Once()
+	mockTransactionMuxedBatchInsertBuilder.On("Exec", s.ctx).Return(nil).Once()
+	mockOperationMuxedBatchInsertBuilder.On("Exec", s.ctx).Return(nil).Once()
+
+	s.Assert().NoError(s.processor.ProcessTransaction(s.ctx, tx))
+	s.Assert().NoError(s.processor.Commit(s.ctx))
+}
+
 func (s *ParticipantsProcessorTestSuiteLedger) TestCreateAccountsFails() {
 	s.mockQ.On("CreateAccounts", s.ctx, mock.AnythingOfType("[]string"), maxBatchSize).
 		Return(s.addressToID, errors.New("transient error")).Once()
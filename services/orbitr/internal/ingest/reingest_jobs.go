@@ -0,0 +1,343 @@
+package ingest
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/support/db"
+	"github.com/lantah/go/support/errors"
+)
+
+// maxQueuedReingestJobs is the maximum number of reingestion jobs waiting to
+// be run.
+const maxQueuedReingestJobs = 100
+
+// maxFinishedReingestJobs is the maximum number of finished reingestion jobs
+// which are kept, older ones are pruned once a job finishes.
+const maxFinishedReingestJobs = 100
+
+var (
+	// ErrReingestJobNotFound is returned when a reingestion job does not exist.
+	ErrReingestJobNotFound = errors.New("reingestion job not found")
+	// ErrReingestJobFinished is returned when cancelling a reingestion job
+	// which is no longer queued or running.
+	ErrReingestJobFinished = errors.New("reingestion job already finished")
+	// ErrReingestQueueFull is returned when too many reingestion jobs are
+	// waiting to be run.
+	ErrReingestQueueFull = errors.New("too many queued reingestion jobs")
+)
+
+// ErrInvalidReingestRanges is returned when enqueuing a reingestion job with
+// invalid ranges.
+type ErrInvalidReingestRanges struct {
+	reason error
+}
+
+func (e ErrInvalidReingestRanges) Error() string {
+	return e.reason.Error()
+}
+
+// ReingestJobStatus is the status of a reingestion job.
+type ReingestJobStatus string
+
+const (
+	ReingestJobQueued    ReingestJobStatus = "queued"
+	ReingestJobRunning   ReingestJobStatus = "running"
+	ReingestJobSucceeded ReingestJobStatus = "succeeded"
+	ReingestJobFailed    ReingestJobStatus = "failed"
+	ReingestJobCancelled ReingestJobStatus = "cancelled"
+)
+
+// ReingestJob is a snapshot of a reingestion job run by a ReingestJobManager.
+type ReingestJob struct {
+	ID     string
+	Ranges []history.LedgerRange
+	Force  bool
+	Status ReingestJobStatus
+	// Error is the reason a failed job failed.
+	Error string
+	// TotalLedgers is the number of ledgers in Ranges, IngestedLedgers is the
+	// number of those which have been processed so far and CurrentLedger the
+	// last ledger processed.
+	TotalLedgers    uint32
+	IngestedLedgers uint32
+	CurrentLedger   uint32
+	CreatedAt       time.Time
+	StartedAt       time.Time
+	FinishedAt      time.Time
+}
+
+// ReingestJobManager runs reingestion jobs in the background, one at a time,
+// each with its own ingestion system running the reingestHistoryRangeState.
+// It lets operators reingest ranges from the admin API instead of running
+// the `db reingest range` command.
+type ReingestJobManager struct {
+	historyQ  *history.Q
+	newSystem func(progress func(ledger uint32)) (System, error)
+	queue     chan string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mutex     sync.Mutex
+	nextID    uint64
+	jobs      map[string]*ReingestJob
+	order     []string
+	runningID string
+	running   System
+	// stopped is set once Cancel has shut down the running system.
+	stopped bool
+}
+
+// NewReingestJobManager returns a ReingestJobManager running jobs with
+// ingestion systems created from config. Every ingestion system closes its
+// database session on shutdown so openHistorySession is called to open a
+// dedicated one for each job, config.HistorySession is only used to check for
+// range conflicts when enqueuing jobs.
+func NewReingestJobManager(config Config, openHistorySession func() (db.SessionInterface, error)) *ReingestJobManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ReingestJobManager{
+		historyQ: &history.Q{SessionInterface: config.HistorySession.Clone()},
+		newSystem: func(progress func(ledger uint32)) (System, error) {
+			session, err := openHistorySession()
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot open OrbitR DB")
+			}
+			jobConfig := config
+			jobConfig.HistorySession = session
+			jobConfig.ReingestEnabled = true
+			jobConfig.reingestProgress = progress
+			return NewSystem(jobConfig)
+		},
+		queue:  make(chan string, maxQueuedReingestJobs),
+		ctx:    ctx,
+		cancel: cancel,
+		jobs:   map[string]*ReingestJob{},
+	}
+}
+
+// Run runs the queued jobs until Shutdown is called.
+func (m *ReingestJobManager) Run() {
+	m.wg.Add(1)
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case id := <-m.queue:
+			m.runJob(id)
+		}
+	}
+}
+
+// Shutdown stops the job being run, if any, and waits for Run to return.
+func (m *ReingestJobManager) Shutdown() {
+	m.cancel()
+
+	m.mutex.Lock()
+	id := m.runningID
+	m.mutex.Unlock()
+	if id != "" {
+		// the job may have finished in the meantime
+		_ = m.Cancel(id)
+	}
+
+	m.wg.Wait()
+}
+
+// Enqueue queues a job reingesting the given sorted ranges. Unless force is
+// set, ranges overlapping with the ledgers ingested by the live ingestion
+// system are rejected with ErrReingestRangeConflict, as when running the
+// `db reingest range` command.
+func (m *ReingestJobManager) Enqueue(ctx context.Context, ranges []history.LedgerRange, force bool) (ReingestJob, error) {
+	if len(ranges) == 0 {
+		return ReingestJob{}, ErrInvalidReingestRanges{errors.New("no ranges to reingest")}
+	}
+	if err := validateRanges(ranges); err != nil {
+		return ReingestJob{}, ErrInvalidReingestRanges{err}
+	}
+
+	if !force {
+		lastIngestedLedger, err := m.historyQ.GetLastLedgerIngestNonBlocking(ctx)
+		if err != nil {
+			return ReingestJob{}, errors.Wrap(err, getLastIngestedErrMsg)
+		}
+		if lastIngestedLedger > 0 && ranges[len(ranges)-1].EndSequence >= lastIngestedLedger {
+			return ReingestJob{}, ErrReingestRangeConflict{lastIngestedLedger}
+		}
+	}
+
+	var total uint32
+	for _, r := range ranges {
+		total += r.EndSequence - r.StartSequence + 1
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.nextID++
+	job := &ReingestJob{
+		ID:           strconv.FormatUint(m.nextID, 10),
+		Ranges:       ranges,
+		Force:        force,
+		Status:       ReingestJobQueued,
+		TotalLedgers: total,
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	select {
+	case m.queue <- job.ID:
+	default:
+		return ReingestJob{}, ErrReingestQueueFull
+	}
+	m.jobs[job.ID] = job
+	m.order = append(m.order, job.ID)
+	return *job, nil
+}
+
+// Job returns the job with the given id.
+func (m *ReingestJobManager) Job(id string) (ReingestJob, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return ReingestJob{}, ErrReingestJobNotFound
+	}
+	return *job, nil
+}
+
+// Jobs returns all the jobs, in the order they were enqueued. Only the last
+// maxFinishedReingestJobs finished jobs are kept.
+func (m *ReingestJobManager) Jobs() []ReingestJob {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	jobs := make([]ReingestJob, 0, len(m.order))
+	for _, id := range m.order {
+		jobs = append(jobs, *m.jobs[id])
+	}
+	return jobs
+}
+
+// Cancel cancels a queued job or stops a running one. Ledgers already
+// reingested by a stopped job are kept.
+func (m *ReingestJobManager) Cancel(id string) error {
+	m.mutex.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mutex.Unlock()
+		return ErrReingestJobNotFound
+	}
+
+	switch {
+	case job.Status == ReingestJobQueued:
+		job.Status = ReingestJobCancelled
+		job.FinishedAt = time.Now().UTC()
+		m.pruneFinishedJobs()
+		m.mutex.Unlock()
+		return nil
+	case job.Status == ReingestJobRunning:
+		job.Status = ReingestJobCancelled
+		system := m.running
+		if system == nil {
+			// the system is still being created, runJob will not start it
+			m.mutex.Unlock()
+			return nil
+		}
+		m.stopped = true
+		m.mutex.Unlock()
+		system.Shutdown()
+		return nil
+	default:
+		m.mutex.Unlock()
+		return ErrReingestJobFinished
+	}
+}
+
+func (m *ReingestJobManager) runJob(id string) {
+	m.mutex.Lock()
+	// cancelled jobs stay in the queue and may have been pruned since
+	job, ok := m.jobs[id]
+	if !ok || job.Status != ReingestJobQueued {
+		m.mutex.Unlock()
+		return
+	}
+	job.Status = ReingestJobRunning
+	job.StartedAt = time.Now().UTC()
+	m.runningID = id
+	m.mutex.Unlock()
+
+	system, err := m.newSystem(func(ledger uint32) {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		job.IngestedLedgers++
+		job.CurrentLedger = ledger
+	})
+
+	m.mutex.Lock()
+	cancelled := job.Status == ReingestJobCancelled
+	if err == nil {
+		m.running = system
+	}
+	m.mutex.Unlock()
+
+	if err == nil && !cancelled {
+		err = system.ReingestRange(job.Ranges, job.Force)
+	}
+
+	m.mutex.Lock()
+	// Cancel shuts down the system itself to stop ReingestRange
+	stopped := m.stopped
+	m.running = nil
+	m.runningID = ""
+	m.stopped = false
+	job.FinishedAt = time.Now().UTC()
+	if job.Status != ReingestJobCancelled {
+		if err != nil {
+			job.Status = ReingestJobFailed
+			job.Error = err.Error()
+		} else {
+			job.Status = ReingestJobSucceeded
+		}
+	}
+	m.pruneFinishedJobs()
+	m.mutex.Unlock()
+
+	if system != nil && !stopped {
+		system.Shutdown()
+	}
+}
+
+// pruneFinishedJobs removes the oldest finished jobs so that at most
+// maxFinishedReingestJobs are kept. It must be called with the mutex held.
+func (m *ReingestJobManager) pruneFinishedJobs() {
+	finished := 0
+	for _, id := range m.order {
+		if m.jobs[id].finished() {
+			finished++
+		}
+	}
+
+	order := m.order[:0]
+	for _, id := range m.order {
+		if finished > maxFinishedReingestJobs && m.jobs[id].finished() {
+			delete(m.jobs, id)
+			finished--
+			continue
+		}
+		order = append(order, id)
+	}
+	m.order = order
+}
+
+// finished returns true once the job has stopped. A running job which is
+// being cancelled is already ReingestJobCancelled but is not finished yet.
+func (j *ReingestJob) finished() bool {
+	return !j.FinishedAt.IsZero()
+}
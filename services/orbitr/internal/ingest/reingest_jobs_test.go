@@ -0,0 +1,217 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/support/db"
+	"github.com/lantah/go/support/errors"
+)
+
+func newTestReingestJobManager(system *mockSystem, lastIngestedLedger string) (*ReingestJobManager, *db.MockSession) {
+	session := &db.MockSession{}
+	session.On("Get", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			*args.Get(1).(*string) = lastIngestedLedger
+		}).
+		Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ReingestJobManager{
+		historyQ: &history.Q{SessionInterface: session},
+		newSystem: func(progress func(ledger uint32)) (System, error) {
+			system.On("ReingestRange", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				for _, r := range args.Get(0).([]history.LedgerRange) {
+					for ledger := r.StartSequence; ledger <= r.EndSequence; ledger++ {
+						progress(ledger)
+					}
+				}
+			}).Return(nil)
+			return system, nil
+		},
+		queue:  make(chan string, maxQueuedReingestJobs),
+		ctx:    ctx,
+		cancel: cancel,
+		jobs:   map[string]*ReingestJob{},
+	}, session
+}
+
+func waitForJob(t *testing.T, manager *ReingestJobManager, id string, status ReingestJobStatus) ReingestJob {
+	var job ReingestJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = manager.Job(id)
+		require.NoError(t, err)
+		return job.Status == status
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestReingestJobManagerInvalidRanges(t *testing.T) {
+	manager, _ := newTestReingestJobManager(&mockSystem{}, "")
+
+	_, err := manager.Enqueue(context.Background(), nil, false)
+	assert.EqualError(t, err, "no ranges to reingest")
+
+	_, err = manager.Enqueue(context.Background(), []history.LedgerRange{
+		{StartSequence: 10, EndSequence: 5},
+	}, false)
+	assert.EqualError(t, err, "Invalid range: {10 5} from > to")
+
+	assert.Empty(t, manager.Jobs())
+}
+
+func TestReingestJobManagerRangeConflict(t *testing.T) {
+	manager, _ := newTestReingestJobManager(&mockSystem{}, "150")
+
+	_, err := manager.Enqueue(context.Background(), []history.LedgerRange{
+		{StartSequence: 100, EndSequence: 200},
+	}, false)
+	assert.Equal(t, ErrReingestRangeConflict{150}, err)
+
+	job, err := manager.Enqueue(context.Background(), []history.LedgerRange{
+		{StartSequence: 100, EndSequence: 149},
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, ReingestJobQueued, job.Status)
+
+	job, err = manager.Enqueue(context.Background(), []history.LedgerRange{
+		{StartSequence: 100, EndSequence: 200},
+	}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, ReingestJobQueued, job.Status)
+}
+
+func TestReingestJobManagerRunJobs(t *testing.T) {
+	system := &mockSystem{}
+	system.On("Shutdown").Return()
+	manager, _ := newTestReingestJobManager(system, "")
+	go manager.Run()
+	defer manager.Shutdown()
+
+	ranges := []history.LedgerRange{
+		{StartSequence: 10, EndSequence: 19},
+		{StartSequence: 30, EndSequence: 34},
+	}
+	job, err := manager.Enqueue(context.Background(), ranges, false)
+	require.NoError(t, err)
+	assert.Equal(t, "1", job.ID)
+	assert.Equal(t, uint32(15), job.TotalLedgers)
+
+	job = waitForJob(t, manager, job.ID, ReingestJobSucceeded)
+	assert.Equal(t, uint32(15), job.IngestedLedgers)
+	assert.Equal(t, uint32(34), job.CurrentLedger)
+	assert.False(t, job.StartedAt.IsZero())
+	assert.False(t, job.FinishedAt.IsZero())
+	assert.Empty(t, job.Error)
+
+	assert.Equal(t, ErrReingestJobFinished, manager.Cancel(job.ID))
+	assert.Equal(t, ErrReingestJobNotFound, manager.Cancel("2"))
+
+	jobs := manager.Jobs()
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, job, jobs[0])
+	system.AssertCalled(t, "ReingestRange", ranges, false)
+}
+
+func TestReingestJobManagerFailedJob(t *testing.T) {
+	system := &mockSystem{}
+	system.On("Shutdown").Return()
+	manager, _ := newTestReingestJobManager(system, "")
+	manager.newSystem = func(progress func(ledger uint32)) (System, error) {
+		system.On("ReingestRange", mock.Anything, mock.Anything).
+			Return(errors.New("error getting ledger"))
+		return system, nil
+	}
+	go manager.Run()
+	defer manager.Shutdown()
+
+	job, err := manager.Enqueue(context.Background(), []history.LedgerRange{
+		{StartSequence: 10, EndSequence: 19},
+	}, true)
+	require.NoError(t, err)
+
+	job = waitForJob(t, manager, job.ID, ReingestJobFailed)
+	assert.Equal(t, "error getting ledger", job.Error)
+	system.AssertNumberOfCalls(t, "Shutdown", 1)
+}
+
+func TestReingestJobManagerCancelJobs(t *testing.T) {
+	system := &mockSystem{}
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	system.On("Shutdown").Run(func(mock.Arguments) { close(stopped) }).Return().Once()
+
+	manager, _ := newTestReingestJobManager(system, "")
+	manager.newSystem = func(progress func(ledger uint32)) (System, error) {
+		system.On("ReingestRange", mock.Anything, mock.Anything).
+			Run(func(mock.Arguments) {
+				progress(10)
+				close(started)
+				<-stopped
+			}).
+			Return(errors.New("context canceled"))
+		return system, nil
+	}
+
+	running, err := manager.Enqueue(context.Background(), []history.LedgerRange{
+		{StartSequence: 10, EndSequence: 19},
+	}, true)
+	require.NoError(t, err)
+	queued, err := manager.Enqueue(context.Background(), []history.LedgerRange{
+		{StartSequence: 20, EndSequence: 29},
+	}, true)
+	require.NoError(t, err)
+
+	go manager.Run()
+	defer manager.Shutdown()
+	<-started
+
+	require.NoError(t, manager.Cancel(queued.ID))
+	require.NoError(t, manager.Cancel(running.ID))
+
+	job := waitForJob(t, manager, queued.ID, ReingestJobCancelled)
+	assert.True(t, job.StartedAt.IsZero())
+
+	require.Eventually(t, func() bool {
+		job, err = manager.Job(running.ID)
+		require.NoError(t, err)
+		return !job.FinishedAt.IsZero()
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, ReingestJobCancelled, job.Status)
+	assert.Equal(t, uint32(1), job.IngestedLedgers)
+	assert.Empty(t, job.Error)
+
+	// the running system is only shut down once
+	system.AssertNumberOfCalls(t, "Shutdown", 1)
+	system.AssertNumberOfCalls(t, "ReingestRange", 1)
+}
+
+func TestReingestJobManagerPrunesFinishedJobs(t *testing.T) {
+	manager, _ := newTestReingestJobManager(&mockSystem{}, "")
+	ranges := []history.LedgerRange{{StartSequence: 10, EndSequence: 19}}
+
+	queued, err := manager.Enqueue(context.Background(), ranges, false)
+	require.NoError(t, err)
+	for i := 0; i < maxFinishedReingestJobs+10; i++ {
+		job, err := manager.Enqueue(context.Background(), ranges, false)
+		require.NoError(t, err)
+		require.NoError(t, manager.Cancel(job.ID))
+		// drain the queue as Run would
+		<-manager.queue
+	}
+
+	jobs := manager.Jobs()
+	require.Len(t, jobs, maxFinishedReingestJobs+1)
+	// queued jobs are never pruned, only the oldest finished ones
+	assert.Equal(t, queued.ID, jobs[0].ID)
+	assert.Equal(t, "12", jobs[1].ID)
+	_, err = manager.Job("2")
+	assert.Equal(t, ErrReingestJobNotFound, err)
+}
@@ -5,11 +5,13 @@ package ingest
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/lantah/go/ingest/ledgerbackend"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
 	"github.com/lantah/go/support/errors"
 	"github.com/lantah/go/xdr"
 )
@@ -166,6 +168,52 @@ func (s *ResumeTestTestSuite) TestGetLatestLedgerLessThanCurrent() {
 	)
 }
 
+func (s *ResumeTestTestSuite) TestNotIngestionLeader() {
+	// Recreate mocks in this single test: the ledger backend is not used.
+	*s.historyQ = mockDBQ{}
+	*s.ledgerBackend = ledgerbackend.MockDatabaseBackend{}
+	s.system.config.LeaderElector = &LeaderElector{}
+	s.historyQ.On("GetLastLedgerIngestNonBlocking", s.ctx).Return(uint32(100), nil).Once()
+
+	next, err := resumeState{latestSuccessfullyProcessedLedger: 100}.run(s.system)
+	s.Assert().NoError(err)
+	s.Assert().Equal(
+		transition{
+			node:          resumeState{latestSuccessfullyProcessedLedger: 100},
+			sleepDuration: defaultSleep,
+		},
+		next,
+	)
+
+	// the leader has ingested more ledgers
+	s.historyQ.On("GetLastLedgerIngestNonBlocking", s.ctx).Return(uint32(105), nil).Once()
+
+	next, err = resumeState{latestSuccessfullyProcessedLedger: 100}.run(s.system)
+	s.Assert().NoError(err)
+	s.Assert().Equal(resumeImmediately(105), next)
+}
+
+func (s *ResumeTestTestSuite) TestIngestionLeaseFencingTokenChanged() {
+	s.system.config.LeaderElector = &LeaderElector{
+		isLeader:   true,
+		lease:      history.IngestionLease{FencingToken: 3},
+		validUntil: time.Now().Add(time.Minute),
+	}
+	s.historyQ.On("Begin", s.ctx).Return(nil).Once()
+	s.historyQ.On("GetLastLedgerIngest", s.ctx).Return(uint32(100), nil).Once()
+	s.historyQ.MockQIngestionLeases.On("CheckIngestionLeaseFencingToken", s.ctx, IngestionLeaseName, int64(3)).Return(false, nil).Once()
+
+	next, err := resumeState{latestSuccessfullyProcessedLedger: 100}.run(s.system)
+	s.Assert().NoError(err)
+	s.Assert().Equal(
+		transition{
+			node:          resumeState{latestSuccessfullyProcessedLedger: 100},
+			sleepDuration: defaultSleep,
+		},
+		next,
+	)
+}
+
 func (s *ResumeTestTestSuite) TestGetIngestionVersionError() {
 	s.historyQ.On("Begin", s.ctx).Return(nil).Once()
 	s.historyQ.On("GetLastLedgerIngest", s.ctx).Return(uint32(100), nil).Once()
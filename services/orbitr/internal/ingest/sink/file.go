@@ -0,0 +1,83 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/lantah/go/support/errors"
+)
+
+// FilePublisher appends the messages of every topic, as newline delimited
+// JSON, to a `<topic>.jsonl` log file in a directory. Consumers can follow the
+// logs like Kafka topics by tailing the files and remembering their offset.
+type FilePublisher struct {
+	dir  string
+	lock sync.Mutex
+}
+
+// NewFilePublisher returns a FilePublisher appending logs to dir, which is
+// created if it does not exist.
+func NewFilePublisher(dir string) (*FilePublisher, error) {
+	if dir == "" {
+		return nil, errors.New("history sink directory is empty")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "could not create history sink directory")
+	}
+	return &FilePublisher{dir: dir}, nil
+}
+
+// LogPath returns the path of the log file of the given topic.
+func (p *FilePublisher) LogPath(topic Topic) string {
+	return filepath.Join(p.dir, string(topic)+".jsonl")
+}
+
+// Publish appends the messages to the log files of their topics and syncs
+// the files before returning.
+func (p *FilePublisher) Publish(ctx context.Context, messages []Message) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	byTopic := map[Topic][]Message{}
+	var topics []Topic
+	for _, message := range messages {
+		if _, ok := byTopic[message.Topic]; !ok {
+			topics = append(topics, message.Topic)
+		}
+		byTopic[message.Topic] = append(byTopic[message.Topic], message)
+	}
+
+	for _, topic := range topics {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := p.appendMessages(topic, byTopic[topic]); err != nil {
+			return errors.Wrapf(err, "could not append to %s log", topic)
+		}
+	}
+	return nil
+}
+
+func (p *FilePublisher) appendMessages(topic Topic, messages []Message) error {
+	file, err := os.OpenFile(p.LogPath(topic), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, message := range messages {
+		if err = encoder.Encode(message); err != nil {
+			return err
+		}
+	}
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
@@ -0,0 +1,314 @@
+package sink
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/lantah/go/ingest"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/toid"
+	"github.com/lantah/go/xdr"
+)
+
+// HistoryQ defines the queries the ingestion processors write ledgers,
+// transactions, operations, effects and trades with.
+type HistoryQ interface {
+	history.QLedgers
+	history.QTransactions
+	history.QOperations
+	history.QEffects
+	history.QTrades
+}
+
+// historyQ writes rows to the history tables using the wrapped HistoryQ and
+// publishes them to a Publisher once they are inserted.
+type historyQ struct {
+	HistoryQ
+	publisher Publisher
+}
+
+// NewHistoryQ returns a HistoryQ which writes rows with q and then publishes
+// them to publisher. Transactions inserted in the temporary table of filtered
+// out transactions are not published.
+func NewHistoryQ(q HistoryQ, publisher Publisher) HistoryQ {
+	return historyQ{HistoryQ: q, publisher: publisher}
+}
+
+func (q historyQ) InsertLedger(ctx context.Context,
+	ledger xdr.LedgerHeaderHistoryEntry,
+	successTxsCount int,
+	failedTxsCount int,
+	opCount int,
+	txSetOpCount int,
+	ingestVersion int,
+) (int64, error) {
+	rowsAffected, err := q.HistoryQ.InsertLedger(ctx, ledger, successTxsCount, failedTxsCount, opCount, txSetOpCount, ingestVersion)
+	if err != nil {
+		return rowsAffected, err
+	}
+
+	ledgerHeader, err := xdr.MarshalBase64(ledger.Header)
+	if err != nil {
+		return rowsAffected, errors.Wrap(err, "could not marshal ledger header")
+	}
+	row := LedgerRow{
+		Sequence:                   uint32(ledger.Header.LedgerSeq),
+		LedgerHash:                 hex.EncodeToString(ledger.Hash[:]),
+		PreviousLedgerHash:         hex.EncodeToString(ledger.Header.PreviousLedgerHash[:]),
+		ClosedAt:                   time.Unix(int64(ledger.Header.ScpValue.CloseTime), 0).UTC(),
+		SuccessfulTransactionCount: successTxsCount,
+		FailedTransactionCount:     failedTxsCount,
+		OperationCount:             opCount,
+		TxSetOperationCount:        txSetOpCount,
+		ProtocolVersion:            uint32(ledger.Header.LedgerVersion),
+		IngestVersion:              ingestVersion,
+		LedgerHeader:               ledgerHeader,
+	}
+	message, err := newMessage(LedgersTopic, row.Sequence, row)
+	if err != nil {
+		return rowsAffected, err
+	}
+	if err = q.publisher.Publish(ctx, []Message{message}); err != nil {
+		return rowsAffected, errors.Wrap(err, "could not publish ledger")
+	}
+	return rowsAffected, nil
+}
+
+func (q historyQ) NewTransactionBatchInsertBuilder(maxBatchSize int) history.TransactionBatchInsertBuilder {
+	return &transactionBatchInsertBuilder{
+		builder: q.HistoryQ.NewTransactionBatchInsertBuilder(maxBatchSize),
+		batch:   batch{publisher: q.publisher},
+	}
+}
+
+func (q historyQ) NewOperationBatchInsertBuilder(maxBatchSize int) history.OperationBatchInsertBuilder {
+	return &operationBatchInsertBuilder{
+		builder: q.HistoryQ.NewOperationBatchInsertBuilder(maxBatchSize),
+		batch:   batch{publisher: q.publisher},
+	}
+}
+
+func (q historyQ) NewEffectBatchInsertBuilder(maxBatchSize int) history.EffectBatchInsertBuilder {
+	return &effectBatchInsertBuilder{
+		builder: q.HistoryQ.NewEffectBatchInsertBuilder(maxBatchSize),
+		batch:   batch{publisher: q.publisher},
+	}
+}
+
+func (q historyQ) NewTradeBatchInsertBuilder(maxBatchSize int) history.TradeBatchInsertBuilder {
+	return &tradeBatchInsertBuilder{
+		builder: q.HistoryQ.NewTradeBatchInsertBuilder(maxBatchSize),
+		batch:   batch{publisher: q.publisher},
+	}
+}
+
+// batch holds the messages added to a batch insert builder until the batch
+// is executed.
+type batch struct {
+	publisher Publisher
+	messages  []Message
+}
+
+func (b *batch) add(topic Topic, ledgerSequence uint32, row interface{}) error {
+	message, err := newMessage(topic, ledgerSequence, row)
+	if err != nil {
+		return err
+	}
+	b.messages = append(b.messages, message)
+	return nil
+}
+
+func (b *batch) publish(ctx context.Context) error {
+	if len(b.messages) == 0 {
+		return nil
+	}
+	if err := b.publisher.Publish(ctx, b.messages); err != nil {
+		return errors.Wrapf(err, "could not publish %s", b.messages[0].Topic)
+	}
+	b.messages = nil
+	return nil
+}
+
+type transactionBatchInsertBuilder struct {
+	builder history.TransactionBatchInsertBuilder
+	batch
+}
+
+func (i *transactionBatchInsertBuilder) Add(ctx context.Context, transaction ingest.LedgerTransaction, sequence uint32) error {
+	if err := i.builder.Add(ctx, transaction, sequence); err != nil {
+		return err
+	}
+
+	envelope, err := xdr.MarshalBase64(transaction.Envelope)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal transaction envelope")
+	}
+	result, err := xdr.MarshalBase64(transaction.Result.Result)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal transaction result")
+	}
+	meta, err := xdr.MarshalBase64(transaction.UnsafeMeta)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal transaction meta")
+	}
+	feeMeta, err := xdr.MarshalBase64(transaction.FeeChanges)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal transaction fee meta")
+	}
+
+	source := transaction.Envelope.SourceAccount()
+	account := source.ToAccountId()
+	var accountMuxed null.String
+	if source.Type == xdr.CryptoKeyTypeKeyTypeMuxedEd25519 {
+		accountMuxed = null.StringFrom(source.Address())
+	}
+
+	return i.add(TransactionsTopic, sequence, TransactionRow{
+		ID:               toid.New(int32(sequence), int32(transaction.Index), 0).ToInt64(),
+		TransactionHash:  hex.EncodeToString(transaction.Result.TransactionHash[:]),
+		LedgerSequence:   sequence,
+		ApplicationOrder: transaction.Index,
+		Account:          account.Address(),
+		AccountMuxed:     accountMuxed,
+		FeeCharged:       int64(transaction.Result.Result.FeeCharged),
+		OperationCount:   len(transaction.Envelope.Operations()),
+		Successful:       transaction.Result.Successful(),
+		TxEnvelope:       envelope,
+		TxResult:         result,
+		TxMeta:           meta,
+		TxFeeMeta:        feeMeta,
+	})
+}
+
+func (i *transactionBatchInsertBuilder) Exec(ctx context.Context) error {
+	if err := i.builder.Exec(ctx); err != nil {
+		return err
+	}
+	return i.publish(ctx)
+}
+
+type operationBatchInsertBuilder struct {
+	builder history.OperationBatchInsertBuilder
+	batch
+}
+
+func (i *operationBatchInsertBuilder) Add(
+	ctx context.Context,
+	id int64,
+	transactionID int64,
+	applicationOrder uint32,
+	operationType xdr.OperationType,
+	details []byte,
+	sourceAccount string,
+	sourceAccountMuxed null.String,
+	isPayment bool,
+) error {
+	err := i.builder.Add(ctx, id, transactionID, applicationOrder, operationType, details, sourceAccount, sourceAccountMuxed, isPayment)
+	if err != nil {
+		return err
+	}
+
+	return i.add(OperationsTopic, uint32(toid.Parse(id).LedgerSequence), OperationRow{
+		ID:                 id,
+		TransactionID:      transactionID,
+		ApplicationOrder:   applicationOrder,
+		Type:               operationType,
+		Details:            details,
+		SourceAccount:      sourceAccount,
+		SourceAccountMuxed: sourceAccountMuxed,
+		IsPayment:          isPayment,
+	})
+}
+
+func (i *operationBatchInsertBuilder) Exec(ctx context.Context) error {
+	if err := i.builder.Exec(ctx); err != nil {
+		return err
+	}
+	return i.publish(ctx)
+}
+
+type effectBatchInsertBuilder struct {
+	builder history.EffectBatchInsertBuilder
+	batch
+}
+
+func (i *effectBatchInsertBuilder) Add(
+	ctx context.Context,
+	accountID int64,
+	muxedAccount null.String,
+	operationID int64,
+	order uint32,
+	effectType history.EffectType,
+	details []byte,
+) error {
+	if err := i.builder.Add(ctx, accountID, muxedAccount, operationID, order, effectType, details); err != nil {
+		return err
+	}
+
+	return i.add(EffectsTopic, uint32(toid.Parse(operationID).LedgerSequence), EffectRow{
+		HistoryAccountID:   accountID,
+		AccountMuxed:       muxedAccount,
+		HistoryOperationID: operationID,
+		Order:              order,
+		Type:               effectType,
+		Details:            details,
+	})
+}
+
+func (i *effectBatchInsertBuilder) Exec(ctx context.Context) error {
+	if err := i.builder.Exec(ctx); err != nil {
+		return err
+	}
+	return i.publish(ctx)
+}
+
+type tradeBatchInsertBuilder struct {
+	builder history.TradeBatchInsertBuilder
+	batch
+}
+
+func (i *tradeBatchInsertBuilder) Add(ctx context.Context, entries ...history.InsertTrade) error {
+	if err := i.builder.Add(ctx, entries...); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err := i.add(TradesTopic, uint32(toid.Parse(entry.HistoryOperationID).LedgerSequence), TradeRow{
+			HistoryOperationID:     entry.HistoryOperationID,
+			Order:                  entry.Order,
+			LedgerCloseTime:        entry.LedgerCloseTime,
+			CounterAssetID:         entry.CounterAssetID,
+			CounterAmount:          entry.CounterAmount,
+			CounterAccountID:       entry.CounterAccountID,
+			CounterOfferID:         entry.CounterOfferID,
+			CounterLiquidityPoolID: entry.CounterLiquidityPoolID,
+			LiquidityPoolFee:       entry.LiquidityPoolFee,
+			BaseAssetID:            entry.BaseAssetID,
+			BaseAmount:             entry.BaseAmount,
+			BaseAccountID:          entry.BaseAccountID,
+			BaseOfferID:            entry.BaseOfferID,
+			BaseLiquidityPoolID:    entry.BaseLiquidityPoolID,
+			BaseIsSeller:           entry.BaseIsSeller,
+			BaseIsExact:            entry.BaseIsExact,
+			Type:                   entry.Type,
+			PriceN:                 entry.PriceN,
+			PriceD:                 entry.PriceD,
+			RoundingSlippage:       entry.RoundingSlippage,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *tradeBatchInsertBuilder) Exec(ctx context.Context) error {
+	if err := i.builder.Exec(ctx); err != nil {
+		return err
+	}
+	return i.publish(ctx)
+}
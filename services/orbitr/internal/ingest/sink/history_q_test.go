@@ -0,0 +1,153 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/toid"
+	"github.com/lantah/go/xdr"
+)
+
+type mockHistoryQ struct {
+	history.MockQLedgers
+	history.MockQTransactions
+	history.MockQOperations
+	history.MockQEffects
+	history.MockQTrades
+}
+
+func (m *mockHistoryQ) CreateAccounts(ctx context.Context, addresses []string, maxBatchSize int) (map[string]int64, error) {
+	return m.MockQEffects.CreateAccounts(ctx, addresses, maxBatchSize)
+}
+
+type recordingPublisher struct {
+	messages []Message
+	err      error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, messages []Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, messages...)
+	return nil
+}
+
+func TestHistoryQInsertLedger(t *testing.T) {
+	ctx := context.Background()
+	q := &mockHistoryQ{}
+	publisher := &recordingPublisher{}
+	sinkQ := NewHistoryQ(q, publisher)
+
+	ledger := xdr.LedgerHeaderHistoryEntry{
+		Hash: xdr.Hash{1, 2, 3},
+		Header: xdr.LedgerHeader{
+			LedgerSeq:     63,
+			LedgerVersion: 20,
+			ScpValue:      xdr.StellarValue{CloseTime: 1000},
+		},
+	}
+	q.MockQLedgers.On("InsertLedger", ctx, ledger, 2, 1, 5, 6, 17).Return(int64(1), nil).Once()
+
+	rowsAffected, err := sinkQ.InsertLedger(ctx, ledger, 2, 1, 5, 6, 17)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rowsAffected)
+	require.Len(t, publisher.messages, 1)
+	assert.Equal(t, LedgersTopic, publisher.messages[0].Topic)
+	assert.Equal(t, uint32(63), publisher.messages[0].LedgerSequence)
+
+	var row LedgerRow
+	require.NoError(t, json.Unmarshal(publisher.messages[0].Row, &row))
+	assert.Equal(t, uint32(63), row.Sequence)
+	assert.Equal(t, "0102030000000000000000000000000000000000000000000000000000000000", row.LedgerHash)
+	assert.Equal(t, int64(1000), row.ClosedAt.Unix())
+	assert.Equal(t, 2, row.SuccessfulTransactionCount)
+	assert.Equal(t, 1, row.FailedTransactionCount)
+	assert.Equal(t, 5, row.OperationCount)
+	assert.Equal(t, 6, row.TxSetOperationCount)
+	assert.Equal(t, uint32(20), row.ProtocolVersion)
+	assert.Equal(t, 17, row.IngestVersion)
+
+	// nothing is published when the ledger cannot be inserted
+	q.MockQLedgers.On("InsertLedger", ctx, ledger, 0, 0, 0, 0, 17).Return(int64(0), errors.New("transient error")).Once()
+	_, err = sinkQ.InsertLedger(ctx, ledger, 0, 0, 0, 0, 17)
+	assert.EqualError(t, err, "transient error")
+	assert.Len(t, publisher.messages, 1)
+	q.MockQLedgers.AssertExpectations(t)
+}
+
+func TestHistoryQOperationBatchInsertBuilder(t *testing.T) {
+	ctx := context.Background()
+	q := &mockHistoryQ{}
+	publisher := &recordingPublisher{}
+	sinkQ := NewHistoryQ(q, publisher)
+
+	builder := &history.MockOperationsBatchInsertBuilder{}
+	q.MockQOperations.On("NewOperationBatchInsertBuilder", 10).Return(builder).Once()
+	operationID := toid.New(100, 1, 1).ToInt64()
+	details := []byte(`{"amount":"10.0000000"}`)
+	builder.On("Add", ctx, operationID, toid.New(100, 1, 0).ToInt64(), uint32(1), xdr.OperationTypePayment,
+		details, "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY", null.String{}, true).Return(nil).Once()
+	builder.On("Exec", ctx).Return(nil).Once()
+
+	batch := sinkQ.NewOperationBatchInsertBuilder(10)
+	require.NoError(t, batch.Add(ctx, operationID, toid.New(100, 1, 0).ToInt64(), 1, xdr.OperationTypePayment,
+		details, "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY", null.String{}, true))
+	// rows are only published once the batch is inserted
+	assert.Empty(t, publisher.messages)
+	require.NoError(t, batch.Exec(ctx))
+
+	require.Len(t, publisher.messages, 1)
+	assert.Equal(t, OperationsTopic, publisher.messages[0].Topic)
+	assert.Equal(t, uint32(100), publisher.messages[0].LedgerSequence)
+	var row OperationRow
+	require.NoError(t, json.Unmarshal(publisher.messages[0].Row, &row))
+	assert.Equal(t, operationID, row.ID)
+	assert.Equal(t, xdr.OperationTypePayment, row.Type)
+	assert.JSONEq(t, string(details), string(row.Details))
+	assert.True(t, row.IsPayment)
+	q.MockQOperations.AssertExpectations(t)
+	builder.AssertExpectations(t)
+}
+
+func TestHistoryQTradeBatchInsertBuilderPublishError(t *testing.T) {
+	ctx := context.Background()
+	q := &mockHistoryQ{}
+	publisher := &recordingPublisher{err: errors.New("bus unavailable")}
+	sinkQ := NewHistoryQ(q, publisher)
+
+	builder := &history.MockTradeBatchInsertBuilder{}
+	q.MockQTrades.On("NewTradeBatchInsertBuilder", 10).Return(builder).Once()
+	trade := history.InsertTrade{
+		HistoryOperationID: toid.New(100, 1, 1).ToInt64(),
+		BaseAssetID:        1,
+		CounterAssetID:     2,
+		PriceN:             1,
+		PriceD:             2,
+	}
+	builder.On("Add", ctx, []history.InsertTrade{trade}).Return(nil).Once()
+	builder.On("Exec", ctx).Return(nil).Once()
+
+	batch := sinkQ.NewTradeBatchInsertBuilder(10)
+	require.NoError(t, batch.Add(ctx, trade))
+	assert.EqualError(t, batch.Exec(ctx), "could not publish trades: bus unavailable")
+	q.MockQTrades.AssertExpectations(t)
+	builder.AssertExpectations(t)
+}
+
+func TestHistoryQFilteredTransactionsAreNotPublished(t *testing.T) {
+	q := &mockHistoryQ{}
+	sinkQ := NewHistoryQ(q, &recordingPublisher{})
+
+	builder := &history.MockTransactionsBatchInsertBuilder{}
+	q.MockQTransactions.On("NewTransactionFilteredTmpBatchInsertBuilder", 10).Return(builder).Once()
+	assert.Equal(t, builder, sinkQ.NewTransactionFilteredTmpBatchInsertBuilder(10))
+	q.MockQTransactions.AssertExpectations(t)
+}
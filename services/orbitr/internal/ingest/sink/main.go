@@ -0,0 +1,159 @@
+// Package sink publishes the history rows written by the OrbitR ingestion
+// processors (ledgers, transactions, operations, effects and trades) to
+// destinations other than the history tables, so that downstream consumers
+// can receive them without tailing Postgres.
+//
+// Rows are published once the batch they belong to has been inserted in the
+// history tables but before the ingestion transaction is committed. Rows can
+// therefore be published more than once, for instance when ingestion of a
+// ledger is retried or a ledger range is reingested, and consumers must
+// deduplicate them by their ID.
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/xdr"
+)
+
+// Topic identifies the kind of rows carried by a Message.
+type Topic string
+
+const (
+	LedgersTopic      Topic = "ledgers"
+	TransactionsTopic Topic = "transactions"
+	OperationsTopic   Topic = "operations"
+	EffectsTopic      Topic = "effects"
+	TradesTopic       Topic = "trades"
+)
+
+// Topics are all the topics rows are published to.
+var Topics = []Topic{LedgersTopic, TransactionsTopic, OperationsTopic, EffectsTopic, TradesTopic}
+
+// Message is a history row published to a Publisher.
+type Message struct {
+	Topic          Topic           `json:"topic"`
+	LedgerSequence uint32          `json:"ledger_sequence"`
+	Row            json.RawMessage `json:"row"`
+}
+
+// Publisher is a destination of the history rows written by the ingestion
+// processors.
+type Publisher interface {
+	// Publish publishes the given messages, in order. It is called with the
+	// rows of a batch once they have been inserted in the history tables.
+	Publish(ctx context.Context, messages []Message) error
+}
+
+// Connect returns the Publisher described by the given URL. Only file://
+// URLs, pointing to the directory where FilePublisher appends its logs, are
+// supported.
+func Connect(sinkURL string) (Publisher, error) {
+	parsed, err := url.Parse(sinkURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse history sink URL")
+	}
+
+	switch parsed.Scheme {
+	case "file":
+		return NewFilePublisher(parsed.Host + parsed.Path)
+	default:
+		return nil, errors.Errorf("unsupported history sink URL scheme: %q", parsed.Scheme)
+	}
+}
+
+// LedgerRow is the row published to LedgersTopic.
+type LedgerRow struct {
+	Sequence                   uint32    `json:"sequence"`
+	LedgerHash                 string    `json:"ledger_hash"`
+	PreviousLedgerHash         string    `json:"previous_ledger_hash"`
+	ClosedAt                   time.Time `json:"closed_at"`
+	SuccessfulTransactionCount int       `json:"successful_transaction_count"`
+	FailedTransactionCount     int       `json:"failed_transaction_count"`
+	OperationCount             int       `json:"operation_count"`
+	TxSetOperationCount        int       `json:"tx_set_operation_count"`
+	ProtocolVersion            uint32    `json:"protocol_version"`
+	IngestVersion              int       `json:"ingest_version"`
+	LedgerHeader               string    `json:"ledger_header"`
+}
+
+// TransactionRow is the row published to TransactionsTopic.
+type TransactionRow struct {
+	ID               int64       `json:"id"`
+	TransactionHash  string      `json:"transaction_hash"`
+	LedgerSequence   uint32      `json:"ledger_sequence"`
+	ApplicationOrder uint32      `json:"application_order"`
+	Account          string      `json:"account"`
+	AccountMuxed     null.String `json:"account_muxed"`
+	FeeCharged       int64       `json:"fee_charged"`
+	OperationCount   int         `json:"operation_count"`
+	Successful       bool        `json:"successful"`
+	TxEnvelope       string      `json:"tx_envelope"`
+	TxResult         string      `json:"tx_result"`
+	TxMeta           string      `json:"tx_meta"`
+	TxFeeMeta        string      `json:"tx_fee_meta"`
+}
+
+// OperationRow is the row published to OperationsTopic.
+type OperationRow struct {
+	ID                 int64             `json:"id"`
+	TransactionID      int64             `json:"transaction_id"`
+	ApplicationOrder   uint32            `json:"application_order"`
+	Type               xdr.OperationType `json:"type"`
+	Details            json.RawMessage   `json:"details"`
+	SourceAccount      string            `json:"source_account"`
+	SourceAccountMuxed null.String       `json:"source_account_muxed"`
+	IsPayment          bool              `json:"is_payment"`
+}
+
+// EffectRow is the row published to EffectsTopic. HistoryAccountID is the ID
+// of the account in the history_accounts table.
+type EffectRow struct {
+	HistoryAccountID   int64              `json:"history_account_id"`
+	AccountMuxed       null.String        `json:"address_muxed"`
+	HistoryOperationID int64              `json:"history_operation_id"`
+	Order              uint32             `json:"order"`
+	Type               history.EffectType `json:"type"`
+	Details            json.RawMessage    `json:"details"`
+}
+
+// TradeRow is the row published to TradesTopic. Asset, account and liquidity
+// pool IDs are the IDs of the rows in the history_assets, history_accounts
+// and history_liquidity_pools tables.
+type TradeRow struct {
+	HistoryOperationID     int64             `json:"history_operation_id"`
+	Order                  int32             `json:"order"`
+	LedgerCloseTime        time.Time         `json:"ledger_closed_at"`
+	CounterAssetID         int64             `json:"counter_asset_id"`
+	CounterAmount          int64             `json:"counter_amount"`
+	CounterAccountID       null.Int          `json:"counter_account_id"`
+	CounterOfferID         null.Int          `json:"counter_offer_id"`
+	CounterLiquidityPoolID null.Int          `json:"counter_liquidity_pool_id"`
+	LiquidityPoolFee       null.Int          `json:"liquidity_pool_fee"`
+	BaseAssetID            int64             `json:"base_asset_id"`
+	BaseAmount             int64             `json:"base_amount"`
+	BaseAccountID          null.Int          `json:"base_account_id"`
+	BaseOfferID            null.Int          `json:"base_offer_id"`
+	BaseLiquidityPoolID    null.Int          `json:"base_liquidity_pool_id"`
+	BaseIsSeller           bool              `json:"base_is_seller"`
+	BaseIsExact            null.Bool         `json:"base_is_exact"`
+	Type                   history.TradeType `json:"trade_type"`
+	PriceN                 int64             `json:"price_n"`
+	PriceD                 int64             `json:"price_d"`
+	RoundingSlippage       null.Int          `json:"rounding_slippage"`
+}
+
+func newMessage(topic Topic, ledgerSequence uint32, row interface{}) (Message, error) {
+	encoded, err := json.Marshal(row)
+	if err != nil {
+		return Message{}, errors.Wrapf(err, "could not marshal %s row", topic)
+	}
+	return Message{Topic: topic, LedgerSequence: ledgerSequence, Row: encoded}, nil
+}
@@ -0,0 +1,63 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readLog(t *testing.T, path string) []Message {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var messages []Message
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var message Message
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &message))
+		messages = append(messages, message)
+	}
+	require.NoError(t, scanner.Err())
+	return messages
+}
+
+func TestFilePublisher(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sink")
+	publisher, err := Connect("file://" + dir)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, publisher.Publish(ctx, []Message{
+		{Topic: LedgersTopic, LedgerSequence: 10, Row: json.RawMessage(`{"sequence":10}`)},
+		{Topic: OperationsTopic, LedgerSequence: 10, Row: json.RawMessage(`{"id":1}`)},
+	}))
+	require.NoError(t, publisher.Publish(ctx, []Message{
+		{Topic: LedgersTopic, LedgerSequence: 11, Row: json.RawMessage(`{"sequence":11}`)},
+	}))
+
+	filePublisher := publisher.(*FilePublisher)
+	ledgers := readLog(t, filePublisher.LogPath(LedgersTopic))
+	require.Len(t, ledgers, 2)
+	assert.Equal(t, uint32(10), ledgers[0].LedgerSequence)
+	assert.JSONEq(t, `{"sequence":10}`, string(ledgers[0].Row))
+	assert.Equal(t, uint32(11), ledgers[1].LedgerSequence)
+
+	operations := readLog(t, filepath.Join(dir, "operations.jsonl"))
+	require.Len(t, operations, 1)
+	assert.JSONEq(t, `{"id":1}`, string(operations[0].Row))
+
+	_, err = os.Stat(filePublisher.LogPath(TradesTopic))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestConnectUnsupportedScheme(t *testing.T) {
+	_, err := Connect("kafka://localhost:9092")
+	assert.EqualError(t, err, `unsupported history sink URL scheme: "kafka"`)
+}
@@ -141,6 +141,10 @@ func (s *system) verifyState(verifyAgainstLatestCheckpoint bool) error {
 		}
 	}
 
+	if s.config.StateVerificationShards > 0 {
+		return s.verifyStateShard(ctx, historyQ, ledgerSequence, localLog)
+	}
+
 	totalByType := map[string]int64{}
 
 	startTime := time.Now()
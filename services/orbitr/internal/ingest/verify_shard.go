@@ -0,0 +1,269 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/lantah/go/ingest"
+	"github.com/lantah/go/ingest/verify"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/services/orbitr/internal/ingest/processors"
+	"github.com/lantah/go/support/errors"
+	logpkg "github.com/lantah/go/support/log"
+	"github.com/lantah/go/xdr"
+)
+
+// stateShardEntryTypes are the types of the ledger entries stored in the
+// database, in the order in which incremental state verification goes through
+// them.
+var stateShardEntryTypes = []xdr.LedgerEntryType{
+	xdr.LedgerEntryTypeAccount,
+	xdr.LedgerEntryTypeData,
+	xdr.LedgerEntryTypeOffer,
+	xdr.LedgerEntryTypeTrustline,
+	xdr.LedgerEntryTypeClaimableBalance,
+	xdr.LedgerEntryTypeLiquidityPool,
+}
+
+// stateShardEntryTypeNames are the names of the entry types recorded in the
+// state_verification_coverage table, they match the labels of the
+// StateVerifyLedgerEntriesCount metric.
+var stateShardEntryTypeNames = map[xdr.LedgerEntryType]string{
+	xdr.LedgerEntryTypeAccount:          "accounts",
+	xdr.LedgerEntryTypeData:             "data",
+	xdr.LedgerEntryTypeOffer:            "offers",
+	xdr.LedgerEntryTypeTrustline:        "trust_lines",
+	xdr.LedgerEntryTypeClaimableBalance: "claimable_balances",
+	xdr.LedgerEntryTypeLiquidityPool:    "liquidity_pools",
+}
+
+// stateShard is the unit of work of incremental state verification: the
+// entries of a single type whose key hashes to shard out of shardCount.
+type stateShard struct {
+	entryType  xdr.LedgerEntryType
+	shard      uint32
+	shardCount uint32
+}
+
+func (s stateShard) String() string {
+	return fmt.Sprintf("%s shard %d/%d", stateShardEntryTypeNames[s.entryType], s.shard, s.shardCount)
+}
+
+// nextStateShard returns the shard to verify next: the first one which has
+// never been verified or else the one verified the longest time ago.
+func nextStateShard(shardCount uint32, coverage []history.StateVerificationCoverage) stateShard {
+	lastVerified := map[string]uint32{}
+	for _, c := range coverage {
+		lastVerified[c.EntryType+"/"+strconv.FormatUint(uint64(c.Shard), 10)] = c.LedgerSequence
+	}
+
+	var next stateShard
+	var oldest uint32
+	first := true
+	for _, entryType := range stateShardEntryTypes {
+		for shard := uint32(0); shard < shardCount; shard++ {
+			ledger, ok := lastVerified[stateShardEntryTypeNames[entryType]+"/"+strconv.FormatUint(uint64(shard), 10)]
+			if !ok {
+				return stateShard{entryType: entryType, shard: shard, shardCount: shardCount}
+			}
+			if first || ledger < oldest {
+				next = stateShard{entryType: entryType, shard: shard, shardCount: shardCount}
+				oldest = ledger
+				first = false
+			}
+		}
+	}
+	return next
+}
+
+// ledgerEntryShardKey returns the key the shard of an entry is computed from,
+// it must match the key used by history.Q.CountLedgerEntriesInShard.
+func ledgerEntryShardKey(entry xdr.LedgerEntry) (string, error) {
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeAccount:
+		return entry.Data.MustAccount().AccountId.Address(), nil
+	case xdr.LedgerEntryTypeData, xdr.LedgerEntryTypeTrustline:
+		key, err := entry.LedgerKey()
+		if err != nil {
+			return "", errors.Wrap(err, "entry.LedgerKey")
+		}
+		return key.MarshalBinaryBase64()
+	case xdr.LedgerEntryTypeOffer:
+		return strconv.FormatInt(int64(entry.Data.MustOffer().OfferId), 10), nil
+	case xdr.LedgerEntryTypeClaimableBalance:
+		return xdr.MarshalHex(entry.Data.MustClaimableBalance().BalanceId)
+	case xdr.LedgerEntryTypeLiquidityPool:
+		return processors.PoolIDToString(entry.Data.MustLiquidityPool().LiquidityPoolId), nil
+	default:
+		return "", errors.Errorf("unexpected ledger entry type %s", entry.Data.Type)
+	}
+}
+
+// verifyStateShard verifies a single shard of the state at ledgerSequence,
+// rotating through all the shards on every call, and records the outcome in
+// the state_verification_coverage table. Asset stats are only checked by full
+// state verification because they depend on all trust lines, claimable
+// balances and liquidity pools.
+func (s *system) verifyStateShard(
+	ctx context.Context,
+	historyQ history.IngestionQ,
+	ledgerSequence uint32,
+	localLog *logpkg.Entry,
+) error {
+	coverage, err := historyQ.GetStateVerificationCoverage(ctx, s.config.StateVerificationShards)
+	if err != nil {
+		return errors.Wrap(err, "Error getting state verification coverage")
+	}
+	shard := nextStateShard(s.config.StateVerificationShards, coverage)
+	localLog = localLog.WithFields(logpkg.F{
+		"entry_type": stateShardEntryTypeNames[shard.entryType],
+		"shard":      shard.shard,
+		"shards":     shard.shardCount,
+	})
+
+	startTime := time.Now()
+	localLog.Info("Starting incremental state verification")
+
+	err = s.verifyStateShardEntries(ctx, historyQ, ledgerSequence, shard, localLog)
+	if err == nil {
+		s.Metrics().StateVerifyDuration.Observe(time.Since(startTime).Seconds())
+		localLog.Info("State shard correct")
+	} else {
+		err = errors.Wrapf(err, "state verification of %s failed", shard)
+	}
+
+	// Only record verifications which completed: either the shard is correct
+	// or it does not match the history archives.
+	if _, ok := errors.Cause(err).(ingest.StateError); err == nil || ok {
+		record := history.StateVerificationCoverage{
+			EntryType:      stateShardEntryTypeNames[shard.entryType],
+			Shard:          shard.shard,
+			ShardCount:     shard.shardCount,
+			LedgerSequence: ledgerSequence,
+			VerifiedAt:     time.Now().UTC(),
+			Success:        err == nil,
+		}
+		if err != nil {
+			record.Error = null.StringFrom(err.Error())
+		}
+		// historyQ is in a read-only transaction
+		if recordErr := s.historyQ.CloneIngestionQ().RecordStateVerification(s.ctx, record); recordErr != nil {
+			localLog.WithError(recordErr).Error("Error recording state verification coverage")
+		}
+	}
+
+	localLog.WithField("duration", time.Since(startTime).Seconds()).Info("Incremental state verification finished")
+	return err
+}
+
+func (s *system) verifyStateShardEntries(
+	ctx context.Context,
+	historyQ history.IngestionQ,
+	ledgerSequence uint32,
+	shard stateShard,
+	localLog *logpkg.Entry,
+) error {
+	stateReader, err := s.historyAdapter.GetState(ctx, ledgerSequence)
+	if err != nil {
+		return errors.Wrap(err, "Error running GetState")
+	}
+	defer stateReader.Close()
+
+	var shardErr error
+	verifier := verify.NewStateVerifier(stateReader, func(entry xdr.LedgerEntry) (bool, xdr.LedgerEntry) {
+		if entry.Data.Type != shard.entryType {
+			return true, entry
+		}
+		key, keyErr := ledgerEntryShardKey(entry)
+		if keyErr != nil {
+			shardErr = keyErr
+			return true, entry
+		}
+		return history.LedgerEntryShard(key, shard.shardCount) != shard.shard, entry
+	})
+
+	// asset stats are not checked but the state verifier helpers build them
+	assetStats := processors.NewAssetStatSet(s.config.NetworkPassphrase)
+	total := 0
+	for {
+		var entries []xdr.LedgerEntry
+		entries, err = verifier.GetLedgerEntries(verifyBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "verifier.GetLedgerEntries")
+		}
+		if shardErr != nil {
+			return errors.Wrap(shardErr, "Error computing ledger entry shard")
+		}
+
+		if len(entries) == 0 {
+			break
+		}
+
+		switch shard.entryType {
+		case xdr.LedgerEntryTypeAccount:
+			accounts := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				accounts = append(accounts, entry.Data.MustAccount().AccountId.Address())
+			}
+			err = addAccountsToStateVerifier(ctx, verifier, historyQ, accounts)
+		case xdr.LedgerEntryTypeData:
+			data := make([]xdr.LedgerKeyData, 0, len(entries))
+			for _, entry := range entries {
+				key, keyErr := entry.LedgerKey()
+				if keyErr != nil {
+					return errors.Wrap(keyErr, "entry.LedgerKey")
+				}
+				data = append(data, *key.Data)
+			}
+			err = addDataToStateVerifier(ctx, verifier, historyQ, data)
+		case xdr.LedgerEntryTypeOffer:
+			offers := make([]int64, 0, len(entries))
+			for _, entry := range entries {
+				offers = append(offers, int64(entry.Data.MustOffer().OfferId))
+			}
+			err = addOffersToStateVerifier(ctx, verifier, historyQ, offers)
+		case xdr.LedgerEntryTypeTrustline:
+			trustLines := make([]xdr.LedgerKeyTrustLine, 0, len(entries))
+			for _, entry := range entries {
+				key, keyErr := entry.LedgerKey()
+				if keyErr != nil {
+					return errors.Wrap(keyErr, "TrustlineEntry.LedgerKey")
+				}
+				trustLines = append(trustLines, key.MustTrustLine())
+			}
+			err = addTrustLinesToStateVerifier(ctx, verifier, assetStats, historyQ, trustLines)
+		case xdr.LedgerEntryTypeClaimableBalance:
+			cBalances := make([]xdr.ClaimableBalanceId, 0, len(entries))
+			for _, entry := range entries {
+				cBalances = append(cBalances, entry.Data.MustClaimableBalance().BalanceId)
+			}
+			err = addClaimableBalanceToStateVerifier(ctx, verifier, assetStats, historyQ, cBalances)
+		case xdr.LedgerEntryTypeLiquidityPool:
+			lPools := make([]xdr.PoolId, 0, len(entries))
+			for _, entry := range entries {
+				lPools = append(lPools, entry.Data.MustLiquidityPool().LiquidityPoolId)
+			}
+			err = addLiquidityPoolsToStateVerifier(ctx, verifier, assetStats, historyQ, lPools)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "adding %s to StateVerifier failed", stateShardEntryTypeNames[shard.entryType])
+		}
+
+		total += len(entries)
+		localLog.WithField("total", total).Info("Batch added to StateVerifier")
+	}
+
+	count, err := historyQ.CountLedgerEntriesInShard(ctx, shard.entryType, shard.shard, shard.shardCount)
+	if err != nil {
+		return errors.Wrap(err, "Error running historyQ.CountLedgerEntriesInShard")
+	}
+
+	if err = verifier.Verify(count); err != nil {
+		return errors.Wrap(err, "verifier.Verify failed")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/xdr"
+)
+
+func TestNextStateShard(t *testing.T) {
+	assert.Equal(t,
+		stateShard{entryType: xdr.LedgerEntryTypeAccount, shard: 0, shardCount: 2},
+		nextStateShard(2, nil),
+	)
+
+	coverage := []history.StateVerificationCoverage{
+		{EntryType: "accounts", Shard: 0, ShardCount: 2, LedgerSequence: 63},
+		{EntryType: "accounts", Shard: 1, ShardCount: 2, LedgerSequence: 127},
+	}
+	assert.Equal(t,
+		stateShard{entryType: xdr.LedgerEntryTypeData, shard: 0, shardCount: 2},
+		nextStateShard(2, coverage),
+	)
+
+	coverage = nil
+	ledger := uint32(63)
+	for _, entryType := range stateShardEntryTypes {
+		coverage = append(coverage, history.StateVerificationCoverage{
+			EntryType: stateShardEntryTypeNames[entryType], Shard: 0, ShardCount: 1, LedgerSequence: ledger,
+		})
+		ledger += 64
+	}
+	// the least recently verified shard is verified again
+	assert.Equal(t,
+		stateShard{entryType: xdr.LedgerEntryTypeAccount, shard: 0, shardCount: 1},
+		nextStateShard(1, coverage),
+	)
+	coverage[0].LedgerSequence = ledger
+	assert.Equal(t,
+		stateShard{entryType: xdr.LedgerEntryTypeData, shard: 0, shardCount: 1},
+		nextStateShard(1, coverage),
+	)
+	assert.Equal(t, "data shard 0/1", nextStateShard(1, coverage).String())
+}
+
+func TestLedgerEntryShardKey(t *testing.T) {
+	accountID := xdr.MustAddress("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB")
+
+	key, err := ledgerEntryShardKey(xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type:    xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{AccountId: accountID},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB", key)
+
+	key, err = ledgerEntryShardKey(xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type:  xdr.LedgerEntryTypeOffer,
+			Offer: &xdr.OfferEntry{SellerId: accountID, OfferId: 1234},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1234", key)
+
+	dataEntry := xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeData,
+			Data: &xdr.DataEntry{AccountId: accountID, DataName: "name"},
+		},
+	}
+	key, err = ledgerEntryShardKey(dataEntry)
+	require.NoError(t, err)
+	ledgerKey, err := dataEntry.LedgerKey()
+	require.NoError(t, err)
+	expected, err := ledgerKey.MarshalBinaryBase64()
+	require.NoError(t, err)
+	assert.Equal(t, expected, key)
+
+	_, err = ledgerEntryShardKey(xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type:         xdr.LedgerEntryTypeContractCode,
+			ContractCode: &xdr.ContractCodeEntry{},
+		},
+	})
+	assert.EqualError(t, err, "unexpected ledger entry type LedgerEntryTypeContractCode")
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/lantah/go/services/orbitr/internal/ingest/processors"
 	"github.com/lantah/go/services/orbitr/internal/test"
 	"github.com/lantah/go/support/db"
+	"github.com/lantah/go/support/errors"
 	"github.com/lantah/go/xdr"
 )
 
@@ -375,3 +376,83 @@ func TestStateVerifier(t *testing.T) {
 	mockChangeReader.AssertExpectations(t)
 	mockHistoryAdapter.AssertExpectations(t)
 }
+
+func TestIncrementalStateVerifier(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetOrbitRDB(t, tt.OrbitRDB)
+	q := &history.Q{&db.Session{DB: tt.OrbitRDB}}
+
+	checkpointLedger := uint32(63)
+	changeProcessor := buildChangeProcessor(q, &ingest.StatsChangeProcessor{}, ledgerSource, checkpointLedger, "")
+
+	gen := randxdr.NewGenerator()
+	var changes []xdr.LedgerEntryChange
+	for i := 0; i < 20; i++ {
+		changes = append(changes,
+			genLiquidityPool(tt, gen),
+			genClaimableBalance(tt, gen),
+			genOffer(tt, gen),
+			genTrustLine(tt, gen),
+			genAccount(tt, gen),
+			genAccountData(tt, gen),
+			genContractCode(tt, gen),
+		)
+	}
+	ingestChanges := ingest.GetChangesFromLedgerEntryChanges(changes)
+	for _, change := range ingestChanges {
+		tt.Assert.NoError(changeProcessor.ProcessChange(tt.Ctx, change))
+	}
+	tt.Assert.NoError(changeProcessor.Commit(tt.Ctx))
+	q.UpdateLastLedgerIngest(tt.Ctx, checkpointLedger)
+
+	mockHistoryAdapter := &mockHistoryArchiveAdapter{}
+	sys := &system{
+		ctx:                          tt.Ctx,
+		historyQ:                     q,
+		historyAdapter:               mockHistoryAdapter,
+		runStateVerificationOnLedger: ledgerEligibleForStateVerification(64, 1),
+		config:                       Config{StateVerificationTimeout: time.Hour, StateVerificationShards: 2},
+	}
+	sys.initMetrics()
+
+	verifyNextShard := func() error {
+		mockChangeReader := &ingest.MockChangeReader{}
+		for _, change := range ingestChanges {
+			mockChangeReader.On("Read").Return(change, nil).Once()
+		}
+		mockChangeReader.On("Read").Return(ingest.Change{}, io.EOF).Twice()
+		mockChangeReader.On("Close").Return(nil).Once()
+		mockHistoryAdapter.On("GetState", mock.AnythingOfType("*context.timerCtx"), checkpointLedger).
+			Return(mockChangeReader, nil).Once()
+		return sys.verifyState(false)
+	}
+
+	// every shard of every entry type is verified once
+	for i := 0; i < len(stateShardEntryTypes)*2; i++ {
+		tt.Assert.NoError(verifyNextShard())
+	}
+	coverage, err := q.GetStateVerificationCoverage(tt.Ctx, 2)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(coverage, len(stateShardEntryTypes)*2)
+	for _, c := range coverage {
+		tt.Assert.True(c.Success)
+		tt.Assert.Equal(checkpointLedger, c.LedgerSequence)
+	}
+
+	// the next verification checks the first shard of accounts again
+	_, err = q.ExecRaw(tt.Ctx, "DELETE FROM accounts")
+	tt.Assert.NoError(err)
+	err = verifyNextShard()
+	tt.Assert.Error(err)
+	tt.Assert.IsType(ingest.StateError{}, errors.Cause(err))
+	tt.Assert.Contains(err.Error(), "state verification of accounts shard 0/2 failed")
+
+	coverage, err = q.GetStateVerificationCoverage(tt.Ctx, 2)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal("accounts", coverage[0].EntryType)
+	tt.Assert.Equal(uint32(0), coverage[0].Shard)
+	tt.Assert.False(coverage[0].Success)
+	tt.Assert.Contains(coverage[0].Error.String, "accounts shard 0/2")
+	mockHistoryAdapter.AssertExpectations(t)
+}
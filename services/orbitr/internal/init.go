@@ -2,6 +2,7 @@ package orbitr
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"runtime"
 
@@ -9,12 +10,18 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/lantah/go/exp/orderbook"
+	"github.com/lantah/go/historyarchive"
+	"github.com/lantah/go/services/orbitr/internal/archivestate"
 	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/services/orbitr/internal/httpx"
 	"github.com/lantah/go/services/orbitr/internal/ingest"
+	"github.com/lantah/go/services/orbitr/internal/ingest/sink"
 	"github.com/lantah/go/services/orbitr/internal/paths"
+	"github.com/lantah/go/services/orbitr/internal/reap"
 	"github.com/lantah/go/services/orbitr/internal/simplepath"
 	"github.com/lantah/go/services/orbitr/internal/txsub"
 	"github.com/lantah/go/services/orbitr/internal/txsub/sequence"
+	apkg "github.com/lantah/go/support/app"
 	"github.com/lantah/go/support/db"
 	"github.com/lantah/go/support/log"
 )
@@ -88,6 +95,26 @@ func mustInitOrbitRDB(app *App) {
 			app.prometheusRegistry,
 		)}
 	}
+
+	for i, replicaURL := range app.config.ReplicaDatabaseURLs {
+		app.replicaQs = append(app.replicaQs, &history.Q{mustNewDBSession(
+			db.Subservice(fmt.Sprintf("history_replica_%d", i)),
+			replicaURL,
+			maxIdle,
+			maxOpen,
+			app.prometheusRegistry,
+			db.StatementTimeout(app.config.ConnectionTimeout),
+			db.IdleTransactionTimeout(app.config.ConnectionTimeout),
+		)})
+	}
+
+	if len(app.replicaQs) > 0 {
+		replicaSessions := make([]db.SessionInterface, 0, len(app.replicaQs))
+		for _, q := range app.replicaQs {
+			replicaSessions = append(replicaSessions, q.SessionInterface)
+		}
+		app.replicaRouter = httpx.NewReplicaRouter(app.historyQ.SessionInterface, replicaSessions)
+	}
 }
 
 func initIngester(app *App) {
@@ -97,7 +124,14 @@ func initIngester(app *App) {
 		coreSession = mustNewDBSession(
 			db.CoreSubservice, app.config.GravityDatabaseURL, ingest.MaxDBConnections, ingest.MaxDBConnections, app.prometheusRegistry)
 	}
-	app.ingester, err = ingest.NewSystem(ingest.Config{
+	if app.config.IngestLeaderElection {
+		app.leaderElector = ingest.NewLeaderElector(ingest.LeaderElectorConfig{
+			HistorySession: app.OrbitRSession(),
+			Identity:       app.config.IngestLeaderID,
+			LeaseTTL:       app.config.IngestLeaderLeaseTTL,
+		})
+	}
+	ingestConfig := ingest.Config{
 		CoreSession: coreSession,
 		HistorySession: mustNewDBSession(
 			db.IngestSubservice, app.config.DatabaseURL, ingest.MaxDBConnections, ingest.MaxDBConnections, app.prometheusRegistry,
@@ -116,12 +150,97 @@ func initIngester(app *App) {
 		DisableStateVerification:             app.config.IngestDisableStateVerification,
 		StateVerificationCheckpointFrequency: uint32(app.config.IngestStateVerificationCheckpointFrequency),
 		StateVerificationTimeout:             app.config.IngestStateVerificationTimeout,
+		StateVerificationShards:              uint32(app.config.IngestStateVerificationShards),
 		EnableReapLookupTables:               app.config.HistoryRetentionCount > 0,
 		EnableExtendedLogLedgerStats:         app.config.IngestEnableExtendedLogLedgerStats,
 		RoundingSlippageFilter:               app.config.RoundingSlippageFilter,
 		EnableIngestionFiltering:             app.config.EnableIngestionFiltering,
+		LedgerEntryChangeTypes:               app.config.IngestLedgerEntryChanges,
+		LeaderElector:                        app.leaderElector,
+	}
+	if app.config.IngestHistorySinkURL != "" {
+		ingestConfig.HistorySink, err = sink.Connect(app.config.IngestHistorySinkURL)
+		if err != nil {
+			log.Fatalf("cannot connect to history sink: %v", err)
+		}
+	}
+	app.ingester, err = ingest.NewSystem(ingestConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// reingestion jobs enqueued with the admin API need to replay ledgers
+	// with captive core
+	if app.config.EnableCaptiveCoreIngestion {
+		app.reingestJobs = ingest.NewReingestJobManager(ingestConfig, func() (db.SessionInterface, error) {
+			session, err := db.Open("postgres", app.config.DatabaseURL)
+			if err != nil {
+				return nil, err
+			}
+			session.DB.SetMaxIdleConns(ingest.MaxDBConnections)
+			session.DB.SetMaxOpenConns(ingest.MaxDBConnections)
+			return session, nil
+		})
+	}
+	if app.config.HistoryGapDetectionInterval > 0 {
+		gapFillerConfig := ingest.GapFillerConfig{
+			HistorySession:   app.OrbitRSession(),
+			Interval:         app.config.HistoryGapDetectionInterval,
+			MaxLedgersPerRun: uint32(app.config.HistoryGapFillingMaxLedgers),
+			LeaderElector:    app.leaderElector,
+		}
+		if app.config.HistoryGapFilling {
+			gapFillerConfig.ReingestJobs = app.reingestJobs
+		}
+		app.gapFiller = ingest.NewGapFiller(gapFillerConfig)
+	}
+}
+
+// ConnectReapedHistoryArchive connects to the storage where the reaper
+// archives history before deleting it.
+func ConnectReapedHistoryArchive(config Config) (historyarchive.ArchiveBackend, error) {
+	return historyarchive.ConnectBackend(config.ReapedHistoryArchiveURL, historyarchive.ConnectOptions{
+		S3Region:   config.ReapedHistoryArchiveS3Region,
+		S3Endpoint: config.ReapedHistoryArchiveS3Endpoint,
+		// reaped history must not be readable by anyone with the bucket URL
+		S3ObjectACL: "private",
 	})
+}
 
+func initReaper(app *App) {
+	app.reaper = reap.New(app.config.HistoryRetentionCount, app.OrbitRSession(), app.ledgerState)
+	if app.config.ReapedHistoryArchiveURL == "" {
+		return
+	}
+
+	archive, err := ConnectReapedHistoryArchive(app.config)
+	if err != nil {
+		log.Fatalf("cannot connect to reaped history archive: %v", err)
+	}
+	app.reaper.Archive = archive
+}
+
+func initArchiveState(app *App) {
+	if app.config.ArchiveStateCacheSize == 0 {
+		return
+	}
+	if len(app.config.HistoryArchiveURLs) == 0 {
+		log.Fatalf("%s must be set to look up ledger entries in history archives", HistoryArchiveURLsFlagName)
+	}
+
+	archive, err := historyarchive.NewArchivePool(
+		app.config.HistoryArchiveURLs,
+		historyarchive.ConnectOptions{
+			Context:             app.ctx,
+			NetworkPassphrase:   app.config.NetworkPassphrase,
+			CheckpointFrequency: app.config.CheckpointFrequency,
+			UserAgent:           fmt.Sprintf("orbitr/%s golang/%s", apkg.Version(), runtime.Version()),
+		},
+	)
+	if err != nil {
+		log.Fatalf("cannot connect to history archives: %v", err)
+	}
+	app.archiveState, err = archivestate.NewReader(archive, int64(app.config.ArchiveStateCacheSize)*1024*1024)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -226,6 +345,12 @@ func initIngestMetrics(app *App) {
 
 	app.ingestingGauge.Inc()
 	app.ingester.RegisterMetrics(app.prometheusRegistry)
+	if app.gapFiller != nil {
+		app.gapFiller.RegisterMetrics(app.prometheusRegistry)
+	}
+	if app.leaderElector != nil {
+		app.leaderElector.RegisterMetrics(app.prometheusRegistry)
+	}
 }
 
 func initTxSubMetrics(app *App) {
@@ -0,0 +1,244 @@
+package reap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/lantah/go/historyarchive"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/support/log"
+	"github.com/lantah/go/toid"
+)
+
+// Reaped history is archived in one directory per ledger range, named
+// `ledgers-<start>-<end>`, containing:
+//
+//   - `<table>.json.gz`: the rows of every table in history.ExportedTables, as
+//     gzipped newline delimited JSON,
+//   - `lookups.json.gz`: the rows of the lookup tables (history_accounts,
+//     history_assets, ...) referenced by the archived rows, and
+//   - `manifest.json`: the ledger range and the number of rows archived per
+//     table. It is uploaded last so that incomplete archives are ignored.
+const (
+	archiveManifestFile = "manifest.json"
+	archiveLookupsFile  = "lookups.json.gz"
+)
+
+var archiveManifestRegexp = regexp.MustCompile(`ledgers-(\d+)-(\d+)/manifest\.json$`)
+
+type archiveManifest struct {
+	StartLedger uint32         `json:"start_ledger"`
+	EndLedger   uint32         `json:"end_ledger"`
+	Rows        map[string]int `json:"rows"`
+}
+
+func (m archiveManifest) dir() string {
+	return archiveDir(m.StartLedger, m.EndLedger)
+}
+
+type archivedLookupRow struct {
+	Table string          `json:"table"`
+	Row   json.RawMessage `json:"row"`
+}
+
+func archiveDir(startSeq, endSeq uint32) string {
+	return fmt.Sprintf("ledgers-%d-%d", startSeq, endSeq)
+}
+
+func tableFile(table history.ExportedTable) string {
+	return table.Name + ".json.gz"
+}
+
+// archiveBefore archives the history of the ledgers in [startSeq, endSeq) in
+// batches of batchSize ledgers.
+func (r *System) archiveBefore(ctx context.Context, startSeq, endSeq int32) error {
+	for batchStartSeq := startSeq; batchStartSeq < endSeq; batchStartSeq += batchSize {
+		batchEndSeq := batchStartSeq + batchSize - 1
+		if batchEndSeq >= endSeq {
+			batchEndSeq = endSeq - 1
+		}
+		log.WithField("start_ledger", batchStartSeq).WithField("end_ledger", batchEndSeq).Info("reaper: archiving")
+
+		if err := archiveRange(ctx, r.HistoryQ, r.Archive, batchStartSeq, batchEndSeq); err != nil {
+			return errors.Wrapf(err, "could not archive ledgers %d-%d", batchStartSeq, batchEndSeq)
+		}
+	}
+	return nil
+}
+
+// archiveRange uploads the history of the ledgers in [startSeq, endSeq] to
+// the archive.
+func archiveRange(
+	ctx context.Context,
+	q *history.Q,
+	archive historyarchive.ArchiveBackend,
+	startSeq, endSeq int32,
+) error {
+	start, end, err := toid.LedgerRangeInclusive(startSeq, endSeq)
+	if err != nil {
+		return err
+	}
+
+	manifest := archiveManifest{
+		StartLedger: uint32(startSeq),
+		EndLedger:   uint32(endSeq),
+		Rows:        map[string]int{},
+	}
+	lookupIDs := map[string]map[int64]struct{}{}
+
+	for _, table := range history.ExportedTables {
+		count := 0
+		err = putGzippedFile(archive, path.Join(manifest.dir(), tableFile(table)), func(w io.Writer) error {
+			return q.ExportHistoryRows(ctx, table, start, end, func(row json.RawMessage) error {
+				count++
+				if err := collectLookupIDs(table, row, lookupIDs); err != nil {
+					return err
+				}
+				return writeLine(w, row)
+			})
+		})
+		if err != nil {
+			return err
+		}
+		manifest.Rows[table.Name] = count
+	}
+
+	err = putGzippedFile(archive, path.Join(manifest.dir(), archiveLookupsFile), func(w io.Writer) error {
+		return exportLookups(ctx, q, lookupIDs, w)
+	})
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "could not encode manifest")
+	}
+	return archive.PutFile(
+		path.Join(manifest.dir(), archiveManifestFile),
+		ioutil.NopCloser(bytes.NewReader(encoded)),
+	)
+}
+
+func exportLookups(ctx context.Context, q *history.Q, lookupIDs map[string]map[int64]struct{}, w io.Writer) error {
+	const selectBatchSize = 10000
+
+	tables := make([]string, 0, len(lookupIDs))
+	for table := range lookupIDs {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		ids := make([]int64, 0, len(lookupIDs[table]))
+		for id := range lookupIDs[table] {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		for i := 0; i < len(ids); i += selectBatchSize {
+			batchEnd := i + selectBatchSize
+			if batchEnd > len(ids) {
+				batchEnd = len(ids)
+			}
+			rows, err := q.ExportLookupRows(ctx, table, ids[i:batchEnd])
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				line, err := json.Marshal(archivedLookupRow{Table: table, Row: row})
+				if err != nil {
+					return errors.Wrapf(err, "could not encode %s row", table)
+				}
+				if err = writeLine(w, line); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// collectLookupIDs adds the ids of the lookup rows referenced by row to ids.
+func collectLookupIDs(table history.ExportedTable, row json.RawMessage, ids map[string]map[int64]struct{}) error {
+	if len(table.Lookups) == 0 {
+		return nil
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(row, &values); err != nil {
+		return errors.Wrapf(err, "could not decode %s row", table.Name)
+	}
+	for column, lookupTable := range table.Lookups {
+		id, ok, err := int64Value(values, column)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s.%s", table.Name, column)
+		}
+		if !ok {
+			continue
+		}
+		if ids[lookupTable] == nil {
+			ids[lookupTable] = map[int64]struct{}{}
+		}
+		ids[lookupTable][id] = struct{}{}
+	}
+	return nil
+}
+
+// int64Value returns the integer value of a column of a decoded row, ok is
+// false if the column is null.
+func int64Value(values map[string]json.RawMessage, column string) (value int64, ok bool, err error) {
+	raw, present := values[column]
+	if !present || string(raw) == "null" {
+		return 0, false, nil
+	}
+	value, err = strconv.ParseInt(string(raw), 10, 64)
+	return value, err == nil, err
+}
+
+func writeLine(w io.Writer, line []byte) error {
+	if _, err := w.Write(line); err != nil {
+		return err
+	}
+	_, err := w.Write([]byte{'\n'})
+	return err
+}
+
+// putGzippedFile uploads the gzipped output of write to the archive. The
+// output is buffered in a temporary file as it can be much larger than what
+// should be held in memory.
+func putGzippedFile(archive historyarchive.ArchiveBackend, pth string, write func(io.Writer) error) error {
+	tmp, err := ioutil.TempFile("", "orbitr-reaped-history")
+	if err != nil {
+		return errors.Wrap(err, "could not create temporary file")
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	gz := gzip.NewWriter(tmp)
+	if err = write(gz); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return errors.Wrapf(err, "could not compress %s", pth)
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrapf(err, "could not rewind %s", pth)
+	}
+
+	if err = archive.PutFile(pth, ioutil.NopCloser(tmp)); err != nil {
+		return errors.Wrapf(err, "could not upload %s", pth)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package reap
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lantah/go/historyarchive"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/services/orbitr/internal/ledger"
+	"github.com/lantah/go/services/orbitr/internal/test"
+)
+
+func TestCoveringRanges(t *testing.T) {
+	manifests := []archiveManifest{
+		{StartLedger: 2, EndLedger: 10},
+		{StartLedger: 11, EndLedger: 20},
+		{StartLedger: 21, EndLedger: 30},
+		{StartLedger: 41, EndLedger: 50},
+	}
+
+	covering, err := coveringRanges(manifests, 5, 25)
+	assert.NoError(t, err)
+	assert.Equal(t, manifests[:3], covering)
+
+	covering, err = coveringRanges(manifests, 11, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, manifests[1:2], covering)
+
+	_, err = coveringRanges(manifests, 25, 45)
+	assert.EqualError(t, err, "ledgers 31-45 have not been archived")
+
+	_, err = coveringRanges(manifests, 1, 5)
+	assert.EqualError(t, err, "ledgers 1-5 have not been archived")
+}
+
+func TestListArchivedRanges(t *testing.T) {
+	archive, err := historyarchive.ConnectBackend("file://"+t.TempDir(), historyarchive.ConnectOptions{})
+	require.NoError(t, err)
+
+	put := func(pth string) {
+		require.NoError(t, archive.PutFile(pth, ioutil.NopCloser(bytes.NewReader([]byte("{}")))))
+	}
+	put(path.Join(archiveDir(11, 20), archiveManifestFile))
+	put(path.Join(archiveDir(2, 10), archiveManifestFile))
+	put(path.Join(archiveDir(2, 10), archiveLookupsFile))
+	// incomplete archives have no manifest
+	put(path.Join(archiveDir(21, 30), archiveLookupsFile))
+
+	manifests, err := listArchivedRanges(archive)
+	assert.NoError(t, err)
+	assert.Equal(t, []archiveManifest{
+		{StartLedger: 2, EndLedger: 10},
+		{StartLedger: 11, EndLedger: 20},
+	}, manifests)
+}
+
+func TestReadManifest(t *testing.T) {
+	archive, err := historyarchive.ConnectBackend("file://"+t.TempDir(), historyarchive.ConnectOptions{})
+	require.NoError(t, err)
+
+	put := func(startSeq, endSeq uint32, manifest string) {
+		pth := path.Join(archiveDir(startSeq, endSeq), archiveManifestFile)
+		require.NoError(t, archive.PutFile(pth, ioutil.NopCloser(bytes.NewReader([]byte(manifest)))))
+	}
+	put(2, 10, `{"start_ledger":2,"end_ledger":10,"rows":{"history_ledgers":9}}`)
+	put(11, 20, `{"start_ledger":2,"end_ledger":10,"rows":{"history_ledgers":9}}`)
+	put(21, 30, `{"start_ledger":21,"end_ledger":30}`)
+	put(31, 40, `{`)
+
+	manifest, err := readManifest(archive, archiveManifest{StartLedger: 2, EndLedger: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, archiveManifest{
+		StartLedger: 2,
+		EndLedger:   10,
+		Rows:        map[string]int{"history_ledgers": 9},
+	}, manifest)
+
+	_, err = readManifest(archive, archiveManifest{StartLedger: 11, EndLedger: 20})
+	assert.EqualError(t, err, "ledgers-11-20/manifest.json is for ledgers 2-10")
+	_, err = readManifest(archive, archiveManifest{StartLedger: 21, EndLedger: 30})
+	assert.EqualError(t, err, "ledgers-21-30/manifest.json does not list the archived rows")
+	_, err = readManifest(archive, archiveManifest{StartLedger: 31, EndLedger: 40})
+	assert.Error(t, err)
+	_, err = readManifest(archive, archiveManifest{StartLedger: 41, EndLedger: 50})
+	assert.Error(t, err)
+}
+
+func TestArchiveAndRestoreHistory(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	ledgerState := &ledger.State{}
+	ledgerState.SetStatus(tt.Scenario("kahuna"))
+
+	db := tt.OrbitRSession()
+	archive, err := historyarchive.ConnectBackend("file://"+t.TempDir(), historyarchive.ConnectOptions{})
+	tt.Require.NoError(err)
+
+	sys := New(10, db, ledgerState)
+	sys.Archive = archive
+	sleep = 0
+
+	count := func(table string) int {
+		var count int
+		tt.Require.NoError(db.GetRaw(tt.Ctx, &count, `SELECT COUNT(*) FROM `+table))
+		return count
+	}
+	prev := map[string]int{}
+	for _, table := range history.ExportedTables {
+		prev[table.Name] = count(table.Name)
+	}
+
+	ledgerState.SetStatus(tt.LoadLedgerStatus())
+	elder := ledgerState.CurrentStatus().HistoryElder
+	latest := ledgerState.CurrentStatus().HistoryLatest
+	tt.Require.NoError(sys.DeleteUnretainedHistory(tt.Ctx))
+	tt.Assert.Equal(10, count("history_ledgers"))
+
+	manifests, err := listArchivedRanges(archive)
+	tt.Require.NoError(err)
+	tt.Assert.Equal([]archiveManifest{
+		{StartLedger: uint32(elder), EndLedger: uint32(latest - 10)},
+	}, manifests)
+
+	// lookup rows referenced only by reaped history are recreated on restore
+	q := &history.Q{SessionInterface: tt.OrbitRSession()}
+	tt.Require.NoError(q.Begin(tt.Ctx))
+	_, _, err = q.ReapLookupTables(tt.Ctx, nil)
+	tt.Require.NoError(err)
+	tt.Require.NoError(q.Commit())
+
+	err = RestoreRange(tt.Ctx, q, archive, uint32(elder), uint32(latest-10), 0)
+	tt.Require.NoError(err)
+	for _, table := range history.ExportedTables {
+		tt.Assert.Equal(prev[table.Name], count(table.Name), table.Name)
+	}
+
+	// ranges which have not been archived cannot be restored
+	err = RestoreRange(tt.Ctx, q, archive, uint32(elder), uint32(latest), 0)
+	tt.Assert.EqualError(err, fmt.Sprintf("ledgers %d-%d have not been archived", latest-9, latest))
+}
@@ -7,6 +7,7 @@ package reap
 import (
 	"context"
 
+	"github.com/lantah/go/historyarchive"
 	"github.com/lantah/go/services/orbitr/internal/db2/history"
 	"github.com/lantah/go/services/orbitr/internal/ledger"
 	"github.com/lantah/go/support/db"
@@ -19,6 +20,10 @@ type System struct {
 	ledgerState    *ledger.State
 	ctx            context.Context
 	cancel         context.CancelFunc
+
+	// Archive is where history is archived before being deleted. Reaped
+	// history is not archived when nil.
+	Archive historyarchive.ArchiveBackend
 }
 
 // New initializes the reaper, causing it to begin polling the gravity
@@ -0,0 +1,399 @@
+package reap
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/lantah/go/historyarchive"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/support/log"
+	"github.com/lantah/go/toid"
+	"github.com/lantah/go/xdr"
+)
+
+const restoreBatchSize = 1000
+
+// RestoreRange re-imports the history of the ledgers in [startSeq, endSeq]
+// from the archive written by the reaper. Any history already present in the
+// range is replaced. The whole range must be covered by archived ledger ranges.
+func RestoreRange(
+	ctx context.Context,
+	q *history.Q,
+	archive historyarchive.ArchiveBackend,
+	startSeq, endSeq uint32,
+	roundingSlippageFilter int,
+) error {
+	start, end, err := toid.LedgerRangeInclusive(int32(startSeq), int32(endSeq))
+	if err != nil {
+		return err
+	}
+
+	manifests, err := listArchivedRanges(archive)
+	if err != nil {
+		return err
+	}
+	manifests, err = coveringRanges(manifests, startSeq, endSeq)
+	if err != nil {
+		return err
+	}
+	// The manifests are read before anything is deleted so that a broken
+	// archive leaves the database untouched.
+	for i := range manifests {
+		if manifests[i], err = readManifest(archive, manifests[i]); err != nil {
+			return err
+		}
+	}
+
+	if err = q.Begin(ctx); err != nil {
+		return errors.Wrap(err, "Error in begin")
+	}
+	defer q.Rollback()
+
+	if err = q.DeleteRangeAll(ctx, start, end); err != nil {
+		return errors.Wrap(err, "Error in DeleteRangeAll")
+	}
+	for seq := history.HistoryPartitionStart(startSeq); seq <= endSeq; seq += history.HistoryPartitionLedgers {
+		if err = q.EnsureHistoryPartitions(ctx, seq); err != nil {
+			return err
+		}
+	}
+
+	for _, manifest := range manifests {
+		log.WithField("start_ledger", manifest.StartLedger).
+			WithField("end_ledger", manifest.EndLedger).
+			Info("restoring archived history")
+		if err = restoreArchivedRange(ctx, q, archive, manifest, start, end); err != nil {
+			return errors.Wrapf(err, "could not restore ledgers %d-%d", manifest.StartLedger, manifest.EndLedger)
+		}
+	}
+
+	err = q.RebuildTradeAggregationBuckets(ctx, startSeq, endSeq, roundingSlippageFilter)
+	if err != nil {
+		return errors.Wrap(err, "Error rebuilding trade aggregations")
+	}
+
+	return q.Commit()
+}
+
+// listArchivedRanges returns the ledger ranges of all the complete archived
+// ledger ranges, sorted by start ledger. Only the ledger ranges of the
+// returned manifests are set, readManifest returns their full contents.
+func listArchivedRanges(archive historyarchive.ArchiveBackend) ([]archiveManifest, error) {
+	var manifests []archiveManifest
+	files, errs := archive.ListFiles("")
+	for files != nil || errs != nil {
+		select {
+		case file, ok := <-files:
+			if !ok {
+				files = nil
+				continue
+			}
+			matches := archiveManifestRegexp.FindStringSubmatch(file)
+			if matches == nil {
+				continue
+			}
+			startSeq, err := strconv.ParseUint(matches[1], 10, 32)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid archived range %s", file)
+			}
+			endSeq, err := strconv.ParseUint(matches[2], 10, 32)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid archived range %s", file)
+			}
+			manifests = append(manifests, archiveManifest{
+				StartLedger: uint32(startSeq),
+				EndLedger:   uint32(endSeq),
+			})
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			return nil, errors.Wrap(err, "could not list archived history")
+		}
+	}
+
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].StartLedger < manifests[j].StartLedger
+	})
+	return manifests, nil
+}
+
+// readManifest downloads and decodes the manifest of an archived ledger
+// range listed by listArchivedRanges.
+func readManifest(archive historyarchive.ArchiveBackend, listed archiveManifest) (archiveManifest, error) {
+	pth := path.Join(listed.dir(), archiveManifestFile)
+	file, err := archive.GetFile(pth)
+	if err != nil {
+		return archiveManifest{}, errors.Wrapf(err, "could not download %s", pth)
+	}
+	defer file.Close()
+
+	var manifest archiveManifest
+	if err = json.NewDecoder(file).Decode(&manifest); err != nil {
+		return archiveManifest{}, errors.Wrapf(err, "could not decode %s", pth)
+	}
+	if manifest.StartLedger != listed.StartLedger || manifest.EndLedger != listed.EndLedger {
+		return archiveManifest{}, errors.Errorf(
+			"%s is for ledgers %d-%d", pth, manifest.StartLedger, manifest.EndLedger,
+		)
+	}
+	if manifest.Rows == nil {
+		return archiveManifest{}, errors.Errorf("%s does not list the archived rows", pth)
+	}
+	return manifest, nil
+}
+
+// coveringRanges returns the archived ranges overlapping [startSeq, endSeq]
+// and errors if some ledgers of the range have not been archived.
+func coveringRanges(manifests []archiveManifest, startSeq, endSeq uint32) ([]archiveManifest, error) {
+	var covering []archiveManifest
+	next := startSeq
+	for _, manifest := range manifests {
+		if manifest.EndLedger < startSeq || manifest.StartLedger > endSeq {
+			continue
+		}
+		if manifest.StartLedger > next {
+			break
+		}
+		covering = append(covering, manifest)
+		if manifest.EndLedger >= next {
+			next = manifest.EndLedger + 1
+		}
+		if next > endSeq {
+			return covering, nil
+		}
+	}
+	return nil, errors.Errorf("ledgers %d-%d have not been archived", next, endSeq)
+}
+
+// restoreArchivedRange imports the rows of an archived range whose toid is
+// within [start, end).
+func restoreArchivedRange(
+	ctx context.Context,
+	q *history.Q,
+	archive historyarchive.ArchiveBackend,
+	manifest archiveManifest,
+	start, end int64,
+) error {
+	ids, err := restoreLookups(ctx, q, archive, manifest)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range history.ExportedTables {
+		// archives written before a table was exported do not include it
+		if _, ok := manifest.Rows[table.Name]; !ok {
+			continue
+		}
+
+		var batch []json.RawMessage
+		err = readGzippedLines(archive, path.Join(manifest.dir(), tableFile(table)), func(row json.RawMessage) error {
+			var values map[string]json.RawMessage
+			if err := json.Unmarshal(row, &values); err != nil {
+				return errors.Wrapf(err, "could not decode %s row", table.Name)
+			}
+
+			id, _, err := int64Value(values, table.Column)
+			if err != nil {
+				return errors.Wrapf(err, "invalid %s.%s", table.Name, table.Column)
+			}
+			if id < start || id >= end {
+				return nil
+			}
+
+			if len(table.Lookups) > 0 {
+				if row, err = remapLookups(table, values, ids); err != nil {
+					return err
+				}
+			}
+
+			batch = append(batch, row)
+			if len(batch) < restoreBatchSize {
+				return nil
+			}
+			err = q.ImportHistoryRows(ctx, table, batch)
+			batch = batch[:0]
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if err = q.ImportHistoryRows(ctx, table, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remapLookups replaces the archived ids of the lookup rows referenced by a
+// row with their ids in the database, which differ if they were reaped and
+// recreated since the row was archived.
+func remapLookups(
+	table history.ExportedTable,
+	values map[string]json.RawMessage,
+	ids map[string]map[int64]int64,
+) (json.RawMessage, error) {
+	for column, lookupTable := range table.Lookups {
+		archivedID, ok, err := int64Value(values, column)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s.%s", table.Name, column)
+		}
+		if !ok {
+			continue
+		}
+		id, ok := ids[lookupTable][archivedID]
+		if !ok {
+			return nil, errors.Errorf("%s row %d referenced by %s is missing from the archive", lookupTable, archivedID, table.Name)
+		}
+		values[column] = json.RawMessage(strconv.FormatInt(id, 10))
+	}
+
+	row, err := json.Marshal(values)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not encode %s row", table.Name)
+	}
+	return row, nil
+}
+
+// restoreLookups creates the lookup rows of an archived range which no longer
+// exist and returns, for every lookup table, the mapping from archived ids to
+// the ids in the database.
+func restoreLookups(
+	ctx context.Context,
+	q *history.Q,
+	archive historyarchive.ArchiveBackend,
+	manifest archiveManifest,
+) (map[string]map[int64]int64, error) {
+	var (
+		accounts          = map[int64]string{}
+		assets            = map[int64]xdr.Asset{}
+		claimableBalances = map[int64]string{}
+		liquidityPools    = map[int64]string{}
+		accountAddresses  []string
+		assetList         []xdr.Asset
+		balanceIDs        []string
+		liquidityPoolIDs  []string
+	)
+
+	err := readGzippedLines(archive, path.Join(manifest.dir(), archiveLookupsFile), func(line json.RawMessage) error {
+		var lookup archivedLookupRow
+		if err := json.Unmarshal(line, &lookup); err != nil {
+			return errors.Wrap(err, "could not decode lookup row")
+		}
+
+		var row struct {
+			ID                 int64  `json:"id"`
+			Address            string `json:"address"`
+			AssetType          string `json:"asset_type"`
+			AssetCode          string `json:"asset_code"`
+			AssetIssuer        string `json:"asset_issuer"`
+			ClaimableBalanceID string `json:"claimable_balance_id"`
+			LiquidityPoolID    string `json:"liquidity_pool_id"`
+		}
+		if err := json.Unmarshal(lookup.Row, &row); err != nil {
+			return errors.Wrapf(err, "could not decode %s row", lookup.Table)
+		}
+
+		switch lookup.Table {
+		case "history_accounts":
+			accounts[row.ID] = row.Address
+			accountAddresses = append(accountAddresses, row.Address)
+		case "history_assets":
+			asset, err := xdr.BuildAsset(row.AssetType, row.AssetIssuer, row.AssetCode)
+			if err != nil {
+				return errors.Wrapf(err, "invalid history_assets row %d", row.ID)
+			}
+			assets[row.ID] = asset
+			assetList = append(assetList, asset)
+		case "history_claimable_balances":
+			claimableBalances[row.ID] = row.ClaimableBalanceID
+			balanceIDs = append(balanceIDs, row.ClaimableBalanceID)
+		case "history_liquidity_pools":
+			liquidityPools[row.ID] = row.LiquidityPoolID
+			liquidityPoolIDs = append(liquidityPoolIDs, row.LiquidityPoolID)
+		default:
+			return errors.Errorf("unexpected lookup table %s", lookup.Table)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := map[string]map[int64]int64{
+		"history_accounts":           {},
+		"history_assets":             {},
+		"history_claimable_balances": {},
+		"history_liquidity_pools":    {},
+	}
+
+	accountIDs, err := q.CreateAccounts(ctx, accountAddresses, restoreBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	for archivedID, address := range accounts {
+		ids["history_accounts"][archivedID] = accountIDs[address]
+	}
+
+	assetRows, err := q.CreateAssets(ctx, assetList, restoreBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	for archivedID, asset := range assets {
+		ids["history_assets"][archivedID] = assetRows[asset.String()].ID
+	}
+
+	balanceRowIDs, err := q.CreateHistoryClaimableBalances(ctx, balanceIDs, restoreBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	for archivedID, balanceID := range claimableBalances {
+		ids["history_claimable_balances"][archivedID] = balanceRowIDs[balanceID]
+	}
+
+	poolRowIDs, err := q.CreateHistoryLiquidityPools(ctx, liquidityPoolIDs, restoreBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	for archivedID, poolID := range liquidityPools {
+		ids["history_liquidity_pools"][archivedID] = poolRowIDs[poolID]
+	}
+
+	return ids, nil
+}
+
+// readGzippedLines calls callback with every line of a gzipped newline
+// delimited JSON file of the archive.
+func readGzippedLines(archive historyarchive.ArchiveBackend, pth string, callback func(json.RawMessage) error) error {
+	file, err := archive.GetFile(pth)
+	if err != nil {
+		return errors.Wrapf(err, "could not download %s", pth)
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return errors.Wrapf(err, "could not decompress %s", pth)
+	}
+	defer gz.Close()
+
+	decoder := json.NewDecoder(gz)
+	for {
+		var line json.RawMessage
+		if err = decoder.Decode(&line); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "could not decode %s", pth)
+		}
+		if err = callback(line); err != nil {
+			return err
+		}
+	}
+}
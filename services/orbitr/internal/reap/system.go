@@ -26,7 +26,21 @@ func (r *System) DeleteUnretainedHistory(ctx context.Context) error {
 		return nil
 	}
 
-	err := r.clearBefore(ctx, latest.HistoryElder, targetElder)
+	if r.Archive != nil {
+		err := r.archiveBefore(ctx, latest.HistoryElder, targetElder)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := r.dropPartitionsBefore(ctx, targetElder)
+	if err != nil {
+		return err
+	}
+
+	// Rows of partially expired partitions, history_ledgers and the trade
+	// aggregations are still deleted row by row.
+	err = r.clearBefore(ctx, latest.HistoryElder, targetElder)
 	if err != nil {
 		return err
 	}
@@ -70,6 +84,32 @@ func (r *System) runOnce(ctx context.Context) {
 	}
 }
 
+// dropPartitionsBefore drops the partitions of the history tables which only
+// contain ledgers older than endSeq. Dropping a partition is much cheaper than
+// deleting its rows and leaves no dead tuples behind to be vacuumed.
+func (r *System) dropPartitionsBefore(ctx context.Context, endSeq int32) error {
+	err := r.HistoryQ.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Error in begin")
+	}
+	defer r.HistoryQ.Rollback()
+
+	dropped, err := r.HistoryQ.DropHistoryPartitionsBefore(ctx, uint32(endSeq))
+	if err != nil {
+		return errors.Wrap(err, "Error in DropHistoryPartitionsBefore")
+	}
+
+	err = r.HistoryQ.Commit()
+	if err != nil {
+		return errors.Wrap(err, "Error in commit")
+	}
+
+	if len(dropped) > 0 {
+		log.WithField("partitions", dropped).Info("reaper: dropped history partitions")
+	}
+	return nil
+}
+
 // Work backwards in 100k ledger blocks to prevent using all the CPU.
 //
 // This runs every hour, so we need to make sure it doesn't
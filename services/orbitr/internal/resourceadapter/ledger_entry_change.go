@@ -0,0 +1,51 @@
+package resourceadapter
+
+import (
+	"context"
+	"strconv"
+
+	protocol "github.com/lantah/go/protocols/orbitr"
+	orbitrContext "github.com/lantah/go/services/orbitr/internal/context"
+	"github.com/lantah/go/services/orbitr/internal/db2/history"
+	"github.com/lantah/go/support/render/hal"
+	"github.com/lantah/go/toid"
+	"github.com/lantah/go/xdr"
+)
+
+var ledgerEntryChangeTypeNames = map[xdr.LedgerEntryChangeType]string{
+	xdr.LedgerEntryChangeTypeLedgerEntryCreated: "created",
+	xdr.LedgerEntryChangeTypeLedgerEntryUpdated: "updated",
+	xdr.LedgerEntryChangeTypeLedgerEntryRemoved: "removed",
+}
+
+// PopulateLedgerEntryChange fills out the resource's fields
+func PopulateLedgerEntryChange(
+	ctx context.Context,
+	dest *protocol.LedgerEntryChange,
+	row history.LedgerEntryChangeWithTransaction,
+) {
+	dest.ID = row.PagingToken()
+	dest.PT = row.PagingToken()
+	dest.Ledger = row.LedgerSequence()
+	dest.LedgerCloseTime = row.LedgerCloseTime
+	dest.TransactionHash = row.TransactionHash
+	dest.LedgerKey = row.LedgerKey
+	for name, entryType := range history.LedgerEntryTypesByName {
+		if entryType == row.EntryType {
+			dest.EntryType = name
+		}
+	}
+	dest.ChangeType = ledgerEntryChangeTypeNames[row.ChangeType]
+	dest.PreEntry = row.PreEntry.String
+	dest.PostEntry = row.PostEntry.String
+
+	lb := hal.LinkBuilder{Base: orbitrContext.BaseURL(ctx)}
+	dest.Links.Transaction = lb.Linkf("/transactions/%s", row.TransactionHash)
+	if row.OperationIndex.Valid {
+		id := toid.Parse(row.TransactionID)
+		id.OperationOrder = int32(row.OperationIndex.Int64) + 1
+		dest.OperationID = strconv.FormatInt(id.ToInt64(), 10)
+		link := lb.Linkf("/operations/%s", dest.OperationID)
+		dest.Links.Operation = &link
+	}
+}
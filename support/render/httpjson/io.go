@@ -1,8 +1,11 @@
 package httpjson
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/lantah/go/support/errors"
 )
@@ -40,6 +43,52 @@ func RenderStatus(w http.ResponseWriter, statusCode int, data interface{}, cType
 		return
 	}
 
+	write(w, statusCode, js, cType)
+}
+
+// RenderCacheable write data to w, after marshaling to json, like Render.
+// Unless cacheControl is empty, the response carries a strong ETag derived
+// from its body and cacheControl as its Cache-Control header, and requests
+// whose If-None-Match header matches the ETag get a 304 response without a
+// body.
+func RenderCacheable(w http.ResponseWriter, r *http.Request, data interface{}, cType contentType, cacheControl string) {
+	js, err := renderToString(data, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if cacheControl != "" {
+		sum := sha256.Sum256(js)
+		etag := `"` + hex.EncodeToString(sum[:]) + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", cacheControl)
+		if etagMatches(r, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	write(w, http.StatusOK, js, cType)
+}
+
+// etagMatches reports whether the If-None-Match header of r matches etag,
+// using the weak comparison mandated by RFC 7232.
+func etagMatches(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func write(w http.ResponseWriter, statusCode int, js []byte, cType contentType) {
 	w.Header().Set("Content-Disposition", "inline")
 	switch cType {
 	case HALJSON:
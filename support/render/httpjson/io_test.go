@@ -99,3 +99,39 @@ func TestRenderStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderCacheable(t *testing.T) {
+	data := map[string]interface{}{"key": "value"}
+
+	w := httptest.NewRecorder()
+	RenderCacheable(w, httptest.NewRequest("GET", "/", nil), data, HALJSON, "")
+	assert.Equal(t, 200, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.Empty(t, w.Header().Get("Cache-Control"))
+
+	w = httptest.NewRecorder()
+	RenderCacheable(w, httptest.NewRequest("GET", "/", nil), data, HALJSON, "public, max-age=5")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/hal+json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=5", w.Header().Get("Cache-Control"))
+	etag := w.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{64}"$`, etag)
+	assert.JSONEq(t, `{"key":"value"}`, w.Body.String())
+
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("If-None-Match", ifNoneMatch)
+		w = httptest.NewRecorder()
+		RenderCacheable(w, r, data, HALJSON, "public, max-age=5")
+		assert.Equal(t, 304, w.Code, ifNoneMatch)
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+		assert.Empty(t, w.Body.String())
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", `"other"`)
+	w = httptest.NewRecorder()
+	RenderCacheable(w, r, data, HALJSON, "public, max-age=5")
+	assert.Equal(t, 200, w.Code)
+	assert.JSONEq(t, `{"key":"value"}`, w.Body.String())
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/howeyc/gopass"
 	"github.com/lantah/go/txnbuild"
+	"github.com/lantah/go/txnbuild/txrep"
 	"github.com/lantah/go/xdr"
 )
 
@@ -115,9 +116,10 @@ func (router *SignOrVerify) doSign(gentx *txnbuild.GenericTransaction) string {
 
 var in *bufio.Reader
 
-var infile = flag.String("infile", "", "transaction envelope")
+var infile = flag.String("infile", "", "transaction envelope, base64 encoded or in txrep")
 var verify = flag.Bool("verify", false, "Verify the transaction instead of signing")
 var testnet = flag.Bool("testnet", false, "Sign or verify the transaction using Testnet passphrase instead of Public")
+var showTxrep = flag.Bool("txrep", false, "Print the transaction in txrep (SEP-11) instead of a summary, and the signed transaction in txrep too")
 
 func main() {
 	flag.Parse()
@@ -129,8 +131,9 @@ func main() {
 	)
 
 	if *infile == "" {
-		// read envelope
-		env, err = readLine("Enter envelope (base64): ", false)
+		// read envelope, txrep spans several lines so it can only be read
+		// from a file
+		env, err = readLine("Enter envelope (base64, use -infile for txrep): ", false)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -151,37 +154,26 @@ func main() {
 		env = string(raw)
 	}
 
-	// parse the envelope
+	// parse the envelope, which may be in txrep rather than base64
 	var txe xdr.TransactionEnvelope
-	err = xdr.SafeUnmarshalBase64(env, &txe)
+	err = xdr.SafeUnmarshalBase64(strings.TrimSpace(env), &txe)
 	if err != nil {
-		log.Fatal(err)
-	}
-
-	isFeeBump := txe.IsFeeBump()
-
-	fmt.Println("")
-	fmt.Println("Transaction Summary:")
-	sourceAccount := txe.SourceAccount().ToAccountId()
-	fmt.Printf("  type: %s\n", txe.Type.String())
-	if isFeeBump {
-		fmt.Printf("  fee bump source: %s\n", txe.FeeBumpAccount().ToAccountId().Address())
-	}
-	fmt.Printf("  source: %s\n", sourceAccount.Address())
-	fmt.Printf("  ops: %d\n", len(txe.Operations()))
-	fmt.Printf("  sigs: %d\n", len(txe.Signatures()))
-	for _, signature := range txe.Signatures() {
-		fmt.Printf("    %s\n", b64.StdEncoding.EncodeToString(signature.Signature))
-	}
-	if isFeeBump {
-		fmt.Printf("  fee bump sigs: %d\n", len(txe.FeeBumpSignatures()))
-		for _, feeBumpSignature := range txe.FeeBumpSignatures() {
-			fmt.Printf("    %s\n", b64.StdEncoding.EncodeToString(feeBumpSignature.Signature))
+		var txrepErr error
+		txe, txrepErr = txrep.Unmarshal(env)
+		if txrepErr != nil {
+			log.Fatalf("Envelope is neither base64 (%v) nor txrep (%v)", err, txrepErr)
+		}
+		env, err = xdr.MarshalBase64(txe)
+		if err != nil {
+			log.Fatal(err)
 		}
 	}
-	fmt.Println("")
 
-	// TODO: add operation details
+	if *showTxrep {
+		printTxrep(txe)
+	} else {
+		printSummary(txe)
+	}
 
 	passPhrase := network.PublicNetworkPassphrase
 	if *testnet {
@@ -211,6 +203,14 @@ func main() {
 		}
 	} else {
 		newEnv := flowRouter.doSign(parsed)
+		if *showTxrep {
+			var signed xdr.TransactionEnvelope
+			if err = xdr.SafeUnmarshalBase64(newEnv, &signed); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Print("\n==== Result (txrep) ====\n")
+			printTxrep(signed)
+		}
 		fmt.Print("\n==== Result ====\n\n")
 		fmt.Print("```\n")
 		fmt.Println(newEnv)
@@ -219,6 +219,41 @@ func main() {
 
 }
 
+func printTxrep(txe xdr.TransactionEnvelope) {
+	rep, err := txrep.Marshal(txe)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("")
+	fmt.Print(rep)
+	fmt.Println("")
+}
+
+func printSummary(txe xdr.TransactionEnvelope) {
+	isFeeBump := txe.IsFeeBump()
+
+	fmt.Println("")
+	fmt.Println("Transaction Summary:")
+	sourceAccount := txe.SourceAccount().ToAccountId()
+	fmt.Printf("  type: %s\n", txe.Type.String())
+	if isFeeBump {
+		fmt.Printf("  fee bump source: %s\n", txe.FeeBumpAccount().ToAccountId().Address())
+	}
+	fmt.Printf("  source: %s\n", sourceAccount.Address())
+	fmt.Printf("  ops: %d\n", len(txe.Operations()))
+	fmt.Printf("  sigs: %d\n", len(txe.Signatures()))
+	for _, signature := range txe.Signatures() {
+		fmt.Printf("    %s\n", b64.StdEncoding.EncodeToString(signature.Signature))
+	}
+	if isFeeBump {
+		fmt.Printf("  fee bump sigs: %d\n", len(txe.FeeBumpSignatures()))
+		for _, feeBumpSignature := range txe.FeeBumpSignatures() {
+			fmt.Printf("    %s\n", b64.StdEncoding.EncodeToString(feeBumpSignature.Signature))
+		}
+	}
+	fmt.Println("")
+}
+
 func readLine(prompt string, private bool) (string, error) {
 	fmt.Println(prompt)
 	var line string
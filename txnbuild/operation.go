@@ -24,6 +24,20 @@ func SetOpSourceAccount(op *xdr.Operation, sourceAccount string) {
 	op.SourceAccount = &opSourceAccountID
 }
 
+// OperationWithSourceAccount returns a copy of op with sourceAccount as its source account, or op
+// itself if it already has a source account.
+func OperationWithSourceAccount(op Operation, sourceAccount string) (Operation, error) {
+	if op.GetSourceAccount() != "" {
+		return op, nil
+	}
+	xdrOp, err := op.BuildXDR()
+	if err != nil {
+		return nil, err
+	}
+	SetOpSourceAccount(&xdrOp, sourceAccount)
+	return operationFromXDR(xdrOp)
+}
+
 // operationFromXDR returns a txnbuild Operation from its corresponding XDR operation
 func operationFromXDR(xdrOp xdr.Operation) (Operation, error) {
 	var newOp Operation
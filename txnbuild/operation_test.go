@@ -66,6 +66,29 @@ func TestZeroBalanceAccount(t *testing.T) {
 	assert.NoErrorf(t, err, "zero-balance account creation should work")
 }
 
+func TestOperationWithSourceAccount(t *testing.T) {
+	source, other := newKeypair0().Address(), newKeypair1().Address()
+	payment := &Payment{
+		Destination: newKeypair2().Address(),
+		Amount:      "10",
+		Asset:       NativeAsset{},
+	}
+
+	op, err := OperationWithSourceAccount(payment, source)
+	if assert.NoError(t, err) {
+		assert.Equal(t, source, op.GetSourceAccount())
+		assert.Equal(t, payment.Destination, op.(*Payment).Destination)
+		assert.Equal(t, "10.000000", op.(*Payment).Amount)
+		assert.Equal(t, "", payment.SourceAccount, "the operation should not be modified")
+	}
+
+	payment.SourceAccount = other
+	op, err = OperationWithSourceAccount(payment, source)
+	if assert.NoError(t, err) {
+		assert.Equal(t, other, op.GetSourceAccount())
+	}
+}
+
 func TestPaymentFromXDR(t *testing.T) {
 	txeB64 := "AAAAAGigiN2q4qBXAERImNEncpaADylyBRtzdqpEsku6CN0xAAABkAAADXYAAAABAAAAAAAAAAAAAAACAAAAAQAAAABooIjdquKgVwBESJjRJ3KWgA8pcgUbc3aqRLJLugjdMQAAAAEAAAAAEH3Rayw4M0iCLoEe96rPFNGYim8AVHJU0z4ebYZW4JwAAAAAAAAAAAX14QAAAAAAAAAAAQAAAAAQfdFrLDgzSIIugR73qs8U0ZiKbwBUclTTPh5thlbgnAAAAAFYWQAAAAAAAGigiN2q4qBXAERImNEncpaADylyBRtzdqpEsku6CN0xAAAAAE/exwAAAAAAAAAAAA=="
 
@@ -0,0 +1,336 @@
+// Package sep7 builds, parses, signs and verifies SEP-7 URIs, which hand off
+// transactions and payments to external wallets.
+//
+// See https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0007.md
+package sep7
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+
+	"github.com/lantah/go/clients/stellartoml"
+	"github.com/lantah/go/keypair"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/txnbuild"
+)
+
+// Scheme is the scheme of SEP-7 URIs.
+const Scheme = "web+stellar:"
+
+// MaxMsgLength is the maximum length of the msg parameter.
+const MaxMsgLength = 300
+
+// callbackPrefix prefixes the URL of the callback parameter.
+const callbackPrefix = "url:"
+
+// signaturePrefix is prepended to the URI signed by the origin domain.
+const signaturePrefix = "stellar.sep.7 - URI Scheme"
+
+// Operation is the operation of a SEP-7 URI.
+type Operation string
+
+const (
+	// OperationTx requests the signature of a transaction.
+	OperationTx Operation = "tx"
+	// OperationPay requests a payment.
+	OperationPay Operation = "pay"
+)
+
+var (
+	// ErrNotSigned is returned when verifying a URI without a signature.
+	ErrNotSigned = errors.New("uri is not signed")
+	// ErrInvalidSignature is returned when the signature of a URI was not
+	// made by the URI_REQUEST_SIGNING_KEY of its origin domain.
+	ErrInvalidSignature = errors.New("uri signature is invalid")
+)
+
+// URI is a SEP-7 URI. The parameters which do not apply to its operation are
+// ignored.
+type URI struct {
+	Operation Operation
+
+	// XDR is the transaction envelope of a tx operation, base64 encoded.
+	XDR string
+	// Replace lists the fields of the transaction to be replaced by the
+	// wallet, in the txrep format of SEP-11.
+	Replace string
+	// Pubkey is the account which should sign the transaction.
+	Pubkey string
+	// Chain is the URI which requested this URI.
+	Chain string
+
+	// Destination is the account receiving the payment of a pay operation.
+	Destination string
+	// Amount is the amount of the payment, chosen by the user if empty.
+	Amount string
+	// AssetCode and AssetIssuer are the asset of the payment, which is the
+	// native asset if AssetCode is empty.
+	AssetCode   string
+	AssetIssuer string
+	// Memo and MemoType are the memo of the payment. MemoType is one of
+	// MEMO_TEXT, MEMO_ID, MEMO_HASH and MEMO_RETURN, and hash memos are base64
+	// encoded.
+	Memo     string
+	MemoType string
+
+	// Callback is the URL the signed transaction is posted to rather than
+	// being submitted by the wallet.
+	Callback string
+	// Msg is a message shown to the user, up to MaxMsgLength characters.
+	Msg string
+	// NetworkPassphrase is the passphrase of the network, the public network
+	// if empty.
+	NetworkPassphrase string
+	// OriginDomain is the domain whose URI_REQUEST_SIGNING_KEY signed the URI.
+	OriginDomain string
+	// Signature is the signature of the URI by its origin domain, base64
+	// encoded.
+	Signature string
+
+	// signed is the URI covered by the signature of a parsed URI, which may
+	// not be encoded exactly like String would.
+	signed string
+}
+
+// NewTransactionURI returns a tx URI requesting the signature of tx.
+func NewTransactionURI(tx *txnbuild.Transaction) (*URI, error) {
+	xdr, err := tx.Base64()
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding transaction")
+	}
+	return &URI{Operation: OperationTx, XDR: xdr}, nil
+}
+
+// NewPayURI returns a pay URI requesting a payment of amount of asset to
+// destination.
+func NewPayURI(destination, amount string, asset txnbuild.Asset) *URI {
+	u := &URI{Operation: OperationPay, Destination: destination, Amount: amount}
+	if asset != nil && !asset.IsNative() {
+		u.AssetCode = asset.GetCode()
+		u.AssetIssuer = asset.GetIssuer()
+	}
+	return u
+}
+
+// Transaction decodes the transaction of a tx URI.
+func (u *URI) Transaction() (*txnbuild.GenericTransaction, error) {
+	if u.Operation != OperationTx {
+		return nil, errors.Errorf("uri operation is %s, not %s", u.Operation, OperationTx)
+	}
+	return txnbuild.TransactionFromXDR(u.XDR)
+}
+
+// Validate returns an error if the required parameters of the operation of
+// the URI are missing or if a parameter is invalid.
+func (u *URI) Validate() error {
+	switch u.Operation {
+	case OperationTx:
+		if u.XDR == "" {
+			return errors.New("xdr is required")
+		}
+	case OperationPay:
+		if u.Destination == "" {
+			return errors.New("destination is required")
+		}
+		if u.AssetCode != "" && u.AssetIssuer == "" {
+			return errors.New("asset_issuer is required with asset_code")
+		}
+	default:
+		return errors.Errorf("unknown operation %q", u.Operation)
+	}
+	if len(u.Msg) > MaxMsgLength {
+		return errors.Errorf("msg is longer than %d characters", MaxMsgLength)
+	}
+	if u.Callback != "" {
+		if _, err := url.ParseRequestURI(u.Callback); err != nil {
+			return errors.Wrap(err, "invalid callback")
+		}
+	}
+	if u.Signature != "" && u.OriginDomain == "" {
+		return errors.New("origin_domain is required with signature")
+	}
+	return nil
+}
+
+// String returns the URI, or an error if it is invalid. The signature is the
+// last parameter, as required to verify it.
+func (u *URI) String() (string, error) {
+	unsigned, err := u.unsignedString()
+	if err != nil {
+		return "", err
+	}
+	if u.Signature == "" {
+		return unsigned, nil
+	}
+	return unsigned + "&signature=" + escape(u.Signature), nil
+}
+
+func (u *URI) unsignedString() (string, error) {
+	if err := u.Validate(); err != nil {
+		return "", err
+	}
+
+	var params [][2]string
+	add := func(key, value string) {
+		if value != "" {
+			params = append(params, [2]string{key, value})
+		}
+	}
+	switch u.Operation {
+	case OperationTx:
+		add("xdr", u.XDR)
+		add("replace", u.Replace)
+		add("pubkey", u.Pubkey)
+		add("chain", u.Chain)
+	case OperationPay:
+		add("destination", u.Destination)
+		add("amount", u.Amount)
+		add("asset_code", u.AssetCode)
+		add("asset_issuer", u.AssetIssuer)
+		add("memo", u.Memo)
+		add("memo_type", u.MemoType)
+	}
+	if u.Callback != "" {
+		add("callback", callbackPrefix+u.Callback)
+	}
+	add("msg", u.Msg)
+	add("network_passphrase", u.NetworkPassphrase)
+	add("origin_domain", u.OriginDomain)
+
+	var b strings.Builder
+	b.WriteString(Scheme)
+	b.WriteString(string(u.Operation))
+	for i, param := range params {
+		if i == 0 {
+			b.WriteByte('?')
+		} else {
+			b.WriteByte('&')
+		}
+		b.WriteString(param[0])
+		b.WriteByte('=')
+		b.WriteString(escape(param[1]))
+	}
+	return b.String(), nil
+}
+
+// escape encodes value as a query parameter, spaces included, which SEP-7
+// encodes as %20.
+func escape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// Parse parses a SEP-7 URI.
+func Parse(uri string) (*URI, error) {
+	if !strings.HasPrefix(uri, Scheme) {
+		return nil, errors.Errorf("uri scheme is not %s", Scheme)
+	}
+	operation, query, _ := strings.Cut(strings.TrimPrefix(uri, Scheme), "?")
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing uri parameters")
+	}
+
+	u := &URI{
+		Operation:         Operation(operation),
+		XDR:               values.Get("xdr"),
+		Replace:           values.Get("replace"),
+		Pubkey:            values.Get("pubkey"),
+		Chain:             values.Get("chain"),
+		Destination:       values.Get("destination"),
+		Amount:            values.Get("amount"),
+		AssetCode:         values.Get("asset_code"),
+		AssetIssuer:       values.Get("asset_issuer"),
+		Memo:              values.Get("memo"),
+		MemoType:          values.Get("memo_type"),
+		Msg:               values.Get("msg"),
+		NetworkPassphrase: values.Get("network_passphrase"),
+		OriginDomain:      values.Get("origin_domain"),
+		Signature:         values.Get("signature"),
+	}
+	if callback := values.Get("callback"); callback != "" {
+		if !strings.HasPrefix(callback, callbackPrefix) {
+			return nil, errors.Errorf("callback is not prefixed with %s", callbackPrefix)
+		}
+		u.Callback = strings.TrimPrefix(callback, callbackPrefix)
+	}
+	if u.Signature != "" {
+		i := strings.LastIndex(uri, "&signature=")
+		if i < 0 || strings.Contains(uri[i+1:], "&") {
+			return nil, errors.New("signature is not the last parameter")
+		}
+		u.signed = uri[:i]
+	}
+
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// payload returns the data signed by the origin domain for the unsigned URI.
+func payload(unsigned string) []byte {
+	data := make([]byte, 36, 36+len(signaturePrefix)+len(unsigned))
+	data[35] = 4
+	data = append(data, signaturePrefix...)
+	return append(data, unsigned...)
+}
+
+// Sign sets the signature of the URI by signer, the URI_REQUEST_SIGNING_KEY of
+// the origin domain, which must be set.
+func (u *URI) Sign(signer *keypair.Full) error {
+	if u.OriginDomain == "" {
+		return errors.New("origin_domain is required to sign")
+	}
+	u.Signature = ""
+	u.signed = ""
+	unsigned, err := u.unsignedString()
+	if err != nil {
+		return err
+	}
+	signature, err := signer.Sign(payload(unsigned))
+	if err != nil {
+		return errors.Wrap(err, "signing uri")
+	}
+	u.Signature = base64.StdEncoding.EncodeToString(signature)
+	return nil
+}
+
+// VerifySignature verifies that the URI was signed by the
+// URI_REQUEST_SIGNING_KEY of the stellar.toml of its origin domain. A parsed
+// URI is verified as it was parsed.
+func (u *URI) VerifySignature(client stellartoml.ClientInterface) error {
+	if u.Signature == "" {
+		return ErrNotSigned
+	}
+	if u.OriginDomain == "" {
+		return errors.New("origin_domain is required to verify the signature")
+	}
+
+	toml, err := client.GetStellarToml(u.OriginDomain)
+	if err != nil {
+		return errors.Wrapf(err, "fetching stellar.toml of %s", u.OriginDomain)
+	}
+	if toml.UriRequestSigningKey == "" {
+		return errors.Errorf("stellar.toml of %s has no URI_REQUEST_SIGNING_KEY", u.OriginDomain)
+	}
+	signingKey, err := keypair.ParseAddress(toml.UriRequestSigningKey)
+	if err != nil {
+		return errors.Wrap(err, "parsing URI_REQUEST_SIGNING_KEY")
+	}
+
+	unsigned := u.signed
+	if unsigned == "" {
+		if unsigned, err = u.unsignedString(); err != nil {
+			return err
+		}
+	}
+	signature, err := base64.StdEncoding.DecodeString(u.Signature)
+	if err != nil {
+		return errors.Wrap(err, "decoding signature")
+	}
+	if err := signingKey.Verify(payload(unsigned), signature); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
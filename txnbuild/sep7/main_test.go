@@ -0,0 +1,126 @@
+package sep7
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lantah/go/clients/stellartoml"
+	"github.com/lantah/go/keypair"
+	"github.com/lantah/go/network"
+	"github.com/lantah/go/txnbuild"
+)
+
+var signingKP = keypair.MustParseFull("SDQQUZMIPUP5TSDWH3UJYAKUOP55IJ4KTBXTY7RCOMEFRQGYA6GIR3OD")
+
+func TestPayURI(t *testing.T) {
+	asset := txnbuild.CreditAsset{Code: "USD", Issuer: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU"}
+	u := NewPayURI("GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR", "120.1234567", asset)
+	u.Memo = "skdjfasf"
+	u.MemoType = "MEMO_TEXT"
+	u.Msg = "pay me with lumens"
+	u.Callback = "https://example.com/callback?a=1&b=2"
+
+	s, err := u.String()
+	require.NoError(t, err)
+	assert.Equal(t,
+		"web+stellar:pay?destination=GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR"+
+			"&amount=120.1234567&asset_code=USD&asset_issuer=GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU"+
+			"&memo=skdjfasf&memo_type=MEMO_TEXT&callback=url%3Ahttps%3A%2F%2Fexample.com%2Fcallback%3Fa%3D1%26b%3D2"+
+			"&msg=pay%20me%20with%20lumens",
+		s,
+	)
+
+	parsed, err := Parse(s)
+	require.NoError(t, err)
+	assert.Equal(t, u, parsed)
+}
+
+func TestTransactionURI(t *testing.T) {
+	kp := keypair.MustParseFull("SA5ZEFDVFZ52GRU7YUGR6EDPBNRU2WLA6IQFQ7S2IH2DG3VFV3DOMV2Q")
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: kp.Address(), Sequence: 1},
+		IncrementSequenceNum: true,
+		Operations:           []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 10}},
+		BaseFee:              txnbuild.MinBaseFee,
+		Preconditions:        txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()},
+	})
+	require.NoError(t, err)
+
+	u, err := NewTransactionURI(tx)
+	require.NoError(t, err)
+	u.Pubkey = kp.Address()
+	u.NetworkPassphrase = network.TestNetworkPassphrase
+
+	s, err := u.String()
+	require.NoError(t, err)
+	parsed, err := Parse(s)
+	require.NoError(t, err)
+	assert.Equal(t, u, parsed)
+	assert.Equal(t, network.TestNetworkPassphrase, parsed.NetworkPassphrase)
+
+	generic, err := parsed.Transaction()
+	require.NoError(t, err)
+	decoded, ok := generic.Transaction()
+	require.True(t, ok)
+	assert.Equal(t, int64(2), decoded.SequenceNumber())
+}
+
+func TestURIValidation(t *testing.T) {
+	for _, testCase := range []struct {
+		name string
+		uri  string
+	}{
+		{"wrong scheme", "web+other:pay?destination=GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR"},
+		{"unknown operation", "web+stellar:sell?destination=GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR"},
+		{"missing xdr", "web+stellar:tx?pubkey=GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR"},
+		{"missing destination", "web+stellar:pay?amount=10"},
+		{"callback without prefix", "web+stellar:pay?destination=GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR&callback=https%3A%2F%2Fexample.com"},
+		{"signature without origin domain", "web+stellar:pay?destination=GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR&signature=abc"},
+		{"signature not last", "web+stellar:pay?destination=GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR&signature=abc&origin_domain=example.com"},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Parse(testCase.uri)
+			assert.Error(t, err)
+		})
+	}
+
+	u := NewPayURI("GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR", "", txnbuild.NativeAsset{})
+	u.Msg = string(make([]byte, MaxMsgLength+1))
+	_, err := u.String()
+	assert.EqualError(t, err, "msg is longer than 300 characters")
+}
+
+func TestSignature(t *testing.T) {
+	client := &stellartoml.MockClient{}
+	client.On("GetStellarToml", "example.com").
+		Return(&stellartoml.Response{UriRequestSigningKey: signingKP.Address()}, nil)
+
+	u := NewPayURI("GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR", "10", txnbuild.NativeAsset{})
+	assert.Equal(t, ErrNotSigned, u.VerifySignature(client))
+	assert.Error(t, u.Sign(signingKP), "origin_domain is required to sign")
+
+	u.OriginDomain = "example.com"
+	require.NoError(t, u.Sign(signingKP))
+	assert.NoError(t, u.VerifySignature(client))
+
+	s, err := u.String()
+	require.NoError(t, err)
+	parsed, err := Parse(s)
+	require.NoError(t, err)
+	assert.NoError(t, parsed.VerifySignature(client))
+
+	// the signature does not cover another uri
+	tampered, err := Parse(s[:len("web+stellar:pay?destination=")] +
+		"GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU" +
+		s[len("web+stellar:pay?destination=GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR"):])
+	require.NoError(t, err)
+	assert.Equal(t, ErrInvalidSignature, tampered.VerifySignature(client))
+
+	// nor is it made by another key
+	other := &stellartoml.MockClient{}
+	other.On("GetStellarToml", "example.com").
+		Return(&stellartoml.Response{UriRequestSigningKey: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU"}, nil)
+	assert.Equal(t, ErrInvalidSignature, parsed.VerifySignature(other))
+}
@@ -0,0 +1,409 @@
+package txnbuild
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sort"
+
+	"github.com/lantah/go/keypair"
+	"github.com/lantah/go/strkey"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/xdr"
+)
+
+// maxSignerWeight is the maximum weight a signer contributes to a threshold.
+const maxSignerWeight = 255
+
+// AccountSigners are the signers of an account with their weights, the master
+// key included, and the thresholds of the account.
+type AccountSigners struct {
+	Signers         SignerSummary
+	LowThreshold    Threshold
+	MediumThreshold Threshold
+	HighThreshold   Threshold
+}
+
+// AccountSignatures is the state of the signatures of an account which must
+// authorize a transaction.
+type AccountSignatures struct {
+	AccountID string
+	// Threshold is the highest threshold of the operations of the account in
+	// the transaction, and the low threshold for the source account of the
+	// transaction.
+	Threshold Threshold
+	// Weight is the total weight of the signers who signed.
+	Weight int32
+	// Signed are the signers who signed, in the order of the signatures.
+	Signed []string
+	// Missing are the signers who did not sign, by decreasing weight.
+	Missing []string
+}
+
+// Met returns true if the signers who signed meet the threshold, which always
+// takes at least one signature.
+func (s AccountSignatures) Met() bool {
+	return s.Weight > 0 && s.Weight >= int32(s.Threshold)
+}
+
+// SignatureStatus is the state of the signatures of a transaction, telling
+// who still needs to sign it.
+type SignatureStatus struct {
+	// Accounts are the accounts which must authorize the transaction, its
+	// source account first and then the source accounts of its operations.
+	Accounts []AccountSignatures
+	// MissingExtraSigners are the extra signers required by the
+	// preconditions of the transaction which did not sign it.
+	MissingExtraSigners []string
+	// UnusedSignatures are the signatures which were not made by any signer,
+	// which make the transaction fail.
+	UnusedSignatures []xdr.DecoratedSignature
+}
+
+// Complete returns true if the thresholds of all the accounts are met, all
+// the extra signers signed and all the signatures are used.
+func (s SignatureStatus) Complete() bool {
+	for _, account := range s.Accounts {
+		if !account.Met() {
+			return false
+		}
+	}
+	return len(s.MissingExtraSigners) == 0 && len(s.UnusedSignatures) == 0
+}
+
+// Unmet returns the accounts whose threshold is not met.
+func (s SignatureStatus) Unmet() []AccountSignatures {
+	var unmet []AccountSignatures
+	for _, account := range s.Accounts {
+		if !account.Met() {
+			unmet = append(unmet, account)
+		}
+	}
+	return unmet
+}
+
+// SignatureStatus returns which of the accounts authorizing the transaction
+// meet their threshold given its signatures and which signers are missing.
+// accounts maps the account IDs of the source accounts of the transaction and
+// of its operations to their signers.
+//
+// The signatures of Soroban authorization entries are not covered, only the
+// signatures of the transaction.
+func (t *Transaction) SignatureStatus(network string, accounts map[string]AccountSigners) (SignatureStatus, error) {
+	hash, err := t.Hash(network)
+	if err != nil {
+		return SignatureStatus{}, err
+	}
+
+	thresholds := map[string]Threshold{}
+	var order []string
+	require := func(accountID string, level thresholdLevel) error {
+		id, err := unmuxedAccountID(accountID)
+		if err != nil {
+			return err
+		}
+		signers, ok := accounts[id]
+		if !ok {
+			return errors.Errorf("signers of account %s are missing", id)
+		}
+		threshold := signers.threshold(level)
+		if current, ok := thresholds[id]; !ok {
+			order = append(order, id)
+			thresholds[id] = threshold
+		} else if threshold > current {
+			thresholds[id] = threshold
+		}
+		return nil
+	}
+
+	if err = require(t.sourceAccount.AccountID, thresholdLow); err != nil {
+		return SignatureStatus{}, err
+	}
+	for _, op := range t.operations {
+		sourceAccount := op.GetSourceAccount()
+		if sourceAccount == "" {
+			sourceAccount = t.sourceAccount.AccountID
+		}
+		if err = require(sourceAccount, operationThreshold(op)); err != nil {
+			return SignatureStatus{}, err
+		}
+	}
+
+	checker := newSignatureChecker(hash, t.Signatures())
+	status := SignatureStatus{}
+	for _, id := range order {
+		status.Accounts = append(status.Accounts, checker.accountSignatures(id, thresholds[id], accounts[id].Signers))
+	}
+	for _, signer := range t.preconditions.ExtraSigners {
+		if !checker.signed(signer) {
+			status.MissingExtraSigners = append(status.MissingExtraSigners, signer)
+		}
+	}
+	status.UnusedSignatures = checker.unused()
+	return status, nil
+}
+
+// SignatureStatus returns whether the fee account of the fee bump meets its
+// low threshold given the signatures of the fee bump. The signatures of the
+// inner transaction are checked by its own SignatureStatus.
+func (t *FeeBumpTransaction) SignatureStatus(network string, accounts map[string]AccountSigners) (SignatureStatus, error) {
+	hash, err := t.Hash(network)
+	if err != nil {
+		return SignatureStatus{}, err
+	}
+	id, err := unmuxedAccountID(t.FeeAccount())
+	if err != nil {
+		return SignatureStatus{}, err
+	}
+	signers, ok := accounts[id]
+	if !ok {
+		return SignatureStatus{}, errors.Errorf("signers of account %s are missing", id)
+	}
+
+	checker := newSignatureChecker(hash, t.Signatures())
+	return SignatureStatus{
+		Accounts:         []AccountSignatures{checker.accountSignatures(id, signers.LowThreshold, signers.Signers)},
+		UnusedSignatures: checker.unused(),
+	}, nil
+}
+
+// MergeSignatures returns a new Transaction instance with the signatures of
+// the current instance and of others, which are the same transaction signed
+// by other parties. Duplicate signatures are only kept once.
+func (t *Transaction) MergeSignatures(network string, others ...*Transaction) (*Transaction, error) {
+	hash, err := t.Hash(network)
+	if err != nil {
+		return nil, err
+	}
+	signatures := t.Signatures()
+	for i, other := range others {
+		otherHash, err := other.Hash(network)
+		if err != nil {
+			return nil, err
+		}
+		if otherHash != hash {
+			return nil, errors.Errorf("transaction %d to merge is a different transaction", i)
+		}
+		signatures = mergeSignatures(signatures, other.Signatures())
+	}
+	return t.clone(signatures), nil
+}
+
+// MergeSignatures returns a new FeeBumpTransaction instance with the
+// signatures of the current instance and of others, which are the same fee
+// bump signed by other parties. Duplicate signatures are only kept once.
+func (t *FeeBumpTransaction) MergeSignatures(network string, others ...*FeeBumpTransaction) (*FeeBumpTransaction, error) {
+	hash, err := t.Hash(network)
+	if err != nil {
+		return nil, err
+	}
+	signatures := t.Signatures()
+	for i, other := range others {
+		otherHash, err := other.Hash(network)
+		if err != nil {
+			return nil, err
+		}
+		if otherHash != hash {
+			return nil, errors.Errorf("transaction %d to merge is a different transaction", i)
+		}
+		signatures = mergeSignatures(signatures, other.Signatures())
+	}
+	return t.clone(signatures), nil
+}
+
+func mergeSignatures(signatures, others []xdr.DecoratedSignature) []xdr.DecoratedSignature {
+	merged := make([]xdr.DecoratedSignature, len(signatures), len(signatures)+len(others))
+	copy(merged, signatures)
+	for _, other := range others {
+		duplicate := false
+		for _, signature := range merged {
+			if signature.Hint == other.Hint && bytes.Equal(signature.Signature, other.Signature) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			merged = append(merged, other)
+		}
+	}
+	return merged
+}
+
+// thresholdLevel is the threshold of an account required by an operation.
+type thresholdLevel int
+
+const (
+	thresholdLow thresholdLevel = iota
+	thresholdMedium
+	thresholdHigh
+)
+
+func (s AccountSigners) threshold(level thresholdLevel) Threshold {
+	switch level {
+	case thresholdLow:
+		return s.LowThreshold
+	case thresholdHigh:
+		return s.HighThreshold
+	default:
+		return s.MediumThreshold
+	}
+}
+
+// operationThreshold returns the threshold of its source account required by
+// op, as defined by the protocol.
+func operationThreshold(op Operation) thresholdLevel {
+	switch op := op.(type) {
+	case *AllowTrust, *SetTrustLineFlags, *BumpSequence, *ClaimClaimableBalance,
+		*Inflation, *BumpFootprintExpiration, *RestoreFootprint:
+		return thresholdLow
+	case *AccountMerge:
+		return thresholdHigh
+	case *SetOptions:
+		if op.MasterWeight != nil || op.LowThreshold != nil || op.MediumThreshold != nil ||
+			op.HighThreshold != nil || op.Signer != nil {
+			return thresholdHigh
+		}
+		return thresholdMedium
+	default:
+		return thresholdMedium
+	}
+}
+
+func unmuxedAccountID(accountID string) (string, error) {
+	var account xdr.MuxedAccount
+	if err := account.SetAddress(accountID); err != nil {
+		return "", errors.Wrapf(err, "invalid account %s", accountID)
+	}
+	unmuxed := account.ToAccountId()
+	return unmuxed.Address(), nil
+}
+
+// signatureChecker matches the signatures of a transaction to signers.
+type signatureChecker struct {
+	hash       [32]byte
+	signatures []xdr.DecoratedSignature
+	used       []bool
+}
+
+func newSignatureChecker(hash [32]byte, signatures []xdr.DecoratedSignature) *signatureChecker {
+	return &signatureChecker{
+		hash:       hash,
+		signatures: signatures,
+		used:       make([]bool, len(signatures)),
+	}
+}
+
+func (c *signatureChecker) accountSignatures(accountID string, threshold Threshold, signers SignerSummary) AccountSignatures {
+	result := AccountSignatures{AccountID: accountID, Threshold: threshold}
+
+	keys := make([]string, 0, len(signers))
+	for key := range signers {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if signers[keys[i]] != signers[keys[j]] {
+			return signers[keys[i]] > signers[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	signedAt := map[string]int{}
+	for _, key := range keys {
+		weight := signers[key]
+		if weight <= 0 {
+			continue
+		}
+		i, ok := c.find(key)
+		if !ok {
+			result.Missing = append(result.Missing, key)
+			continue
+		}
+		signedAt[key] = i
+		result.Signed = append(result.Signed, key)
+		if weight > maxSignerWeight {
+			weight = maxSignerWeight
+		}
+		result.Weight += weight
+	}
+	sort.SliceStable(result.Signed, func(i, j int) bool {
+		return signedAt[result.Signed[i]] < signedAt[result.Signed[j]]
+	})
+	return result
+}
+
+func (c *signatureChecker) signed(signer string) bool {
+	_, ok := c.find(signer)
+	return ok
+}
+
+// find returns the index of the signature of signer, which is marked as used,
+// or -1 for a pre-authorized transaction signer matching the transaction,
+// which needs no signature. It returns false if signer did not sign.
+func (c *signatureChecker) find(signer string) (int, bool) {
+	version, err := strkey.Version(signer)
+	if err != nil {
+		return 0, false
+	}
+
+	switch version {
+	case strkey.VersionByteAccountID:
+		kp, err := keypair.ParseAddress(signer)
+		if err != nil {
+			return 0, false
+		}
+		return c.match(kp.Hint(), func(signature []byte) bool {
+			return kp.Verify(c.hash[:], signature) == nil
+		})
+	case strkey.VersionByteHashX:
+		hashX, err := strkey.Decode(strkey.VersionByteHashX, signer)
+		if err != nil {
+			return 0, false
+		}
+		var hint [4]byte
+		copy(hint[:], hashX[28:])
+		return c.match(hint, func(signature []byte) bool {
+			preimageHash := sha256.Sum256(signature)
+			return bytes.Equal(preimageHash[:], hashX)
+		})
+	case strkey.VersionByteHashTx:
+		preAuthTx, err := strkey.Decode(strkey.VersionByteHashTx, signer)
+		if err != nil {
+			return 0, false
+		}
+		return -1, bytes.Equal(preAuthTx, c.hash[:])
+	case strkey.VersionByteSignedPayload:
+		signedPayload, err := strkey.DecodeSignedPayload(signer)
+		if err != nil {
+			return 0, false
+		}
+		kp, err := keypair.ParseAddress(signedPayload.Signer())
+		if err != nil {
+			return 0, false
+		}
+		hint := xdr.NewDecoratedSignatureForPayload(nil, kp.Hint(), signedPayload.Payload()).Hint
+		return c.match(hint, func(signature []byte) bool {
+			return kp.Verify(signedPayload.Payload(), signature) == nil
+		})
+	default:
+		return 0, false
+	}
+}
+
+func (c *signatureChecker) match(hint [4]byte, verify func(signature []byte) bool) (int, bool) {
+	for i, signature := range c.signatures {
+		if signature.Hint == hint && verify(signature.Signature) {
+			c.used[i] = true
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (c *signatureChecker) unused() []xdr.DecoratedSignature {
+	var unused []xdr.DecoratedSignature
+	for i, signature := range c.signatures {
+		if !c.used[i] {
+			unused = append(unused, signature)
+		}
+	}
+	return unused
+}
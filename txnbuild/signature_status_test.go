@@ -0,0 +1,199 @@
+package txnbuild
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lantah/go/network"
+	"github.com/lantah/go/strkey"
+	"github.com/lantah/go/xdr"
+)
+
+func TestSignatureStatus(t *testing.T) {
+	kp0, kp1, kp2 := newKeypair0(), newKeypair1(), newKeypair2()
+	preimage := []byte("secret")
+	preimageHash := sha256.Sum256(preimage)
+	hashX, err := strkey.Encode(strkey.VersionByteHashX, preimageHash[:])
+	require.NoError(t, err)
+
+	accounts := map[string]AccountSigners{
+		kp0.Address(): {
+			Signers:         SignerSummary{kp0.Address(): 1, kp1.Address(): 1, kp2.Address(): 2},
+			LowThreshold:    1,
+			MediumThreshold: 2,
+			HighThreshold:   3,
+		},
+		kp1.Address(): {
+			Signers: SignerSummary{kp1.Address(): 1},
+		},
+	}
+
+	sourceAccount := NewSimpleAccount(kp0.Address(), 1)
+	tx, err := NewTransaction(TransactionParams{
+		SourceAccount:        &sourceAccount,
+		IncrementSequenceNum: true,
+		Operations: []Operation{
+			&Payment{Destination: kp1.Address(), Amount: "10", Asset: NativeAsset{}},
+			&BumpSequence{BumpTo: 100, SourceAccount: kp1.Address()},
+		},
+		BaseFee: MinBaseFee,
+		Preconditions: Preconditions{
+			TimeBounds:   NewInfiniteTimeout(),
+			ExtraSigners: []string{hashX},
+		},
+	})
+	require.NoError(t, err)
+
+	status, err := tx.SignatureStatus(network.TestNetworkPassphrase, accounts)
+	require.NoError(t, err)
+	assert.False(t, status.Complete())
+	assert.Equal(t, []AccountSignatures{
+		{
+			AccountID: kp0.Address(),
+			Threshold: 2,
+			Missing:   []string{kp2.Address(), kp1.Address(), kp0.Address()},
+		},
+		{
+			AccountID: kp1.Address(),
+			Missing:   []string{kp1.Address()},
+		},
+	}, status.Accounts)
+	assert.Equal(t, []string{hashX}, status.MissingExtraSigners)
+	assert.Empty(t, status.UnusedSignatures)
+
+	// each party signs its own copy of the transaction
+	signedBy0, err := tx.Sign(network.TestNetworkPassphrase, kp0)
+	require.NoError(t, err)
+	signedBy1, err := tx.Sign(network.TestNetworkPassphrase, kp1)
+	require.NoError(t, err)
+	signedByHashX, err := tx.SignHashX(preimage)
+	require.NoError(t, err)
+
+	merged, err := signedBy0.MergeSignatures(network.TestNetworkPassphrase, signedBy1, signedByHashX, signedBy1)
+	require.NoError(t, err)
+	assert.Len(t, merged.Signatures(), 3)
+	assert.Len(t, signedBy0.Signatures(), 1)
+
+	// kp1 signs for both accounts, which meets the medium threshold of kp0
+	// with kp0
+	status, err = merged.SignatureStatus(network.TestNetworkPassphrase, accounts)
+	require.NoError(t, err)
+	assert.True(t, status.Complete())
+	assert.Empty(t, status.Unmet())
+	assert.Equal(t, AccountSignatures{
+		AccountID: kp0.Address(),
+		Threshold: 2,
+		Weight:    2,
+		Signed:    []string{kp0.Address(), kp1.Address()},
+		Missing:   []string{kp2.Address()},
+	}, status.Accounts[0])
+	assert.Equal(t, AccountSignatures{
+		AccountID: kp1.Address(),
+		Weight:    1,
+		Signed:    []string{kp1.Address()},
+	}, status.Accounts[1])
+
+	// a signature of a key which is not a signer makes the transaction fail
+	extra, err := merged.Sign(network.TestNetworkPassphrase, newKeypair("SA5ZEFDVFZ52GRU7YUGR6EDPBNRU2WLA6IQFQ7S2IH2DG3VFV3DOMV2Q"))
+	require.NoError(t, err)
+	status, err = extra.SignatureStatus(network.TestNetworkPassphrase, accounts)
+	require.NoError(t, err)
+	assert.False(t, status.Complete())
+	assert.Equal(t, []xdr.DecoratedSignature{extra.Signatures()[3]}, status.UnusedSignatures)
+
+	// the signers of every account authorizing the transaction are required
+	delete(accounts, kp1.Address())
+	_, err = merged.SignatureStatus(network.TestNetworkPassphrase, accounts)
+	assert.EqualError(t, err, "signers of account "+kp1.Address()+" are missing")
+}
+
+func TestSignatureStatusThresholds(t *testing.T) {
+	kp0, kp1 := newKeypair0(), newKeypair1()
+	accounts := map[string]AccountSigners{
+		kp0.Address(): {
+			Signers:         SignerSummary{kp0.Address(): 1, kp1.Address(): 1},
+			LowThreshold:    1,
+			MediumThreshold: 1,
+			HighThreshold:   2,
+		},
+	}
+
+	for _, testCase := range []struct {
+		name      string
+		operation Operation
+		threshold Threshold
+	}{
+		{"low", &BumpSequence{BumpTo: 100}, 1},
+		{"medium", &SetOptions{HomeDomain: NewHomeDomain("example.com")}, 1},
+		{"high set options", &SetOptions{MasterWeight: NewThreshold(2)}, 2},
+		{"high account merge", &AccountMerge{Destination: kp1.Address()}, 2},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			sourceAccount := NewSimpleAccount(kp0.Address(), 1)
+			tx, err := NewTransaction(TransactionParams{
+				SourceAccount:        &sourceAccount,
+				IncrementSequenceNum: true,
+				Operations:           []Operation{testCase.operation},
+				BaseFee:              MinBaseFee,
+				Preconditions:        Preconditions{TimeBounds: NewInfiniteTimeout()},
+			})
+			require.NoError(t, err)
+			tx, err = tx.Sign(network.TestNetworkPassphrase, kp0)
+			require.NoError(t, err)
+
+			status, err := tx.SignatureStatus(network.TestNetworkPassphrase, accounts)
+			require.NoError(t, err)
+			require.Len(t, status.Accounts, 1)
+			assert.Equal(t, testCase.threshold, status.Accounts[0].Threshold)
+			assert.Equal(t, testCase.threshold == 1, status.Complete())
+		})
+	}
+}
+
+func TestFeeBumpSignatureStatus(t *testing.T) {
+	kp0, kp1 := newKeypair0(), newKeypair1()
+	sourceAccount := NewSimpleAccount(kp0.Address(), 1)
+	inner, err := NewTransaction(TransactionParams{
+		SourceAccount:        &sourceAccount,
+		IncrementSequenceNum: true,
+		Operations:           []Operation{&BumpSequence{BumpTo: 100}},
+		BaseFee:              MinBaseFee,
+		Preconditions:        Preconditions{TimeBounds: NewInfiniteTimeout()},
+	})
+	require.NoError(t, err)
+	inner, err = inner.Sign(network.TestNetworkPassphrase, kp0)
+	require.NoError(t, err)
+	feeBump, err := NewFeeBumpTransaction(FeeBumpTransactionParams{
+		Inner:      inner,
+		FeeAccount: kp1.Address(),
+		BaseFee:    MinBaseFee,
+	})
+	require.NoError(t, err)
+
+	accounts := map[string]AccountSigners{kp1.Address(): {Signers: SignerSummary{kp1.Address(): 1}}}
+	status, err := feeBump.SignatureStatus(network.TestNetworkPassphrase, accounts)
+	require.NoError(t, err)
+	assert.False(t, status.Complete())
+	assert.Equal(t, []string{kp1.Address()}, status.Accounts[0].Missing)
+
+	signed, err := feeBump.Sign(network.TestNetworkPassphrase, kp1)
+	require.NoError(t, err)
+	merged, err := feeBump.MergeSignatures(network.TestNetworkPassphrase, signed)
+	require.NoError(t, err)
+	status, err = merged.SignatureStatus(network.TestNetworkPassphrase, accounts)
+	require.NoError(t, err)
+	assert.True(t, status.Complete())
+
+	// only the same fee bump can be merged
+	other, err := NewFeeBumpTransaction(FeeBumpTransactionParams{
+		Inner:      inner,
+		FeeAccount: kp1.Address(),
+		BaseFee:    2 * MinBaseFee,
+	})
+	require.NoError(t, err)
+	_, err = feeBump.MergeSignatures(network.TestNetworkPassphrase, other)
+	assert.EqualError(t, err, "transaction 0 to merge is a different transaction")
+}
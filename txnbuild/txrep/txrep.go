@@ -0,0 +1,433 @@
+// Package txrep encodes transaction envelopes in txrep, the human readable
+// representation of SEP-11, and decodes them back.
+//
+// Every field of the envelope is a line "name: value" where the name is the
+// path of the field in the XDR definitions. Accounts and signer keys are
+// strkeys, assets are "native" or "CODE:ISSUER", strings are quoted and opaque
+// data is hex encoded. Enums and unions are named after the XDR definitions,
+// e.g. "tx.operations[0].body.type: PAYMENT".
+//
+// See https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0011.md
+package txrep
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	goxdr "github.com/xdrpp/goxdr/xdr"
+
+	"github.com/lantah/go/gxdr"
+	"github.com/lantah/go/support/errors"
+	"github.com/lantah/go/xdr"
+)
+
+// Marshal returns the txrep of env.
+func Marshal(env xdr.TransactionEnvelope) (txrep string, err error) {
+	raw, err := env.MarshalBinary()
+	if err != nil {
+		return "", errors.Wrap(err, "encoding transaction envelope")
+	}
+	var shape gxdr.TransactionEnvelope
+	defer recoverXdrError(&err)
+	shape.XdrMarshal(goxdr.XdrIn{In: bytes.NewReader(raw)}, "")
+
+	e := &encoder{}
+	shape.XdrMarshal(e, "")
+	return e.out.String(), nil
+}
+
+// Unmarshal decodes the transaction envelope of txrep. Every field of the
+// envelope must be present, and no other field.
+func Unmarshal(txrep string) (env xdr.TransactionEnvelope, err error) {
+	fields, err := parseFields(txrep)
+	if err != nil {
+		return env, err
+	}
+
+	var shape gxdr.TransactionEnvelope
+	d := &decoder{fields: fields, used: map[string]bool{}}
+	if err = decode(d, &shape); err != nil {
+		return env, err
+	}
+	for _, name := range fields.names {
+		if !d.used[name] {
+			return env, errors.Errorf("unexpected field %s", name)
+		}
+	}
+
+	if err = gxdr.Convert(&shape, &env); err != nil {
+		return env, errors.Wrap(err, "decoding transaction envelope")
+	}
+	return env, nil
+}
+
+func decode(d *decoder, shape *gxdr.TransactionEnvelope) (err error) {
+	defer recoverXdrError(&err)
+	shape.XdrMarshal(d, "")
+	return nil
+}
+
+// recoverXdrError turns the panics of goxdr marshaling into errors.
+func recoverXdrError(err *error) {
+	if r := recover(); r != nil {
+		switch e := r.(type) {
+		case goxdr.XdrError:
+			*err = e
+		case error:
+			*err = errors.Wrap(e, "invalid transaction envelope")
+		default:
+			panic(r)
+		}
+	}
+}
+
+func field(name, f string) string {
+	if name == "" {
+		return f
+	}
+	return name + "." + f
+}
+
+// encoder is a goxdr.XDR which writes the txrep of the values it marshals.
+type encoder struct {
+	out strings.Builder
+}
+
+func (e *encoder) Sprintf(f string, args ...interface{}) string {
+	return fmt.Sprintf(f, args...)
+}
+
+func (e *encoder) write(name, value string) {
+	e.out.WriteString(name)
+	e.out.WriteByte(':')
+	if value != "" {
+		e.out.WriteByte(' ')
+		e.out.WriteString(value)
+	}
+	e.out.WriteByte('\n')
+}
+
+func (e *encoder) Marshal(name string, v goxdr.XdrType) {
+	if value, ok := compactString(v); ok {
+		e.write(name, value)
+		return
+	}
+
+	switch t := goxdr.XdrBaseType(v).(type) {
+	case *gxdr.TransactionEnvelope:
+		// the single arm of the most common envelope is left out of the
+		// names, e.g. tx.fee rather than v1.tx.fee
+		if t.Type == gxdr.ENVELOPE_TYPE_TX {
+			e.Marshal(field(name, "type"), &t.Type)
+			t.V1().XdrRecurse(e, name)
+			return
+		}
+		t.XdrRecurse(e, name)
+	case *gxdr.XdrAnon_FeeBumpTransaction_InnerTx:
+		if t.Type == gxdr.ENVELOPE_TYPE_TX {
+			e.Marshal(field(name, "type"), &t.Type)
+			t.V1().XdrRecurse(e, name)
+			return
+		}
+		t.XdrRecurse(e, name)
+	case goxdr.XdrString:
+		e.write(name, strconv.Quote(t.GetString()))
+	case goxdr.XdrBytes:
+		e.write(name, hex.EncodeToString(t.GetByteSlice()))
+	case goxdr.XdrNum32:
+		e.write(name, t.String())
+	case goxdr.XdrNum64:
+		e.write(name, t.String())
+	case goxdr.XdrPtr:
+		e.write(field(name, "_present"), strconv.FormatBool(t.GetPresent()))
+		t.XdrMarshalValue(e, name)
+	case goxdr.XdrVec:
+		e.write(field(name, "len"), strconv.FormatUint(uint64(t.GetVecLen()), 10))
+		t.XdrMarshalN(e, name, t.GetVecLen())
+	case goxdr.XdrAggregate:
+		t.XdrRecurse(e, name)
+	default:
+		goxdr.XdrPanic("field %s has unexpected xdr type %T", name, t)
+	}
+}
+
+// decoder is a goxdr.XDR which sets the values it marshals from their
+// txrep.
+type decoder struct {
+	fields fields
+	used   map[string]bool
+}
+
+func (d *decoder) Sprintf(f string, args ...interface{}) string {
+	return fmt.Sprintf(f, args...)
+}
+
+func (d *decoder) get(name string) string {
+	value, ok := d.fields.values[name]
+	if !ok {
+		goxdr.XdrPanic("missing field %s", name)
+	}
+	d.used[name] = true
+	return value
+}
+
+func (d *decoder) scan(name string, v fmt.Scanner) {
+	if _, err := fmt.Sscan(d.get(name), v); err != nil {
+		goxdr.XdrPanic("invalid value of %s: %v", name, err)
+	}
+}
+
+func (d *decoder) Marshal(name string, v goxdr.XdrType) {
+	if _, ok := d.fields.values[name]; ok && hasCompactString(v) {
+		raw, err := parseCompactString(v, d.get(name))
+		if err != nil {
+			goxdr.XdrPanic("invalid value of %s: %v", name, err)
+		}
+		v.XdrMarshal(goxdr.XdrIn{In: bytes.NewReader(raw)}, "")
+		return
+	}
+
+	switch t := goxdr.XdrBaseType(v).(type) {
+	case *gxdr.TransactionEnvelope:
+		d.Marshal(field(name, "type"), &t.Type)
+		if t.Type == gxdr.ENVELOPE_TYPE_TX {
+			t.V1().XdrRecurse(d, name)
+			return
+		}
+		t.XdrRecurse(d, name)
+	case *gxdr.XdrAnon_FeeBumpTransaction_InnerTx:
+		d.Marshal(field(name, "type"), &t.Type)
+		if t.Type == gxdr.ENVELOPE_TYPE_TX {
+			t.V1().XdrRecurse(d, name)
+			return
+		}
+		t.XdrRecurse(d, name)
+	case goxdr.XdrString:
+		s, err := strconv.Unquote(d.get(name))
+		if err != nil {
+			goxdr.XdrPanic("invalid value of %s: %v", name, err)
+		}
+		t.SetString(s)
+	case goxdr.XdrVarBytes:
+		t.SetByteSlice(d.hex(name))
+	case goxdr.XdrBytes:
+		b := d.hex(name)
+		if len(b) != len(t.GetByteSlice()) {
+			goxdr.XdrPanic("invalid value of %s: expected %d bytes", name, len(t.GetByteSlice()))
+		}
+		copy(t.GetByteSlice(), b)
+	case goxdr.XdrNum32:
+		d.scan(name, t)
+	case goxdr.XdrNum64:
+		d.scan(name, t)
+	case goxdr.XdrPtr:
+		present, err := strconv.ParseBool(d.get(field(name, "_present")))
+		if err != nil {
+			goxdr.XdrPanic("invalid value of %s._present: %v", name, err)
+		}
+		t.SetPresent(present)
+		t.XdrMarshalValue(d, name)
+	case goxdr.XdrVec:
+		n, err := strconv.ParseUint(d.get(field(name, "len")), 10, 32)
+		if err != nil {
+			goxdr.XdrPanic("invalid value of %s.len: %v", name, err)
+		}
+		// every element has at least one field, which bounds the length
+		// before the vector is allocated
+		if uint32(n) > t.XdrBound() || int(n) > len(d.fields.names) {
+			goxdr.XdrPanic("invalid value of %s.len: %d is too long", name, n)
+		}
+		t.XdrMarshalN(d, name, uint32(n))
+	case goxdr.XdrAggregate:
+		t.XdrRecurse(d, name)
+	default:
+		goxdr.XdrPanic("field %s has unexpected xdr type %T", name, t)
+	}
+}
+
+func (d *decoder) hex(name string) []byte {
+	b, err := hex.DecodeString(d.get(name))
+	if err != nil {
+		goxdr.XdrPanic("invalid value of %s: %v", name, err)
+	}
+	return b
+}
+
+// hasCompactString returns true if values of the type of v can be written as
+// a single strkey or asset.
+func hasCompactString(v goxdr.XdrType) bool {
+	switch goxdr.XdrBaseType(v).(type) {
+	case *gxdr.PublicKey, *gxdr.MuxedAccount, *gxdr.SignerKey,
+		*gxdr.Asset, *gxdr.ChangeTrustAsset, *gxdr.TrustLineAsset:
+		return true
+	}
+	return false
+}
+
+// compactString returns the strkey or asset string of v, or false if v
+// cannot be written as such, e.g. an asset with a non canonical code.
+func compactString(v goxdr.XdrType) (string, bool) {
+	if !hasCompactString(v) {
+		return "", false
+	}
+	raw := gxdr.Dump(v)
+
+	var s string
+	var err error
+	switch t := goxdr.XdrBaseType(v).(type) {
+	case *gxdr.PublicKey:
+		var account xdr.AccountId
+		if err = account.UnmarshalBinary(raw); err == nil {
+			s, err = account.GetAddress()
+		}
+	case *gxdr.MuxedAccount:
+		var account xdr.MuxedAccount
+		if err = account.UnmarshalBinary(raw); err == nil {
+			s, err = account.GetAddress()
+		}
+	case *gxdr.SignerKey:
+		var key xdr.SignerKey
+		if err = key.UnmarshalBinary(raw); err == nil {
+			s, err = key.GetAddress()
+		}
+	case *gxdr.Asset:
+		s, err = assetString(raw)
+	case *gxdr.ChangeTrustAsset:
+		if !isAsset(t.Type) {
+			return "", false
+		}
+		s, err = assetString(raw)
+	case *gxdr.TrustLineAsset:
+		if !isAsset(t.Type) {
+			return "", false
+		}
+		s, err = assetString(raw)
+	}
+	if err != nil {
+		return "", false
+	}
+
+	// the string must decode to the same value, which is not the case of
+	// asset codes padded with anything but zeros or too short for their type
+	parsed, err := parseCompactString(v, s)
+	if err != nil || !bytes.Equal(parsed, raw) {
+		return "", false
+	}
+	return s, true
+}
+
+// isAsset returns true if assetType is the type of an asset rather than a
+// liquidity pool. The native and credit arms of asset unions are encoded
+// like an Asset.
+func isAsset(assetType gxdr.AssetType) bool {
+	switch assetType {
+	case gxdr.ASSET_TYPE_NATIVE, gxdr.ASSET_TYPE_CREDIT_ALPHANUM4, gxdr.ASSET_TYPE_CREDIT_ALPHANUM12:
+		return true
+	}
+	return false
+}
+
+func assetString(raw []byte) (string, error) {
+	var asset xdr.Asset
+	if err := asset.UnmarshalBinary(raw); err != nil {
+		return "", err
+	}
+	var assetType, code, issuer string
+	if err := asset.Extract(&assetType, &code, &issuer); err != nil {
+		return "", err
+	}
+	if asset.Type == xdr.AssetTypeAssetTypeNative {
+		return assetType, nil
+	}
+	for _, c := range code {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return "", errors.Errorf("asset code %q is not alphanumeric", code)
+		}
+	}
+	return code + ":" + issuer, nil
+}
+
+// parseCompactString returns the XDR encoding of the strkey or asset s as the
+// type of v.
+func parseCompactString(v goxdr.XdrType, s string) ([]byte, error) {
+	switch goxdr.XdrBaseType(v).(type) {
+	case *gxdr.PublicKey:
+		var account xdr.AccountId
+		if err := account.SetAddress(s); err != nil {
+			return nil, err
+		}
+		return account.MarshalBinary()
+	case *gxdr.MuxedAccount:
+		var account xdr.MuxedAccount
+		if err := account.SetAddress(s); err != nil {
+			return nil, err
+		}
+		return account.MarshalBinary()
+	case *gxdr.SignerKey:
+		var key xdr.SignerKey
+		if err := key.SetAddress(s); err != nil {
+			return nil, err
+		}
+		return key.MarshalBinary()
+	default:
+		if s == "native" {
+			return xdr.MustNewNativeAsset().MarshalBinary()
+		}
+		code, issuer, ok := strings.Cut(s, ":")
+		if !ok {
+			return nil, errors.Errorf("%q is not native nor CODE:ISSUER", s)
+		}
+		asset, err := xdr.NewCreditAsset(code, issuer)
+		if err != nil {
+			return nil, err
+		}
+		return asset.MarshalBinary()
+	}
+}
+
+// fields are the fields of a txrep, in order.
+type fields struct {
+	names  []string
+	values map[string]string
+}
+
+// parseFields parses the "name: value" lines of txrep. Empty lines are
+// ignored, as is anything following a value, e.g. a comment.
+func parseFields(txrep string) (fields, error) {
+	f := fields{values: map[string]string{}}
+	scanner := bufio.NewScanner(strings.NewReader(txrep))
+	scanner.Buffer(nil, len(txrep)+1)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			return f, errors.Errorf("line %d: missing colon", lineNumber)
+		}
+		name = strings.TrimSpace(name)
+		rest = strings.TrimSpace(rest)
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			var err error
+			if value, err = strconv.QuotedPrefix(rest); err != nil {
+				return f, errors.Errorf("line %d: invalid quoted value", lineNumber)
+			}
+		} else if values := strings.Fields(rest); len(values) > 0 {
+			value = values[0]
+		}
+
+		if _, ok := f.values[name]; ok {
+			return f, errors.Errorf("line %d: duplicate field %s", lineNumber, name)
+		}
+		f.names = append(f.names, name)
+		f.values[name] = value
+	}
+	return f, errors.Wrap(scanner.Err(), "reading txrep")
+}
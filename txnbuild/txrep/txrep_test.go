@@ -0,0 +1,140 @@
+package txrep
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goxdr "github.com/xdrpp/goxdr/xdr"
+
+	"github.com/lantah/go/gxdr"
+	"github.com/lantah/go/keypair"
+	"github.com/lantah/go/network"
+	"github.com/lantah/go/randxdr"
+	"github.com/lantah/go/txnbuild"
+	"github.com/lantah/go/xdr"
+)
+
+func TestMarshal(t *testing.T) {
+	kp := keypair.MustParseFull("SA5ZEFDVFZ52GRU7YUGR6EDPBNRU2WLA6IQFQ7S2IH2DG3VFV3DOMV2Q")
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: kp.Address(), Sequence: 1},
+		IncrementSequenceNum: true,
+		Operations: []txnbuild.Operation{&txnbuild.Payment{
+			Destination: "GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR",
+			Amount:      "10",
+			Asset: txnbuild.CreditAsset{
+				Code:   "USD",
+				Issuer: "GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU",
+			},
+		}},
+		BaseFee:       txnbuild.MinBaseFee,
+		Memo:          txnbuild.MemoText("invoice 42"),
+		Preconditions: txnbuild.Preconditions{TimeBounds: txnbuild.NewTimebounds(0, 1700000000)},
+	})
+	require.NoError(t, err)
+	tx, err = tx.Sign(network.TestNetworkPassphrase, kp)
+	require.NoError(t, err)
+	env := tx.ToXDR()
+
+	txrep, err := Marshal(env)
+	require.NoError(t, err)
+	signature := env.Signatures()[0]
+	assert.Equal(t, `type: ENVELOPE_TYPE_TX
+tx.sourceAccount: `+kp.Address()+`
+tx.fee: 100
+tx.seqNum: 2
+tx.cond.type: PRECOND_TIME
+tx.cond.timeBounds.minTime: 0
+tx.cond.timeBounds.maxTime: 1700000000
+tx.memo.type: MEMO_TEXT
+tx.memo.text: "invoice 42"
+tx.operations.len: 1
+tx.operations[0].sourceAccount._present: false
+tx.operations[0].body.type: PAYMENT
+tx.operations[0].body.paymentOp.destination: GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR
+tx.operations[0].body.paymentOp.asset: USD:GCLWGQPMKXQSPF776IU33AH4PZNOOWNAWGGKVTBQMIC5IMKUNP3E6NVU
+tx.operations[0].body.paymentOp.amount: 10000000
+tx.ext.v: 0
+signatures.len: 1
+signatures[0].hint: `+hex.EncodeToString(signature.Hint[:])+`
+signatures[0].signature: `+hex.EncodeToString(signature.Signature)+`
+`, txrep)
+
+	// blank lines and comments following the values are ignored
+	decoded, err := Unmarshal("\n" + txrep[:len(txrep)-1] + " (signed by the source account)\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, env, decoded)
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	valid := `type: ENVELOPE_TYPE_TX
+tx.sourceAccount: GAIH3ULLFQ4DGSECF2AR555KZ4KNDGEKN4AFI4SU2M7B43MGK3QJZNSR
+tx.fee: 100
+tx.seqNum: 2
+tx.cond.type: PRECOND_NONE
+tx.memo.type: MEMO_NONE
+tx.operations.len: 1
+tx.operations[0].sourceAccount._present: false
+tx.operations[0].body.type: BUMP_SEQUENCE
+tx.operations[0].body.bumpSequenceOp.bumpTo: 10
+tx.ext.v: 0
+signatures.len: 0
+`
+	_, err := Unmarshal(valid)
+	require.NoError(t, err)
+
+	for _, testCase := range []struct {
+		name     string
+		txrep    string
+		expected string
+	}{
+		{"missing field", valid[:len(valid)-len("signatures.len: 0\n")], "missing field signatures.len"},
+		{"unexpected field", valid + "tx.timeBounds._present: false\n", "unexpected field tx.timeBounds._present"},
+		{"duplicate field", valid + "tx.fee: 200\n", "line 13: duplicate field tx.fee"},
+		{"missing colon", valid + "tx.fee\n", "line 13: missing colon"},
+		{"invalid account", strings.Replace(valid, "tx.sourceAccount: GAIH", "tx.sourceAccount: GAIG", 1), "invalid value of tx.sourceAccount"},
+		{"invalid enum", strings.Replace(valid, "MEMO_NONE", "MEMO_UNKNOWN", 1), "invalid value of tx.memo.type"},
+		{"invalid number", strings.Replace(valid, "tx.fee: 100", "tx.fee: lots", 1), "invalid value of tx.fee"},
+		{"vector too long", strings.Replace(valid, "tx.operations.len: 1", "tx.operations.len: 1000", 1), "invalid value of tx.operations.len: 1000 is too long"},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Unmarshal(testCase.txrep)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.expected)
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	gen := randxdr.NewGenerator()
+	for i := 0; i < 1000; i++ {
+		shape := &gxdr.TransactionEnvelope{}
+		gen.Next(
+			shape,
+			[]randxdr.Preset{
+				{Selector: randxdr.IsDeepAuthorizedInvocationTree, Setter: randxdr.SetVecLen(0)},
+			},
+		)
+		var env xdr.TransactionEnvelope
+		require.NoError(t, gxdr.Convert(shape, &env))
+
+		txrep, err := Marshal(env)
+		require.NoError(t, err)
+		decoded, err := Unmarshal(txrep)
+		if !assert.NoError(t, err) {
+			t.Log(txrep)
+			continue
+		}
+
+		expected, err := env.MarshalBinary()
+		require.NoError(t, err)
+		actual, err := decoded.MarshalBinary()
+		require.NoError(t, err)
+		if !assert.Equal(t, expected, actual) {
+			t.Log(goxdr.XdrToString(shape))
+		}
+	}
+}